@@ -13,6 +13,8 @@ import (
 	"github.com/gardener/docforge/cmd/gendocs"
 	"github.com/gardener/docforge/cmd/hugo"
 	"github.com/gardener/docforge/cmd/version"
+	"github.com/gardener/docforge/pkg/docforge"
+	"github.com/gardener/docforge/pkg/metrics"
 	"github.com/gardener/docforge/pkg/registry/repositoryhost"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -28,9 +30,10 @@ const (
 
 // options data structure with all the options for docforge
 type options struct {
-	Options                    `mapstructure:",squash"`
+	docforge.Options           `mapstructure:",squash"`
 	hugo.Hugo                  `mapstructure:",squash"`
 	repositoryhost.InitOptions `mapstructure:",squash"`
+	Metrics                    metrics.Options `mapstructure:",squash"`
 }
 
 // NewCommand creates a new root command and propagates
@@ -47,12 +50,17 @@ func NewCommand(ctx context.Context) *cobra.Command {
 		return exec(ctx, vip)
 	}
 
-	version := version.NewVersionCmd()
-	cmd.AddCommand(version)
+	cmd.Version = version.Info()
+	cmd.SetVersionTemplate("{{.Version}}\n")
+	versionCmd := version.NewVersionCmd()
+	cmd.AddCommand(versionCmd)
 
 	genCmdDocs := gendocs.NewGenCmdDocs()
 	cmd.AddCommand(genCmdDocs)
 
+	structure := newStructureCmd(ctx)
+	cmd.AddCommand(structure)
+
 	klog.InitFlags(nil)
 	addFlags(cmd)
 
@@ -6,18 +6,17 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"sync"
+	"net/http"
+	"os"
+	"strings"
 
-	"github.com/gardener/docforge/pkg/manifest"
+	"github.com/gardener/docforge/pkg/docforge"
+	"github.com/gardener/docforge/pkg/metrics"
 	"github.com/gardener/docforge/pkg/osfakes/osshim"
-	"github.com/gardener/docforge/pkg/registry"
 	"github.com/gardener/docforge/pkg/registry/repositoryhost"
-	"github.com/gardener/docforge/pkg/workers/document"
-	"github.com/gardener/docforge/pkg/workers/githubinfo"
-	"github.com/gardener/docforge/pkg/workers/linkvalidator"
-	"github.com/gardener/docforge/pkg/workers/resourcedownloader"
-	"github.com/gardener/docforge/pkg/workers/taskqueue"
+	"github.com/gardener/docforge/pkg/writers"
 	"github.com/spf13/viper"
 	"k8s.io/klog/v2"
 )
@@ -37,63 +36,48 @@ func exec(ctx context.Context, vip *viper.Viper) error {
 	}
 	klog.Infof("Output dir: %s", options.DestinationPath)
 	if err != nil {
-		return err
+		return ErrConfig{Err: err}
 	}
-	if rhs, err = initRepositoryHosts(ctx, options.InitOptions); err != nil {
-		return err
+	if rhs, err = initRepositoryHosts(ctx, options.InitOptions, readsFileSystem(options)); err != nil {
+		return ErrConfig{Err: err}
 	}
-
-	config := getReactorConfig(options.Options, options.Hugo, rhs)
-	manifestURL := options.ManifestPath
-	var (
-		ghInfo      githubinfo.GitHubInfo
-		ghInfoTasks taskqueue.QueueController
-	)
-	reactorWG := &sync.WaitGroup{}
-
-	rhRegistry := registry.NewRegistry(append(localRH, config.RepositoryHosts...)...)
-	documentNodes, err := manifest.ResolveManifest(manifestURL, rhRegistry, options.Options.ContentFileFormats)
-	if err != nil {
-		return fmt.Errorf("failed to resolve manifest %s. %+v", config.ManifestPath, err)
+	if err = resolveLocalManifests(&options); err != nil {
+		return ErrConfig{Err: err}
 	}
-	if config.DryRun {
-		fmt.Println(documentNodes[0])
+	if options.Metrics.Address != "" {
+		server, err := metrics.Serve(options.Metrics.Address)
+		if err != nil {
+			return ErrConfig{Err: err}
+		}
+		defer server.Shutdown(context.Background())
 	}
-
-	dScheduler, downloadTasks, err := resourcedownloader.New(config.ResourceDownloadWorkersCount, config.FailFast, reactorWG, rhRegistry, config.ResourceDownloadWriter)
-	if err != nil {
-		return err
+	config := docforge.NewConfig(options.Options, options.Hugo, append(localRH, rhs...))
+	config.HTTPClient = options.Headers.Client(nil)
+	var (
+		archive *os.File
+		tw      *writers.TarWriter
+	)
+	switch options.OutputFormat {
+	case "", "dir":
+	case "tar":
+		if archive, err = os.Create(options.DestinationPath); err != nil {
+			return ErrConfig{Err: err}
+		}
+		tw = config.UseTarWriter(archive, strings.HasSuffix(options.DestinationPath, ".gz") || strings.HasSuffix(options.DestinationPath, ".tgz"))
+	default:
+		return ErrConfig{Err: fmt.Errorf("unknown output format '%s'. Must be one of [dir tar]", options.OutputFormat)}
 	}
-	v, validatorTasks, err := linkvalidator.New(config.ValidationWorkersCount, config.FailFast, reactorWG, rhRegistry, config.HostsToReport)
-	if err != nil {
-		return err
+	result, err := docforge.Run(ctx, config)
+	if options.DryRun && result != nil {
+		fmt.Println(result.Nodes[0])
 	}
-	docProcessor, docTasks, err := document.New(config.DocumentWorkersCount, config.FailFast, reactorWG, documentNodes, config.ResourcesWebsitePath, dScheduler, v, rhRegistry, config.Hugo, config.Writer, config.SkipLinkValidation)
-	if err != nil {
-		return err
+	if tw != nil {
+		err = errors.Join(err, tw.Close(), archive.Close())
 	}
-
-	qcc := taskqueue.NewQueueControllerCollection(reactorWG, downloadTasks, validatorTasks, docTasks)
-
-	if config.GitInfoWriter != nil {
-		ghInfo, ghInfoTasks, err = githubinfo.New(config.ResourceDownloadWorkersCount, config.FailFast, reactorWG, rhRegistry, config.GitInfoWriter)
-		if err != nil {
-			return err
-		}
-		for _, node := range documentNodes {
-			ghInfo.WriteGitHubInfo(node)
+	if options.Metrics.Pushgateway != "" {
+		if pushErr := metrics.Push(ctx, http.DefaultClient, options.Metrics.Pushgateway, "docforge"); pushErr != nil {
+			klog.Warning(pushErr.Error())
 		}
-		qcc.Add(ghInfoTasks)
 	}
-
-	for _, node := range documentNodes {
-		docProcessor.ProcessNode(node)
-	}
-
-	qcc.Start(ctx)
-	qcc.Wait()
-	qcc.Stop()
-	qcc.LogTaskProcessed()
-	rhRegistry.LogRateLimits(ctx)
-	return qcc.GetErrorList().ErrorOrNil()
+	return err
 }
@@ -0,0 +1,52 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"errors"
+
+	"github.com/gardener/docforge/pkg/docforge"
+	"github.com/gardener/docforge/pkg/workers/linkvalidator"
+	"github.com/google/go-github/v43/github"
+)
+
+// Exit codes of docforge for the different failure classes
+const (
+	// ExitCodeFailure is returned for failures without a specific class
+	ExitCodeFailure = -1
+	// ExitCodeBrokenLinks is returned when documents have links that have to be fixed
+	ExitCodeBrokenLinks = 2
+	// ExitCodeRateLimit is returned when a repository host API rate limit is exceeded or too low for the documents
+	ExitCodeRateLimit = 3
+	// ExitCodeConfig is returned for invalid configuration, credentials or manifests
+	ExitCodeConfig = 4
+)
+
+// ErrConfig indicates invalid configuration, credentials or manifests
+type ErrConfig = docforge.ErrConfig
+
+// ExitCode maps an error returned by the docforge command to the exit code of its failure class.
+// If the error has several classes, rate limit takes precedence over broken links and configuration
+func ExitCode(err error) int {
+	var (
+		rateLimitErr      *github.RateLimitError
+		abuseRateLimitErr *github.AbuseRateLimitError
+		brokenLinkErr     linkvalidator.ErrBrokenLink
+		configErr         ErrConfig
+		budgetErr         docforge.ErrRateLimitBudget
+	)
+	switch {
+	case err == nil:
+		return 0
+	case errors.As(err, &rateLimitErr), errors.As(err, &abuseRateLimitErr), errors.As(err, &budgetErr):
+		return ExitCodeRateLimit
+	case errors.As(err, &brokenLinkErr):
+		return ExitCodeBrokenLinks
+	case errors.As(err, &configErr):
+		return ExitCodeConfig
+	default:
+		return ExitCodeFailure
+	}
+}
@@ -0,0 +1,38 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app_test
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gardener/docforge/cmd/app"
+	"github.com/gardener/docforge/pkg/docforge"
+	"github.com/gardener/docforge/pkg/workers/linkvalidator"
+	"github.com/google/go-github/v43/github"
+	"github.com/hashicorp/go-multierror"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Exit code", func() {
+	brokenLink := linkvalidator.ErrBrokenLink{Err: errors.New("doc.md has link https://internal.example.com with host to report")}
+	rateLimit := fmt.Errorf("reading source failed: %w", &github.RateLimitError{Message: "API rate limit exceeded"})
+
+	DescribeTable("maps failure classes", func(err error, expected int) {
+		Expect(app.ExitCode(err)).To(Equal(expected))
+	},
+		Entry("no error", nil, 0),
+		Entry("unclassified error", errors.New("fake error"), app.ExitCodeFailure),
+		Entry("broken link", multierror.Append(errors.New("fake error"), brokenLink), app.ExitCodeBrokenLinks),
+		Entry("rate limit", multierror.Append(nil, rateLimit), app.ExitCodeRateLimit),
+		Entry("abuse rate limit", &github.AbuseRateLimitError{Message: "secondary rate limit"}, app.ExitCodeRateLimit),
+		Entry("rate limit budget", docforge.ErrRateLimitBudget{Err: errors.New("only 1 API calls remain")}, app.ExitCodeRateLimit),
+		Entry("config error", app.ErrConfig{Err: errors.New("no resource handlers were loaded")}, app.ExitCodeConfig),
+		Entry("rate limit while resolving manifest", app.ErrConfig{Err: fmt.Errorf("failed to resolve manifest. %w", rateLimit)}, app.ExitCodeRateLimit),
+		Entry("rate limit and broken link", multierror.Append(nil, brokenLink, rateLimit), app.ExitCodeRateLimit),
+	)
+})
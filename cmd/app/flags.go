@@ -7,7 +7,9 @@ package app
 import (
 	"os"
 	"path/filepath"
+	"time"
 
+	"github.com/gardener/docforge/pkg/registry/repositoryhost"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -17,10 +19,18 @@ func configureFlags(command *cobra.Command, vip *viper.Viper) {
 		"Destination path.")
 	_ = vip.BindPFlag("destination", command.Flags().Lookup("destination"))
 
+	command.Flags().String("output-format", "dir",
+		"Format the bundle is written in, dir for writing it into the destination dir or tar for writing it into a tar archive at the destination path. The archive is gzipped when the path ends with .gz or .tgz.")
+	_ = vip.BindPFlag("output-format", command.Flags().Lookup("output-format"))
+
 	command.Flags().StringP("manifest", "f", "",
 		"Manifest path.")
 	_ = vip.BindPFlag("manifest", command.Flags().Lookup("manifest"))
 
+	command.Flags().StringSlice("additional-manifests", []string{},
+		"Manifest paths whose structures are merged in the given order into the structure of the manifest. Top-level dirs with the same name are merged.")
+	_ = vip.BindPFlag("additional-manifests", command.Flags().Lookup("additional-manifests"))
+
 	command.Flags().String("resources-download-path", "__resources",
 		"Resources download path.")
 	_ = vip.BindPFlag("resources-download-path", command.Flags().Lookup("resources-download-path"))
@@ -33,10 +43,34 @@ func configureFlags(command *cobra.Command, vip *viper.Viper) {
 		"GitHub personal tokens authorizing read access from repositories per GitHub instance. Note that if the GitHub token is already provided by `github-oauth-token` it will be overridden by it.")
 	_ = vip.BindPFlag("github-oauth-token-map", command.Flags().Lookup("github-oauth-token-map"))
 
+	command.Flags().String("user-agent", "",
+		"User-Agent header of the requests to repository hosts, of the downloads and of the external link checks. Clients keep their default user agent if empty.")
+	_ = vip.BindPFlag("user-agent", command.Flags().Lookup("user-agent"))
+
+	command.Flags().StringToString("http-headers", map[string]string{},
+		"Headers set on the requests to repository hosts, on the downloads and on the external link checks (example: X-Api-Key=secret).")
+	_ = vip.BindPFlag("http-headers", command.Flags().Lookup("http-headers"))
+
+	command.Flags().StringSlice("http-headers-hosts", []string{},
+		"Patterns of the hosts (example: *.example.com) the http-headers are sent to, including the hosts requests are redirected to. They are sent to no host if empty.")
+	_ = vip.BindPFlag("http-headers-hosts", command.Flags().Lookup("http-headers-hosts"))
+
 	command.Flags().String("github-info-destination", "",
 		"If specified, docforge will download also additional github info for the files from the documentation structure into this destination.")
 	_ = vip.BindPFlag("github-info-destination", command.Flags().Lookup("github-info-destination"))
 
+	command.Flags().Bool("github-info-graphql", false,
+		"Reads the github info with batched GitHub GraphQL queries, falling back to the REST API for files that couldn't be queried.")
+	_ = vip.BindPFlag("github-info-graphql", command.Flags().Lookup("github-info-graphql"))
+
+	command.Flags().String("github-info-date-layout", repositoryhost.DateFormat,
+		"Go time layout of the lastmod and publishdate github info fields (example: 2006-01-02T15:04:05Z07:00 for RFC3339).")
+	_ = vip.BindPFlag("github-info-date-layout", command.Flags().Lookup("github-info-date-layout"))
+
+	command.Flags().Bool("github-info-contributors-by-identity", false,
+		"Deduplicates github info contributors by login, lowercased email or lowercased name instead of by email. GitHub noreply emails are matched with the login of the user.")
+	_ = vip.BindPFlag("github-info-contributors-by-identity", command.Flags().Lookup("github-info-contributors-by-identity"))
+
 	command.Flags().Bool("fail-fast", false,
 		"Fail-fast vs fault tolerant operation.")
 	_ = vip.BindPFlag("fail-fast", command.Flags().Lookup("fail-fast"))
@@ -49,6 +83,10 @@ func configureFlags(command *cobra.Command, vip *viper.Viper) {
 		"Number of parallel workers for document processing.")
 	_ = vip.BindPFlag("document-workers", command.Flags().Lookup("document-workers"))
 
+	command.Flags().Int("render-workers", 1,
+		"Number of sources of a document read and rendered in parallel by each document worker. The rendered sources are written in the order of the manifest.")
+	_ = vip.BindPFlag("render-workers", command.Flags().Lookup("render-workers"))
+
 	command.Flags().Int("validation-workers", 10,
 		"Number of parallel workers to validate the markdown links")
 	_ = vip.BindPFlag("validation-workers", command.Flags().Lookup("validation-workers"))
@@ -57,12 +95,48 @@ func configureFlags(command *cobra.Command, vip *viper.Viper) {
 		"Number of workers downloading document resources in parallel.")
 	_ = vip.BindPFlag("download-workers", command.Flags().Lookup("download-workers"))
 
+	command.Flags().Int("download-workers-per-host", 0,
+		"Maximum number of concurrent downloads from the same host. Zero means no limit besides --download-workers.")
+	_ = vip.BindPFlag("download-workers-per-host", command.Flags().Lookup("download-workers-per-host"))
+
+	command.Flags().Int("download-retries", 3,
+		"Number of retries with exponential backoff of resource downloads failing with transient errors (timeouts, 429 and 5xx HTTP statuses).")
+	_ = vip.BindPFlag("download-retries", command.Flags().Lookup("download-retries"))
+
+	command.Flags().Int("manifest-workers", 10,
+		"Number of workers loading the repositories referenced by the manifest and selecting the files of its file trees in parallel.")
+	_ = vip.BindPFlag("manifest-workers", command.Flags().Lookup("manifest-workers"))
+
+	command.Flags().Bool("strict-manifest", false,
+		"Fails on manifest fields that are unknown, e.g. misspelled node properties like nodeselector, instead of ignoring them.")
+	_ = vip.BindPFlag("strict-manifest", command.Flags().Lookup("strict-manifest"))
+
+	command.Flags().String("weight-prefix-pattern", "",
+		"Regular expression matching numeric file name prefixes like ^(\\d+)-. Matched prefixes are stripped from the file names and the number captured by the first group is set as hugo weight in the frontmatter.")
+	_ = vip.BindPFlag("weight-prefix-pattern", command.Flags().Lookup("weight-prefix-pattern"))
+
+	command.Flags().Bool("manifest-templates", false,
+		"Renders the manifests as Go templates before parsing them.")
+	_ = vip.BindPFlag("manifest-templates", command.Flags().Lookup("manifest-templates"))
+
+	command.Flags().StringToString("manifest-template-vars", map[string]string{},
+		"Variables available in manifest templates as .Vars (example: release=v1.90.0). Only useful with --manifest-templates=true")
+	_ = vip.BindPFlag("manifest-template-vars", command.Flags().Lookup("manifest-template-vars"))
+
+	command.Flags().StringToString("manifest-template-functions", map[string]string{},
+		"Additional manifest template functions with one argument defined by templates rendered with the argument as dot (example: repo=https://github.com/gardener/{{.}}). Only useful with --manifest-templates=true")
+	_ = vip.BindPFlag("manifest-template-functions", command.Flags().Lookup("manifest-template-functions"))
+
+	command.Flags().String("manifest-template-prereleases", "include",
+		"Treatment of prereleases (example: v1.0.0-rc.1) by the latestVersions manifest template function: include counts them like the other versions, exclude drops them and separate selects the latest prereleases in addition to the latest other versions. Only useful with --manifest-templates=true")
+	_ = vip.BindPFlag("manifest-template-prereleases", command.Flags().Lookup("manifest-template-prereleases"))
+
 	command.Flags().Bool("hugo", false,
 		"Build documentation bundle for hugo.")
 	_ = vip.BindPFlag("hugo", command.Flags().Lookup("hugo"))
 
 	command.Flags().Bool("hugo-pretty-urls", true,
-		"Build documentation bundle for hugo with pretty URLs (./sample.md -> ../sample). Only useful with --hugo=true")
+		"Build documentation bundle for hugo with pretty URLs (./sample.md -> ../sample). With --hugo-pretty-urls=false links point to .html pages (./sample.md -> ../sample.html) and sections to their index.html. Only useful with --hugo=true")
 	_ = vip.BindPFlag("hugo-pretty-urls", command.Flags().Lookup("hugo-pretty-urls"))
 
 	command.Flags().String("hugo-base-url", "",
@@ -73,10 +147,26 @@ func configureFlags(command *cobra.Command, vip *viper.Viper) {
 		"When building a Hugo-compliant documentation bundle, files with filename matching one form this list (in that order) will be renamed to _index.md. Only useful with --hugo=true")
 	_ = vip.BindPFlag("hugo-section-files", command.Flags().Lookup("hugo-section-files"))
 
+	command.Flags().String("hugo-permalink", "",
+		"Permalink pattern of the documentation files built from the tokens :section, :slug, :filename, :year, :month and :day (example: :section/:slug). Files are written to their permalinks and links are rewritten accordingly. Only useful with --hugo=true")
+	_ = vip.BindPFlag("hugo-permalink", command.Flags().Lookup("hugo-permalink"))
+
+	command.Flags().String("hugo-menu-file", "",
+		"If specified, docforge writes a Hugo menus configuration derived from the resolved structure into this file (example: config/_default/menus.yaml).")
+	_ = vip.BindPFlag("hugo-menu-file", command.Flags().Lookup("hugo-menu-file"))
+
+	command.Flags().String("hugo-menu-name", "main",
+		"Name of the Hugo menu written with --hugo-menu-file.")
+	_ = vip.BindPFlag("hugo-menu-name", command.Flags().Lookup("hugo-menu-name"))
+
 	command.Flags().StringSlice("content-files-formats", []string{".md"},
 		"Supported content format extensions (example: .md)")
 	_ = vip.BindPFlag("content-files-formats", command.Flags().Lookup("content-files-formats"))
 
+	command.Flags().String("default-file-extension", ".md",
+		"Extension of file names without one in the manifest, when the type of their source is unknown. Names get .md for markdown sources and .html for HTML sources.")
+	_ = vip.BindPFlag("default-file-extension", command.Flags().Lookup("default-file-extension"))
+
 	command.Flags().Bool("skip-link-validation", false,
 		"Links validation will be skipped")
 	_ = vip.BindPFlag("skip-link-validation", command.Flags().Lookup("skip-link-validation"))
@@ -85,6 +175,206 @@ func configureFlags(command *cobra.Command, vip *viper.Viper) {
 		"When a link has a host from the given array it will get reported")
 	_ = vip.BindPFlag("hosts-to-report", command.Flags().Lookup("hosts-to-report"))
 
+	command.Flags().StringToString("link-redirects", map[string]string{},
+		"Redirects links to moved resources to their new location. Chained redirects are followed up to max-redirect-depth.")
+	_ = vip.BindPFlag("link-redirects", command.Flags().Lookup("link-redirects"))
+
+	command.Flags().StringToString("anchor-redirects", map[string]string{},
+		"Redirects links to anchors of renamed sections to their new anchors in all documents (example: old-section=new-section). The anchorRedirects property of file nodes redirects the anchors of their document.")
+	_ = vip.BindPFlag("anchor-redirects", command.Flags().Lookup("anchor-redirects"))
+
+	command.Flags().Int("max-redirect-depth", 5,
+		"Maximum number of chained link redirects to follow, 0 for no limit. Redirect loops fail in any case.")
+	_ = vip.BindPFlag("max-redirect-depth", command.Flags().Lookup("max-redirect-depth"))
+
+	command.Flags().Bool("normalize-line-endings", true,
+		"Normalizes CRLF and CR line endings of documents to LF before processing.")
+	_ = vip.BindPFlag("normalize-line-endings", command.Flags().Lookup("normalize-line-endings"))
+
+	command.Flags().StringToString("code-language-aliases", map[string]string{},
+		"Aliases applied to fenced code block languages (example: yml=yaml,sh=bash). Languages without alias are kept.")
+	_ = vip.BindPFlag("code-language-aliases", command.Flags().Lookup("code-language-aliases"))
+
+	command.Flags().Bool("code-snippets", false,
+		"Inlines files referenced by fenced code blocks into the code block (example: ```go {file=\"main.go#L1-L10\"}), and snippet directives into a fenced code block with the language of the file extension (example: <!-- snippet: main.go#L1-L10 -->). Relative paths are resolved against the document source.")
+	_ = vip.BindPFlag("code-snippets", command.Flags().Lookup("code-snippets"))
+
+	command.Flags().Bool("frontmatter-override", true,
+		"Manifest node frontmatter overrides source document frontmatter for conflicting keys. If false, the source document frontmatter is kept. Aliases are always merged.")
+	_ = vip.BindPFlag("frontmatter-override", command.Flags().Lookup("frontmatter-override"))
+
+	command.Flags().Bool("source-frontmatter", false,
+		"Adds the source_url, source_repo, source_path and source_ref frontmatter keys describing the document source. For multiSource nodes the first source is used.")
+	_ = vip.BindPFlag("source-frontmatter", command.Flags().Lookup("source-frontmatter"))
+
+	command.Flags().Bool("edit-url", false,
+		"Adds the link for editing the document source to the document frontmatter. For multiSource nodes the first source is used.")
+	_ = vip.BindPFlag("edit-url", command.Flags().Lookup("edit-url"))
+
+	command.Flags().String("edit-url-key", "editURL",
+		"Frontmatter key of the edit link added with --edit-url.")
+	_ = vip.BindPFlag("edit-url-key", command.Flags().Lookup("edit-url-key"))
+
+	command.Flags().StringToString("host-aliases", map[string]string{"www.github.com": "github.com"},
+		"Host variants mapped to their canonical host when matching links to document sources (example: www.github.com=github.com).")
+	_ = vip.BindPFlag("host-aliases", command.Flags().Lookup("host-aliases"))
+
+	command.Flags().StringSlice("absolute-link-repos", []string{},
+		"Hosts, owners or repositories (example: github.com/gardener/docforge) whose links are kept absolute even if they refer to documents in the structure.")
+	_ = vip.BindPFlag("absolute-link-repos", command.Flags().Lookup("absolute-link-repos"))
+
+	command.Flags().StringSlice("badge-hosts", []string{"img.shields.io", "badge.fury.io", "badgen.net", "codecov.io", "goreportcard.com", "api.reuse.software", "bestpractices.coreinfrastructure.org", "www.bestpractices.dev", "github.com/*/*/actions/workflows/*/badge.svg", "github.com/*/*/workflows/*/badge.svg"},
+		"Patterns of hosts (example: *.shields.io), or of hosts and paths (example: github.com/*/*/actions/workflows/*/badge.svg), of dynamic images like badges. Their image links are kept absolute instead of downloaded or inlined.")
+	_ = vip.BindPFlag("badge-hosts", command.Flags().Lookup("badge-hosts"))
+
+	command.Flags().StringToString("lint-rules", map[string]string{},
+		"Rules the markdown documents are checked with and their severity, warning or error (example: image-alt=error,bare-url=warning). The rules are missing-heading, image-alt and bare-url. Warnings are logged and errors fail the documents.")
+	_ = vip.BindPFlag("lint-rules", command.Flags().Lookup("lint-rules"))
+
+	command.Flags().String("title-case", "title",
+		"Case of the titles derived from node names for hugo documents without a frontmatter title: title (File Node Title), sentence (File node title) or as-is (words kept as written).")
+	_ = vip.BindPFlag("title-case", command.Flags().Lookup("title-case"))
+
+	command.Flags().StringSlice("title-acronyms", []string{},
+		"Words kept as listed in the titles derived from node names regardless of the title case (example: API,gRPC,CLI).")
+	_ = vip.BindPFlag("title-acronyms", command.Flags().Lookup("title-acronyms"))
+
+	command.Flags().StringToString("title-replacements", map[string]string{},
+		"Words of node names replaced in the derived titles (example: k8s=Kubernetes,howto=How-To). Words match regardless of their case.")
+	_ = vip.BindPFlag("title-replacements", command.Flags().Lookup("title-replacements"))
+
+	command.Flags().String("rate-limit-check", "off",
+		"Check of the remaining rate limit of the repository hosts before processing the documents: off, warn or abort. The API calls are estimated from the document sources of each host, and when fewer remain, a warning with the reset time is logged or the run is aborted.")
+	_ = vip.BindPFlag("rate-limit-check", command.Flags().Lookup("rate-limit-check"))
+
+	command.Flags().StringSlice("relative-version-links", []string{},
+		"Versions, set by the version frontmatter property of documents, whose links to documents of the same version are written relative to the linking document, so they stay valid when the version is also served under a path like latest. * stands for all versions. Links to documents of other versions pin their version.")
+	_ = vip.BindPFlag("relative-version-links", command.Flags().Lookup("relative-version-links"))
+
+	command.Flags().String("coverage-report", "",
+		"If specified, docforge writes a JSON report of the document nodes that produced output and the ones that were empty, failed or skipped into this file.")
+	_ = vip.BindPFlag("coverage-report", command.Flags().Lookup("coverage-report"))
+
+	command.Flags().Bool("llms-txt", false,
+		"Writes an llms.txt index with the titles and links of the written documents into the destination.")
+	_ = vip.BindPFlag("llms-txt", command.Flags().Lookup("llms-txt"))
+
+	command.Flags().Bool("llms-full-txt", false,
+		"Writes an llms-full.txt with the concatenated content of the written documents into the destination.")
+	_ = vip.BindPFlag("llms-full-txt", command.Flags().Lookup("llms-full-txt"))
+
+	command.Flags().String("llms-title", "Documentation",
+		"Title of the llms.txt and llms-full.txt files.")
+	_ = vip.BindPFlag("llms-title", command.Flags().Lookup("llms-title"))
+
+	command.Flags().Bool("redirects", false,
+		"Writes a _redirects file of Netlify or Cloudflare Pages redirecting the aliases of the documents to their links into the destination.")
+	_ = vip.BindPFlag("redirects", command.Flags().Lookup("redirects"))
+
+	command.Flags().Bool("robots-txt", false,
+		"Writes a robots.txt into the destination. It references the sitemap of Hugo when --hugo-base-url is an absolute URL.")
+	_ = vip.BindPFlag("robots-txt", command.Flags().Lookup("robots-txt"))
+
+	command.Flags().StringSlice("robots-disallow", []string{},
+		"Website paths disallowed for all user agents in the robots.txt written with --robots-txt.")
+	_ = vip.BindPFlag("robots-disallow", command.Flags().Lookup("robots-disallow"))
+
+	command.Flags().String("noindex-frontmatter-key", "robots",
+		"Frontmatter key set to noindex in the documents of manifest nodes with the noIndex property, to exclude them from search engines.")
+	_ = vip.BindPFlag("noindex-frontmatter-key", command.Flags().Lookup("noindex-frontmatter-key"))
+
+	command.Flags().String("since", "",
+		"Base git ref e.g. a branch or a commit, or a date e.g. 2024-01-31 or 2024-01-31T15:04:05Z. Only documents whose sources changed since it are built, together with the section files of their dirs. Repositories without the ref are considered unchanged. With a date, sources are changed when their last commit is after it.")
+	_ = vip.BindPFlag("since", command.Flags().Lookup("since"))
+
+	command.Flags().String("definition-lists", "",
+		"Parses definition lists and renders them as markdown or html. Definition lists aren't parsed when empty.")
+	_ = vip.BindPFlag("definition-lists", command.Flags().Lookup("definition-lists"))
+
+	command.Flags().String("heading-style", "atx",
+		"Style headings are rendered in: atx, setext for level 1 and 2 headings, or preserve to keep the style of the source.")
+	_ = vip.BindPFlag("heading-style", command.Flags().Lookup("heading-style"))
+
+	command.Flags().String("bullet-list-marker", "",
+		"Marker bullet list items are rendered with: -, * or +. List items keep their marker when empty.")
+	_ = vip.BindPFlag("bullet-list-marker", command.Flags().Lookup("bullet-list-marker"))
+
+	command.Flags().String("ordered-list-delimiter", "",
+		"Delimiter following the numbers of ordered list items: . or ). List items keep their delimiter when empty.")
+	_ = vip.BindPFlag("ordered-list-delimiter", command.Flags().Lookup("ordered-list-delimiter"))
+
+	command.Flags().String("ordered-list-numbering", "",
+		"Numbering of ordered list items: preserve keeps the numbers of the source, renumber numbers them sequentially. Every item gets the start number of its list when empty.")
+	_ = vip.BindPFlag("ordered-list-numbering", command.Flags().Lookup("ordered-list-numbering"))
+
+	command.Flags().Int("inline-images-max-size", 0,
+		"Size in bytes up to which images are inlined in the documents as base64 data URIs instead of being downloaded. 0 disables inlining.")
+	_ = vip.BindPFlag("inline-images-max-size", command.Flags().Lookup("inline-images-max-size"))
+
+	command.Flags().Int("inline-svgs-max-size", 0,
+		"Size in bytes up to which SVG images are inlined in the documents as svg elements, without their scripts, instead of being downloaded. 0 disables inlining.")
+	_ = vip.BindPFlag("inline-svgs-max-size", command.Flags().Lookup("inline-svgs-max-size"))
+
+	command.Flags().String("inaccessible-links", "",
+		"Treatment of links to resources of repositories responding with HTTP status 401, 403 or 404, like private repositories: error, warn-and-keep-absolute or drop. Such links are only validated when not set.")
+	_ = vip.BindPFlag("inaccessible-links", command.Flags().Lookup("inaccessible-links"))
+
+	command.Flags().String("state-file", "",
+		"File recording the document nodes written and the resources downloaded by the build, so that a failed build can be resumed with --resume.")
+	_ = vip.BindPFlag("state-file", command.Flags().Lookup("state-file"))
+
+	command.Flags().Bool("resume", false,
+		"Resumes the build recorded in --state-file, skipping the document nodes written from the same sources and the resources downloaded with unchanged content.")
+	_ = vip.BindPFlag("resume", command.Flags().Lookup("resume"))
+
+	command.Flags().Bool("skip-unchanged-writes", false,
+		"Keeps the files whose content didn't change instead of rewriting them, so that their modification time is preserved.")
+	_ = vip.BindPFlag("skip-unchanged-writes", command.Flags().Lookup("skip-unchanged-writes"))
+
+	command.Flags().Bool("git-mod-times", false,
+		"Sets the modification times of the written documents to the last commit dates of their sources, as read with the github info. Requires --github-info-destination.")
+	_ = vip.BindPFlag("git-mod-times", command.Flags().Lookup("git-mod-times"))
+
+	command.Flags().String("base-path", "",
+		"Sub-directory the website is served under (example: /docs). Prefixes the root-relative links to documents, sections and downloaded resources.")
+	_ = vip.BindPFlag("base-path", command.Flags().Lookup("base-path"))
+
+	command.Flags().Bool("resource-integrity", false,
+		"Writes integrity.json into the resources dir, mapping the downloaded resources to their sha256 subresource integrity.")
+	_ = vip.BindPFlag("resource-integrity", command.Flags().Lookup("resource-integrity"))
+
+	command.Flags().Bool("sanitize-svg", false,
+		"Strips script elements, event handler attributes and javascript: links from downloaded SVG resources.")
+	_ = vip.BindPFlag("sanitize-svg", command.Flags().Lookup("sanitize-svg"))
+
+	command.Flags().String("resources-manifest", "",
+		"File listing the downloaded resources with their source URL, path, sha256 hash, size and referencing documents. Written as CSV for a .csv file, otherwise as JSON.")
+	_ = vip.BindPFlag("resources-manifest", command.Flags().Lookup("resources-manifest"))
+
+	command.Flags().Duration("document-timeout", 0,
+		"Timeout for reading and rendering each document, e.g. 30s. Documents timing out are reported as failed with a warning and the other documents are processed. 0 disables the timeout.")
+	_ = vip.BindPFlag("document-timeout", command.Flags().Lookup("document-timeout"))
+
+	command.Flags().Duration("shutdown-grace-period", 10*time.Second,
+		"Time the documents and downloads in progress are given to complete when docforge is interrupted. The completed documents are written, the queued ones are skipped.")
+	_ = vip.BindPFlag("shutdown-grace-period", command.Flags().Lookup("shutdown-grace-period"))
+
+	command.Flags().String("local-repository", "",
+		"Local directory read as the repository identified by --local-repository-url, without network access. Relative manifest paths are resolved in this directory.")
+	_ = vip.BindPFlag("local-repository", command.Flags().Lookup("local-repository"))
+
+	command.Flags().String("local-repository-url", "https://github.com/local/repository/tree/master",
+		"Tree URL of the root of the repository read from --local-repository. Links to resources in this repository are resolved locally. Only useful with --local-repository")
+	_ = vip.BindPFlag("local-repository-url", command.Flags().Lookup("local-repository-url"))
+
+	command.Flags().String("metrics-address", "",
+		"Address e.g. :9090 on which the Prometheus metrics of the run are served at /metrics while it lasts. Metrics aren't served when empty.")
+	_ = vip.BindPFlag("metrics-address", command.Flags().Lookup("metrics-address"))
+
+	command.Flags().String("metrics-pushgateway", "",
+		"URL of a Prometheus pushgateway the metrics of the run are pushed to when it ends. Metrics aren't pushed when empty.")
+	_ = vip.BindPFlag("metrics-pushgateway", command.Flags().Lookup("metrics-pushgateway"))
+
 	cacheDir := ""
 	userHomeDir, err := os.UserHomeDir()
 	if err == nil {
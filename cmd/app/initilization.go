@@ -12,20 +12,39 @@ import (
 	"path/filepath"
 	"strings"
 
-	"github.com/gardener/docforge/cmd/hugo"
+	"github.com/gardener/docforge/pkg/metrics"
+	"github.com/gardener/docforge/pkg/osfakes/httpclient"
+	"github.com/gardener/docforge/pkg/osfakes/osshim"
 	"github.com/gardener/docforge/pkg/registry/repositoryhost"
-	"github.com/gardener/docforge/pkg/writers"
 	"github.com/google/go-github/v43/github"
 	"github.com/gregjones/httpcache"
 	"github.com/gregjones/httpcache/diskcache"
 	"github.com/hashicorp/go-multierror"
 	"github.com/peterbourgon/diskv"
 	"golang.org/x/oauth2"
+	"k8s.io/klog/v2"
 )
 
-func initRepositoryHosts(ctx context.Context, o repositoryhost.InitOptions) ([]repositoryhost.Interface, error) {
+func initRepositoryHosts(ctx context.Context, o repositoryhost.InitOptions, fileSystem bool) ([]repositoryhost.Interface, error) {
 	var rhs []repositoryhost.Interface
 	var errs *multierror.Error
+	if len(o.Headers.Values) > 0 && len(o.Headers.Hosts) == 0 {
+		klog.Warningf("http-headers are not sent as no http-headers-hosts are set\n")
+	}
+	if fileSystem {
+		fs, err := repositoryhost.NewFileSystem(&osshim.OsShim{})
+		if err != nil {
+			return nil, err
+		}
+		rhs = append(rhs, fs)
+	}
+	if o.LocalRepository != "" {
+		local, err := repositoryhost.NewLocalRepository(&osshim.OsShim{}, o.LocalRepositoryURL, o.LocalRepository)
+		if err != nil {
+			return nil, err
+		}
+		rhs = append(rhs, local)
+	}
 	for host, oAuthToken := range o.Credentials {
 		instance := host
 		if !strings.HasPrefix(instance, "https://") && !strings.HasPrefix(instance, "http://") {
@@ -37,12 +56,16 @@ func initRepositoryHosts(ctx context.Context, o repositoryhost.InitOptions) ([]r
 			continue
 		}
 		cachePath := filepath.Join(o.CacheHomeDir, "diskv", host)
-		client, httpClient, err := buildClient(ctx, oAuthToken, instance, cachePath)
+		client, httpClient, err := buildClient(ctx, oAuthToken, instance, cachePath, o.Headers)
 		if err != nil {
 			errs = multierror.Append(errs, err)
 		}
-		rh := newRepositoryHost(u.Host, client, httpClient)
+		rh := newRepositoryHost(u.Host, client, httpClient, o.GitInfoOptions)
 		rhs = append(rhs, rh)
+		if u.Host == "github.com" {
+			// gists are read with the credentials of github.com
+			rhs = append(rhs, repositoryhost.NewGists(client.Gists, httpClient, []string{"gist.github.com", "gist.githubusercontent.com"}))
+		}
 	}
 	if len(rhs) == 0 {
 		return rhs, fmt.Errorf("no resource handlers were loaded. Is the config yaml file correct?")
@@ -50,13 +73,55 @@ func initRepositoryHosts(ctx context.Context, o repositoryhost.InitOptions) ([]r
 	return rhs, errs.ErrorOrNil()
 }
 
-func buildClient(ctx context.Context, accessToken string, host string, cachePath string) (*github.Client, *http.Client, error) {
+// readsFileSystem checks if a manifest is read from the file system, as a file URL or as a path without a local repository
+func readsFileSystem(o options) bool {
+	for _, manifestPath := range append([]string{o.ManifestPath}, o.AdditionalManifestPaths...) {
+		if isFileSystemManifest(manifestPath, o.LocalRepository) {
+			return true
+		}
+	}
+	return false
+}
+
+// isFileSystemManifest checks if the manifest is a file URL, or a path when there is no local repository
+func isFileSystemManifest(manifestPath string, localRepository string) bool {
+	return repositoryhost.IsFileURL(manifestPath) || (manifestPath != "" && localRepository == "" && repositoryhost.IsRelative(manifestPath))
+}
+
+// resolveLocalManifests resolves relative manifest paths in the local repository, or in the file system
+// without a local repository, and file URLs in the file system
+func resolveLocalManifests(o *options) error {
+	resolve := func(manifestPath string) (string, error) {
+		if isFileSystemManifest(manifestPath, o.LocalRepository) {
+			return repositoryhost.FileSystemResource(manifestPath, "blob")
+		}
+		if !repositoryhost.IsRelative(manifestPath) {
+			return manifestPath, nil
+		}
+		return repositoryhost.LocalRepositoryFile(o.LocalRepositoryURL, manifestPath)
+	}
+	var err error
+	if o.ManifestPath, err = resolve(o.ManifestPath); err != nil {
+		return err
+	}
+	for i := range o.AdditionalManifestPaths {
+		if o.AdditionalManifestPaths[i], err = resolve(o.AdditionalManifestPaths[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func buildClient(ctx context.Context, accessToken string, host string, cachePath string, headers httpclient.Headers) (*github.Client, *http.Client, error) {
 	base := http.DefaultTransport
 	if len(accessToken) > 0 {
 		// if token provided replace base RoundTripper
 		ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: accessToken})
 		base = oauth2.NewClient(ctx, ts).Transport
 	}
+	base = headers.RoundTripper(base)
+	// count the requests not served from cache
+	base = metrics.InstrumentRoundTripper(base)
 
 	flatTransform := func(s string) []string { return []string{} }
 	d := diskv.New(diskv.Options{
@@ -86,36 +151,10 @@ func buildClient(ctx context.Context, accessToken string, host string, cachePath
 	return client, httpClient, err
 }
 
-func newRepositoryHost(host string, client *github.Client, httpClient *http.Client) repositoryhost.Interface {
+func newRepositoryHost(host string, client *github.Client, httpClient *http.Client, gitInfoOptions repositoryhost.GitInfoOptions) repositoryhost.Interface {
 	rawHost := "raw." + host
 	if host == "github.com" {
 		rawHost = "raw.githubusercontent.com"
 	}
-	return repositoryhost.NewGHC(host, client, client.Repositories, client.Git, httpClient, []string{host, rawHost})
-}
-
-// NewReactor creates a Reactor from Options
-func getReactorConfig(options Options, hugo hugo.Hugo, rhs []repositoryhost.Interface) Config {
-	config := Config{
-		Options:         options,
-		RepositoryHosts: rhs,
-		Hugo:            hugo,
-	}
-
-	config.Writer = &writers.FSWriter{
-		Root: config.DestinationPath,
-		Hugo: config.Hugo.Enabled,
-	}
-	config.ResourceDownloadWriter = &writers.FSWriter{
-		Root: filepath.Join(config.DestinationPath, config.ResourcesDownloadPath),
-	}
-
-	if len(config.GhInfoDestination) > 0 {
-		config.GitInfoWriter = &writers.FSWriter{
-			Root: filepath.Join(config.DestinationPath, config.GhInfoDestination),
-			Ext:  "json",
-		}
-	}
-
-	return config
+	return repositoryhost.NewGHC(host, client, client.Repositories, client.Git, httpClient, []string{host, rawHost}, gitInfoOptions)
 }
@@ -0,0 +1,71 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/gardener/docforge/pkg/manifest"
+	"github.com/gardener/docforge/pkg/osfakes/osshim"
+	"github.com/gardener/docforge/pkg/registry"
+	"github.com/gardener/docforge/pkg/registry/repositoryhost"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// newStructureCmd creates a command that resolves the manifest
+// and prints the documentation structure without writing output
+func newStructureCmd(ctx context.Context) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "structure",
+		Short: "Print the resolved documentation structure",
+	}
+	vip := configure(cmd)
+	// the output-format flag of the bundle is shared with configure and selects the printed format instead
+	outputFormat := cmd.Flags().Lookup("output-format")
+	outputFormat.Usage = "Format of the printed structure. Must be one of: text or json."
+	outputFormat.DefValue = "text"
+	_ = outputFormat.Value.Set("text")
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		cmd.SilenceUsage = true
+		return printStructure(ctx, vip)
+	}
+	return cmd
+}
+
+func printStructure(ctx context.Context, vip *viper.Viper) error {
+	var options options
+	if err := vip.Unmarshal(&options); err != nil {
+		return ErrConfig{Err: err}
+	}
+	rhs, err := initRepositoryHosts(ctx, options.InitOptions, readsFileSystem(options))
+	if err != nil {
+		return ErrConfig{Err: err}
+	}
+	if err = resolveLocalManifests(&options); err != nil {
+		return ErrConfig{Err: err}
+	}
+	for resource, mapped := range options.ResourceMappings {
+		rhs = append([]repositoryhost.Interface{repositoryhost.NewLocal(&osshim.OsShim{}, resource, mapped)}, rhs...)
+	}
+	permalinks := manifest.PermalinkOptions{}
+	if options.Hugo.Enabled {
+		permalinks = manifest.PermalinkOptions{Pattern: options.Hugo.Permalink, SectionFiles: options.Hugo.IndexFileNames}
+	}
+	documentNodes, err := manifest.ResolveManifests(append([]string{options.ManifestPath}, options.AdditionalManifestPaths...), registry.NewRegistry(rhs...), options.ContentFileFormats, manifest.Options{
+		Workers:             options.ManifestWorkersCount,
+		WeightPrefixPattern: options.WeightPrefixPattern,
+		Permalinks:          permalinks,
+		Templates:           options.TemplateOptions,
+		DefaultExtension:    options.DefaultFileExtension,
+		Strict:              options.StrictManifest,
+	})
+	if err != nil {
+		return ErrConfig{Err: fmt.Errorf("failed to resolve manifest %s. %w", options.ManifestPath, err)}
+	}
+	return manifest.WriteTree(os.Stdout, documentNodes[0], vip.GetString("output-format"))
+}
@@ -10,4 +10,5 @@ type Hugo struct {
 	PrettyURLs     bool     `mapstructure:"hugo-pretty-urls"`
 	BaseURL        string   `mapstructure:"hugo-base-url"`
 	IndexFileNames []string `mapstructure:"hugo-section-files"`
+	Permalink      string   `mapstructure:"hugo-permalink"`
 }
@@ -40,6 +40,6 @@ func main() {
 		panic(err.Error())
 	}
 	if err := command.Execute(); err != nil {
-		os.Exit(-1)
+		os.Exit(app.ExitCode(err))
 	}
 }
@@ -7,22 +7,79 @@ package version
 import (
 	// for reading default version
 	_ "embed"
+	"encoding/json"
 	"fmt"
+	"io"
+	"path"
+	"reflect"
+	"runtime"
 
+	"github.com/google/go-github/v43/github"
 	"github.com/spf13/cobra"
 )
 
 // NewVersionCmd creates a version command printing
-// the binary version as reported by the pkg/version/Version
-// variable
+// the binary version, git commit and build date as reported
+// by the Version, GitCommit and BuildDate variables, along with
+// the Go version and the GitHub client version the binary is built with
 func NewVersionCmd() *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "version",
 		Short: "Print the version",
-		Run: func(cmd *cobra.Command, args []string) {
-			fmt.Println(Version)
-		},
 	}
+	cmd.Flags().String("output-format", "text",
+		"Format of the printed version. Must be one of: text or json.")
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		format, _ := cmd.Flags().GetString("output-format")
+		return Write(cmd.OutOrStdout(), format)
+	}
+	return cmd
+}
+
+// BuildInfo describes the build of the binary
+type BuildInfo struct {
+	Version      string `json:"version"`
+	GitCommit    string `json:"gitCommit"`
+	BuildDate    string `json:"buildDate"`
+	GoVersion    string `json:"goVersion"`
+	GitHubClient string `json:"githubClient"`
+}
+
+// Get returns the build info of the binary
+func Get() BuildInfo {
+	return BuildInfo{
+		Version:      Version,
+		GitCommit:    GitCommit,
+		BuildDate:    BuildDate,
+		GoVersion:    runtime.Version(),
+		GitHubClient: githubClientVersion(),
+	}
+}
+
+// githubClientVersion returns the major version of the go-github module in use, e.g. v43
+func githubClientVersion() string {
+	// the package path is github.com/google/go-github/<major>/github
+	return path.Base(path.Dir(reflect.TypeOf(github.Client{}).PkgPath()))
+}
+
+// Info returns the version, git commit and build date of the binary
+func Info() string {
+	i := Get()
+	return fmt.Sprintf("Version: %s\nGitCommit: %s\nBuildDate: %s\nGoVersion: %s\nGitHubClient: %s", i.Version, i.GitCommit, i.BuildDate, i.GoVersion, i.GitHubClient)
+}
+
+// Write writes the build info of the binary in the format
+func Write(w io.Writer, format string) error {
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(Get())
+	case "text":
+		_, err := fmt.Fprintln(w, Info())
+		return err
+	}
+	return fmt.Errorf("unknown format '%s'. Must be one of %v", format, []string{"text", "json"})
 }
 
 // Version is a global variable which is set during compile time via -ld-flags in the `go build` process.
@@ -31,3 +88,9 @@ func NewVersionCmd() *cobra.Command {
 //
 //go:embed default_version.txt
 var Version string
+
+// GitCommit is the git commit the binary is built from. It is set during compile time via -ld-flags.
+var GitCommit = "unknown"
+
+// BuildDate is the date the binary is built on. It is set during compile time via -ld-flags.
+var BuildDate = "unknown"
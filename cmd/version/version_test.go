@@ -5,6 +5,10 @@
 package version_test
 
 import (
+	"bytes"
+	"encoding/json"
+	"runtime"
+
 	"github.com/gardener/docforge/cmd/version"
 
 	. "github.com/onsi/ginkgo"
@@ -17,4 +21,45 @@ var _ = Describe("version", func() {
 			Expect(version.Version).To(Equal("binary was not built properly"))
 		})
 	})
+	Describe("version command", func() {
+		var defaultVersion, defaultGitCommit, defaultBuildDate string
+		BeforeEach(func() {
+			defaultVersion, defaultGitCommit, defaultBuildDate = version.Version, version.GitCommit, version.BuildDate
+			version.Version, version.GitCommit, version.BuildDate = "v1.2.3", "abc123", "2023-01-02T03:04:05Z"
+		})
+		AfterEach(func() {
+			version.Version, version.GitCommit, version.BuildDate = defaultVersion, defaultGitCommit, defaultBuildDate
+		})
+		It("prints the injected version, git commit and build date", func() {
+			var out bytes.Buffer
+			cmd := version.NewVersionCmd()
+			cmd.SetOut(&out)
+			cmd.SetArgs([]string{})
+			Expect(cmd.Execute()).To(Succeed())
+			Expect(out.String()).To(Equal("Version: v1.2.3\nGitCommit: abc123\nBuildDate: 2023-01-02T03:04:05Z\nGoVersion: " + runtime.Version() + "\nGitHubClient: v43\n"))
+		})
+		It("prints the build info as json", func() {
+			var out bytes.Buffer
+			cmd := version.NewVersionCmd()
+			cmd.SetOut(&out)
+			cmd.SetArgs([]string{"--output-format", "json"})
+			Expect(cmd.Execute()).To(Succeed())
+			var info map[string]string
+			Expect(json.Unmarshal(out.Bytes(), &info)).To(Succeed())
+			Expect(info).To(Equal(map[string]string{
+				"version":      "v1.2.3",
+				"gitCommit":    "abc123",
+				"buildDate":    "2023-01-02T03:04:05Z",
+				"goVersion":    runtime.Version(),
+				"githubClient": "v43",
+			}))
+		})
+		It("fails on unknown output format", func() {
+			cmd := version.NewVersionCmd()
+			cmd.SetOut(&bytes.Buffer{})
+			cmd.SetErr(&bytes.Buffer{})
+			cmd.SetArgs([]string{"--output-format", "yaml"})
+			Expect(cmd.Execute()).To(MatchError("unknown format 'yaml'. Must be one of [text json]"))
+		})
+	})
 })
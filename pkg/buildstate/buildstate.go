@@ -0,0 +1,182 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package buildstate
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/gardener/docforge/pkg/manifest"
+)
+
+// State records the document nodes written and the resources downloaded by a build in a file,
+// so that a resumed build skips them. A nil State records nothing and skips nothing
+type State struct {
+	file         string
+	resourcesDir string
+	// lock for appending to the state file
+	mux sync.Mutex
+	// nodes are the document nodes written by the previous build by node path
+	nodes map[string]Node
+	// resources are the resources downloaded by the previous build by target
+	resources map[string]Resource
+}
+
+// Node is the outcome of writing a document node, which a resumed build reports for the node instead of writing it
+type Node struct {
+	// Path is the node path
+	Path string `json:"path"`
+	// Sources identify the content of the node by its sources, which include their ref
+	Sources string `json:"sources"`
+	// Status and Reason are the coverage of the node
+	Status string `json:"status"`
+	Reason string `json:"reason,omitempty"`
+	// Title, Link and Content are the llms.txt entry of the node, which has none when Link is empty.
+	// Content is set only for llms-full.txt
+	Title   string `json:"title,omitempty"`
+	Link    string `json:"link,omitempty"`
+	Content []byte `json:"content,omitempty"`
+	// Downloads are the resources the node scheduled for download
+	Downloads []Download `json:"downloads,omitempty"`
+}
+
+// Download is a resource a document scheduled for download
+type Download struct {
+	Source   string `json:"source"`
+	Target   string `json:"target"`
+	Document string `json:"document"`
+}
+
+// Resource is a resource downloaded by a build
+type Resource struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	// SHA256 is the hex encoded sha256 hash of the resource content
+	SHA256 string `json:"sha256"`
+	// Size is the size of the resource content in bytes
+	Size int `json:"size"`
+}
+
+// record is a line of the state file
+type record struct {
+	Node     *Node     `json:"node,omitempty"`
+	Resource *Resource `json:"resource,omitempty"`
+}
+
+// Load creates the state recorded in file. When resume is set, the work recorded by the previous build is
+// loaded, otherwise the file is truncated. Downloaded resources are verified in resourcesDir
+func Load(file string, resume bool, resourcesDir string) (*State, error) {
+	s := &State{
+		file:         file,
+		resourcesDir: resourcesDir,
+		nodes:        map[string]Node{},
+		resources:    map[string]Resource{},
+	}
+	if !resume {
+		if err := os.WriteFile(file, nil, 0644); err != nil {
+			return nil, fmt.Errorf("creating build state file %s failed: %w", file, err)
+		}
+		return s, nil
+	}
+	content, err := os.ReadFile(file)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("reading build state file %s failed: %w", file, err)
+	}
+	for _, line := range bytes.Split(content, []byte("\n")) {
+		var r record
+		// a line cut off by a failed build is ignored
+		if json.Unmarshal(line, &r) != nil {
+			continue
+		}
+		if r.Node != nil {
+			s.nodes[r.Node.Path] = *r.Node
+		}
+		if r.Resource != nil {
+			s.resources[r.Resource.Target] = *r.Resource
+		}
+	}
+	return s, nil
+}
+
+// nodeSources identifies the content of a document node by its sources, which include their ref
+func nodeSources(node *manifest.Node) string {
+	return strings.Join(append([]string{node.Source, node.ExternalURL}, node.MultiSource...), " ")
+}
+
+// WrittenNode returns the outcome of writing the document node by the previous build, if it wrote it from the same sources
+func (s *State) WrittenNode(node *manifest.Node) (Node, bool) {
+	if s == nil || node.Type != "file" {
+		return Node{}, false
+	}
+	written, ok := s.nodes[node.NodePath()]
+	if !ok || written.Sources != nodeSources(node) {
+		return Node{}, false
+	}
+	return written, true
+}
+
+// RecordNode records the outcome of writing the document node
+func (s *State) RecordNode(node *manifest.Node, written Node) error {
+	if s == nil || node.Type != "file" {
+		return nil
+	}
+	written.Path = node.NodePath()
+	written.Sources = nodeSources(node)
+	return s.record(record{Node: &written}, "node", written.Path)
+}
+
+// DownloadedResource returns the resource downloaded by the previous build as target, if the target still has the
+// downloaded content
+func (s *State) DownloadedResource(target string) (Resource, bool) {
+	if s == nil {
+		return Resource{}, false
+	}
+	resource, ok := s.resources[target]
+	if !ok {
+		return Resource{}, false
+	}
+	content, err := os.ReadFile(filepath.Join(s.resourcesDir, target))
+	if err != nil {
+		return Resource{}, false
+	}
+	sum := sha256.Sum256(content)
+	if hex.EncodeToString(sum[:]) != resource.SHA256 {
+		return Resource{}, false
+	}
+	return resource, true
+}
+
+// RecordResource records the downloaded resource
+func (s *State) RecordResource(resource Resource) error {
+	if s == nil {
+		return nil
+	}
+	return s.record(record{Resource: &resource}, "resource", resource.Target)
+}
+
+func (s *State) record(r record, kind string, name string) error {
+	line, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("recording %s %s in build state file %s failed: %w", kind, name, s.file, err)
+	}
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	f, err := os.OpenFile(s.file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("recording %s %s in build state file %s failed: %w", kind, name, s.file, err)
+	}
+	defer f.Close()
+	if _, err = f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("recording %s %s in build state file %s failed: %w", kind, name, s.file, err)
+	}
+	return nil
+}
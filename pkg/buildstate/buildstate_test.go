@@ -0,0 +1,111 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package buildstate_test
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/gardener/docforge/pkg/buildstate"
+	"github.com/gardener/docforge/pkg/manifest"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Build state", func() {
+	var (
+		dir       string
+		stateFile string
+		node      *manifest.Node
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "buildstate")
+		Expect(err).NotTo(HaveOccurred())
+		stateFile = filepath.Join(dir, "state")
+		node = &manifest.Node{FileType: manifest.FileType{File: "intro.md", Source: "https://github.com/gardener/docforge/blob/v1.0.0/docs/intro.md"}, Type: "file", Path: "guides"}
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(dir)).To(Succeed())
+	})
+
+	It("skips the nodes written from the same sources", func() {
+		state, err := buildstate.Load(stateFile, false, dir)
+		Expect(err).NotTo(HaveOccurred())
+		download := buildstate.Download{Source: "https://github.com/gardener/docforge/blob/v1.0.0/docs/logo.png", Target: "logo_123456.png", Document: node.Source}
+		Expect(state.RecordNode(node, buildstate.Node{Status: "written", Title: "Intro", Link: "/guides/intro/", Downloads: []buildstate.Download{download}})).To(Succeed())
+		_, ok := state.WrittenNode(node)
+		Expect(ok).To(BeFalse())
+
+		resumed, err := buildstate.Load(stateFile, true, dir)
+		Expect(err).NotTo(HaveOccurred())
+		written, ok := resumed.WrittenNode(node)
+		Expect(ok).To(BeTrue())
+		Expect(written).To(Equal(buildstate.Node{Path: "guides/intro.md", Sources: node.Source + " ", Status: "written", Title: "Intro", Link: "/guides/intro/", Downloads: []buildstate.Download{download}}))
+		node.Source = "https://github.com/gardener/docforge/blob/v1.1.0/docs/intro.md"
+		_, ok = resumed.WrittenNode(node)
+		Expect(ok).To(BeFalse())
+	})
+
+	It("skips the resources whose content is unchanged", func() {
+		state, err := buildstate.Load(stateFile, false, dir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.WriteFile(filepath.Join(dir, "logo_123456.png"), []byte("png"), 0644)).To(Succeed())
+		// sha256 of png
+		resource := buildstate.Resource{Source: "https://github.com/gardener/docforge/blob/v1.0.0/docs/logo.png", Target: "logo_123456.png", SHA256: "8f8cbb7dcf46e0bc7d53265749a6c17d116093a6ba95e442764060c76fd4a86c", Size: 3}
+		Expect(state.RecordResource(resource)).To(Succeed())
+
+		resumed, err := buildstate.Load(stateFile, true, dir)
+		Expect(err).NotTo(HaveOccurred())
+		downloaded, ok := resumed.DownloadedResource("logo_123456.png")
+		Expect(ok).To(BeTrue())
+		Expect(downloaded).To(Equal(resource))
+		_, ok = resumed.DownloadedResource("other_123456.png")
+		Expect(ok).To(BeFalse())
+		Expect(os.WriteFile(filepath.Join(dir, "logo_123456.png"), []byte("changed"), 0644)).To(Succeed())
+		_, ok = resumed.DownloadedResource("logo_123456.png")
+		Expect(ok).To(BeFalse())
+	})
+
+	It("ignores a record cut off by a failed build", func() {
+		state, err := buildstate.Load(stateFile, false, dir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(state.RecordNode(node, buildstate.Node{Status: "written"})).To(Succeed())
+		f, err := os.OpenFile(stateFile, os.O_APPEND|os.O_WRONLY, 0644)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = f.WriteString(`{"node":{"path":"guides/setup.md","sour`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(f.Close()).To(Succeed())
+
+		resumed, err := buildstate.Load(stateFile, true, dir)
+		Expect(err).NotTo(HaveOccurred())
+		_, ok := resumed.WrittenNode(node)
+		Expect(ok).To(BeTrue())
+	})
+
+	It("starts over without resume", func() {
+		state, err := buildstate.Load(stateFile, false, dir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(state.RecordNode(node, buildstate.Node{Status: "written"})).To(Succeed())
+		restarted, err := buildstate.Load(stateFile, false, dir)
+		Expect(err).NotTo(HaveOccurred())
+		_, ok := restarted.WrittenNode(node)
+		Expect(ok).To(BeFalse())
+		content, err := os.ReadFile(stateFile)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(content).To(BeEmpty())
+	})
+
+	It("records and skips nothing when nil", func() {
+		var state *buildstate.State
+		Expect(state.RecordNode(node, buildstate.Node{Status: "written"})).To(Succeed())
+		_, ok := state.WrittenNode(node)
+		Expect(ok).To(BeFalse())
+		_, ok = state.DownloadedResource("logo_123456.png")
+		Expect(ok).To(BeFalse())
+	})
+})
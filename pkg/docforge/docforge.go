@@ -0,0 +1,514 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package docforge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gardener/docforge/cmd/hugo"
+	"github.com/gardener/docforge/pkg/buildstate"
+	"github.com/gardener/docforge/pkg/manifest"
+	"github.com/gardener/docforge/pkg/registry"
+	"github.com/gardener/docforge/pkg/registry/repositoryhost"
+	"github.com/gardener/docforge/pkg/workers/document"
+	"github.com/gardener/docforge/pkg/workers/githubinfo"
+	"github.com/gardener/docforge/pkg/workers/linkresolver"
+	"github.com/gardener/docforge/pkg/workers/linkvalidator"
+	"github.com/gardener/docforge/pkg/workers/resourcedownloader"
+	"github.com/gardener/docforge/pkg/workers/taskqueue"
+	"github.com/gardener/docforge/pkg/writers"
+	"k8s.io/klog/v2"
+)
+
+// ErrConfig indicates invalid configuration, credentials or manifests
+type ErrConfig struct {
+	Err error
+}
+
+// Error returns the underlying error message
+func (e ErrConfig) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e ErrConfig) Unwrap() error {
+	return e.Err
+}
+
+// ErrRateLimitBudget indicates a remaining rate limit of a repository host too low for the calls a run needs
+type ErrRateLimitBudget struct {
+	// Reset is the time the rate limit is reset
+	Reset time.Time
+	Err   error
+}
+
+// Error returns the underlying error message
+func (e ErrRateLimitBudget) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e ErrRateLimitBudget) Unwrap() error {
+	return e.Err
+}
+
+// Result is the outcome of a docforge run
+type Result struct {
+	// Nodes are the nodes of the resolved structure, starting with its root
+	Nodes []*manifest.Node
+	// Coverage is the outcome of processing the document nodes
+	Coverage *document.Coverage
+	// UnchangedFiles is the number of files kept because their content didn't change
+	UnchangedFiles int
+}
+
+// NewConfig creates a Config writing into the options destination path
+func NewConfig(options Options, hugo hugo.Hugo, rhs []repositoryhost.Interface) Config {
+	config := Config{
+		Options:         options,
+		RepositoryHosts: rhs,
+		Hugo:            hugo,
+	}
+
+	config.Writer = &writers.FSWriter{
+		Root:          config.DestinationPath,
+		Hugo:          config.Hugo.Enabled,
+		SkipUnchanged: config.SkipUnchangedWrites,
+	}
+	config.ResourceDownloadWriter = &writers.FSWriter{
+		Root:          filepath.Join(config.DestinationPath, config.ResourcesDownloadPath),
+		SkipUnchanged: config.SkipUnchangedWrites,
+	}
+
+	if len(config.GhInfoDestination) > 0 {
+		config.GitInfoWriter = &writers.FSWriter{
+			Root:          filepath.Join(config.DestinationPath, config.GhInfoDestination),
+			Ext:           "json",
+			SkipUnchanged: config.SkipUnchangedWrites,
+		}
+	}
+
+	return config
+}
+
+// UseTarWriter makes the config write the bundle into a tar archive streamed to w, gzipped if gzipped is set.
+// The documents, the resources and the git info have their paths relative to the destination in the archive.
+// The returned writer finishes the archive when closed after the run
+func (c *Config) UseTarWriter(w io.Writer, gzipped bool) *writers.TarWriter {
+	tw := writers.NewTarWriter(w, gzipped)
+	documents := tw.In("")
+	documents.Hugo = c.Hugo.Enabled
+	c.Writer = documents
+	c.ResourceDownloadWriter = tw.In(c.ResourcesDownloadPath)
+	c.GitInfoWriter = nil
+	if len(c.GhInfoDestination) > 0 {
+		gitInfo := tw.In(c.GhInfoDestination)
+		gitInfo.Ext = "json"
+		c.GitInfoWriter = gitInfo
+	}
+	return tw
+}
+
+// UseS3Writer makes the config write the bundle as objects of the bucket with the client. The documents, the resources and
+// the git info have their paths relative to the destination under the key prefix
+func (c *Config) UseS3Writer(client writers.S3Client, bucket string, prefix string) {
+	s3 := &writers.S3Writer{Client: client, Bucket: bucket, Prefix: prefix}
+	documents := s3.In("")
+	documents.Hugo = c.Hugo.Enabled
+	c.Writer = documents
+	c.ResourceDownloadWriter = s3.In(c.ResourcesDownloadPath)
+	c.GitInfoWriter = nil
+	if len(c.GhInfoDestination) > 0 {
+		gitInfo := s3.In(c.GhInfoDestination)
+		gitInfo.Ext = "json"
+		c.GitInfoWriter = gitInfo
+	}
+}
+
+// Run resolves the manifests of the config with its repository hosts and writes the documentation bundle.
+// Errors caused by invalid configuration or manifests are ErrConfig. The result is returned also when
+// processing documents failed
+func Run(ctx context.Context, config Config) (*Result, error) {
+	if config.Writer == nil || config.ResourceDownloadWriter == nil {
+		return nil, ErrConfig{fmt.Errorf("config has no document or resource writer")}
+	}
+	var (
+		ghInfo      githubinfo.GitHubInfo
+		ghInfoTasks taskqueue.QueueController
+	)
+	reactorWG := &sync.WaitGroup{}
+
+	client := config.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	rhRegistry := registry.NewRegistryWithClient(client, config.RepositoryHosts...)
+	permalinks := manifest.PermalinkOptions{}
+	if config.Hugo.Enabled {
+		permalinks = manifest.PermalinkOptions{Pattern: config.Hugo.Permalink, SectionFiles: config.Hugo.IndexFileNames}
+	}
+	documentNodes, err := manifest.ResolveManifests(append([]string{config.ManifestPath}, config.AdditionalManifestPaths...), rhRegistry, config.ContentFileFormats, manifest.Options{
+		Workers:             config.ManifestWorkersCount,
+		WeightPrefixPattern: config.WeightPrefixPattern,
+		Permalinks:          permalinks,
+		Templates:           config.TemplateOptions,
+		DefaultExtension:    config.DefaultFileExtension,
+		Strict:              config.StrictManifest,
+	})
+	if err != nil {
+		return nil, ErrConfig{fmt.Errorf("failed to resolve manifest %s. %w", config.ManifestPath, err)}
+	}
+	manifest.SetNoIndexFrontmatter(documentNodes, config.NoIndexFrontmatterKey)
+	if config.OnNodeResolved != nil {
+		for _, node := range documentNodes {
+			if err = config.OnNodeResolved(node); err != nil {
+				return nil, fmt.Errorf("node %s: %w", node.NodePath(), err)
+			}
+		}
+	}
+	// links are resolved to all nodes of the structure, also to the ones not processed since the base
+	resolvedNodes := documentNodes
+	if config.Since != "" {
+		changed := changedSources(ctx, rhRegistry, config.Since)
+		if date, ok := parseSinceDate(config.Since); ok {
+			changed = modifiedSources(ctx, rhRegistry, date)
+		}
+		documentNodes, err = manifest.ChangedNodes(documentNodes[0], changed, config.Hugo.IndexFileNames)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find the documents changed since %s: %w", config.Since, err)
+		}
+	}
+	if err = checkRateLimit(ctx, rhRegistry, documentNodes, config); err != nil {
+		return nil, err
+	}
+	var state *buildstate.State
+	if config.StateFile != "" {
+		if state, err = buildstate.Load(config.StateFile, config.Resume, filepath.Join(config.DestinationPath, config.ResourcesDownloadPath)); err != nil {
+			return nil, ErrConfig{err}
+		}
+	} else if config.Resume {
+		return nil, ErrConfig{errors.New("resuming a build requires a state file")}
+	}
+
+	dScheduler, downloadTasks, err := resourcedownloader.New(config.ResourceDownloadWorkersCount, config.FailFast, reactorWG, rhRegistry, config.ResourceDownloadWriter, resourcedownloader.Options{
+		Retries:       config.DownloadRetries,
+		Integrity:     config.ResourceIntegrity,
+		SanitizeSVG:   config.SanitizeSVG,
+		State:         state,
+		HostDownloads: config.HostDownloadWorkersCount,
+	})
+	if err != nil {
+		return nil, ErrConfig{err}
+	}
+	v, validatorTasks, err := linkvalidator.New(config.ValidationWorkersCount, config.FailFast, reactorWG, rhRegistry, config.HostsToReport)
+	if err != nil {
+		return nil, ErrConfig{err}
+	}
+	editURLKey := ""
+	if config.EditURL {
+		editURLKey = config.EditURLKey
+	}
+	docProcessor, docTasks, err := document.New(config.DocumentWorkersCount, config.FailFast, reactorWG, resolvedNodes, config.ResourcesWebsitePath, dScheduler, v, rhRegistry, config.Hugo, config.Writer, document.Options{
+		SkipLinkValidation:      config.SkipLinkValidation,
+		LinkRedirects:           config.LinkRedirects,
+		MaxRedirectDepth:        config.MaxRedirectDepth,
+		AnchorRedirects:         config.AnchorRedirects,
+		HostAliases:             config.HostAliases,
+		AbsoluteLinkRepos:       config.AbsoluteLinkRepos,
+		RelativeVersionLinks:    config.RelativeVersionLinks,
+		BasePath:                config.BasePath,
+		KeepLineEndings:         !config.NormalizeLineEndings,
+		CodeLanguageAliases:     config.CodeLanguageAliases,
+		KeepDocumentFrontmatter: !config.FrontmatterOverride,
+		SourceFrontmatter:       config.SourceFrontmatter,
+		CodeSnippets:            config.CodeSnippets,
+		EditURLKey:              editURLKey,
+		ContentTransforms:       config.ContentTransforms,
+		DefinitionLists:         config.DefinitionLists,
+		HeadingStyle:            config.HeadingStyle,
+		BulletListMarker:        config.BulletListMarker,
+		OrderedListDelimiter:    config.OrderedListDelimiter,
+		OrderedListNumbering:    config.OrderedListNumbering,
+		InlineImagesMaxSize:     config.InlineImagesMaxSize,
+		InlineSVGsMaxSize:       config.InlineSVGsMaxSize,
+		BadgeHosts:              config.BadgeHosts,
+		InaccessibleLinks:       config.InaccessibleLinks,
+		ResourcesDownloadPath:   config.ResourcesDownloadPath,
+		State:                   state,
+		DocumentTimeout:         config.DocumentTimeout,
+		RenderWorkers:           config.RenderWorkersCount,
+		LintRules:               config.LintRules,
+		TitleCase:               config.TitleCase,
+		TitleAcronyms:           config.TitleAcronyms,
+		TitleReplacements:       config.TitleReplacements,
+	})
+	if err != nil {
+		return nil, ErrConfig{err}
+	}
+
+	qcc := taskqueue.NewQueueControllerCollection(reactorWG, downloadTasks, validatorTasks, docTasks)
+
+	if config.GitInfoWriter != nil {
+		ghInfo, ghInfoTasks, err = githubinfo.New(config.ResourceDownloadWorkersCount, config.FailFast, reactorWG, rhRegistry, config.GitInfoWriter)
+		if err != nil {
+			return nil, ErrConfig{err}
+		}
+		if config.GitHubInfoGraphQL {
+			ghInfo.Prefetch(ctx, documentNodes)
+		}
+		for _, node := range documentNodes {
+			ghInfo.WriteGitHubInfo(node)
+		}
+		qcc.Add(ghInfoTasks)
+	}
+
+	docProcessor.LLMsIndex().Full = config.LLMsFullTxt
+	for _, node := range documentNodes {
+		docProcessor.ProcessNode(node)
+	}
+
+	workCtx, cancelWork := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancelWork()
+	go stopOnCancel(ctx, workCtx, cancelWork, qcc, config.ShutdownGracePeriod)
+	qcc.Start(workCtx)
+	qcc.Wait()
+	cancelWork()
+	qcc.Stop()
+	qcc.LogTaskProcessed()
+	docProcessor.Coverage().LogCoverage()
+	result := &Result{Nodes: documentNodes, Coverage: docProcessor.Coverage()}
+	if ctx.Err() != nil {
+		return result, fmt.Errorf("build interrupted: %w", ctx.Err())
+	}
+	if config.GitModTimes {
+		if err = setModTimes(config, ghInfo, documentNodes); err != nil {
+			return result, err
+		}
+	}
+	if config.CoverageReport != "" {
+		report, err := docProcessor.Coverage().Report()
+		if err != nil {
+			return result, err
+		}
+		if err = os.WriteFile(config.CoverageReport, report, 0644); err != nil {
+			return result, fmt.Errorf("failed to write coverage report %s: %w", config.CoverageReport, err)
+		}
+	}
+	if config.HugoMenuFile != "" {
+		var menu bytes.Buffer
+		if err = manifest.WriteHugoMenu(&menu, documentNodes[0], config.HugoMenuName, config.Hugo.IndexFileNames); err != nil {
+			return result, err
+		}
+		if err = os.WriteFile(config.HugoMenuFile, menu.Bytes(), 0644); err != nil {
+			return result, fmt.Errorf("failed to write hugo menu %s: %w", config.HugoMenuFile, err)
+		}
+	}
+	if config.ResourcesManifest != "" {
+		report, err := resourcedownloader.ResourcesReport(dScheduler.Resources(), strings.EqualFold(filepath.Ext(config.ResourcesManifest), ".csv"))
+		if err != nil {
+			return result, err
+		}
+		if err = os.WriteFile(config.ResourcesManifest, report, 0644); err != nil {
+			return result, fmt.Errorf("failed to write resources manifest %s: %w", config.ResourcesManifest, err)
+		}
+	}
+	if config.ResourceIntegrity {
+		integrity, err := json.MarshalIndent(dScheduler.Integrity(), "", "  ")
+		if err != nil {
+			return result, err
+		}
+		if err = config.ResourceDownloadWriter.Write("integrity.json", "", integrity, nil, nil); err != nil {
+			return result, err
+		}
+	}
+	if config.LLMsTxt {
+		if err = config.Writer.Write("llms.txt", "", docProcessor.LLMsIndex().Index(config.LLMsTitle), nil, nil); err != nil {
+			return result, err
+		}
+	}
+	if config.LLMsFullTxt {
+		if err = config.Writer.Write("llms-full.txt", "", docProcessor.LLMsIndex().FullIndex(config.LLMsTitle), nil, nil); err != nil {
+			return result, err
+		}
+	}
+	if config.Redirects {
+		if err = config.Writer.Write("_redirects", "", linkresolver.Redirects(documentNodes, config.BasePath, config.Hugo), nil, nil); err != nil {
+			return result, err
+		}
+	}
+	if config.RobotsTxt {
+		if err = config.Writer.Write("robots.txt", "", linkresolver.RobotsTxt(config.RobotsDisallow, config.BasePath, config.Hugo), nil, nil); err != nil {
+			return result, err
+		}
+	}
+	if config.SkipUnchangedWrites {
+		for _, w := range []writers.Writer{config.Writer, config.ResourceDownloadWriter, config.GitInfoWriter} {
+			if counter, ok := w.(interface{ Unchanged() int }); ok {
+				result.UnchangedFiles += counter.Unchanged()
+			}
+		}
+		klog.Infof("%d files unchanged\n", result.UnchangedFiles)
+	}
+	rhRegistry.LogRateLimits(ctx)
+	return result, qcc.GetErrorList().ErrorOrNil()
+}
+
+// stopOnCancel stops the queues when ctx is canceled, so that the queued tasks are skipped, and cancels workCtx of the
+// tasks in progress when they don't complete within the grace period. The tasks in progress can still add tasks, which
+// the stopped queues skip
+func stopOnCancel(ctx context.Context, workCtx context.Context, cancelWork context.CancelFunc, qcc *taskqueue.QueueControllerCollection, gracePeriod time.Duration) {
+	select {
+	case <-workCtx.Done():
+		return
+	case <-ctx.Done():
+	}
+	klog.Warningf("build interrupted, waiting up to %s for the documents and downloads in progress\n", gracePeriod)
+	qcc.Stop()
+	select {
+	case <-workCtx.Done():
+	case <-time.After(gracePeriod):
+		cancelWork()
+	}
+}
+
+// setModTimes sets the modification times of the written documents to the last modified dates of their git info.
+// Documents without git info keep their modification time
+func setModTimes(config Config, ghInfo githubinfo.GitHubInfo, documentNodes []*manifest.Node) error {
+	w, ok := config.Writer.(interface {
+		SetModTime(name, path string, IndexFileNames []string, modTime time.Time) error
+	})
+	if ghInfo == nil || !ok {
+		klog.Warning("modification times of the documents are set only when writing github info to the file system\n")
+		return nil
+	}
+	for _, node := range documentNodes {
+		if lastModified, ok := ghInfo.LastModified(node); ok {
+			if err := w.SetModTime(node.Name(), node.Path, config.Hugo.IndexFileNames, lastModified); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkRateLimit compares the remaining rate limit of each repository host with the calls estimated for the documents
+// read from it, a read and a git info request per source, and warns or fails as configured when the remaining calls
+// are fewer. The calls resolving the manifests, loading the repositories and reading the ignore files of their file
+// trees, are made before the check and are already counted by the remaining calls
+func checkRateLimit(ctx context.Context, r registry.Interface, documentNodes []*manifest.Node, config Config) error {
+	switch config.RateLimitCheck {
+	case "", RateLimitCheckOff:
+		return nil
+	case RateLimitCheckWarn, RateLimitCheckAbort:
+	default:
+		return ErrConfig{fmt.Errorf("rate limit check can be %s, %s or %s, not %s", RateLimitCheckOff, RateLimitCheckWarn, RateLimitCheckAbort, config.RateLimitCheck)}
+	}
+	var sources []string
+	for _, node := range documentNodes {
+		if node.HasContent() {
+			sources = append(sources, node.MultiSource...)
+			if node.Source != "" {
+				sources = append(sources, node.Source)
+			}
+		}
+	}
+	rateLimits, err := r.RateLimits(ctx, sources)
+	if err != nil {
+		klog.Warningf("skipping rate limit check: %v\n", err)
+		return nil
+	}
+	callsPerSource := 1
+	if config.GitInfoWriter != nil {
+		callsPerSource = 2
+	}
+	for _, rateLimit := range rateLimits {
+		required := rateLimit.Resources * callsPerSource
+		if required <= rateLimit.Remaining {
+			continue
+		}
+		err = fmt.Errorf("the documents need about %d API calls of %s but only %d remain until the rate limit is reset at %s", required, rateLimit.Host, rateLimit.Remaining, rateLimit.Reset.Format(time.RFC3339))
+		if config.RateLimitCheck == RateLimitCheckWarn {
+			klog.Warningf("%v\n", err)
+			continue
+		}
+		return ErrRateLimitBudget{rateLimit.Reset, err}
+	}
+	return nil
+}
+
+// parseSinceDate parses since as a date, which is either an RFC3339 timestamp or a day
+func parseSinceDate(since string) (time.Time, bool) {
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		if date, err := time.Parse(layout, since); err == nil {
+			return date, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// modifiedSources returns a check if a source was last modified after the date as reported by its git info.
+// Sources without commits are unchanged
+func modifiedSources(ctx context.Context, r registry.Interface, date time.Time) func(string) (bool, error) {
+	return func(source string) (bool, error) {
+		content, err := r.ReadGitInfo(ctx, source)
+		if err != nil || content == nil {
+			return false, err
+		}
+		var info repositoryhost.GitInfo
+		if err = json.Unmarshal(content, &info); err != nil {
+			return false, err
+		}
+		if info.LastModifiedDate == nil {
+			return false, nil
+		}
+		layout := r.GitInfoOptions(source).DateLayout
+		if layout == "" {
+			layout = repositoryhost.DateFormat
+		}
+		lastModified, err := time.Parse(layout, *info.LastModifiedDate)
+		if err != nil {
+			return false, fmt.Errorf("parsing the last modified date of %s failed: %w", source, err)
+		}
+		return lastModified.After(date), nil
+	}
+}
+
+// changedSources returns a check if a source changed since the base ref. The changes of each repository reference
+// are compared once and repositories without the base ref are unchanged
+func changedSources(ctx context.Context, r registry.Interface, base string) func(string) (bool, error) {
+	changes := map[string]map[string]bool{}
+	return func(source string) (bool, error) {
+		url, err := r.ResourceURL(source)
+		if err != nil {
+			return false, err
+		}
+		ref := url.ReferenceURL().String()
+		if _, ok := changes[ref]; !ok {
+			files, err := r.ChangedFiles(ctx, source, base)
+			var notFound repositoryhost.ErrResourceNotFound
+			if errors.As(err, &notFound) {
+				klog.Warningf("base %s not found in %s, its files are considered unchanged", base, ref)
+			} else if err != nil {
+				return false, err
+			}
+			changes[ref] = map[string]bool{}
+			for _, file := range files {
+				changes[ref][file] = true
+			}
+		}
+		return changes[ref][url.ResourceURL()], nil
+	}
+}
@@ -0,0 +1,598 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package docforge_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"embed"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gardener/docforge/cmd/hugo"
+	"github.com/gardener/docforge/pkg/docforge"
+	"github.com/gardener/docforge/pkg/manifest"
+	"github.com/gardener/docforge/pkg/metrics"
+	"github.com/gardener/docforge/pkg/osfakes/httpclient"
+	"github.com/gardener/docforge/pkg/osfakes/osshim"
+	"github.com/gardener/docforge/pkg/registry/repositoryhost"
+	"github.com/gardener/docforge/pkg/registry/repositoryhost/repositoryhostfakes"
+	"github.com/gardener/docforge/pkg/workers/document"
+	"github.com/gardener/docforge/pkg/writers"
+	"github.com/gardener/docforge/pkg/writers/writersfakes"
+	"github.com/google/go-github/v43/github"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+//go:embed tests/*
+var repo embed.FS
+
+// changedHost simulates the diff of a repository host
+type changedHost struct {
+	repositoryhost.Interface
+	changed []string
+	err     error
+}
+
+func (h *changedHost) ChangedFiles(_ context.Context, _ repositoryhost.URL, _ string) ([]string, error) {
+	return h.changed, h.err
+}
+
+// gitInfoHost simulates the commits of the files of a repository host by path
+type gitInfoHost struct {
+	repositoryhost.Interface
+	commitDates map[string]string
+}
+
+func (h *gitInfoHost) Repositories() repositoryhost.Repositories {
+	repositories := &repositoryhostfakes.FakeRepositories{}
+	repositories.ListCommitsCalls(func(_ context.Context, _ string, _ string, opts *github.CommitsListOptions) ([]*github.RepositoryCommit, *github.Response, error) {
+		date, ok := h.commitDates[opts.Path]
+		if !ok {
+			return nil, nil, nil
+		}
+		committed, err := time.Parse(time.RFC3339, date)
+		if err != nil {
+			return nil, nil, err
+		}
+		return []*github.RepositoryCommit{{
+			Commit: &github.Commit{Committer: &github.CommitAuthor{Date: &committed}},
+			Author: &github.User{Login: github.String("author"), Type: github.String("User")},
+		}}, nil, nil
+	})
+	return repositories
+}
+
+// rateLimitedHost simulates the remaining rate limit of a repository host
+type rateLimitedHost struct {
+	repositoryhost.Interface
+	remaining int
+	reset     time.Time
+}
+
+func (h *rateLimitedHost) GetRateLimit(_ context.Context) (int, int, time.Time, error) {
+	return 5000, h.remaining, h.reset, nil
+}
+
+// cancelingHost cancels the build when the file named cancelOn is read
+type cancelingHost struct {
+	repositoryhost.Interface
+	cancelOn string
+	cancel   context.CancelFunc
+}
+
+func (h *cancelingHost) Read(ctx context.Context, resource repositoryhost.URL) ([]byte, error) {
+	if path.Base(resource.GetResourcePath()) == h.cancelOn {
+		h.cancel()
+	}
+	return h.Interface.Read(ctx, resource)
+}
+
+// headersTransport records the headers of the requests and responds with 200 OK
+type headersTransport struct {
+	mux     sync.Mutex
+	headers map[string]http.Header
+}
+
+func (t *headersTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mux.Lock()
+	defer t.mux.Unlock()
+	t.headers[req.URL.Host] = req.Header.Clone()
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("")), Request: req}, nil
+}
+
+// recordingWriter records the names of the written files and fails writing the file named fail. It calls
+// afterWrite with the name of each written file
+type recordingWriter struct {
+	writers.Writer
+	fail       string
+	written    []string
+	afterWrite func(name string)
+}
+
+func (w *recordingWriter) Write(name, path string, content []byte, node *manifest.Node, indexFileNames []string) error {
+	if w.fail != "" && name == w.fail {
+		return errors.New("disk full")
+	}
+	w.written = append(w.written, name)
+	if err := w.Writer.Write(name, path, content, node, indexFileNames); err != nil {
+		return err
+	}
+	if w.afterWrite != nil {
+		w.afterWrite(name)
+	}
+	return nil
+}
+
+var _ = Describe("Docforge", func() {
+	var (
+		destination string
+		options     docforge.Options
+		rhs         []repositoryhost.Interface
+	)
+
+	BeforeEach(func() {
+		var err error
+		destination, err = os.MkdirTemp("", "docforge")
+		Expect(err).NotTo(HaveOccurred())
+		options = docforge.Options{
+			DocumentWorkersCount:         1,
+			ValidationWorkersCount:       1,
+			ResourceDownloadWorkersCount: 1,
+			ManifestWorkersCount:         1,
+			DestinationPath:              destination,
+			ManifestPath:                 "https://github.com/gardener/docforge/blob/master/manifest.yaml",
+			ContentFileFormats:           []string{".md"},
+		}
+		rhs = []repositoryhost.Interface{repositoryhost.NewLocalTest(repo, "https://github.com/gardener/docforge", "tests")}
+	})
+
+	AfterEach(func() {
+		Expect(os.RemoveAll(destination)).To(Succeed())
+	})
+
+	Describe("#Run", func() {
+		It("writes the documentation bundle", func() {
+			result, err := docforge.Run(context.TODO(), docforge.NewConfig(options, hugo.Hugo{Enabled: true, PrettyURLs: true}, rhs))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Nodes[0].Structure).To(HaveLen(1))
+			Expect(result.Coverage.Counts()).To(Equal(map[document.CoverageStatus]int{document.CoverageWritten: 2}))
+			intro, err := os.ReadFile(filepath.Join(destination, "guides", "intro.md"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(intro)).To(ContainSubstring("[setup](/guides/setup/)"))
+			Expect(filepath.Join(destination, "guides", "setup.md")).To(BeAnExistingFile())
+		})
+
+		It("writes the documentation bundle into a tar archive", func() {
+			var archive bytes.Buffer
+			config := docforge.NewConfig(options, hugo.Hugo{Enabled: true, PrettyURLs: true}, rhs)
+			tw := config.UseTarWriter(&archive, false)
+			_, err := docforge.Run(context.TODO(), config)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tw.Close()).To(Succeed())
+			names := []string{}
+			tr := tar.NewReader(&archive)
+			for {
+				h, err := tr.Next()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				Expect(err).NotTo(HaveOccurred())
+				names = append(names, h.Name)
+			}
+			Expect(names).To(ContainElements("guides/intro.md", "guides/setup.md"))
+			Expect(filepath.Join(destination, "guides")).NotTo(BeADirectory())
+		})
+
+		It("publishes the documentation bundle to a bucket", func() {
+			client := &writersfakes.FakeS3Client{}
+			config := docforge.NewConfig(options, hugo.Hugo{Enabled: true, PrettyURLs: true}, rhs)
+			config.UseS3Writer(client, "docs", "site")
+			_, err := docforge.Run(context.TODO(), config)
+			Expect(err).NotTo(HaveOccurred())
+			keys := []string{}
+			for i := 0; i < client.PutObjectCallCount(); i++ {
+				bucket, key, _, contentType := client.PutObjectArgsForCall(i)
+				Expect(bucket).To(Equal("docs"))
+				Expect(contentType).To(Equal("text/markdown; charset=utf-8"))
+				keys = append(keys, key)
+			}
+			Expect(keys).To(ConsistOf("site/guides/intro.md", "site/guides/setup.md"))
+		})
+
+		It("counts the processed documents", func() {
+			written := metrics.DocumentsProcessed.Value(string(document.CoverageWritten))
+			observed := metrics.DocumentDuration.Count()
+			_, err := docforge.Run(context.TODO(), docforge.NewConfig(options, hugo.Hugo{Enabled: true, PrettyURLs: true}, rhs))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(metrics.DocumentsProcessed.Value(string(document.CoverageWritten))).To(Equal(written + 2))
+			Expect(metrics.DocumentDuration.Count()).To(Equal(observed + 2))
+		})
+
+		It("fails with a config error for an unresolvable manifest", func() {
+			options.ManifestPath = "https://github.com/gardener/docforge/blob/master/missing.yaml"
+			result, err := docforge.Run(context.TODO(), docforge.NewConfig(options, hugo.Hugo{}, rhs))
+			Expect(result).To(BeNil())
+			Expect(errors.As(err, &docforge.ErrConfig{})).To(BeTrue())
+		})
+
+		It("writes the changes of the resolved nodes callback", func() {
+			config := docforge.NewConfig(options, hugo.Hugo{Enabled: true, PrettyURLs: true}, rhs)
+			config.OnNodeResolved = func(node *manifest.Node) error {
+				if node.Name() == "intro.md" {
+					node.Frontmatter = map[string]interface{}{"category": "embedded"}
+				}
+				return nil
+			}
+			_, err := docforge.Run(context.TODO(), config)
+			Expect(err).NotTo(HaveOccurred())
+			intro, err := os.ReadFile(filepath.Join(destination, "guides", "intro.md"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(intro)).To(ContainSubstring("category: embedded"))
+		})
+
+		It("fails when the resolved nodes callback fails", func() {
+			config := docforge.NewConfig(options, hugo.Hugo{}, rhs)
+			rejected := errors.New("rejected")
+			config.OnNodeResolved = func(node *manifest.Node) error {
+				if node.Name() == "setup.md" {
+					return rejected
+				}
+				return nil
+			}
+			result, err := docforge.Run(context.TODO(), config)
+			Expect(result).To(BeNil())
+			Expect(err).To(MatchError(ContainSubstring("node guides/setup.md: rejected")))
+			Expect(errors.Is(err, rejected)).To(BeTrue())
+			Expect(filepath.Join(destination, "guides")).NotTo(BeADirectory())
+		})
+
+		Context("rate limit check", func() {
+			var reset time.Time
+
+			BeforeEach(func() {
+				reset = time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+				rhs = []repositoryhost.Interface{&rateLimitedHost{rhs[0], 1, reset}}
+			})
+
+			It("aborts when the remaining rate limit is too low", func() {
+				options.RateLimitCheck = docforge.RateLimitCheckAbort
+				result, err := docforge.Run(context.TODO(), docforge.NewConfig(options, hugo.Hugo{}, rhs))
+				Expect(result).To(BeNil())
+				budgetErr := docforge.ErrRateLimitBudget{}
+				Expect(errors.As(err, &budgetErr)).To(BeTrue())
+				Expect(budgetErr.Reset).To(Equal(reset))
+				Expect(err).To(MatchError("the documents need about 2 API calls of local https://github.com/gardener/docforge but only 1 remain until the rate limit is reset at 2023-05-01T12:00:00Z"))
+				Expect(filepath.Join(destination, "guides")).NotTo(BeADirectory())
+			})
+
+			It("only warns when the remaining rate limit is too low", func() {
+				options.RateLimitCheck = docforge.RateLimitCheckWarn
+				result, err := docforge.Run(context.TODO(), docforge.NewConfig(options, hugo.Hugo{}, rhs))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.Coverage.Counts()).To(Equal(map[document.CoverageStatus]int{document.CoverageWritten: 2}))
+			})
+
+			It("passes when the remaining rate limit is sufficient", func() {
+				options.RateLimitCheck = docforge.RateLimitCheckAbort
+				rhs = []repositoryhost.Interface{&rateLimitedHost{rhs[0].(*rateLimitedHost).Interface, 2, reset}}
+				_, err := docforge.Run(context.TODO(), docforge.NewConfig(options, hugo.Hugo{}, rhs))
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("compares the remaining rate limit of each host", func() {
+				options.RateLimitCheck = docforge.RateLimitCheckAbort
+				other := &rateLimitedHost{repositoryhost.NewLocalTest(repo, "https://github.com/gardener/other", "tests"), 100, reset}
+				_, err := docforge.Run(context.TODO(), docforge.NewConfig(options, hugo.Hugo{}, append(rhs, other)))
+				Expect(errors.As(err, &docforge.ErrRateLimitBudget{})).To(BeTrue())
+			})
+
+			It("rejects unknown checks", func() {
+				options.RateLimitCheck = "fail"
+				_, err := docforge.Run(context.TODO(), docforge.NewConfig(options, hugo.Hugo{}, rhs))
+				Expect(errors.As(err, &docforge.ErrConfig{})).To(BeTrue())
+			})
+		})
+
+		It("writes the documentation bundle from a local repository", func() {
+			local, err := os.MkdirTemp("", "local")
+			Expect(err).NotTo(HaveOccurred())
+			defer os.RemoveAll(local)
+			files := map[string]string{
+				"manifest.yaml":        "structure:\n- dir: guides\n  structure:\n  - fileTree: docs\n",
+				"docs/intro.md":        "# Intro\n\nContinue with [setup](./setup.md) and the [overview](../README.md).\n",
+				"docs/setup.md":        "# Setup\n\n![logo](images/logo.png)\n",
+				"docs/images/logo.png": "png",
+				"README.md":            "# Overview\n",
+			}
+			for name, content := range files {
+				Expect(os.MkdirAll(filepath.Dir(filepath.Join(local, name)), 0755)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(local, name), []byte(content), 0644)).To(Succeed())
+			}
+			rh, err := repositoryhost.NewLocalRepository(&osshim.OsShim{}, "https://github.com/acme/docs/tree/main", local)
+			Expect(err).NotTo(HaveOccurred())
+			options.ManifestPath, err = repositoryhost.LocalRepositoryFile("https://github.com/acme/docs/tree/main", "manifest.yaml")
+			Expect(err).NotTo(HaveOccurred())
+			options.ResourcesWebsitePath = "__resources"
+			options.ResourceIntegrity = true
+			result, err := docforge.Run(context.TODO(), docforge.NewConfig(options, hugo.Hugo{Enabled: true, PrettyURLs: true}, []repositoryhost.Interface{rh}))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Coverage.Counts()).To(Equal(map[document.CoverageStatus]int{document.CoverageWritten: 2}))
+			intro, err := os.ReadFile(filepath.Join(destination, "guides", "intro.md"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(intro)).To(ContainSubstring("[setup](/guides/setup/)"))
+			Expect(string(intro)).To(ContainSubstring("[overview](https://github.com/acme/docs/blob/main/README.md)"))
+			setup, err := os.ReadFile(filepath.Join(destination, "guides", "setup.md"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(setup)).To(ContainSubstring("![logo](/__resources/logo_"))
+			sidecar, err := os.ReadFile(filepath.Join(destination, "integrity.json"))
+			Expect(err).NotTo(HaveOccurred())
+			var integrity map[string]string
+			Expect(json.Unmarshal(sidecar, &integrity)).To(Succeed())
+			Expect(integrity).To(HaveLen(1))
+			for target, hash := range integrity {
+				Expect(string(setup)).To(ContainSubstring("![logo](/__resources/" + target + ")"))
+				Expect(hash).To(Equal("sha256-j4y7fc9G4Lx9UyZXSabBfRFgk6a6leRCdkBgx2/UqGw="))
+			}
+		})
+
+		It("resumes a failed build from the state file", func() {
+			options.StateFile = filepath.Join(destination, "state")
+			config := docforge.NewConfig(options, hugo.Hugo{Enabled: true, PrettyURLs: true}, rhs)
+			config.Writer = &recordingWriter{Writer: config.Writer, fail: "setup.md"}
+			result, err := docforge.Run(context.TODO(), config)
+			Expect(err).To(MatchError(ContainSubstring("disk full")))
+			Expect(result.Coverage.Counts()).To(Equal(map[document.CoverageStatus]int{document.CoverageWritten: 1, document.CoverageFailed: 1}))
+
+			options.Resume = true
+			config = docforge.NewConfig(options, hugo.Hugo{Enabled: true, PrettyURLs: true}, rhs)
+			writer := &recordingWriter{Writer: config.Writer}
+			config.Writer = writer
+			result, err = docforge.Run(context.TODO(), config)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Coverage.Counts()).To(Equal(map[document.CoverageStatus]int{document.CoverageWritten: 2}))
+			Expect(writer.written).To(ContainElement("setup.md"))
+			Expect(writer.written).NotTo(ContainElement("intro.md"))
+			Expect(filepath.Join(destination, "guides", "intro.md")).To(BeAnExistingFile())
+			Expect(filepath.Join(destination, "guides", "setup.md")).To(BeAnExistingFile())
+		})
+
+		It("writes the same artifacts when a failed build is resumed", func() {
+			local, err := os.MkdirTemp("", "local")
+			Expect(err).NotTo(HaveOccurred())
+			defer os.RemoveAll(local)
+			files := map[string]string{
+				"manifest.yaml":           "structure:\n- dir: guides\n  structure:\n  - fileTree: docs\n",
+				"docs/intro.md":           "---\ntitle: Introduction\n---\n# Intro\n\n![logo](images/logo.png)\n",
+				"docs/setup.md":           "# Setup\n\n![diagram](images/diagram.png)\n",
+				"docs/images/logo.png":    "png",
+				"docs/images/diagram.png": "diagram",
+			}
+			for name, content := range files {
+				Expect(os.MkdirAll(filepath.Dir(filepath.Join(local, name)), 0755)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(local, name), []byte(content), 0644)).To(Succeed())
+			}
+			rh, err := repositoryhost.NewLocalRepository(&osshim.OsShim{}, "https://github.com/acme/docs/tree/main", local)
+			Expect(err).NotTo(HaveOccurred())
+			options.ManifestPath, err = repositoryhost.LocalRepositoryFile("https://github.com/acme/docs/tree/main", "manifest.yaml")
+			Expect(err).NotTo(HaveOccurred())
+			options.ResourcesWebsitePath = "__resources"
+			options.ResourceIntegrity = true
+			options.LLMsTxt = true
+			options.LLMsFullTxt = true
+			artifacts := []string{"llms.txt", "llms-full.txt", "integrity.json", "coverage.json", "resources.json"}
+			build := func(dest string, fail string) error {
+				Expect(os.MkdirAll(dest, 0755)).To(Succeed())
+				options.DestinationPath = dest
+				options.StateFile = filepath.Join(dest, "state")
+				options.CoverageReport = filepath.Join(dest, "coverage.json")
+				options.ResourcesManifest = filepath.Join(dest, "resources.json")
+				config := docforge.NewConfig(options, hugo.Hugo{Enabled: true, PrettyURLs: true}, []repositoryhost.Interface{rh})
+				config.Writer = &recordingWriter{Writer: config.Writer, fail: fail}
+				_, err := docforge.Run(context.TODO(), config)
+				return err
+			}
+			full := filepath.Join(destination, "full")
+			Expect(build(full, "")).To(Succeed())
+			resumed := filepath.Join(destination, "resumed")
+			Expect(build(resumed, "setup.md")).To(MatchError(ContainSubstring("disk full")))
+			options.Resume = true
+			Expect(build(resumed, "")).To(Succeed())
+			for _, artifact := range artifacts {
+				expected, err := os.ReadFile(filepath.Join(full, artifact))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(os.ReadFile(filepath.Join(resumed, artifact))).To(Equal(expected), artifact)
+			}
+			llms, err := os.ReadFile(filepath.Join(resumed, "llms.txt"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(llms)).To(ContainSubstring("[Introduction](/guides/intro/)"))
+			var integrity map[string]string
+			sidecar, err := os.ReadFile(filepath.Join(resumed, "integrity.json"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(json.Unmarshal(sidecar, &integrity)).To(Succeed())
+			Expect(integrity).To(HaveLen(2))
+		})
+
+		It("writes the completed documents when the build is interrupted", func() {
+			options.ShutdownGracePeriod = time.Second
+			options.LLMsTxt = true
+			ctx, cancel := context.WithCancel(context.TODO())
+			defer cancel()
+			config := docforge.NewConfig(options, hugo.Hugo{Enabled: true, PrettyURLs: true}, rhs)
+			writer := &recordingWriter{Writer: config.Writer, afterWrite: func(name string) {
+				if name == "intro.md" {
+					cancel()
+				}
+			}}
+			config.Writer = writer
+			result, err := docforge.Run(ctx, config)
+			Expect(err).To(MatchError(context.Canceled))
+			Expect(result.Coverage.Counts()[document.CoverageWritten]).To(BeNumerically(">=", 1))
+			Expect(writer.written).To(ContainElement("intro.md"))
+			Expect(writer.written).NotTo(ContainElement("llms.txt"))
+			intro, err := os.ReadFile(filepath.Join(destination, "guides", "intro.md"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(intro)).To(ContainSubstring("[setup](/guides/setup/)"))
+		})
+
+		It("completes the document scheduling its downloads while the build is interrupted", func() {
+			local, err := os.MkdirTemp("", "local")
+			Expect(err).NotTo(HaveOccurred())
+			defer os.RemoveAll(local)
+			files := map[string]string{
+				"manifest.yaml":        "structure:\n- dir: guides\n  structure:\n  - fileTree: docs\n",
+				"docs/setup.md":        "# Setup\n\n![logo](images/logo.png)\n",
+				"docs/images/logo.png": "png",
+			}
+			for name, content := range files {
+				Expect(os.MkdirAll(filepath.Dir(filepath.Join(local, name)), 0755)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(local, name), []byte(content), 0644)).To(Succeed())
+			}
+			rh, err := repositoryhost.NewLocalRepository(&osshim.OsShim{}, "https://github.com/acme/docs/tree/main", local)
+			Expect(err).NotTo(HaveOccurred())
+			options.ManifestPath, err = repositoryhost.LocalRepositoryFile("https://github.com/acme/docs/tree/main", "manifest.yaml")
+			Expect(err).NotTo(HaveOccurred())
+			options.ShutdownGracePeriod = time.Second
+			ctx, cancel := context.WithCancel(context.TODO())
+			defer cancel()
+			rhs = []repositoryhost.Interface{&cancelingHost{Interface: rh, cancelOn: "setup.md", cancel: cancel}}
+			result, err := docforge.Run(ctx, docforge.NewConfig(options, hugo.Hugo{Enabled: true, PrettyURLs: true}, rhs))
+			Expect(err).To(MatchError(context.Canceled))
+			Expect(result.Coverage.Counts()[document.CoverageFailed] + result.Coverage.Counts()[document.CoverageWritten]).To(Equal(1))
+		})
+
+		It("sends the http headers only to their hosts when checking links", func() {
+			local, err := os.MkdirTemp("", "docforge-repo")
+			Expect(err).NotTo(HaveOccurred())
+			defer os.RemoveAll(local)
+			Expect(os.WriteFile(filepath.Join(local, "manifest.yaml"), []byte("structure:\n- file: setup.md\n  source: setup.md\n"), 0644)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(local, "setup.md"), []byte("# Setup\n\n[cdn](https://cdn.example.com/page) and [other](https://other.example.com/page)\n"), 0644)).To(Succeed())
+			rh, err := repositoryhost.NewLocalRepository(&osshim.OsShim{}, "https://github.com/acme/docs/tree/main", local)
+			Expect(err).NotTo(HaveOccurred())
+			options.ManifestPath, err = repositoryhost.LocalRepositoryFile("https://github.com/acme/docs/tree/main", "manifest.yaml")
+			Expect(err).NotTo(HaveOccurred())
+			transport := &headersTransport{headers: map[string]http.Header{}}
+			config := docforge.NewConfig(options, hugo.Hugo{}, []repositoryhost.Interface{rh})
+			config.HTTPClient = httpclient.Headers{Values: map[string]string{"X-Api-Key": "secret"}, Hosts: []string{"cdn.example.com"}}.Client(transport)
+			_, err = docforge.Run(context.TODO(), config)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(transport.headers).To(HaveKey("cdn.example.com"))
+			Expect(transport.headers["cdn.example.com"].Get("X-Api-Key")).To(Equal("secret"))
+			Expect(transport.headers).To(HaveKey("other.example.com"))
+			Expect(transport.headers["other.example.com"].Get("X-Api-Key")).To(BeEmpty())
+		})
+
+		It("fails with a config error when resuming without a state file", func() {
+			options.Resume = true
+			_, err := docforge.Run(context.TODO(), docforge.NewConfig(options, hugo.Hugo{}, rhs))
+			Expect(errors.As(err, &docforge.ErrConfig{})).To(BeTrue())
+		})
+
+		It("writes only the documents changed since the base ref", func() {
+			options.Since = "v1.0.0"
+			rhs = []repositoryhost.Interface{&changedHost{Interface: rhs[0], changed: []string{"docs/setup.md", "README.md"}}}
+			result, err := docforge.Run(context.TODO(), docforge.NewConfig(options, hugo.Hugo{Enabled: true, PrettyURLs: true}, rhs))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Coverage.Counts()).To(Equal(map[document.CoverageStatus]int{document.CoverageWritten: 1}))
+			Expect(filepath.Join(destination, "guides", "setup.md")).To(BeAnExistingFile())
+			Expect(filepath.Join(destination, "guides", "intro.md")).NotTo(BeAnExistingFile())
+		})
+
+		It("resolves the links of the changed documents to the unchanged documents", func() {
+			options.Since = "v1.0.0"
+			rhs = []repositoryhost.Interface{&changedHost{Interface: rhs[0], changed: []string{"docs/intro.md"}}}
+			_, err := docforge.Run(context.TODO(), docforge.NewConfig(options, hugo.Hugo{Enabled: true, PrettyURLs: true}, rhs))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(filepath.Join(destination, "guides", "setup.md")).NotTo(BeAnExistingFile())
+			intro, err := os.ReadFile(filepath.Join(destination, "guides", "intro.md"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(intro)).To(ContainSubstring("[setup](/guides/setup/)"))
+		})
+
+		It("writes only the documents modified after the date", func() {
+			options.Since = "2024-01-31"
+			rhs = []repositoryhost.Interface{&gitInfoHost{Interface: rhs[0], commitDates: map[string]string{
+				"docs/setup.md": "2024-02-01T10:00:00Z",
+				"docs/intro.md": "2024-01-30T10:00:00Z",
+			}}}
+			result, err := docforge.Run(context.TODO(), docforge.NewConfig(options, hugo.Hugo{Enabled: true, PrettyURLs: true}, rhs))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Coverage.Counts()).To(Equal(map[document.CoverageStatus]int{document.CoverageWritten: 1}))
+			Expect(filepath.Join(destination, "guides", "setup.md")).To(BeAnExistingFile())
+			Expect(filepath.Join(destination, "guides", "intro.md")).NotTo(BeAnExistingFile())
+		})
+
+		It("keeps the documents of the previous build that weren't modified after the date", func() {
+			Expect(os.MkdirAll(filepath.Join(destination, "guides"), 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(destination, "guides", "intro.md"), []byte("previous build"), 0644)).To(Succeed())
+			options.Since = "2024-01-31T12:00:00Z"
+			rhs = []repositoryhost.Interface{&gitInfoHost{Interface: rhs[0], commitDates: map[string]string{
+				"docs/setup.md": "2024-01-31T13:00:00Z",
+				"docs/intro.md": "2024-01-31T11:00:00Z",
+			}}}
+			_, err := docforge.Run(context.TODO(), docforge.NewConfig(options, hugo.Hugo{Enabled: true, PrettyURLs: true}, rhs))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(filepath.Join(destination, "guides", "setup.md")).To(BeAnExistingFile())
+			Expect(os.ReadFile(filepath.Join(destination, "guides", "intro.md"))).To(Equal([]byte("previous build")))
+		})
+
+		It("sets the modification times of the documents to their last commit dates", func() {
+			options.GhInfoDestination = "gitinfo"
+			options.GitModTimes = true
+			rhs = []repositoryhost.Interface{&gitInfoHost{Interface: rhs[0], commitDates: map[string]string{
+				"docs/setup.md": "2024-02-01T10:00:00Z",
+			}}}
+			_, err := docforge.Run(context.TODO(), docforge.NewConfig(options, hugo.Hugo{Enabled: true, PrettyURLs: true}, rhs))
+			Expect(err).NotTo(HaveOccurred())
+			setup, err := os.Stat(filepath.Join(destination, "guides", "setup.md"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(setup.ModTime()).To(BeTemporally("==", time.Date(2024, 2, 1, 10, 0, 0, 0, time.UTC)))
+			intro, err := os.Stat(filepath.Join(destination, "guides", "intro.md"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(intro.ModTime()).To(BeTemporally("~", time.Now(), time.Minute))
+		})
+
+		It("writes no documents when the base ref is unknown", func() {
+			options.Since = "missing"
+			rhs = []repositoryhost.Interface{&changedHost{Interface: rhs[0], err: repositoryhost.ErrResourceNotFound("missing")}}
+			result, err := docforge.Run(context.TODO(), docforge.NewConfig(options, hugo.Hugo{Enabled: true, PrettyURLs: true}, rhs))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.Nodes).To(HaveLen(1))
+			Expect(result.Coverage.Counts()).To(BeEmpty())
+		})
+
+		It("fails when the changes can't be compared", func() {
+			options.Since = "v1.0.0"
+			rhs = []repositoryhost.Interface{&changedHost{Interface: rhs[0], err: errors.New("rate limited")}}
+			_, err := docforge.Run(context.TODO(), docforge.NewConfig(options, hugo.Hugo{Enabled: true, PrettyURLs: true}, rhs))
+			Expect(err).To(MatchError("failed to find the documents changed since v1.0.0: rate limited"))
+		})
+
+		It("writes the documents of an archive", func() {
+			options.ManifestPath = "https://github.com/gardener/docforge/blob/master/archive.yaml"
+			_, err := docforge.Run(context.TODO(), docforge.NewConfig(options, hugo.Hugo{}, rhs))
+			Expect(err).NotTo(HaveOccurred())
+			intro, err := os.ReadFile(filepath.Join(destination, "archived", "intro.md"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(intro)).To(ContainSubstring("[setup](/archived/setup.md/)"))
+			Expect(filepath.Join(destination, "archived", "setup.md")).To(BeAnExistingFile())
+		})
+
+		It("fails without writers", func() {
+			_, err := docforge.Run(context.TODO(), docforge.Config{Options: options, RepositoryHosts: rhs})
+			Expect(err).To(MatchError("config has no document or resource writer"))
+		})
+	})
+})
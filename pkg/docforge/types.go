@@ -0,0 +1,170 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package docforge
+
+import (
+	"time"
+
+	"github.com/gardener/docforge/cmd/hugo"
+	"github.com/gardener/docforge/pkg/manifest"
+	"github.com/gardener/docforge/pkg/osfakes/httpclient"
+	"github.com/gardener/docforge/pkg/registry/repositoryhost"
+	"github.com/gardener/docforge/pkg/workers/document"
+	"github.com/gardener/docforge/pkg/writers"
+)
+
+const (
+	// RateLimitCheckOff skips the rate limit check before processing the documents
+	RateLimitCheckOff = "off"
+	// RateLimitCheckWarn logs a warning when the remaining rate limit is too low for the documents
+	RateLimitCheckWarn = "warn"
+	// RateLimitCheckAbort fails the run when the remaining rate limit is too low for the documents
+	RateLimitCheckAbort = "abort"
+)
+
+// Options encapsulates the parameters of a docforge run
+type Options struct {
+	DocumentWorkersCount         int               `mapstructure:"document-workers"`
+	RenderWorkersCount           int               `mapstructure:"render-workers"`
+	ValidationWorkersCount       int               `mapstructure:"validation-workers"`
+	FailFast                     bool              `mapstructure:"fail-fast"`
+	DestinationPath              string            `mapstructure:"destination"`
+	ResourcesDownloadPath        string            `mapstructure:"resources-download-path"`
+	ResourcesWebsitePath         string            `mapstructure:"resources-website-path"`
+	ManifestPath                 string            `mapstructure:"manifest"`
+	AdditionalManifestPaths      []string          `mapstructure:"additional-manifests"`
+	ResourceDownloadWorkersCount int               `mapstructure:"download-workers"`
+	HostDownloadWorkersCount     int               `mapstructure:"download-workers-per-host"`
+	ManifestWorkersCount         int               `mapstructure:"manifest-workers"`
+	WeightPrefixPattern          string            `mapstructure:"weight-prefix-pattern"`
+	DownloadRetries              int               `mapstructure:"download-retries"`
+	GhInfoDestination            string            `mapstructure:"github-info-destination"`
+	GitHubInfoGraphQL            bool              `mapstructure:"github-info-graphql"`
+	DryRun                       bool              `mapstructure:"dry-run"`
+	ContentFileFormats           []string          `mapstructure:"content-files-formats"`
+	HostsToReport                []string          `mapstructure:"hosts-to-report"`
+	SkipLinkValidation           bool              `mapstructure:"skip-link-validation"`
+	LinkRedirects                map[string]string `mapstructure:"link-redirects"`
+	NormalizeLineEndings         bool              `mapstructure:"normalize-line-endings"`
+	CodeLanguageAliases          map[string]string `mapstructure:"code-language-aliases"`
+	FrontmatterOverride          bool              `mapstructure:"frontmatter-override"`
+	HostAliases                  map[string]string `mapstructure:"host-aliases"`
+	AbsoluteLinkRepos            []string          `mapstructure:"absolute-link-repos"`
+	SourceFrontmatter            bool              `mapstructure:"source-frontmatter"`
+	CodeSnippets                 bool              `mapstructure:"code-snippets"`
+	EditURL                      bool              `mapstructure:"edit-url"`
+	EditURLKey                   string            `mapstructure:"edit-url-key"`
+	CoverageReport               string            `mapstructure:"coverage-report"`
+	HugoMenuFile                 string            `mapstructure:"hugo-menu-file"`
+	HugoMenuName                 string            `mapstructure:"hugo-menu-name"`
+	LLMsTxt                      bool              `mapstructure:"llms-txt"`
+	LLMsFullTxt                  bool              `mapstructure:"llms-full-txt"`
+	LLMsTitle                    string            `mapstructure:"llms-title"`
+
+	// Redirects writes a _redirects file redirecting the aliases of the documents to their links
+	Redirects bool `mapstructure:"redirects"`
+	// RobotsTxt writes a robots.txt disallowing the RobotsDisallow paths of the website for all user agents
+	RobotsTxt      bool     `mapstructure:"robots-txt"`
+	RobotsDisallow []string `mapstructure:"robots-disallow"`
+	// NoIndexFrontmatterKey is the frontmatter key set to noindex in the documents of manifest nodes with noIndex, robots if empty
+	NoIndexFrontmatterKey string `mapstructure:"noindex-frontmatter-key"`
+
+	// MaxRedirectDepth is the maximum number of chained LinkRedirects followed for a link, unbounded when 0. Redirect
+	// loops fail in any case
+	MaxRedirectDepth int `mapstructure:"max-redirect-depth"`
+	// Since is a base git ref or a date. When set, only the documents whose sources changed since it are built
+	Since string `mapstructure:"since"`
+	// RelativeVersionLinks are the versions, set by the version frontmatter property, whose links to documents of the same version
+	// are relative to the linking document. * stands for all versions. Other links pin the version of the linked document
+	RelativeVersionLinks []string `mapstructure:"relative-version-links"`
+	// OutputFormat is dir for writing the bundle into the destination dir or tar for writing it into a tar archive at the
+	// destination path, gzipped when the path ends with .gz or .tgz. Defaults to dir
+	OutputFormat string `mapstructure:"output-format"`
+	// DefaultFileExtension is the extension of file names without one whose source type is unknown. Defaults to .md
+	DefaultFileExtension string `mapstructure:"default-file-extension"`
+	// DefinitionLists enables parsing definition lists and is the form they are rendered in, markdown or html
+	DefinitionLists string `mapstructure:"definition-lists"`
+	// HeadingStyle is the style headings are rendered in, atx, setext or preserve. Defaults to atx
+	HeadingStyle string `mapstructure:"heading-style"`
+	// BulletListMarker is the marker bullet list items are rendered with, -, * or +. Items keep their marker when empty
+	BulletListMarker string `mapstructure:"bullet-list-marker"`
+	// OrderedListDelimiter follows the numbers of ordered list items, . or ). Items keep their delimiter when empty
+	OrderedListDelimiter string `mapstructure:"ordered-list-delimiter"`
+	// OrderedListNumbering numbers ordered list items as in the source with preserve or sequentially with renumber.
+	// Every item gets the start number of its list when empty
+	OrderedListNumbering string `mapstructure:"ordered-list-numbering"`
+	// ResourceIntegrity writes the sha256 subresource integrity of the downloaded resources to integrity.json in the resources dir
+	ResourceIntegrity bool `mapstructure:"resource-integrity"`
+	// SanitizeSVG strips script elements, event handler attributes and javascript: links from downloaded SVG resources
+	SanitizeSVG bool `mapstructure:"sanitize-svg"`
+	// InlineImagesMaxSize is the size in bytes up to which images are inlined as data URIs instead of downloaded. 0 disables inlining
+	InlineImagesMaxSize int `mapstructure:"inline-images-max-size"`
+	// InlineSVGsMaxSize is the size in bytes up to which SVG images are inlined as svg elements instead of downloaded. 0 disables inlining
+	InlineSVGsMaxSize int `mapstructure:"inline-svgs-max-size"`
+	// InaccessibleLinks is the treatment of links to resources of repositories responding with 401, 403 or 404:
+	// error, warn-and-keep-absolute or drop. Such links are only validated when empty
+	InaccessibleLinks string `mapstructure:"inaccessible-links"`
+	// StateFile records the document nodes written and the resources downloaded by the build
+	StateFile string `mapstructure:"state-file"`
+	// Resume skips the document nodes and the resources recorded in StateFile by the previous build
+	Resume bool `mapstructure:"resume"`
+	// SkipUnchangedWrites keeps the files whose content didn't change instead of rewriting them
+	SkipUnchangedWrites bool `mapstructure:"skip-unchanged-writes"`
+	// GitModTimes sets the modification times of the written documents to the last modified dates of their git info
+	GitModTimes bool `mapstructure:"git-mod-times"`
+	// BasePath is the sub-directory the website is served under, e.g. /docs. It prefixes the links to documents and resources
+	BasePath string `mapstructure:"base-path"`
+	// ResourcesManifest is the file the downloaded resources are listed in, as CSV if it has .csv extension, otherwise as JSON
+	ResourcesManifest string `mapstructure:"resources-manifest"`
+	// DocumentTimeout bounds the processing of each document. Documents timing out are reported without failing the build. 0 disables the timeout
+	DocumentTimeout time.Duration `mapstructure:"document-timeout"`
+	// ShutdownGracePeriod is the time the documents and downloads in progress are given to complete when the build is
+	// interrupted. Queued documents and downloads are skipped
+	ShutdownGracePeriod time.Duration `mapstructure:"shutdown-grace-period"`
+	// BadgeHosts are the patterns of hosts, or of hosts and paths, of dynamic images like badges, whose links are kept
+	// absolute instead of downloaded or inlined
+	BadgeHosts []string `mapstructure:"badge-hosts"`
+	// StrictManifest rejects manifests with unknown fields, like misspelled node properties, instead of ignoring them
+	StrictManifest bool `mapstructure:"strict-manifest"`
+	// AnchorRedirects maps the anchors of renamed sections of all documents to their new anchors
+	AnchorRedirects map[string]string `mapstructure:"anchor-redirects"`
+	// LintRules are the severities, warning or error, of the rules the markdown documents are checked with, by rule
+	LintRules map[string]string `mapstructure:"lint-rules"`
+	// TitleCase is the case, title, sentence or as-is, of the titles derived from node names
+	TitleCase string `mapstructure:"title-case"`
+	// TitleAcronyms are the words kept as listed in the titles derived from node names
+	TitleAcronyms []string `mapstructure:"title-acronyms"`
+	// TitleReplacements maps words of node names to the text replacing them in the derived titles
+	TitleReplacements map[string]string `mapstructure:"title-replacements"`
+	// RateLimitCheck compares the remaining rate limit of the repository hosts with the calls estimated for the documents
+	// before processing them. It can be off, warn or abort
+	RateLimitCheck string `mapstructure:"rate-limit-check"`
+
+	// ContentTransforms can only be set in the configuration file
+	ContentTransforms []document.ContentTransform `mapstructure:"content-transforms"`
+
+	manifest.TemplateOptions `mapstructure:",squash"`
+}
+
+// Writers struct that collects all the writesr
+type Writers struct {
+	ResourceDownloadWriter writers.Writer
+	GitInfoWriter          writers.Writer
+	Writer                 writers.Writer
+}
+
+// Config is the configuration of a docforge run
+type Config struct {
+	Options
+	Writers
+	hugo.Hugo
+	// RepositoryHosts serve the manifests and documents, the first one accepting a URL is used
+	RepositoryHosts []repositoryhost.Interface
+	// HTTPClient accesses the URLs no repository host accepts, like external links. http.DefaultClient is used when nil
+	HTTPClient httpclient.Client
+	// OnNodeResolved is called with each node of the resolved structure, after its name and source are final and
+	// before its document is processed. It can change the node, e.g. its frontmatter. An error aborts the run
+	OnNodeResolved func(*manifest.Node) error
+}
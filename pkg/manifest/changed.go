@@ -0,0 +1,47 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package manifest
+
+// ChangedNodes restricts the resolved structure to the file nodes with a changed source, the section files
+// of their dirs and their ancestor dirs. It returns all nodes of the restricted structure, starting with its root
+func ChangedNodes(root *Node, changed func(source string) (bool, error), sectionFiles []string) ([]*Node, error) {
+	if _, err := keepChanged(root, changed, sectionFiles); err != nil {
+		return nil, err
+	}
+	return getAllNodes(root), nil
+}
+
+// keepChanged removes the unchanged nodes from the structure of the node and checks if anything changed in it
+func keepChanged(node *Node, changed func(source string) (bool, error), sectionFiles []string) (bool, error) {
+	if node.Type == "file" {
+		for _, source := range append([]string{node.Source}, node.MultiSource...) {
+			if source == "" {
+				continue
+			}
+			if ok, err := changed(source); ok || err != nil {
+				return ok, err
+			}
+		}
+		return false, nil
+	}
+	kept := make([]bool, len(node.Structure))
+	anyKept := false
+	for i, child := range node.Structure {
+		ok, err := keepChanged(child, changed, sectionFiles)
+		if err != nil {
+			return false, err
+		}
+		kept[i] = ok
+		anyKept = anyKept || ok
+	}
+	structure := []*Node{}
+	for i, child := range node.Structure {
+		if kept[i] || (anyKept && child.Type == "file" && isSectionFile(child.File, sectionFiles)) {
+			structure = append(structure, child)
+		}
+	}
+	node.Structure = structure
+	return anyKept, nil
+}
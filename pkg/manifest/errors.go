@@ -0,0 +1,40 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package manifest
+
+// ErrManifestParse indicates a manifest whose content is malformed, like invalid YAML or an invalid template
+type ErrManifestParse struct {
+	// Manifest is the URL of the malformed manifest
+	Manifest string
+	Err      error
+}
+
+// Error returns the underlying error message
+func (e ErrManifestParse) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e ErrManifestParse) Unwrap() error {
+	return e.Err
+}
+
+// ErrCyclicImport indicates a manifest that imports itself, or a manifest file that includes itself, directly or
+// through other manifests or files
+type ErrCyclicImport struct {
+	// Manifest is the URL of the manifest or file imported in the cycle
+	Manifest string
+	Err      error
+}
+
+// Error returns the underlying error message
+func (e ErrCyclicImport) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e ErrCyclicImport) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,97 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package manifest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gardener/docforge/pkg/registry"
+	"github.com/gardener/docforge/pkg/registry/repositoryhost"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// includeTag is the YAML tag of manifest values replaced by the content of another YAML file
+const includeTag = "!include"
+
+// expandIncludes replaces the values tagged with !include in the content of the YAML file at fileURL
+// by the content of the referenced files. Included sequences are spliced into including sequences.
+// including holds the files that are currently being expanded to detect cycles.
+// Malformed content is returned as is for the decoder to report the error
+func expandIncludes(content []byte, fileURL string, r registry.Interface, including []string) ([]byte, error) {
+	if !strings.Contains(string(content), includeTag) {
+		return content, nil
+	}
+	doc := &yamlv3.Node{}
+	if err := yamlv3.Unmarshal(content, doc); err != nil {
+		return content, nil
+	}
+	if err := expandIncludeNodes(doc, fileURL, r, append(including, fileURL)); err != nil {
+		return nil, err
+	}
+	return yamlv3.Marshal(doc)
+}
+
+func expandIncludeNodes(node *yamlv3.Node, fileURL string, r registry.Interface, including []string) error {
+	content := []*yamlv3.Node{}
+	for _, child := range node.Content {
+		if child.Tag != includeTag {
+			if err := expandIncludeNodes(child, fileURL, r, including); err != nil {
+				return err
+			}
+			content = append(content, child)
+			continue
+		}
+		included, err := readInclude(child, fileURL, r, including)
+		if err != nil {
+			return err
+		}
+		if node.Kind == yamlv3.SequenceNode && included.Kind == yamlv3.SequenceNode {
+			content = append(content, included.Content...)
+		} else {
+			content = append(content, included)
+		}
+	}
+	node.Content = content
+	return nil
+}
+
+// readInclude reads and expands the YAML file referenced by an !include node
+func readInclude(node *yamlv3.Node, fileURL string, r registry.Interface, including []string) (*yamlv3.Node, error) {
+	if node.Kind != yamlv3.ScalarNode || node.Value == "" {
+		return nil, fmt.Errorf("%s in %s at line %d expects a file URL", includeTag, fileURL, node.Line)
+	}
+	includeURL := node.Value
+	if repositoryhost.IsRelative(includeURL) {
+		var err error
+		if includeURL, err = r.ResolveRelativeLink(fileURL, includeURL); err != nil {
+			return nil, fmt.Errorf("can't build included file %s absolute URL : %w", node.Value, err)
+		}
+	}
+	for _, f := range including {
+		if f == includeURL {
+			return nil, ErrCyclicImport{includeURL, fmt.Errorf("file %s includes itself", includeURL)}
+		}
+	}
+	if err := r.LoadRepository(context.TODO(), includeURL); err != nil {
+		return nil, err
+	}
+	content, err := r.Read(context.TODO(), includeURL)
+	if err != nil {
+		return nil, fmt.Errorf("can't get included file %s content : %w", includeURL, err)
+	}
+	if content, err = expandIncludes(content, includeURL, r, including); err != nil {
+		return nil, err
+	}
+	doc := &yamlv3.Node{}
+	if err = yamlv3.Unmarshal(content, doc); err != nil {
+		return nil, fmt.Errorf("can't parse included file %s yaml content : %w%s", includeURL, err, errorSnippet(content, err))
+	}
+	if len(doc.Content) == 0 {
+		return &yamlv3.Node{Kind: yamlv3.ScalarNode, Tag: "!!null"}, nil
+	}
+	return doc.Content[0], nil
+}
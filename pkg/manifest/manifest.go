@@ -5,21 +5,32 @@
 package manifest
 
 import (
+	"bytes"
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
+	"maps"
 	"net/url"
 	"path"
+	"reflect"
+	"regexp"
 	"slices"
+	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/gardener/docforge/pkg/registry"
 	"github.com/gardener/docforge/pkg/registry/repositoryhost"
 	"gopkg.in/yaml.v2"
+	"k8s.io/klog/v2"
 )
 
 const sectionFile = "_index.md"
 
+// yamlErrorLine extracts the line from yaml decoder errors
+var yamlErrorLine = regexp.MustCompile(`line (\d+)`)
+
 type nodeTransformation func(node *Node, parent *Node, manifest *Node, r registry.Interface, contentFileFormats []string) error
 
 func processManifest(node *Node, parent *Node, manifest *Node, r registry.Interface, contentFileFormats []string, functions ...nodeTransformation) error {
@@ -50,26 +61,81 @@ func processTransformation(f nodeTransformation, node *Node, parent *Node, manif
 	return nil
 }
 
-func loadRepositoriesOfResources(node *Node, parent *Node, manifest *Node, r registry.Interface, _ []string) error {
-	loadRepoFrom := func(resourceURL string) error {
-		if repositoryhost.IsResourceURL(resourceURL) {
-			return r.LoadRepository(context.TODO(), resourceURL)
+// repositoryLoader loads the repositories of node resources with bounded concurrency
+type repositoryLoader struct {
+	workers   int
+	resources []string
+	collected map[string]bool
+}
+
+func (l *repositoryLoader) collectResources(node *Node, parent *Node, manifest *Node, r registry.Interface, _ []string) error {
+	resources := make([]string, 0, 4+len(node.MultiSource)+len(node.SourceFallbacks)+len(node.FileTrees))
+	resources = append(resources, node.File, node.Source, node.FileTree, node.Manifest)
+	resources = append(resources, node.MultiSource...)
+	resources = append(resources, node.SourceFallbacks...)
+	resources = append(resources, node.FileTrees...)
+	if l.collected == nil {
+		l.collected = map[string]bool{}
+	}
+	for _, resourceURL := range resources {
+		if repositoryhost.IsResourceURL(resourceURL) && !l.collected[resourceURL] {
+			l.collected[resourceURL] = true
+			l.resources = append(l.resources, resourceURL)
 		}
-		return nil
 	}
-	loadErr := errors.Join(loadRepoFrom(node.File), loadRepoFrom(node.Source), loadRepoFrom(node.FileTree), loadRepoFrom(node.Manifest))
-	for _, multiSource := range node.MultiSource {
-		loadErr = errors.Join(loadErr, loadRepoFrom(multiSource))
+	return nil
+}
+
+// loadRepositories loads the collected resources in parallel, joining errors in collection order
+func (l *repositoryLoader) loadRepositories(r registry.Interface) error {
+	errs := make([]error, len(l.resources))
+	sem := make(chan struct{}, max(l.workers, 1))
+	var wg sync.WaitGroup
+	for i, resourceURL := range l.resources {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, resourceURL string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			errs[i] = r.LoadRepository(context.TODO(), resourceURL)
+		}(i, resourceURL)
 	}
-	return loadErr
+	wg.Wait()
+	return errors.Join(errs...)
 }
 
-func loadManifestNodes(node *Node, parent *Node, manifest *Node, r registry.Interface, _ []string) error {
+// manifestLoader loads manifest nodes, parsing each imported manifest only once
+type manifestLoader struct {
+	// parsed manifests by manifest resource URL, which includes the ref
+	parsed map[string]*Node
+	// includedBy maps a manifest node to the manifest node that imports it
+	includedBy map[*Node]*Node
+	// template renders the manifests before parsing, nil if they aren't templates
+	template *manifestTemplate
+	// strict rejects manifests with unknown fields
+	strict bool
+}
+
+func newManifestLoader() *manifestLoader {
+	return &manifestLoader{
+		parsed:     map[string]*Node{},
+		includedBy: map[*Node]*Node{},
+	}
+}
+
+func (l *manifestLoader) loadManifestNodes(node *Node, parent *Node, manifest *Node, r registry.Interface, _ []string) error {
 	// skip non-manifest nodes
 	if node.Manifest == "" {
 		return nil
 	}
 	// node.Manifest is a manifest to be loaded
+	if repositoryhost.IsFileURL(node.Manifest) {
+		manifestResourceURL, err := repositoryhost.FileSystemResource(node.Manifest, "blob")
+		if err != nil {
+			return err
+		}
+		node.Manifest = manifestResourceURL
+	}
 	if repositoryhost.IsRelative(node.Manifest) {
 		// manifest.Manifest has already been loaded into registry
 		manifestResourceURL, err := r.ResolveRelativeLink(manifest.Manifest, node.Manifest)
@@ -78,25 +144,93 @@ func loadManifestNodes(node *Node, parent *Node, manifest *Node, r registry.Inte
 		}
 		node.Manifest = manifestResourceURL
 	}
+	// the recursion guard is checked before the cache, as a cached manifest can still import itself
+	if node != manifest {
+		l.includedBy[node] = manifest
+	}
+	for m := l.includedBy[node]; m != nil; m = l.includedBy[m] {
+		if m.Manifest == node.Manifest {
+			return ErrCyclicImport{node.Manifest, fmt.Errorf("manifest %s imports itself", node.Manifest)}
+		}
+	}
 	// load for the read to succeed
 	if err := r.LoadRepository(context.TODO(), node.Manifest); err != nil {
 		return err
 	}
-	byteContent, err := r.Read(context.TODO(), node.Manifest)
+	key := node.Manifest
+	if resourceURL, err := r.ResourceURL(node.Manifest); err == nil {
+		key = resourceURL.ResourceURL()
+	}
+	parsed, ok := l.parsed[key]
+	if !ok {
+		byteContent, err := r.Read(context.TODO(), node.Manifest)
+		if err != nil {
+			return fmt.Errorf("can't get manifest file content : %w", err)
+		}
+		if l.template != nil {
+			if byteContent, err = l.template.render(node.Manifest, byteContent); err != nil {
+				return err
+			}
+		}
+		parsed = &Node{}
+		if err = parseManifest(node.Manifest, byteContent, parsed, r, l.strict); err != nil {
+			return err
+		}
+		l.parsed[key] = parsed
+	}
+	// the properties of the importing node override the properties of the manifest root
+	node.merge(parsed.clone())
+	return nil
+}
+
+// parseManifest parses the manifest content after expanding its includes, reporting the offending line on error.
+// In strict mode unknown and duplicate fields are errors, otherwise they are ignored
+func parseManifest(manifestURL string, content []byte, node *Node, r registry.Interface, strict bool) error {
+	trimmed := bytes.ToLower(bytes.TrimSpace(content))
+	if bytes.HasPrefix(trimmed, []byte("<!doctype html")) || bytes.HasPrefix(trimmed, []byte("<html")) {
+		return ErrManifestParse{manifestURL, fmt.Errorf("manifest %s content is an HTML page instead of YAML", manifestURL)}
+	}
+	content, err := expandIncludes(content, manifestURL, r, nil)
 	if err != nil {
-		return fmt.Errorf("can't get manifest file content : %w", err)
+		return ErrManifestParse{manifestURL, fmt.Errorf("can't expand includes of manifest %s : %w", manifestURL, err)}
+	}
+	unmarshal := yaml.Unmarshal
+	if strict {
+		unmarshal = yaml.UnmarshalStrict
 	}
-	if err = yaml.Unmarshal(byteContent, node); err != nil {
-		return fmt.Errorf("can't parse manifest %s yaml content : %w", node.Manifest, err)
+	if err := unmarshal(content, node); err != nil {
+		return ErrManifestParse{manifestURL, fmt.Errorf("can't parse manifest %s yaml content : %w%s", manifestURL, err, errorSnippet(content, err))}
 	}
 	return nil
 }
 
+// errorSnippet returns the manifest line referenced by a yaml decoder error
+func errorSnippet(content []byte, err error) string {
+	m := yamlErrorLine.FindStringSubmatch(err.Error())
+	if m == nil {
+		return ""
+	}
+	line, _ := strconv.Atoi(m[1])
+	lines := strings.Split(string(content), "\n")
+	if line < 1 || line > len(lines) {
+		return ""
+	}
+	return fmt.Sprintf("\n%d | %s", line, lines[line-1])
+}
+
 func moveManifestContentIntoTree(node *Node, parent *Node, manifest *Node, r registry.Interface, _ []string) error {
 	if node.Type != "manifest" {
 		return nil
 	}
 	if parent != nil {
+		// the manifest node is removed from the tree, so its properties are passed down to its content
+		for _, child := range node.Structure {
+			for _, propagate := range []nodeTransformation{propagateFrontmatter, propagateSkipValidation, propagateNoIndex, propagateResourcesPath} {
+				if err := propagate(child, node, manifest, r, nil); err != nil {
+					return err
+				}
+			}
+		}
 		parent.Structure = append(parent.Structure, node.Structure...)
 		node.Structure = nil
 	}
@@ -109,13 +243,13 @@ func decideNodeType(node *Node, _ *Node, _ *Node, _ registry.Interface, _ []stri
 	if node.Manifest != "" {
 		candidateType = append(candidateType, "manifest")
 	}
-	if node.File != "" {
+	if node.File != "" || isSourceGlob(node.Source) {
 		candidateType = append(candidateType, "file")
 	}
 	if node.Dir != "" {
 		candidateType = append(candidateType, "dir")
 	}
-	if node.FileTree != "" {
+	if node.FileTree != "" || len(node.FileTrees) > 0 {
 		candidateType = append(candidateType, "fileTree")
 	}
 	switch len(candidateType) {
@@ -149,10 +283,17 @@ func calculatePath(node *Node, parent *Node, _ *Node, _ registry.Interface, _ []
 }
 
 func resolveRelativeLinks(node *Node, _ *Node, manifest *Node, r registry.Interface, _ []string) error {
-	resolveLink := func(link *string) error {
+	resolveLink := func(link *string, resourceType string) error {
 		if *link == "" {
 			return nil
 		}
+		if repositoryhost.IsFileURL(*link) {
+			fileLink, err := repositoryhost.FileSystemResource(*link, resourceType)
+			if err != nil {
+				return err
+			}
+			*link = fileLink
+		}
 		if repositoryhost.IsResourceURL(*link) {
 			if _, err := r.ResourceURL(*link); err != nil {
 				return fmt.Errorf("%s does not exist: %w", *link, err)
@@ -169,6 +310,9 @@ func resolveRelativeLinks(node *Node, _ *Node, manifest *Node, r registry.Interf
 
 	switch node.Type {
 	case "file":
+		if node.ExternalURL != "" {
+			return checkExternalURL(node)
+		}
 		// Don't calculate source for empty _index.md file
 		if node.File == sectionFile && node.Source == "" {
 			return nil
@@ -177,62 +321,195 @@ func resolveRelativeLinks(node *Node, _ *Node, manifest *Node, r registry.Interf
 			node.Source = node.File
 			node.File = path.Base(node.File)
 		}
+		if isSourceGlob(node.Source) {
+			return resolveSourceGlob(node, resolveLink)
+		}
 		for i := range node.MultiSource {
-			if err := resolveLink(&node.MultiSource[i]); err != nil {
+			if err := resolveLink(&node.MultiSource[i], "blob"); err != nil {
 				return err
 			}
 		}
-		return resolveLink(&node.Source)
+		if len(node.SourceFallbacks) > 0 {
+			return resolveSourceFallbacks(node, resolveLink)
+		}
+		return resolveLink(&node.Source, "blob")
 	case "fileTree":
-		return resolveLink(&node.FileTree)
+		for i := range node.FileTrees {
+			if err := resolveLink(&node.FileTrees[i], "tree"); err != nil {
+				return err
+			}
+		}
+		return resolveLink(&node.FileTree, "tree")
+	}
+	return nil
+}
+
+// resolveSourceFallbacks sets the source of the node to the first of its source and its fallbacks that exists
+func resolveSourceFallbacks(node *Node, resolveLink func(link *string, resourceType string) error) error {
+	if node.Source == "" {
+		return fmt.Errorf("node \n\n%s\nwith sourceFallbacks has no source", node)
+	}
+	var notFound repositoryhost.ErrResourceNotFound
+	candidates := append([]string{node.Source}, node.SourceFallbacks...)
+	for i, candidate := range candidates {
+		err := resolveLink(&candidate, "blob")
+		if errors.As(err, &notFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			klog.Warningf("source %s of node %s doesn't exist, using fallback %s\n", node.Source, node.NodePath(), candidate)
+		}
+		node.Source = candidate
+		node.SourceFallbacks = nil
+		return nil
+	}
+	return fmt.Errorf("none of the sources %s of node %s exists", strings.Join(candidates, ", "), node.NodePath())
+}
+
+// isSourceGlob checks if the source is a glob pattern matching multiple files. The fragment of the source and its
+// query, which starts with a question mark following the extension of the file name, are not part of the pattern
+func isSourceGlob(source string) bool {
+	source, _, _ = strings.Cut(source, "#")
+	if i := strings.Index(source, "?"); i >= 0 && path.Ext(source[:i]) != "" {
+		source = source[:i]
+	}
+	return strings.ContainsAny(source, "*?[")
+}
+
+// resolveSourceGlob resolves the dir of a source glob to a tree URL, the pattern is allowed in the file name only
+func resolveSourceGlob(node *Node, resolveLink func(link *string, resourceType string) error) error {
+	if len(node.MultiSource) > 0 {
+		return fmt.Errorf("node \n\n%s\nwith source glob can't have multiSource", node)
+	}
+	i := strings.LastIndex(node.Source, "/")
+	dir, pattern := node.Source[:i+1], node.Source[i+1:]
+	if isSourceGlob(dir) {
+		return fmt.Errorf("source glob %s has patterns out of the file name", node.Source)
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return fmt.Errorf("invalid source glob %s: %w", node.Source, err)
+	}
+	dir = strings.Replace(strings.TrimSuffix(dir, "/"), "/blob/", "/tree/", 1)
+	if dir == "" {
+		dir = "."
+	}
+	if err := resolveLink(&dir, "tree"); err != nil {
+		return err
+	}
+	node.Source = dir + "/" + pattern
+	return nil
+}
+
+// checkExternalURL checks that a node linking to an external URL has no sources
+func checkExternalURL(node *Node) error {
+	if node.HasContent() || strings.Contains(node.File, "/") {
+		return fmt.Errorf("node \n\n%s\nwith externalURL can't have sources", node)
+	}
+	u, err := url.Parse(node.ExternalURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("externalURL %s of node %s is not an absolute web URL", node.ExternalURL, node.File)
 	}
 	return nil
 }
 
 func checkFileTypeFormats(node *Node, _ *Node, manifest *Node, r registry.Interface, contentFileFormats []string) error {
-	if node.Type != "file" {
+	// the files matching source globs are filtered by format when expanded
+	if node.Type != "file" || isSourceGlob(node.Source) {
 		return nil
 	}
 	files := append(node.FileType.MultiSource, node.FileType.Source, node.FileType.File)
 	for _, file := range files {
-		// we do || file == "" to skip empty fields
-		if !slices.ContainsFunc(contentFileFormats, func(fileFormat string) bool { return strings.HasSuffix(file, fileFormat) || file == "" }) {
+		// the format is the one of the file name without the query of the source
+		name := strings.SplitN(file, "?", 2)[0]
+		// we do || name == "" to skip empty fields, files without extension are of unknown type
+		if !slices.ContainsFunc(contentFileFormats, func(fileFormat string) bool {
+			return strings.HasSuffix(name, fileFormat) || name == "" || path.Ext(name) == ""
+		}) {
 			return fmt.Errorf("file format of %s isn't supported", file)
 		}
 	}
 	return nil
 }
 
-func extractFilesFromNode(node *Node, parent *Node, manifest *Node, r registry.Interface, contentFileFormats []string) error {
-	if node.Type != "fileTree" {
-		return nil
-	}
-	files, err := r.Tree(node.FileTree)
-	if err != nil {
-		return err
-	}
-	if err := constructNodeTree(files, node, parent, contentFileFormats); err != nil {
-		return err
+// treeSelector selects the files of the trees of fileTree nodes with bounded concurrency, as the trees are independent
+// and the frontmatter filter reads each of their files
+type treeSelector struct {
+	workers int
+	nodes   []*Node
+	// selected files of the trees of the nodes, in the order of the trees
+	selected map[*Node][]treeFiles
+}
+
+// treeFiles are the files of a tree and the ones selected as nodes
+type treeFiles struct {
+	tree     string
+	files    []string
+	selected []string
+}
+
+func (s *treeSelector) collectFileTrees(node *Node, _ *Node, _ *Node, _ registry.Interface, _ []string) error {
+	if node.Type == "fileTree" {
+		s.nodes = append(s.nodes, node)
 	}
-	removeNodeFromParent(node, parent)
 	return nil
 }
 
-func removeNodeFromParent(node *Node, parent *Node) {
-	for i, child := range parent.Structure {
-		if child == node {
-			size := len(parent.Structure)
-			parent.Structure[i] = parent.Structure[size-1]
-			parent.Structure = parent.Structure[:size-1]
-			return
+// selectFiles selects the files of the trees of the collected nodes in parallel, returning the first error in
+// collection order like a sequential selection
+func (s *treeSelector) selectFiles(r registry.Interface, contentFileFormats []string) error {
+	type job struct {
+		node *Node
+		i    int
+	}
+	var jobs []job
+	s.selected = make(map[*Node][]treeFiles, len(s.nodes))
+	for _, node := range s.nodes {
+		trees := node.trees()
+		s.selected[node] = make([]treeFiles, len(trees))
+		for i, tree := range trees {
+			s.selected[node][i].tree = tree
+			jobs = append(jobs, job{node, i})
 		}
 	}
+	errs := make([]error, len(jobs))
+	sem := make(chan struct{}, max(s.workers, 1))
+	var wg sync.WaitGroup
+	for i, j := range jobs {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, j job) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			t := &s.selected[j.node][j.i]
+			t.files, t.selected, errs[i] = selectTreeFiles(t.tree, j.node, r, contentFileFormats)
+		}(i, j)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-func constructNodeTree(files []string, node *Node, parent *Node, contentFileFormats []string) error {
-	pathToDirNode := map[string]*Node{}
-	pathToDirNode[node.Path] = parent
+// selectTreeFiles returns the files of the tree and the ones of them included as nodes of the fileTree node
+func selectTreeFiles(tree string, node *Node, r registry.Interface, contentFileFormats []string) ([]string, []string, error) {
+	if len(node.Extensions) > 0 {
+		contentFileFormats = node.Extensions
+	}
+	files, err := r.Tree(tree)
+	if err != nil {
+		return nil, nil, err
+	}
+	var selected []string
 	for _, file := range files {
+		if path.Base(file) == metaFile {
+			continue
+		}
 		if !slices.ContainsFunc(contentFileFormats, func(fileFormat string) bool { return strings.HasSuffix(file, fileFormat) }) {
 			continue
 		}
@@ -246,30 +523,320 @@ func constructNodeTree(files []string, node *Node, parent *Node, contentFileForm
 		if shouldExclude {
 			continue
 		}
-		source, err := url.JoinPath(strings.Replace(node.FileTree, "/tree/", "/blob/", 1), file)
+		if len(node.FrontmatterFilter) > 0 {
+			source, err := fileTreeSource(tree, file)
+			if err != nil {
+				return nil, nil, err
+			}
+			matches, err := matchesFrontmatter(r, source, node.FrontmatterFilter)
+			if err != nil {
+				return nil, nil, err
+			}
+			if !matches {
+				continue
+			}
+		}
+		selected = append(selected, file)
+	}
+	return files, selected, nil
+}
+
+// extractFilesFromNode replaces a fileTree node by the nodes of its selected files, merging its trees in order
+func (s *treeSelector) extractFilesFromNode(node *Node, parent *Node, _ *Node, r registry.Interface, contentFileFormats []string) error {
+	if node.Type != "fileTree" {
+		return nil
+	}
+	if len(node.Extensions) > 0 {
+		contentFileFormats = node.Extensions
+	}
+	type treeNodes struct {
+		treeFiles
+		pathToDirNode map[string]*Node
+		constructed   map[*Node]bool
+	}
+	var (
+		trees []treeNodes
+		empty = true
+		// paths of the files in the trees, the dirs of the trees are merged by mergeFolders
+		selected = map[string]bool{}
+	)
+	for _, t := range s.selected[node] {
+		unique := make([]string, 0, len(t.selected))
+		for _, file := range t.selected {
+			if selected[file] {
+				klog.Warningf("file %s of file tree %s is skipped as an earlier file tree of the node in %s has it\n", file, t.tree, node.Path)
+				continue
+			}
+			unique = append(unique, file)
+		}
+		pathToDirNode, constructed, err := constructNodeTree(unique, t.tree, node, parent, contentFileFormats)
 		if err != nil {
 			return err
 		}
-		// url.JoinPath escapes once so we revert it's escape
-		source, err = url.PathUnescape(source)
+		for _, file := range unique {
+			selected[file] = true
+		}
+		empty = empty && len(constructed) == 0
+		trees = append(trees, treeNodes{t, pathToDirNode, constructed})
+	}
+	removeNodeFromParent(node, parent)
+	if empty && node.PruneIfEmpty {
+		parent.prunable = true
+	}
+	// applied after the removal as it swaps the last child of parent in place of the node
+	for _, t := range trees {
+		if err := applyDirMetas(t.files, t.tree, node, t.pathToDirNode, t.constructed, r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// expandSourceGlob replaces a node with a source glob by the nodes of the files in its dir matching the glob
+func expandSourceGlob(node *Node, parent *Node, _ *Node, r registry.Interface, contentFileFormats []string) error {
+	if node.Type != "file" || !isSourceGlob(node.Source) {
+		return nil
+	}
+	i := strings.LastIndex(node.Source, "/")
+	tree, pattern := node.Source[:i], node.Source[i+1:]
+	files, err := r.Tree(tree)
+	if err != nil {
+		return err
+	}
+	matched := 0
+	for _, file := range files {
+		// the pattern is validated when the source glob is resolved
+		if ok, _ := path.Match(pattern, file); !ok {
+			continue
+		}
+		if !slices.ContainsFunc(contentFileFormats, func(fileFormat string) bool { return strings.HasSuffix(file, fileFormat) }) {
+			continue
+		}
+		source, err := fileTreeSource(tree, file)
 		if err != nil {
 			return err
 		}
+		fileNode := &Node{
+			FileType: FileType{
+				File:            file,
+				Source:          source,
+				AnchorRedirects: maps.Clone(node.AnchorRedirects),
+			},
+			SkipValidation: node.SkipValidation,
+			ResourcesPath:  node.ResourcesPath,
+			ResourcesRoot:  node.ResourcesRoot,
+			NoIndex:        node.NoIndex,
+			Type:           "file",
+			Path:           node.Path,
+		}
+		if node.Frontmatter != nil {
+			fileNode.Frontmatter = cloneValue(node.Frontmatter).(map[string]interface{})
+		}
+		parent.Structure = append(parent.Structure, fileNode)
+		matched++
+	}
+	// removed after appending the files as it swaps the last child of parent in place of the node
+	removeNodeFromParent(node, parent)
+	if matched == 0 {
+		klog.Warningf("source glob %s of node in %s matches no files\n", node.Source, node.Path)
+	}
+	return nil
+}
+
+// pruneEmptyDirs removes the prunable dirs left without content from the structure of node
+// and reports whether node is a prunable dir that is left without content itself
+func pruneEmptyDirs(node *Node) bool {
+	structure := node.Structure[:0]
+	for _, child := range node.Structure {
+		if pruneEmptyDirs(child) {
+			klog.Warningf("dir %s is removed as its file trees have no files\n", child.NodePath())
+			// node may be left without content by the removal
+			node.prunable = true
+			continue
+		}
+		structure = append(structure, child)
+	}
+	node.Structure = structure
+	return node.prunable && node.Type == "dir" && len(node.Structure) == 0
+}
+
+func removeNodeFromParent(node *Node, parent *Node) {
+	for i, child := range parent.Structure {
+		if child == node {
+			size := len(parent.Structure)
+			parent.Structure[i] = parent.Structure[size-1]
+			parent.Structure = parent.Structure[:size-1]
+			return
+		}
+	}
+}
+
+// constructNodeTree adds the files to the parent structure and returns the dir nodes by path and the constructed nodes
+func constructNodeTree(files []string, tree string, node *Node, parent *Node, contentFileFormats []string) (map[string]*Node, map[*Node]bool, error) {
+	pathToDirNode := map[string]*Node{}
+	pathToDirNode[node.Path] = parent
+	// nodes constructed from the tree, as parent has other children too
+	constructed := map[*Node]bool{}
+	for _, file := range files {
+		source, err := fileTreeSource(tree, file)
+		if err != nil {
+			return nil, nil, err
+		}
 		fileName := path.Base(file)
 		filePath := path.Join(node.Path, path.Dir(file))
 		parentNode := getParrentNode(pathToDirNode, filePath, contentFileFormats)
-		parentNode.Structure = append(parentNode.Structure, &Node{
+		fileNode := &Node{
 			FileType: FileType{
 				File:   fileName,
 				Source: source,
 			},
 			Type: "file",
 			Path: filePath,
-		})
+		}
+		parentNode.Structure = append(parentNode.Structure, fileNode)
+		constructed[fileNode] = true
+	}
+	for _, dir := range pathToDirNode {
+		if dir != parent {
+			constructed[dir] = true
+		}
+	}
+	return pathToDirNode, constructed, nil
+}
+
+// applyDirMetas applies the meta files in the file tree to the dirs constructed from it
+func applyDirMetas(files []string, tree string, node *Node, pathToDirNode map[string]*Node, constructed map[*Node]bool, r registry.Interface) error {
+	for dirPath, dir := range pathToDirNode {
+		// pathToDirNode has the dirs with content only
+		metaPath := path.Join(strings.TrimPrefix(strings.TrimPrefix(dirPath, node.Path), "/"), metaFile)
+		if !slices.Contains(files, metaPath) {
+			continue
+		}
+		source, err := fileTreeSource(tree, metaPath)
+		if err != nil {
+			return err
+		}
+		if err = applyDirMeta(r, source, dir, constructed); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
+// fileTreeSource returns the source URL of the file in the file tree
+func fileTreeSource(fileTree string, file string) (string, error) {
+	source, err := url.JoinPath(strings.Replace(fileTree, "/tree/", "/blob/", 1), file)
+	if err != nil {
+		return "", err
+	}
+	// url.JoinPath escapes once so we revert it's escape
+	return url.PathUnescape(source)
+}
+
+// metaFile orders and titles the children of a file tree directory
+const metaFile = "_meta.yaml"
+
+// dirMeta is the content of a metaFile
+type dirMeta struct {
+	// Order lists the names of the files and dirs in the order they come first
+	Order []string `yaml:"order"`
+	// Titles are the display titles of files and dirs by name
+	Titles map[string]string `yaml:"titles"`
+}
+
+// applyDirMeta orders the children of dir constructed from the file tree by the meta source and sets their titles.
+// Listed children come first and are weighted by their position, the unlisted keep their order.
+// The title and weight of a child dir are set on its section file
+func applyDirMeta(r registry.Interface, source string, dir *Node, constructed map[*Node]bool) error {
+	content, err := r.Read(context.TODO(), source)
+	if err != nil {
+		return err
+	}
+	meta := dirMeta{}
+	if err = yaml.Unmarshal(content, &meta); err != nil {
+		return fmt.Errorf("can't parse %s: %w", source, err)
+	}
+	positions := map[string]int{}
+	for i, name := range meta.Order {
+		positions[name] = i + 1
+	}
+	var (
+		slots    []int
+		children []*Node
+	)
+	for i, child := range dir.Structure {
+		if constructed[child] {
+			slots = append(slots, i)
+			children = append(children, child)
+		}
+	}
+	slices.SortStableFunc(children, func(a, b *Node) int {
+		pa, pb := positions[a.Name()], positions[b.Name()]
+		if pa == 0 || pb == 0 {
+			return cmp.Compare(pb, pa)
+		}
+		return cmp.Compare(pa, pb)
+	})
+	for i, child := range children {
+		dir.Structure[slots[i]] = child
+		target := child
+		if child.Type == "dir" {
+			if target = sectionFileOf(child); target == nil {
+				continue
+			}
+		}
+		if title, ok := meta.Titles[child.Name()]; ok {
+			setFrontmatter(target, "title", title)
+		}
+		if position, ok := positions[child.Name()]; ok {
+			setFrontmatter(target, "weight", position)
+		}
+	}
+	return nil
+}
+
+// sectionFileOf returns the section file of the dir, nil if it has none
+func sectionFileOf(dir *Node) *Node {
+	for _, child := range dir.Structure {
+		if child.Type == "file" && child.File == sectionFile {
+			return child
+		}
+	}
+	return nil
+}
+
+func setFrontmatter(node *Node, key string, value interface{}) {
+	if node.Frontmatter == nil {
+		node.Frontmatter = map[string]interface{}{}
+	}
+	node.Frontmatter[key] = value
+}
+
+// matchesFrontmatter checks if the frontmatter of the source has all key values of the filter
+func matchesFrontmatter(r registry.Interface, source string, filter map[string]interface{}) (bool, error) {
+	content, err := r.Read(context.TODO(), source)
+	if err != nil {
+		return false, err
+	}
+	if !bytes.HasPrefix(content, []byte("---\n")) {
+		return false, nil
+	}
+	end := bytes.Index(content[4:], []byte("\n---\n"))
+	if end < 0 {
+		return false, nil
+	}
+	frontmatter := map[string]interface{}{}
+	if err := yaml.Unmarshal(content[4:4+end], &frontmatter); err != nil {
+		return false, fmt.Errorf("can't parse frontmatter of %s: %w", source, err)
+	}
+	for key, value := range filter {
+		if !reflect.DeepEqual(frontmatter[key], value) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
 func getParrentNode(pathToDirNode map[string]*Node, parentPath string, contentFileFormats []string) *Node {
 	if parent, ok := pathToDirNode[parentPath]; ok {
 		return parent
@@ -374,6 +941,51 @@ func propagateSkipValidation(node *Node, parent *Node, manifest *Node, _ registr
 	return nil
 }
 
+func propagateNoIndex(node *Node, parent *Node, _ *Node, _ registry.Interface, _ []string) error {
+	if node.NoIndex == nil && parent != nil {
+		node.NoIndex = parent.NoIndex
+	}
+	return nil
+}
+
+// SetNoIndexFrontmatter sets the key of the frontmatter of the file nodes excluded from search engines to noindex,
+// unless their frontmatter already has it. The key is robots if empty
+func SetNoIndexFrontmatter(nodes []*Node, key string) {
+	if key == "" {
+		key = "robots"
+	}
+	for _, node := range nodes {
+		if node.Type != "file" || node.NoIndex == nil || !*node.NoIndex {
+			continue
+		}
+		if _, ok := node.Frontmatter[key]; !ok {
+			setFrontmatter(node, key, "noindex")
+		}
+	}
+}
+
+func propagateResourcesPath(node *Node, parent *Node, _ *Node, _ registry.Interface, _ []string) error {
+	if node.ResourcesPath != "" {
+		resourcesPath := path.Clean(node.ResourcesPath)
+		if path.IsAbs(resourcesPath) || resourcesPath == ".." || strings.HasPrefix(resourcesPath, "../") {
+			return fmt.Errorf("node %s has resources path %s outside of the resources root", node.NodePath(), node.ResourcesPath)
+		}
+		node.ResourcesPath = resourcesPath
+	} else if parent != nil {
+		node.ResourcesPath = parent.ResourcesPath
+	}
+	if node.ResourcesRoot != "" {
+		resourcesRoot := path.Clean(node.ResourcesRoot)
+		if path.IsAbs(resourcesRoot) || resourcesRoot == ".." || strings.HasPrefix(resourcesRoot, "../") {
+			return fmt.Errorf("node %s has resources root %s outside of the destination", node.NodePath(), node.ResourcesRoot)
+		}
+		node.ResourcesRoot = resourcesRoot
+	} else if parent != nil {
+		node.ResourcesRoot = parent.ResourcesRoot
+	}
+	return nil
+}
+
 func setParent(node *Node, parent *Node, _ *Node, _ registry.Interface, _ []string) error {
 	node.parent = parent
 	return nil
@@ -414,22 +1026,131 @@ func calculateAliases(node *Node, parent *Node, _ *Node, _ registry.Interface, _
 	return nil
 }
 
+// prefixWeights derives the weights of files from numeric prefixes of their names
+type prefixWeights struct {
+	pattern *regexp.Regexp
+}
+
+// stripWeightPrefix removes the prefix matched by the pattern from the file name and sets
+// the number captured by its first group as the weight of the file, unless it already has one
+func (p *prefixWeights) stripWeightPrefix(node *Node, _ *Node, _ *Node, _ registry.Interface, _ []string) error {
+	if node.Type != "file" {
+		return nil
+	}
+	match := p.pattern.FindStringSubmatch(node.File)
+	if len(match) < 2 || len(match[0]) == 0 {
+		return nil
+	}
+	name := node.File[len(match[0]):]
+	if name == "" || strings.HasPrefix(name, ".") {
+		return nil
+	}
+	weight, err := strconv.Atoi(match[1])
+	if err != nil {
+		return nil
+	}
+	node.File = name
+	if node.Frontmatter == nil {
+		node.Frontmatter = map[string]interface{}{}
+	}
+	if _, ok := node.Frontmatter["weight"]; !ok {
+		node.Frontmatter["weight"] = weight
+	}
+	return nil
+}
+
+// fileNames sets the extensions of file names by the type of their sources
+type fileNames struct {
+	// defaultExtension is the extension of file names without one whose source type is unknown
+	defaultExtension string
+}
+
+// resolveFileName sets the extension of the file name of a node with a document extension or none from its first source,
+// .html for HTML sources and .md for markdown sources. File names without extension get the default extension when
+// the type of the source is unknown
+func (f *fileNames) resolveFileName(node *Node, _ *Node, _ *Node, _ registry.Interface, _ []string) error {
+	if node.Type != "file" || !node.HasContent() || isSourceGlob(node.Source) {
+		return nil
+	}
+	source := node.Source
+	if source == "" {
+		source = node.MultiSource[0]
+	}
+	nameExt := path.Ext(node.File)
+	if nameExt != "" && sourceExtension(nameExt) == "" {
+		return nil
+	}
+	ext := sourceExtension(path.Ext(strings.SplitN(source, "?", 2)[0]))
+	if ext == "" {
+		if nameExt != "" {
+			return nil
+		}
+		ext = f.defaultExtension
+	}
+	node.File = strings.TrimSuffix(node.File, nameExt) + ext
+	return nil
+}
+
+// sourceExtension returns the extension of the files of sources with the extension, empty for unknown types of sources
+func sourceExtension(ext string) string {
+	switch strings.ToLower(ext) {
+	case ".md", ".markdown":
+		return ".md"
+	case ".html", ".htm":
+		return ".html"
+	default:
+		return ""
+	}
+}
+
 // ResolveManifest collects files in FileCollector from a given url and resourcehandlers.FileSource
-func ResolveManifest(url string, r registry.Interface, contentFileFormats []string) ([]*Node, error) {
-	manifest := Node{
-		ManifType: ManifType{
-			Manifest: url,
-		},
+func ResolveManifest(url string, r registry.Interface, contentFileFormats []string, options Options) ([]*Node, error) {
+	return ResolveManifests([]string{url}, r, contentFileFormats, options)
+}
+
+// ResolveManifests resolves the structures of multiple manifests merged into a single structure.
+// The first manifest is the root and the next ones are imported into it in the given order,
+// so top-level dirs with the same name merge like sibling dirs and files with the same path collide.
+// File names get the extension of their source type, or the default extension of the options when they have none
+func ResolveManifests(urls []string, r registry.Interface, contentFileFormats []string, options Options) ([]*Node, error) {
+	if len(urls) == 0 {
+		return nil, errors.New("no manifest to resolve")
 	}
-	err := processManifest(&manifest, nil, &manifest, r, contentFileFormats,
-		loadManifestNodes,
-		loadRepositoriesOfResources,
+	defaultExtension := options.DefaultExtension
+	if defaultExtension == "" {
+		defaultExtension = ".md"
+	}
+	if !strings.HasPrefix(defaultExtension, ".") {
+		defaultExtension = "." + defaultExtension
+	}
+	names := &fileNames{defaultExtension: defaultExtension}
+	trees := &treeSelector{workers: options.Workers}
+	// the files of the file trees are selected in parallel between resolving and transforming the nodes
+	resolving := []nodeTransformation{
 		decideNodeType,
 		calculatePath,
 		resolveRelativeLinks,
+		names.resolveFileName,
 		checkFileTypeFormats,
-		extractFilesFromNode,
+		trees.collectFileTrees,
+	}
+	transformations := []nodeTransformation{
+		trees.extractFilesFromNode,
+		expandSourceGlob,
 		moveManifestContentIntoTree,
+	}
+	if options.WeightPrefixPattern != "" {
+		pattern, err := regexp.Compile(options.WeightPrefixPattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid weight prefix pattern %s: %w", options.WeightPrefixPattern, err)
+		}
+		if pattern.NumSubexp() < 1 {
+			return nil, fmt.Errorf("weight prefix pattern %s has no group capturing the weight", options.WeightPrefixPattern)
+		}
+		weights := &prefixWeights{pattern: pattern}
+		transformations = append(transformations, weights.stripWeightPrefix)
+	}
+	transformations = append(transformations,
 		mergeFolders,
 		calculatePath,
 		resolvePersonaFolders,
@@ -439,11 +1160,65 @@ func ResolveManifest(url string, r registry.Interface, contentFileFormats []stri
 		setParent,
 		propagateFrontmatter,
 		propagateSkipValidation,
+		propagateNoIndex,
+		propagateResourcesPath,
 		calculateAliases,
 	)
+	if options.Permalinks.Pattern != "" {
+		p, err := newPermalinks(options.Permalinks)
+		if err != nil {
+			return nil, err
+		}
+		transformations = append(transformations, p.applyPermalink)
+	}
+	manifest := Node{
+		ManifType: ManifType{
+			Manifest: urls[0],
+		},
+	}
+	loader := newManifestLoader()
+	loader.strict = options.Strict
+	if options.Templates.Enabled {
+		var err error
+		if loader.template, err = newManifestTemplate(options.Templates, r); err != nil {
+			return nil, err
+		}
+	}
+	repositories := &repositoryLoader{workers: options.Workers}
+	if err := processTransformation(loader.loadManifestNodes, &manifest, nil, &manifest, r, contentFileFormats); err != nil {
+		return nil, err
+	}
+	for _, url := range urls[1:] {
+		node := &Node{
+			ManifType: ManifType{
+				Manifest: url,
+			},
+		}
+		manifest.Structure = append(manifest.Structure, node)
+		if err := processTransformation(loader.loadManifestNodes, node, &manifest, &manifest, r, contentFileFormats); err != nil {
+			return nil, err
+		}
+	}
+	err := processManifest(&manifest, nil, &manifest, r, contentFileFormats,
+		repositories.collectResources,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err = repositories.loadRepositories(r); err != nil {
+		return nil, err
+	}
+	if err = processManifest(&manifest, nil, &manifest, r, contentFileFormats, resolving...); err != nil {
+		return nil, err
+	}
+	if err = trees.selectFiles(r, contentFileFormats); err != nil {
+		return nil, err
+	}
+	err = processManifest(&manifest, nil, &manifest, r, contentFileFormats, transformations...)
 	if err != nil {
 		return nil, err
 	}
+	pruneEmptyDirs(&manifest)
 	return getAllNodes(&manifest), nil
 }
 
@@ -5,14 +5,24 @@ package manifest_test
 // SPDX-License-Identifier: Apache-2.0
 
 import (
+	"bytes"
+	"context"
 	"embed"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	_ "embed"
 
 	"github.com/gardener/docforge/pkg/manifest"
+	"github.com/gardener/docforge/pkg/osfakes/osshim"
 	"github.com/gardener/docforge/pkg/registry"
+	"github.com/gardener/docforge/pkg/registry/registryfakes"
 	"github.com/gardener/docforge/pkg/registry/repositoryhost"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/ginkgo/extensions/table"
@@ -45,7 +55,7 @@ var _ = Describe("Manifest test", func() {
 
 			url := "https://github.com/gardener/docforge/blob/master/" + exampleFile
 			contentFileFormats := []string{".md", ".yaml"}
-			allNodes, err := manifest.ResolveManifest(url, r, contentFileFormats)
+			allNodes, err := manifest.ResolveManifest(url, r, contentFileFormats, manifest.Options{})
 			Expect(err).ToNot(HaveOccurred())
 			files := []*manifest.Node{}
 			for _, node := range allNodes {
@@ -69,6 +79,19 @@ var _ = Describe("Manifest test", func() {
 		Entry("covering multisource", "multisource"),
 		Entry("covering aliases", "aliases"),
 		Entry("covering fileTree filtering", "fileTree_filtering"),
+		Entry("covering fileTree extensions", "fileTree_extensions"),
+		Entry("covering fileTree frontmatter filter", "fileTree_frontmatter"),
+		Entry("covering fileTree _meta.yaml", "fileTree_meta"),
+		Entry("covering merged file trees", "fileTrees"),
+		Entry("covering archive file trees", "archive"),
+		Entry("covering source globs", "source_glob"),
+		Entry("covering source fallbacks", "source_fallbacks"),
+		Entry("covering frontmatter inheritance", "frontmatter_inheritance"),
+		Entry("covering resources path inheritance", "resources_path"),
+		Entry("covering resources root inheritance", "resources_root"),
+		Entry("covering properties of imported manifests", "import_properties"),
+		Entry("covering external urls", "external_url"),
+		Entry("covering includes and anchors", "include"),
 	)
 
 	DescribeTable("Errors",
@@ -79,11 +102,649 @@ var _ = Describe("Manifest test", func() {
 
 			url := "https://github.com/gardener/docforge/blob/master/" + exampleFile
 			contentFileFormats := []string{".md", ".yaml"}
-			_, err := manifest.ResolveManifest(url, r, contentFileFormats)
+			_, err := manifest.ResolveManifest(url, r, contentFileFormats, manifest.Options{})
 			Expect(err.Error()).To(ContainSubstring(errorMsg))
 
 		},
 		Entry("when there are dirs with frontmatter collision", "colliding_dir_frontmatters", "there are multiple dirs with name foo and path . that have frontmatter. Please only use one"),
 		Entry("referencing a resource in source that isn't allowed", "unsupported_file_format", "invalid.file isn't supported"),
+		Entry("when manifests import each other", "cycle_a", "manifest https://github.com/gardener/docforge/blob/master/manifests/cycle_a.yaml imports itself"),
+		Entry("when a node has an external url and a source", "external_url_with_source", "with externalURL can't have sources"),
+		Entry("when a node has a relative external url", "external_url_relative", "externalURL community/slack of node slack.md is not an absolute web URL"),
+		Entry("when a node has a resources path outside of the resources root", "resources_path_outside", "node guides has resources path ../images outside of the resources root"),
+		Entry("when a node has a resources root outside of the destination", "resources_root_outside", "node guides has resources root ../static outside of the destination"),
+		Entry("when included files include each other", "include_cycle", "file https://github.com/gardener/docforge/blob/master/manifests/fragments/cycle_a.yaml includes itself"),
+		Entry("when the manifest is malformed", "malformed", "yaml: line 4: did not find expected ',' or ']'\n4 |     title: [unclosed"),
+		Entry("when the manifest is an HTML page", "html_page", "manifest https://github.com/gardener/docforge/blob/master/manifests/html_page.yaml content is an HTML page instead of YAML"),
+		Entry("when none of the source fallbacks exists", "source_fallbacks_missing", "none of the sources /contents/howtos/moved.md, /contents/howtos/gone.md of node install.md exists"),
+		Entry("when a source glob has patterns in its dir", "source_glob_dir", "source glob /contents/*/intro.md has patterns out of the file name"),
 	)
+
+	Describe("typed errors", func() {
+		var r registry.Interface
+		BeforeEach(func() {
+			r = registry.NewRegistry(repositoryhost.NewLocalTest(repo, "https://github.com/gardener/docforge", "tests"))
+		})
+		resolve := func(example string) error {
+			_, err := manifest.ResolveManifest("https://github.com/gardener/docforge/blob/master/manifests/"+example+".yaml", r, []string{".md", ".yaml"}, manifest.Options{})
+			return err
+		}
+
+		It("recovers the cyclic manifest of imports", func() {
+			var cyclic manifest.ErrCyclicImport
+			Expect(errors.As(resolve("cycle_a"), &cyclic)).To(BeTrue())
+			Expect(cyclic.Manifest).To(Equal("https://github.com/gardener/docforge/blob/master/manifests/cycle_a.yaml"))
+		})
+
+		It("recovers the cyclic file of includes in the malformed manifest", func() {
+			err := resolve("include_cycle")
+			var cyclic manifest.ErrCyclicImport
+			Expect(errors.As(err, &cyclic)).To(BeTrue())
+			Expect(cyclic.Manifest).To(Equal("https://github.com/gardener/docforge/blob/master/manifests/fragments/cycle_a.yaml"))
+			var parse manifest.ErrManifestParse
+			Expect(errors.As(err, &parse)).To(BeTrue())
+			Expect(parse.Manifest).To(Equal("https://github.com/gardener/docforge/blob/master/manifests/include_cycle.yaml"))
+		})
+
+		It("recovers the malformed manifest", func() {
+			for _, example := range []string{"malformed", "html_page"} {
+				var parse manifest.ErrManifestParse
+				Expect(errors.As(resolve(example), &parse)).To(BeTrue())
+				Expect(parse.Manifest).To(Equal("https://github.com/gardener/docforge/blob/master/manifests/" + example + ".yaml"))
+				Expect(errors.As(parse, new(manifest.ErrCyclicImport))).To(BeFalse())
+			}
+		})
+
+		It("recovers the malformed manifest template", func() {
+			_, err := manifest.ResolveManifest("https://github.com/gardener/docforge/blob/master/manifests/template.yaml", r, []string{".md"}, manifest.Options{Templates: manifest.TemplateOptions{Enabled: true}})
+			Expect(errors.As(err, new(manifest.ErrManifestParse))).To(BeTrue())
+		})
+
+		It("keeps the type of failures reading the manifest", func() {
+			err := resolve("missing")
+			Expect(errors.As(err, new(repositoryhost.ErrResourceNotFound))).To(BeTrue())
+			Expect(errors.As(err, new(manifest.ErrManifestParse))).To(BeFalse())
+		})
+	})
+
+	DescribeTable("sets the extensions of file names by their source types", func(defaultExtension string, expected []string) {
+		r := registry.NewRegistry(repositoryhost.NewLocalTest(repo, "https://github.com/gardener/docforge", "tests"))
+		allNodes, err := manifest.ResolveManifest("https://github.com/gardener/docforge/blob/master/manifests/file_extensions.yaml", r, []string{".md", ".markdown", ".html"}, manifest.Options{DefaultExtension: defaultExtension})
+		Expect(err).ToNot(HaveOccurred())
+		names := []string{}
+		for _, node := range allNodes[1:] {
+			names = append(names, node.Name())
+		}
+		Expect(names).To(Equal(expected))
+	},
+		Entry("with the default extension", "", []string{"page.html", "guide.md", "NOTES.md", "overview.md"}),
+		Entry("with a configured default extension", "html", []string{"page.html", "guide.md", "NOTES.html", "overview.md"}),
+	)
+
+	It("accepts manifests with .yml extension", func() {
+		r := registry.NewRegistry(repositoryhost.NewLocalTest(repo, "https://github.com/gardener/docforge", "tests"))
+		allNodes, err := manifest.ResolveManifest("https://github.com/gardener/docforge/blob/master/manifests/short_extension.yml", r, []string{".md"}, manifest.Options{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(allNodes).To(HaveLen(2))
+		Expect(allNodes[1].NodePath()).To(Equal("two.md"))
+	})
+
+	It("accepts manifests with other extensions", func() {
+		r := registry.NewRegistry(repositoryhost.NewLocalTest(repo, "https://github.com/gardener/docforge", "tests"))
+		allNodes, err := manifest.ResolveManifest("https://github.com/gardener/docforge/blob/master/manifests/manifest.txt", r, []string{".md"}, manifest.Options{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(allNodes).To(HaveLen(2))
+		Expect(allNodes[1].NodePath()).To(Equal("README.md"))
+	})
+
+	Describe("manifests with unknown fields", func() {
+		url := "https://github.com/gardener/docforge/blob/master/manifests/unknown_field.yaml"
+
+		It("ignores them by default", func() {
+			r := registry.NewRegistry(repositoryhost.NewLocalTest(repo, "https://github.com/gardener/docforge", "tests"))
+			allNodes, err := manifest.ResolveManifest(url, r, []string{".md"}, manifest.Options{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(allNodes).To(HaveLen(2))
+			Expect(allNodes[1].Frontmatter).ToNot(HaveKey("title"))
+		})
+
+		It("rejects them in strict mode", func() {
+			r := registry.NewRegistry(repositoryhost.NewLocalTest(repo, "https://github.com/gardener/docforge", "tests"))
+			_, err := manifest.ResolveManifest(url, r, []string{".md"}, manifest.Options{Strict: true})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("line 3: field fronmatter not found in type manifest.Node\n3 |   fronmatter:"))
+		})
+	})
+
+	It("prunes dirs left without content by empty file trees", func() {
+		r := registry.NewRegistry(repositoryhost.NewLocalTest(repo, "https://github.com/gardener/docforge", "tests"))
+		allNodes, err := manifest.ResolveManifest("https://github.com/gardener/docforge/blob/master/manifests/prune_empty.yaml", r, []string{".md"}, manifest.Options{})
+		Expect(err).ToNot(HaveOccurred())
+		paths := []string{}
+		for _, node := range allNodes[1:] {
+			paths = append(paths, node.NodePath())
+		}
+		Expect(paths).To(Equal([]string{"howtos", "howtos/install.md", "unpruned"}))
+	})
+
+	Describe("Reading from the file system", func() {
+		var dir string
+		BeforeEach(func() {
+			var err error
+			dir, err = os.MkdirTemp("", "manifest")
+			Expect(err).NotTo(HaveOccurred())
+			for file, content := range map[string]string{
+				"manifest.yaml":       "structure:\n- dir: guides\n  structure:\n  - fileTree: docs\n- file: file://" + filepath.ToSlash(dir) + "/README.md\n",
+				"README.md":           "# Docs",
+				"docs/intro.md":       "# Intro",
+				"docs/advanced/ha.md": "# HA",
+				"docs/notes.txt":      "notes",
+			} {
+				Expect(os.MkdirAll(filepath.Dir(filepath.Join(dir, file)), 0755)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(dir, file), []byte(content), 0644)).To(Succeed())
+			}
+		})
+		AfterEach(func() {
+			Expect(os.RemoveAll(dir)).To(Succeed())
+		})
+
+		It("resolves a manifest by file URL", func() {
+			fs, err := repositoryhost.NewFileSystem(&osshim.OsShim{})
+			Expect(err).NotTo(HaveOccurred())
+			r := registry.NewRegistry(fs)
+			manifestURL, err := repositoryhost.FileSystemResource("file://"+filepath.ToSlash(dir)+"/manifest.yaml", "blob")
+			Expect(err).NotTo(HaveOccurred())
+			allNodes, err := manifest.ResolveManifest(manifestURL, r, []string{".md"}, manifest.Options{})
+			Expect(err).ToNot(HaveOccurred())
+			root, err := repositoryhost.FileSystemResource("file://"+filepath.ToSlash(dir), "blob")
+			Expect(err).NotTo(HaveOccurred())
+			sources := map[string]string{}
+			for _, node := range allNodes {
+				if node.Type == "file" {
+					sources[node.NodePath()] = strings.TrimPrefix(node.Source, root)
+				}
+			}
+			Expect(sources).To(Equal(map[string]string{
+				"README.md":             "/README.md",
+				"guides/intro.md":       "/docs/intro.md",
+				"guides/advanced/ha.md": "/docs/advanced/ha.md",
+			}))
+			content, err := r.Read(context.TODO(), root+"/docs/intro.md")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("# Intro"))
+			Expect(r.ReadGitInfo(context.TODO(), manifestURL)).To(BeNil())
+		})
+	})
+
+	DescribeTable("Printing the resolved structure",
+		func(format string, resultFile string) {
+			r := registry.NewRegistry(repositoryhost.NewLocalTest(repo, "https://github.com/gardener/docforge", "tests"))
+			allNodes, err := manifest.ResolveManifest("https://github.com/gardener/docforge/blob/master/manifests/merging.yaml", r, []string{".md", ".yaml"}, manifest.Options{})
+			Expect(err).ToNot(HaveOccurred())
+			expected, err := results.ReadFile(resultFile)
+			Expect(err).ToNot(HaveOccurred())
+			var b bytes.Buffer
+			Expect(manifest.WriteTree(&b, allNodes[0], format)).To(Succeed())
+			Expect(b.String()).To(Equal(string(expected)))
+		},
+		Entry("as text", "text", "tests/results/merging_tree.txt"),
+		Entry("as json", "json", "tests/results/merging_tree.json"),
+	)
+
+	It("fails to print the resolved structure in unknown format", func() {
+		err := manifest.WriteTree(&bytes.Buffer{}, &manifest.Node{}, "xml")
+		Expect(err).To(MatchError("unknown format 'xml'. Must be one of [text json]"))
+	})
+
+	Describe("Loading repositories in parallel", func() {
+		DescribeTable("resolves the same structure as sequential loading",
+			func(example string) {
+				resolve := func(workers int) ([]*manifest.Node, *registryfakes.FakeInterface) {
+					r := registry.NewRegistry(repositoryhost.NewLocalTest(repo, "https://github.com/gardener/docforge", "tests"))
+					fake := &registryfakes.FakeInterface{}
+					fake.LoadRepositoryCalls(r.LoadRepository)
+					fake.ReadCalls(r.Read)
+					fake.ResolveRelativeLinkCalls(r.ResolveRelativeLink)
+					fake.ResourceURLCalls(r.ResourceURL)
+					fake.TreeCalls(r.Tree)
+					allNodes, err := manifest.ResolveManifest("https://github.com/gardener/docforge/blob/master/manifests/"+example+".yaml", fake, []string{".md", ".yaml"}, manifest.Options{Workers: workers})
+					Expect(err).ToNot(HaveOccurred())
+					return allNodes, fake
+				}
+				sequential, sequentialFake := resolve(1)
+				parallel, parallelFake := resolve(4)
+				Expect(parallel).To(Equal(sequential))
+				Expect(parallelFake.LoadRepositoryCallCount()).To(Equal(sequentialFake.LoadRepositoryCallCount()))
+				Expect(parallelFake.TreeCallCount()).To(Equal(sequentialFake.TreeCallCount()))
+				Expect(parallelFake.ReadCallCount()).To(Equal(sequentialFake.ReadCallCount()))
+			},
+			Entry("with imported manifests", "manifest"),
+			Entry("with merged file trees", "fileTrees"),
+			Entry("with frontmatter filters of file trees", "fileTree_frontmatter"),
+			Entry("with _meta.yaml of file trees", "fileTree_meta"),
+		)
+
+		It("loads no more repositories at a time than the workers", func() {
+			r := registry.NewRegistry(repositoryhost.NewLocalTest(repo, "https://github.com/gardener/docforge", "tests"))
+			fake := &registryfakes.FakeInterface{}
+			var (
+				mux                 sync.Mutex
+				running, maxRunning int
+			)
+			fake.LoadRepositoryCalls(func(ctx context.Context, resourceURL string) error {
+				mux.Lock()
+				running++
+				maxRunning = max(maxRunning, running)
+				mux.Unlock()
+				time.Sleep(10 * time.Millisecond)
+				mux.Lock()
+				running--
+				mux.Unlock()
+				return r.LoadRepository(ctx, resourceURL)
+			})
+			fake.ReadCalls(r.Read)
+			fake.ResolveRelativeLinkCalls(r.ResolveRelativeLink)
+			fake.ResourceURLCalls(r.ResourceURL)
+			fake.TreeCalls(r.Tree)
+			_, err := manifest.ResolveManifest("https://github.com/gardener/docforge/blob/master/manifests/manifest.yaml", fake, []string{".md", ".yaml"}, manifest.Options{Workers: 2})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fake.LoadRepositoryCallCount()).To(BeNumerically(">", 2))
+			Expect(maxRunning).To(BeNumerically("<=", 2))
+		})
+	})
+
+	Describe("Resolving multiple manifests", func() {
+		var r registry.Interface
+		BeforeEach(func() {
+			r = registry.NewRegistry(repositoryhost.NewLocalTest(repo, "https://github.com/gardener/docforge", "tests"))
+		})
+
+		It("merges top-level sections with the same name", func() {
+			allNodes, err := manifest.ResolveManifests([]string{
+				"https://github.com/gardener/docforge/blob/master/manifests/team_a.yaml",
+				"https://github.com/gardener/docforge/blob/master/manifests/team_b.yaml",
+			}, r, []string{".md"}, manifest.Options{})
+			Expect(err).ToNot(HaveOccurred())
+			paths := []string{}
+			for _, node := range allNodes {
+				if node.Type != "manifest" {
+					paths = append(paths, node.NodePath())
+				}
+			}
+			Expect(paths).To(Equal([]string{"section", "section/foo.md", "section/two.md", "README.md", "other", "other/concept.md"}))
+		})
+
+		It("fails when files of different manifests collide", func() {
+			_, err := manifest.ResolveManifests([]string{
+				"https://github.com/gardener/docforge/blob/master/manifests/team_a.yaml",
+				"https://github.com/gardener/docforge/blob/master/manifests/team_c.yaml",
+			}, r, []string{".md"}, manifest.Options{})
+			Expect(err).To(MatchError(ContainSubstring("causes collision with")))
+		})
+
+		It("fails without manifests", func() {
+			_, err := manifest.ResolveManifests(nil, r, []string{".md"}, manifest.Options{})
+			Expect(err).To(MatchError("no manifest to resolve"))
+		})
+	})
+
+	Describe("Excluding nodes from search engines", func() {
+		var allNodes []*manifest.Node
+		BeforeEach(func() {
+			r := registry.NewRegistry(repositoryhost.NewLocalTest(repo, "https://github.com/gardener/docforge", "tests"))
+			var err error
+			allNodes, err = manifest.ResolveManifest("https://github.com/gardener/docforge/blob/master/manifests/noindex.yaml", r, []string{".md"}, manifest.Options{})
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		frontmatter := func(key string) map[string]interface{} {
+			values := map[string]interface{}{}
+			for _, node := range allNodes {
+				if node.Type == "file" {
+					values[node.NodePath()] = node.Frontmatter[key]
+				}
+			}
+			return values
+		}
+
+		It("sets robots to noindex in the files of the nodes with noIndex", func() {
+			manifest.SetNoIndexFrontmatter(allNodes, "")
+			Expect(frontmatter("robots")).To(Equal(map[string]interface{}{
+				"drafts/foo.md":          "noindex",
+				"drafts/two.md":          nil,
+				"guides/foo.md":          nil,
+				"guides/two.md":          "noindex, nofollow",
+				"guides/internal/foo.md": "noindex",
+			}))
+		})
+
+		It("sets the configured frontmatter key", func() {
+			manifest.SetNoIndexFrontmatter(allNodes, "searchExclude")
+			Expect(frontmatter("searchExclude")).To(Equal(map[string]interface{}{
+				"drafts/foo.md":          "noindex",
+				"drafts/two.md":          nil,
+				"guides/foo.md":          nil,
+				"guides/two.md":          "noindex",
+				"guides/internal/foo.md": "noindex",
+			}))
+		})
+	})
+
+	Describe("Deriving weights from file name prefixes", func() {
+		var (
+			r   registry.Interface
+			url string
+		)
+		BeforeEach(func() {
+			r = registry.NewRegistry(repositoryhost.NewLocalTest(repo, "https://github.com/gardener/docforge", "tests"))
+			url = "https://github.com/gardener/docforge/blob/master/manifests/weight_prefixes.yaml"
+		})
+
+		It("strips the prefixes and sets the weights", func() {
+			allNodes, err := manifest.ResolveManifest(url, r, []string{".md"}, manifest.Options{WeightPrefixPattern: `^(\d+)-`})
+			Expect(err).ToNot(HaveOccurred())
+			weights := map[string]interface{}{}
+			for _, node := range allNodes {
+				if node.Type == "file" {
+					weights[node.NodePath()] = node.Frontmatter["weight"]
+				}
+			}
+			Expect(weights).To(Equal(map[string]interface{}{
+				"guides/intro.md":  1,
+				"guides/setup.md":  2,
+				"guides/faq.md":    nil,
+				"guides/pinned.md": 3,
+			}))
+		})
+
+		It("keeps the file names without pattern", func() {
+			allNodes, err := manifest.ResolveManifest(url, r, []string{".md"}, manifest.Options{})
+			Expect(err).ToNot(HaveOccurred())
+			paths := []string{}
+			for _, node := range allNodes {
+				if node.Type == "file" {
+					paths = append(paths, node.NodePath())
+					Expect(node.Frontmatter["weight"]).To(Or(BeNil(), Equal(3)))
+				}
+			}
+			Expect(paths).To(ConsistOf("guides/01-intro.md", "guides/02-setup.md", "guides/faq.md", "guides/10-pinned.md"))
+		})
+
+		It("fails with a pattern not capturing the weight", func() {
+			_, err := manifest.ResolveManifest(url, r, []string{".md"}, manifest.Options{WeightPrefixPattern: `^\d+-`})
+			Expect(err).To(MatchError(`weight prefix pattern ^\d+- has no group capturing the weight`))
+		})
+	})
+
+	Describe("Moving files to their permalinks", func() {
+		var (
+			r          registry.Interface
+			url        string
+			permalinks manifest.PermalinkOptions
+		)
+		BeforeEach(func() {
+			r = registry.NewRegistry(repositoryhost.NewLocalTest(repo, "https://github.com/gardener/docforge", "tests"))
+			url = "https://github.com/gardener/docforge/blob/master/manifests/permalinks.yaml"
+			permalinks = manifest.PermalinkOptions{Pattern: ":section/:slug", SectionFiles: []string{"README.md"}}
+		})
+		filePaths := func(allNodes []*manifest.Node) []string {
+			paths := []string{}
+			for _, node := range allNodes {
+				if node.Type == "file" {
+					paths = append(paths, node.NodePath())
+				}
+			}
+			return paths
+		}
+
+		It("uses the frontmatter slug or the slugified file name", func() {
+			allNodes, err := manifest.ResolveManifest(url, r, []string{".md"}, manifest.Options{Permalinks: permalinks})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(filePaths(allNodes)).To(Equal([]string{"guides/README.md", "guides/introduction.md", "guides/setup-guide.md"}))
+		})
+
+		It("builds the permalinks from the frontmatter date", func() {
+			permalinks.Pattern = "/blog/:year/:month/:day/:slug/"
+			allNodes, err := manifest.ResolveManifest(url, r, []string{".md"}, manifest.Options{Permalinks: permalinks})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(filePaths(allNodes)).To(Equal([]string{"guides/README.md", "blog/2024/05/17/introduction.md", "blog/2023/11/02/setup-guide.md"}))
+		})
+
+		It("fails when files have the same permalink", func() {
+			permalinks.Pattern = "docs/:section"
+			_, err := manifest.ResolveManifest(url, r, []string{".md"}, manifest.Options{Permalinks: permalinks})
+			Expect(err).To(MatchError(ContainSubstring("files guides/01-intro.md and guides/Setup_Guide.md have the same permalink docs/guides")))
+		})
+
+		It("fails when a file has no date", func() {
+			permalinks.Pattern = ":year/:filename"
+			permalinks.SectionFiles = nil
+			_, err := manifest.ResolveManifest(url, r, []string{".md"}, manifest.Options{Permalinks: permalinks})
+			Expect(err).To(MatchError(ContainSubstring("file guides/README.md has no date in frontmatter for its permalink")))
+		})
+
+		It("fails with a pattern without tokens", func() {
+			permalinks.Pattern = "docs"
+			_, err := manifest.ResolveManifest(url, r, []string{".md"}, manifest.Options{Permalinks: permalinks})
+			Expect(err).To(MatchError("permalink pattern docs has no :section, :slug, :filename, :year, :month or :day token"))
+		})
+	})
+
+	Describe("Restricting the structure to changed nodes", func() {
+		var allNodes []*manifest.Node
+		BeforeEach(func() {
+			r := registry.NewRegistry(repositoryhost.NewLocalTest(repo, "https://github.com/gardener/docforge", "tests"))
+			var err error
+			allNodes, err = manifest.ResolveManifest("https://github.com/gardener/docforge/blob/master/manifests/menu.yaml", r, []string{".md"}, manifest.Options{})
+			Expect(err).ToNot(HaveOccurred())
+		})
+		changedSources := func(sources ...string) func(string) (bool, error) {
+			return func(source string) (bool, error) {
+				for _, s := range sources {
+					if s == source {
+						return true, nil
+					}
+				}
+				return false, nil
+			}
+		}
+		nodePaths := func(nodes []*manifest.Node) []string {
+			paths := []string{}
+			for _, node := range nodes[1:] {
+				paths = append(paths, node.NodePath())
+			}
+			return paths
+		}
+
+		It("keeps the changed files with the section files and the dirs containing them", func() {
+			changed, err := manifest.ChangedNodes(allNodes[0], changedSources("https://github.com/gardener/docforge/blob/master/contents/docs/architecture/concept.md"), nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(nodePaths(changed)).To(Equal([]string{"guides", "guides/_index.md", "guides/concept.md"}))
+		})
+
+		It("keeps only the root when nothing changed", func() {
+			changed, err := manifest.ChangedNodes(allNodes[0], changedSources(), nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(changed).To(HaveLen(1))
+			Expect(changed[0].Structure).To(BeEmpty())
+		})
+
+		It("fails when checking a source fails", func() {
+			_, err := manifest.ChangedNodes(allNodes[0], func(string) (bool, error) { return false, fmt.Errorf("comparing failed") }, nil)
+			Expect(err).To(MatchError("comparing failed"))
+		})
+	})
+
+	Describe("Generating the hugo menu", func() {
+		var allNodes []*manifest.Node
+		BeforeEach(func() {
+			r := registry.NewRegistry(repositoryhost.NewLocalTest(repo, "https://github.com/gardener/docforge", "tests"))
+			var err error
+			allNodes, err = manifest.ResolveManifest("https://github.com/gardener/docforge/blob/master/manifests/menu.yaml", r, []string{".md"}, manifest.Options{})
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("derives the entries from the structure", func() {
+			Expect(manifest.HugoMenu(allNodes[0], []string{"README.md"})).To(Equal([]manifest.MenuEntry{
+				{Identifier: "foo", Name: "foo", PageRef: "/foo.md", Weight: 1},
+				{Identifier: "guides", Name: "Guides", PageRef: "/guides", Weight: 2},
+				{Identifier: "guides/concept", Name: "concept", PageRef: "/guides/concept.md", Parent: "guides", Weight: 1},
+				{Identifier: "guides/two", Name: "Second", PageRef: "/guides/two.md", Parent: "guides", Weight: 2},
+			}))
+		})
+
+		It("writes the entries as menus configuration", func() {
+			var b bytes.Buffer
+			Expect(manifest.WriteHugoMenu(&b, allNodes[0], "docs", nil)).To(Succeed())
+			Expect(b.String()).To(HavePrefix("docs:\n  - identifier: foo\n    name: foo\n    pageRef: /foo.md\n    weight: 1\n"))
+		})
+	})
+
+	Describe("Rendering manifest templates", func() {
+		var (
+			r         registry.Interface
+			url       string
+			templates manifest.TemplateOptions
+		)
+		BeforeEach(func() {
+			r = registry.NewRegistry(repositoryhost.NewLocalTest(repo, "https://github.com/gardener/docforge", "tests"))
+			url = "https://github.com/gardener/docforge/blob/master/manifests/template.yaml"
+			templates = manifest.TemplateOptions{
+				Enabled:   true,
+				Vars:      map[string]string{"sections": "One,Two"},
+				Functions: map[string]string{"content": "/contents/{{ . }}"},
+			}
+		})
+
+		It("renders the manifest with the template functions", func() {
+			allNodes, err := manifest.ResolveManifest(url, r, []string{".md"}, manifest.Options{Templates: templates})
+			Expect(err).ToNot(HaveOccurred())
+			files := map[string]string{}
+			for _, node := range allNodes {
+				if node.Type == "file" {
+					files[node.NodePath()] = node.Source
+				}
+			}
+			Expect(files).To(Equal(map[string]string{
+				"one/foo.md": "https://github.com/gardener/docforge/blob/master/contents/blogs/2024/foo.md",
+				"two/foo.md": "https://github.com/gardener/docforge/blob/master/contents/blogs/2024/foo.md",
+				"two.md":     "https://github.com/gardener/docforge/blob/master/contents/blogs/2024/two.md",
+			}))
+			Expect(allNodes[len(allNodes)-1].Frontmatter).To(HaveKeyWithValue("current", true))
+		})
+
+		It("fails for missing variables", func() {
+			templates.Vars = nil
+			_, err := manifest.ResolveManifest(url, r, []string{".md"}, manifest.Options{Templates: templates})
+			Expect(err).To(MatchError(ContainSubstring("can't render manifest " + url + " template")))
+		})
+
+		It("fails for functions overriding built-in functions", func() {
+			templates.Functions = map[string]string{"lower": "{{ . }}"}
+			_, err := manifest.ResolveManifest(url, r, []string{".md"}, manifest.Options{Templates: templates})
+			Expect(err).To(MatchError("template function lower is already defined"))
+		})
+
+		It("ranges over the refs of repositories", func() {
+			fake := &registryfakes.FakeInterface{}
+			fake.LoadRepositoryCalls(r.LoadRepository)
+			fake.ReadCalls(r.Read)
+			fake.ResolveRelativeLinkCalls(r.ResolveRelativeLink)
+			fake.ResourceURLCalls(r.ResourceURL)
+			fake.TreeCalls(r.Tree)
+			fake.ListRefsReturns([]string{"main", "v9.0", "v10.0", "v10.1"}, nil)
+			url = "https://github.com/gardener/docforge/blob/master/manifests/template_refs.yaml"
+			allNodes, err := manifest.ResolveManifest(url, fake, []string{".md"}, manifest.Options{Templates: templates})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fake.ListRefsCallCount()).To(Equal(1))
+			_, repoURL, pattern := fake.ListRefsArgsForCall(0)
+			Expect(repoURL).To(Equal("https://github.com/gardener/docforge"))
+			Expect(pattern).To(Equal(`^v\d+\.\d+\.\d+$`))
+			var paths []string
+			for _, node := range allNodes {
+				if node.Type == "file" {
+					paths = append(paths, node.NodePath())
+				}
+			}
+			Expect(paths).To(Equal([]string{"v10.1/foo.md", "v10.0/foo.md", "v9.0/foo.md", "main/foo.md"}))
+		})
+
+		It("sorts versions in descending semantic version order", func() {
+			Expect(manifest.SortVersions([]string{"v9.0", "main", "v10.0", "v10.1", "latest"})).To(Equal([]string{"v10.1", "v10.0", "v9.0", "main", "latest"}))
+			Expect(manifest.SortVersions([]string{"1.2.0", "v1.10.0", "v1.10.0-rc.1", "1.9"})).To(Equal([]string{"v1.10.0", "v1.10.0-rc.1", "1.9", "1.2.0"}))
+		})
+
+		DescribeTable("selecting the latest versions",
+			func(prereleases manifest.PrereleasePolicy, expected []string) {
+				versions := []string{"main", "v1.9.0", "v1.10.0-rc.1", "v1.8.0", "v1.10.0-beta.2", "v1.9.1", "v1.7.0-rc.1"}
+				Expect(manifest.LatestVersions(3, versions, prereleases)).To(Equal(expected))
+			},
+			Entry("counts prereleases like the other versions by default", manifest.PrereleasePolicy(""), []string{"v1.10.0-rc.1", "v1.10.0-beta.2", "v1.9.1"}),
+			Entry("counts prereleases like the other versions", manifest.PrereleasesInclude, []string{"v1.10.0-rc.1", "v1.10.0-beta.2", "v1.9.1"}),
+			Entry("excludes prereleases", manifest.PrereleasesExclude, []string{"v1.9.1", "v1.9.0", "v1.8.0"}),
+			Entry("counts prereleases separately", manifest.PrereleasesSeparate, []string{"v1.10.0-rc.1", "v1.10.0-beta.2", "v1.9.1", "v1.9.0", "v1.8.0", "v1.7.0-rc.1"}),
+		)
+
+		It("ranges over the latest versions selected by the prereleases option", func() {
+			fake := &registryfakes.FakeInterface{}
+			fake.LoadRepositoryCalls(r.LoadRepository)
+			fake.ReadCalls(r.Read)
+			fake.ResolveRelativeLinkCalls(r.ResolveRelativeLink)
+			fake.ResourceURLCalls(r.ResourceURL)
+			fake.TreeCalls(r.Tree)
+			fake.ListRefsReturns([]string{"main", "v9.0", "v10.0", "v10.1-rc.1", "v10.1"}, nil)
+			templates.Prereleases = manifest.PrereleasesExclude
+			url = "https://github.com/gardener/docforge/blob/master/manifests/template_latest.yaml"
+			allNodes, err := manifest.ResolveManifest(url, fake, []string{".md"}, manifest.Options{Templates: templates})
+			Expect(err).ToNot(HaveOccurred())
+			var dirs []string
+			for _, node := range allNodes {
+				if node.Type == "dir" {
+					dirs = append(dirs, node.Dir)
+				}
+			}
+			Expect(dirs).To(Equal([]string{"v10.1", "v10.0"}))
+		})
+
+		It("fails for unknown prereleases options", func() {
+			templates.Prereleases = "only"
+			_, err := manifest.ResolveManifest(url, r, []string{".md"}, manifest.Options{Templates: templates})
+			Expect(err).To(MatchError("prereleases can be selected with include, exclude or separate, not with only"))
+		})
+	})
+
+	Describe("Importing the same manifest multiple times", func() {
+		It("reads and parses the manifest once", func() {
+			r := registry.NewRegistry(repositoryhost.NewLocalTest(repo, "https://github.com/gardener/docforge", "tests"))
+			fake := &registryfakes.FakeInterface{}
+			fake.LoadRepositoryCalls(r.LoadRepository)
+			fake.ReadCalls(r.Read)
+			fake.ResolveRelativeLinkCalls(r.ResolveRelativeLink)
+			fake.ResourceURLCalls(r.ResourceURL)
+			fake.TreeCalls(r.Tree)
+
+			url := "https://github.com/gardener/docforge/blob/master/manifests/duplicate_module.yaml"
+			allNodes, err := manifest.ResolveManifest(url, fake, []string{".md"}, manifest.Options{})
+			Expect(err).ToNot(HaveOccurred())
+
+			moduleReads := 0
+			for i := 0; i < fake.ReadCallCount(); i++ {
+				if _, resourceURL := fake.ReadArgsForCall(i); resourceURL == "https://github.com/gardener/docforge/blob/master/manifests/module.yaml" {
+					moduleReads++
+				}
+			}
+			Expect(moduleReads).To(Equal(1))
+			files := []*manifest.Node{}
+			for _, node := range allNodes {
+				if node.Type == "file" {
+					files = append(files, node)
+				}
+			}
+			Expect(files).To(HaveLen(2))
+			Expect(files[0].NodePath()).To(Equal("one/foo.md"))
+			Expect(files[1].NodePath()).To(Equal("two/foo.md"))
+			Expect(files[0].Frontmatter).NotTo(BeIdenticalTo(files[1].Frontmatter))
+			Expect(files[0].Frontmatter["aliases"]).To(Equal([]interface{}{"/module/foo/"}))
+		})
+	})
 })
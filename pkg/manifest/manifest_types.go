@@ -10,8 +10,14 @@ type FileType struct {
 	File string `yaml:"file,omitempty"`
 	// Source is the source of file. If empty File must be the url
 	Source string `yaml:"source,omitempty"`
+	// SourceFallbacks are tried in order when Source doesn't exist. Source is set to the first one that exists
+	SourceFallbacks []string `yaml:"sourceFallbacks,omitempty"`
 	// MultiSource is a file build from multiple sources
 	MultiSource []string `yaml:"multiSource,omitempty"`
+	// ExternalURL is the web URL the file links to instead of having content from a source
+	ExternalURL string `yaml:"externalURL,omitempty"`
+	// AnchorRedirects maps the anchors of renamed sections of the file to their new anchors, so that the links to the old anchors keep working
+	AnchorRedirects map[string]string `yaml:"anchorRedirects,omitempty"`
 }
 
 // DirType represents a directory node
@@ -26,8 +32,16 @@ type DirType struct {
 type FilesTreeType struct {
 	// FileTree is a tree url of a repo
 	FileTree string `yaml:"fileTree,omitempty"`
+	// FileTrees are tree urls merged with FileTree into the same structure. Files with the same path in an earlier tree are skipped
+	FileTrees []string `yaml:"fileTrees,omitempty"`
 	// ExcludeFiles files to be excluded
 	ExcludeFiles []string `yaml:"excludeFiles,omitempty"`
+	// Extensions of files included as nodes. Defaults to the content file formats
+	Extensions []string `yaml:"extensions,omitempty"`
+	// FrontmatterFilter includes only the files whose frontmatter has all of its key values
+	FrontmatterFilter map[string]interface{} `yaml:"frontmatterFilter,omitempty"`
+	// PruneIfEmpty removes the dir containing the file tree when the tree has no files and the dir has no other content
+	PruneIfEmpty bool `yaml:"pruneIfEmpty,omitempty"`
 }
 
 // ManifType represents a manifest node
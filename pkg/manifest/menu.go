@@ -0,0 +1,89 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package manifest
+
+import (
+	"io"
+	"path"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MenuEntry is an entry of a hugo menu
+type MenuEntry struct {
+	Identifier string `yaml:"identifier"`
+	Name       string `yaml:"name"`
+	PageRef    string `yaml:"pageRef"`
+	Parent     string `yaml:"parent,omitempty"`
+	Weight     int    `yaml:"weight"`
+}
+
+// HugoMenu returns the hugo menu entries of the resolved node structure.
+// Dirs are entries of their section and their section files are not listed separately.
+// Entries are weighted by their order in the structure of their parent
+func HugoMenu(node *Node, indexFileNames []string) []MenuEntry {
+	return menuEntries(node, "", indexFileNames)
+}
+
+func menuEntries(node *Node, parent string, indexFileNames []string) []MenuEntry {
+	entries := []MenuEntry{}
+	weight := 0
+	for _, child := range node.Structure {
+		if child.Type == "file" && isSectionFile(child.Name(), indexFileNames) {
+			continue
+		}
+		if child.Type != "file" && child.Type != "dir" {
+			continue
+		}
+		weight++
+		identifier := strings.TrimSuffix(child.NodePath(), ".md")
+		entry := MenuEntry{
+			Identifier: identifier,
+			Name:       menuEntryName(child, indexFileNames),
+			PageRef:    "/" + child.NodePath(),
+			Parent:     parent,
+			Weight:     weight,
+		}
+		entries = append(entries, entry)
+		if child.Type == "dir" {
+			entries = append(entries, menuEntries(child, identifier, indexFileNames)...)
+		}
+	}
+	return entries
+}
+
+// menuEntryName returns the title of the node or of the section file of a dir, defaulting to the node name
+func menuEntryName(node *Node, indexFileNames []string) string {
+	fm := node.Frontmatter
+	if node.Type == "dir" {
+		fm = nil
+		for _, child := range node.Structure {
+			if child.Type == "file" && isSectionFile(child.Name(), indexFileNames) {
+				fm = child.Frontmatter
+			}
+		}
+	}
+	if title, ok := fm["title"].(string); ok && title != "" {
+		return title
+	}
+	return strings.TrimSuffix(path.Base(node.Name()), ".md")
+}
+
+func isSectionFile(name string, indexFileNames []string) bool {
+	return name == sectionFile || slices.Contains(indexFileNames, name)
+}
+
+// WriteHugoMenu writes the hugo menu entries of the resolved node structure as
+// menus configuration with the given menu name
+func WriteHugoMenu(w io.Writer, node *Node, menuName string, indexFileNames []string) error {
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(map[string][]MenuEntry{menuName: HugoMenu(node, indexFileNames)}); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
@@ -5,7 +5,10 @@
 package manifest
 
 import (
+	"fmt"
+	"maps"
 	"path"
+	"slices"
 	"strings"
 
 	"gopkg.in/yaml.v3"
@@ -23,6 +26,13 @@ type Node struct {
 
 	// Properties of the node
 	SkipValidation bool `yaml:"skipValidation,omitempty"`
+	// ResourcesPath is the path relative to the resources root where the resources referenced in the node subtree are downloaded
+	ResourcesPath string `yaml:"resourcesPath,omitempty"`
+	// ResourcesRoot is the path relative to the destination that replaces the resources root of the node subtree, e.g. to
+	// download the resources next to the documents
+	ResourcesRoot string `yaml:"resourcesRoot,omitempty"`
+	// NoIndex excludes the files of the node subtree from search engines, nodes in the subtree can override it
+	NoIndex *bool `yaml:"noIndex,omitempty"`
 	// Frontmatter of the node
 	Frontmatter map[string]interface{} `yaml:"frontmatter,omitempty"`
 	// Type of node
@@ -31,6 +41,8 @@ type Node struct {
 	Path string `yaml:"path,omitempty"`
 	// Parent of node
 	parent *Node
+	// prunable is set on dirs with a file tree that has no files and PruneIfEmpty set
+	prunable bool
 }
 
 // Name is the name of the node
@@ -45,6 +57,14 @@ func (n *Node) Name() string {
 	}
 }
 
+// trees returns the tree urls of a fileTree node
+func (n *Node) trees() []string {
+	if n.FileTree == "" {
+		return n.FileTrees
+	}
+	return append([]string{n.FileTree}, n.FileTrees...)
+}
+
 // NodePath returns fully qualified name of this node
 // i.e. Node.Path + Node.Name
 func (n *Node) NodePath() string {
@@ -59,6 +79,14 @@ func (n *Node) HugoPrettyPath() string {
 	return path.Join(n.Path, name) + "/"
 }
 
+// Version returns the version of the node set by the version frontmatter property, which dirs pass to their children
+func (n *Node) Version() string {
+	if v, ok := n.Frontmatter["version"]; ok && v != nil {
+		return fmt.Sprint(v)
+	}
+	return ""
+}
+
 // HasContent returns true if the node is a document node
 func (n *Node) HasContent() bool {
 	return len(n.MultiSource) > 0 || len(n.Source) > 0
@@ -69,6 +97,121 @@ func (n *Node) Parent() *Node {
 	return n.parent
 }
 
+// clone returns a deep copy of the node and its structure
+func (n *Node) clone() *Node {
+	c := *n
+	c.MultiSource = slices.Clone(n.MultiSource)
+	c.SourceFallbacks = slices.Clone(n.SourceFallbacks)
+	c.ExcludeFiles = slices.Clone(n.ExcludeFiles)
+	c.Extensions = slices.Clone(n.Extensions)
+	c.FileTrees = slices.Clone(n.FileTrees)
+	c.AnchorRedirects = maps.Clone(n.AnchorRedirects)
+	if n.FrontmatterFilter != nil {
+		c.FrontmatterFilter = cloneValue(n.FrontmatterFilter).(map[string]interface{})
+	}
+	if n.Frontmatter != nil {
+		c.Frontmatter = cloneValue(n.Frontmatter).(map[string]interface{})
+	}
+	if n.Structure != nil {
+		c.Structure = make([]*Node, len(n.Structure))
+		for i, child := range n.Structure {
+			c.Structure[i] = child.clone()
+		}
+	}
+	return &c
+}
+
+// merge sets the properties of the node that are not set from the other node, merging the keys of their maps
+func (n *Node) merge(o *Node) {
+	n.Manifest = firstNonEmpty(n.Manifest, o.Manifest)
+	n.File = firstNonEmpty(n.File, o.File)
+	n.Source = firstNonEmpty(n.Source, o.Source)
+	if n.SourceFallbacks == nil {
+		n.SourceFallbacks = o.SourceFallbacks
+	}
+	if n.MultiSource == nil {
+		n.MultiSource = o.MultiSource
+	}
+	n.ExternalURL = firstNonEmpty(n.ExternalURL, o.ExternalURL)
+	n.AnchorRedirects = mergeMaps(n.AnchorRedirects, o.AnchorRedirects)
+	n.Dir = firstNonEmpty(n.Dir, o.Dir)
+	if n.Structure == nil {
+		n.Structure = o.Structure
+	}
+	n.FileTree = firstNonEmpty(n.FileTree, o.FileTree)
+	if n.FileTrees == nil {
+		n.FileTrees = o.FileTrees
+	}
+	if n.ExcludeFiles == nil {
+		n.ExcludeFiles = o.ExcludeFiles
+	}
+	if n.Extensions == nil {
+		n.Extensions = o.Extensions
+	}
+	n.FrontmatterFilter = mergeMaps(n.FrontmatterFilter, o.FrontmatterFilter)
+	n.PruneIfEmpty = n.PruneIfEmpty || o.PruneIfEmpty
+	n.SkipValidation = n.SkipValidation || o.SkipValidation
+	n.ResourcesPath = firstNonEmpty(n.ResourcesPath, o.ResourcesPath)
+	n.ResourcesRoot = firstNonEmpty(n.ResourcesRoot, o.ResourcesRoot)
+	if n.NoIndex == nil {
+		n.NoIndex = o.NoIndex
+	}
+	n.Frontmatter = mergeMaps(n.Frontmatter, o.Frontmatter)
+	n.Type = firstNonEmpty(n.Type, o.Type)
+	n.Path = firstNonEmpty(n.Path, o.Path)
+}
+
+// firstNonEmpty returns the first of the values that is not empty
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// mergeMaps returns the keys of both maps, with the values of the first map for the keys they have in common
+func mergeMaps[V any](m map[string]V, o map[string]V) map[string]V {
+	if o == nil {
+		return m
+	}
+	merged := make(map[string]V, len(m)+len(o))
+	for k, v := range o {
+		merged[k] = v
+	}
+	for k, v := range m {
+		merged[k] = v
+	}
+	return merged
+}
+
+// cloneValue deep copies frontmatter values
+func cloneValue(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		c := make(map[string]interface{}, len(value))
+		for k, e := range value {
+			c[k] = cloneValue(e)
+		}
+		return c
+	case map[interface{}]interface{}:
+		c := make(map[interface{}]interface{}, len(value))
+		for k, e := range value {
+			c[k] = cloneValue(e)
+		}
+		return c
+	case []interface{}:
+		c := make([]interface{}, len(value))
+		for i, e := range value {
+			c[i] = cloneValue(e)
+		}
+		return c
+	default:
+		return v
+	}
+}
+
 func (n *Node) String() string {
 	node, err := yaml.Marshal(n)
 	if err != nil {
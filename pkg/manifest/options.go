@@ -0,0 +1,22 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package manifest
+
+// Options configure how manifests are resolved
+type Options struct {
+	// Workers is the number of repositories of node resources loaded, and of file trees selected, in parallel
+	Workers int
+	// WeightPrefixPattern strips the file name prefixes matching it. The number captured by its first group
+	// becomes the weight of the file
+	WeightPrefixPattern string
+	// Permalinks move the files to their permalinks when a pattern is set
+	Permalinks PermalinkOptions
+	// Templates render the manifests as Go templates when enabled
+	Templates TemplateOptions
+	// DefaultExtension is the extension of file names without one whose source type is unknown, .md when empty
+	DefaultExtension string
+	// Strict fails to parse manifests with unknown fields, like misspelled properties
+	Strict bool
+}
@@ -0,0 +1,119 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package manifest
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gardener/docforge/pkg/registry"
+)
+
+// PermalinkOptions configures the locations of file nodes by a permalink pattern
+type PermalinkOptions struct {
+	// Pattern is the permalink of the file nodes, built from the tokens :section, :slug, :filename, :year, :month and :day
+	Pattern string
+	// SectionFiles are the file names of section files, which keep their location
+	SectionFiles []string
+}
+
+var permalinkTokens = regexp.MustCompile(`:(section|slug|filename|year|month|day)`)
+
+var slugSeparators = regexp.MustCompile(`[\s_]+`)
+
+// permalinks moves file nodes to the locations given by their permalink
+type permalinks struct {
+	PermalinkOptions
+	// nodes are the node paths by permalink, to detect collisions
+	nodes map[string]string
+}
+
+func newPermalinks(opts PermalinkOptions) (*permalinks, error) {
+	if !permalinkTokens.MatchString(opts.Pattern) {
+		return nil, fmt.Errorf("permalink pattern %s has no :section, :slug, :filename, :year, :month or :day token", opts.Pattern)
+	}
+	return &permalinks{PermalinkOptions: opts, nodes: map[string]string{}}, nil
+}
+
+// applyPermalink sets the path and the name of a file node from its permalink
+func (p *permalinks) applyPermalink(node *Node, _ *Node, _ *Node, _ registry.Interface, _ []string) error {
+	if node.Type != "file" || isSectionFile(node.File, p.SectionFiles) {
+		return nil
+	}
+	link, err := p.permalink(node)
+	if err != nil {
+		return err
+	}
+	if other, ok := p.nodes[link]; ok {
+		return fmt.Errorf("files %s and %s have the same permalink %s", other, node.NodePath(), link)
+	}
+	p.nodes[link] = node.NodePath()
+	dir, name := path.Split(link)
+	node.Path = path.Clean(dir)
+	node.File = name + path.Ext(node.File)
+	return nil
+}
+
+// permalink returns the permalink of the file node, relative to the root of the bundle
+func (p *permalinks) permalink(node *Node) (string, error) {
+	filename := strings.TrimSuffix(node.File, path.Ext(node.File))
+	var date *time.Time
+	var err error
+	link := permalinkTokens.ReplaceAllStringFunc(p.Pattern, func(token string) string {
+		switch token {
+		case ":section":
+			return strings.TrimPrefix(node.Path, ".")
+		case ":slug":
+			if slug, ok := node.Frontmatter["slug"].(string); ok && slug != "" {
+				return slug
+			}
+			return strings.ToLower(slugSeparators.ReplaceAllString(filename, "-"))
+		case ":filename":
+			return filename
+		}
+		if date == nil && err == nil {
+			date, err = frontmatterDate(node)
+		}
+		if err != nil {
+			return ""
+		}
+		switch token {
+		case ":year":
+			return date.Format("2006")
+		case ":month":
+			return date.Format("01")
+		default:
+			return date.Format("02")
+		}
+	})
+	if err != nil {
+		return "", err
+	}
+	link = path.Clean(strings.Trim(link, "/"))
+	if link == "." || strings.HasPrefix(link, "..") {
+		return "", fmt.Errorf("file %s has invalid permalink %s", node.NodePath(), link)
+	}
+	return link, nil
+}
+
+// frontmatterDate returns the date from the frontmatter of the node
+func frontmatterDate(node *Node) (*time.Time, error) {
+	switch date := node.Frontmatter["date"].(type) {
+	case time.Time:
+		return &date, nil
+	case string:
+		if len(date) >= len(time.DateOnly) {
+			if t, err := time.Parse(time.DateOnly, date[:len(time.DateOnly)]); err == nil {
+				return &t, nil
+			}
+		}
+		return nil, fmt.Errorf("file %s has invalid date %s in frontmatter", node.NodePath(), date)
+	default:
+		return nil, fmt.Errorf("file %s has no date in frontmatter for its permalink", node.NodePath())
+	}
+}
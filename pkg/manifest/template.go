@@ -0,0 +1,173 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package manifest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/gardener/docforge/pkg/registry"
+	"golang.org/x/mod/semver"
+)
+
+// TemplateOptions configures rendering manifests as Go templates before parsing them
+type TemplateOptions struct {
+	// Enabled renders the manifests as templates
+	Enabled bool `mapstructure:"manifest-templates"`
+	// Vars are available in the templates as .Vars
+	Vars map[string]string `mapstructure:"manifest-template-vars"`
+	// Functions are additional template functions with one argument, defined by templates rendered with the argument as dot
+	Functions map[string]string `mapstructure:"manifest-template-functions"`
+	// Prereleases is the treatment of prereleases, like v1.0.0-rc.1, by the latestVersions function. Defaults to PrereleasesInclude
+	Prereleases PrereleasePolicy `mapstructure:"manifest-template-prereleases"`
+}
+
+// PrereleasePolicy defines how prereleases are selected among the latest versions
+type PrereleasePolicy string
+
+const (
+	// PrereleasesInclude selects prereleases like the other versions
+	PrereleasesInclude PrereleasePolicy = "include"
+	// PrereleasesExclude selects no prereleases
+	PrereleasesExclude PrereleasePolicy = "exclude"
+	// PrereleasesSeparate selects the latest prereleases in addition to the latest other versions
+	PrereleasesSeparate PrereleasePolicy = "separate"
+)
+
+// templateData is the data manifest templates are rendered with
+type templateData struct {
+	// Manifest is the URL of the rendered manifest
+	Manifest string
+	Vars     map[string]string
+}
+
+// templateFuncs returns the functions available in manifest templates. The subject of
+// string functions is their last argument, so that they can be used in pipelines
+func templateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"split":        func(sep, s string) []string { return strings.Split(s, sep) },
+		"join":         func(sep string, elems []string) string { return strings.Join(elems, sep) },
+		"trimPrefix":   func(prefix, s string) string { return strings.TrimPrefix(s, prefix) },
+		"trimSuffix":   func(suffix, s string) string { return strings.TrimSuffix(s, suffix) },
+		"replace":      func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+		"lower":        strings.ToLower,
+		"upper":        strings.ToUpper,
+		"contains":     func(substr, s string) bool { return strings.Contains(s, substr) },
+		"hasPrefix":    func(prefix, s string) bool { return strings.HasPrefix(s, prefix) },
+		"now":          time.Now,
+		"date":         func(layout string, t time.Time) string { return t.Format(layout) },
+		"sortVersions": SortVersions,
+	}
+}
+
+// SortVersions sorts versions like v10.1, v10.0 and v9 in descending semantic version order, with or without the v
+// prefix. The versions that aren't semantic versions, like main, follow in their order
+func SortVersions(versions []string) []string {
+	sorted := slices.Clone(versions)
+	slices.SortStableFunc(sorted, func(a, b string) int {
+		va, vb := canonicalVersion(a), canonicalVersion(b)
+		switch {
+		case va == "" && vb == "":
+			return 0
+		case va == "":
+			return 1
+		case vb == "":
+			return -1
+		}
+		return semver.Compare(vb, va)
+	})
+	return sorted
+}
+
+// LatestVersions returns the n latest semantic versions, in descending order. The other names are dropped. Prereleases are
+// selected by the policy: counted like the other versions, excluded, or counted separately so that the n latest
+// prereleases are selected in addition to the n latest other versions
+func LatestVersions(n int, versions []string, prereleases PrereleasePolicy) []string {
+	var stable, pre []string
+	for _, version := range SortVersions(versions) {
+		canonical := canonicalVersion(version)
+		switch {
+		case canonical == "":
+		case semver.Prerelease(canonical) == "" || prereleases == PrereleasesInclude || prereleases == "":
+			stable = append(stable, version)
+		case prereleases == PrereleasesSeparate:
+			pre = append(pre, version)
+		}
+	}
+	n = max(n, 0)
+	latest := append(stable[:min(n, len(stable))], pre[:min(n, len(pre))]...)
+	return SortVersions(latest)
+}
+
+// canonicalVersion returns the semantic version of the v prefixed version, or "" if it isn't one
+func canonicalVersion(version string) string {
+	if !strings.HasPrefix(version, "v") {
+		version = "v" + version
+	}
+	return semver.Canonical(version)
+}
+
+// manifestTemplate renders manifests as Go templates
+type manifestTemplate struct {
+	funcs template.FuncMap
+	vars  map[string]string
+}
+
+// newManifestTemplate creates a manifestTemplate with the built-in and the configured functions. The refs function
+// lists the branches and tags of repositories with r, sorted by SortVersions, and the latestVersions function selects
+// prereleases by the prereleases option
+func newManifestTemplate(opts TemplateOptions, r registry.Interface) (*manifestTemplate, error) {
+	if opts.Prereleases != "" && opts.Prereleases != PrereleasesInclude && opts.Prereleases != PrereleasesExclude && opts.Prereleases != PrereleasesSeparate {
+		return nil, fmt.Errorf("prereleases can be selected with %s, %s or %s, not with %s", PrereleasesInclude, PrereleasesExclude, PrereleasesSeparate, opts.Prereleases)
+	}
+	funcs := templateFuncs()
+	funcs["latestVersions"] = func(n int, versions []string) []string {
+		return LatestVersions(n, versions, opts.Prereleases)
+	}
+	funcs["refs"] = func(repoURL, pattern string) ([]string, error) {
+		refs, err := r.ListRefs(context.TODO(), repoURL, pattern)
+		return SortVersions(refs), err
+	}
+	names := make([]string, 0, len(opts.Functions))
+	for name := range opts.Functions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, ok := funcs[name]; ok {
+			return nil, fmt.Errorf("template function %s is already defined", name)
+		}
+		// configured functions can use the built-in functions only
+		fn, err := template.New(name).Funcs(templateFuncs()).Parse(opts.Functions[name])
+		if err != nil {
+			return nil, fmt.Errorf("invalid template function %s: %w", name, err)
+		}
+		funcs[name] = func(arg interface{}) (string, error) {
+			var b bytes.Buffer
+			err := fn.Execute(&b, arg)
+			return b.String(), err
+		}
+	}
+	return &manifestTemplate{funcs: funcs, vars: opts.Vars}, nil
+}
+
+// render renders the content of the manifest at manifestURL
+func (t *manifestTemplate) render(manifestURL string, content []byte) ([]byte, error) {
+	tmpl, err := template.New(manifestURL).Funcs(t.funcs).Option("missingkey=error").Parse(string(content))
+	if err != nil {
+		return nil, ErrManifestParse{manifestURL, fmt.Errorf("can't parse manifest %s template : %w", manifestURL, err)}
+	}
+	var b bytes.Buffer
+	if err = tmpl.Execute(&b, templateData{Manifest: manifestURL, Vars: t.vars}); err != nil {
+		return nil, ErrManifestParse{manifestURL, fmt.Errorf("can't render manifest %s template : %w", manifestURL, err)}
+	}
+	return b.Bytes(), nil
+}
@@ -0,0 +1,78 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package manifest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// TreeNode is a printable view of a resolved node
+type TreeNode struct {
+	Name        string      `json:"name"`
+	Type        string      `json:"type"`
+	Path        string      `json:"path,omitempty"`
+	Source      string      `json:"source,omitempty"`
+	MultiSource []string    `json:"multiSource,omitempty"`
+	Children    []*TreeNode `json:"children,omitempty"`
+}
+
+// Tree returns the printable view of a resolved node and its structure
+func Tree(node *Node) *TreeNode {
+	t := &TreeNode{
+		Name:        node.Name(),
+		Type:        node.Type,
+		Path:        node.NodePath(),
+		Source:      node.Source,
+		MultiSource: node.MultiSource,
+	}
+	if t.Name == "" {
+		t.Name = "."
+	}
+	for _, child := range node.Structure {
+		t.Children = append(t.Children, Tree(child))
+	}
+	return t
+}
+
+// WriteTree writes the resolved node structure in `text` or `json` format
+func WriteTree(w io.Writer, node *Node, format string) error {
+	tree := Tree(node)
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(tree)
+	case "text":
+		return writeTextTree(w, tree, 0)
+	}
+	return fmt.Errorf("unknown format '%s'. Must be one of %v", format, []string{"text", "json"})
+}
+
+func writeTextTree(w io.Writer, t *TreeNode, depth int) error {
+	line := strings.Repeat("  ", depth) + t.Name
+	if t.Type == "dir" {
+		line += "/"
+	}
+	if t.Source != "" {
+		line += " <- " + t.Source
+	}
+	if _, err := fmt.Fprintln(w, line); err != nil {
+		return err
+	}
+	for _, s := range t.MultiSource {
+		if _, err := fmt.Fprintln(w, strings.Repeat("  ", depth+1)+"+ "+s); err != nil {
+			return err
+		}
+	}
+	for _, child := range t.Children {
+		if err := writeTextTree(w, child, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,261 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// Options configures exporting the metrics of a run
+type Options struct {
+	// Address serves the metrics at /metrics while the run lasts
+	Address string `mapstructure:"metrics-address"`
+	// Pushgateway is the URL of a Prometheus pushgateway the metrics are pushed to when the run ends
+	Pushgateway string `mapstructure:"metrics-pushgateway"`
+}
+
+var (
+	// DocumentsProcessed counts the processed document nodes by coverage status
+	DocumentsProcessed = NewCounterVec("docforge_documents_processed_total", "Document nodes processed by coverage status.", "status")
+	// ResourcesDownloaded counts the downloaded resources
+	ResourcesDownloaded = NewCounter("docforge_resources_downloaded_total", "Resources downloaded.")
+	// APICalls counts the requests sent to repository hosts, without the ones served from cache, by host
+	APICalls = NewCounterVec("docforge_api_calls_total", "Requests sent to repository hosts by host.", "host")
+	// ValidationFailures counts the links failing validation
+	ValidationFailures = NewCounter("docforge_link_validation_failures_total", "Links failing validation.")
+	// DocumentDuration observes the processing time of document nodes
+	DocumentDuration = NewHistogram("docforge_document_processing_seconds", "Processing time of document nodes in seconds.", []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10})
+
+	collectors = []collector{DocumentsProcessed, ResourcesDownloaded, APICalls, ValidationFailures, DocumentDuration}
+)
+
+// collector writes a metric in the Prometheus text exposition format
+type collector interface {
+	write(w io.Writer)
+}
+
+// Counter is a monotonically increasing value
+type Counter struct {
+	name string
+	help string
+
+	mux   sync.Mutex
+	value float64
+}
+
+// NewCounter creates a counter
+func NewCounter(name string, help string) *Counter {
+	return &Counter{name: name, help: help}
+}
+
+// Inc increments the counter
+func (c *Counter) Inc() {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.value++
+}
+
+// Value returns the counter
+func (c *Counter) Value() float64 {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	return c.value
+}
+
+func (c *Counter) write(w io.Writer) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	writeHeader(w, c.name, c.help, "counter")
+	_, _ = fmt.Fprintf(w, "%s %s\n", c.name, formatValue(c.value))
+}
+
+// CounterVec is a counter partitioned by the values of a label
+type CounterVec struct {
+	name  string
+	help  string
+	label string
+
+	mux    sync.Mutex
+	values map[string]float64
+}
+
+// NewCounterVec creates a counter partitioned by the values of label
+func NewCounterVec(name string, help string, label string) *CounterVec {
+	return &CounterVec{name: name, help: help, label: label, values: map[string]float64{}}
+}
+
+// Inc increments the counter of the label value
+func (c *CounterVec) Inc(value string) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.values[value]++
+}
+
+// Value returns the counter of the label value
+func (c *CounterVec) Value(value string) float64 {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	return c.values[value]
+}
+
+func (c *CounterVec) write(w io.Writer) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	writeHeader(w, c.name, c.help, "counter")
+	values := make([]string, 0, len(c.values))
+	for value := range c.values {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+	for _, value := range values {
+		_, _ = fmt.Fprintf(w, "%s{%s=\"%s\"} %s\n", c.name, c.label, escapeLabel(value), formatValue(c.values[value]))
+	}
+}
+
+// Histogram counts observations in cumulative buckets
+type Histogram struct {
+	name    string
+	help    string
+	buckets []float64
+
+	mux    sync.Mutex
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// NewHistogram creates a histogram with the sorted upper bounds of its buckets
+func NewHistogram(name string, help string, buckets []float64) *Histogram {
+	return &Histogram{name: name, help: help, buckets: buckets, counts: make([]uint64, len(buckets))}
+}
+
+// Observe adds an observation
+func (h *Histogram) Observe(v float64) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	for i, bound := range h.buckets {
+		if v <= bound {
+			h.counts[i]++
+		}
+	}
+	h.count++
+	h.sum += v
+}
+
+// ObserveSince adds the seconds elapsed since start as observation
+func (h *Histogram) ObserveSince(start time.Time) {
+	h.Observe(time.Since(start).Seconds())
+}
+
+// Count returns the number of observations
+func (h *Histogram) Count() uint64 {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	return h.count
+}
+
+func (h *Histogram) write(w io.Writer) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	writeHeader(w, h.name, h.help, "histogram")
+	for i, bound := range h.buckets {
+		_, _ = fmt.Fprintf(w, "%s_bucket{le=\"%s\"} %d\n", h.name, formatValue(bound), h.counts[i])
+	}
+	_, _ = fmt.Fprintf(w, "%s_bucket{le=\"+Inf\"} %d\n", h.name, h.count)
+	_, _ = fmt.Fprintf(w, "%s_sum %s\n", h.name, formatValue(h.sum))
+	_, _ = fmt.Fprintf(w, "%s_count %d\n", h.name, h.count)
+}
+
+func writeHeader(w io.Writer, name string, help string, metricType string) {
+	_, _ = fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, metricType)
+}
+
+func formatValue(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func escapeLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+// Write writes the docforge metrics in the Prometheus text exposition format
+func Write(w io.Writer) {
+	for _, c := range collectors {
+		c.write(w)
+	}
+}
+
+// Handler serves the docforge metrics
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		Write(w)
+	})
+}
+
+// Serve serves the docforge metrics at /metrics on the address until the returned server is shut down
+func Serve(address string) (*http.Server, error) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("can't serve metrics on %s: %w", address, err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", Handler())
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			klog.Warningf("serving metrics on %s failed: %v", address, err)
+		}
+	}()
+	return server, nil
+}
+
+// Push replaces the metrics of the job in the pushgateway with the docforge metrics
+func Push(ctx context.Context, client *http.Client, pushgateway string, job string) error {
+	var b bytes.Buffer
+	Write(&b)
+	pushURL := strings.TrimSuffix(pushgateway, "/") + "/metrics/job/" + url.PathEscape(job)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, pushURL, &b)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; version=0.0.4")
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("pushing metrics to %s failed: %w", pushURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("pushing metrics to %s fails with HTTP status: %d", pushURL, resp.StatusCode)
+	}
+	return nil
+}
+
+// InstrumentRoundTripper counts the requests sent by the round tripper as API calls of their host
+func InstrumentRoundTripper(next http.RoundTripper) http.RoundTripper {
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		APICalls.Inc(req.URL.Host)
+		return next.RoundTrip(req)
+	})
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
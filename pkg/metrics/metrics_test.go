@@ -0,0 +1,84 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package metrics_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/gardener/docforge/pkg/metrics"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Metrics", func() {
+	Describe("#Write", func() {
+		It("writes the metrics in the text exposition format", func() {
+			counter := metrics.NewCounter("test_total", "Test counter.")
+			counter.Inc()
+			vec := metrics.NewCounterVec("test_by_label_total", "Test counter vec.", "label")
+			vec.Inc("b")
+			vec.Inc("a")
+			vec.Inc("a")
+			histogram := metrics.NewHistogram("test_seconds", "Test histogram.", []float64{0.1, 1})
+			histogram.Observe(0.5)
+			histogram.Observe(2)
+			Expect(counter.Value()).To(Equal(float64(1)))
+			Expect(vec.Value("a")).To(Equal(float64(2)))
+			Expect(histogram.Count()).To(Equal(uint64(2)))
+
+			var b bytes.Buffer
+			metrics.Write(&b)
+			Expect(b.String()).To(ContainSubstring("# TYPE docforge_documents_processed_total counter\n"))
+			Expect(b.String()).To(ContainSubstring("# TYPE docforge_document_processing_seconds histogram\n"))
+			Expect(b.String()).To(ContainSubstring("docforge_document_processing_seconds_bucket{le=\"+Inf\"} "))
+		})
+	})
+
+	Describe("#Push", func() {
+		It("puts the metrics for the job", func() {
+			var method, path, body string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				b, _ := io.ReadAll(r.Body)
+				method, path, body = r.Method, r.URL.Path, string(b)
+			}))
+			defer server.Close()
+			Expect(metrics.Push(context.TODO(), server.Client(), server.URL+"/", "docforge")).To(Succeed())
+			Expect(method).To(Equal(http.MethodPut))
+			Expect(path).To(Equal("/metrics/job/docforge"))
+			Expect(body).To(ContainSubstring("docforge_resources_downloaded_total "))
+		})
+
+		It("fails on error status", func() {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusBadRequest)
+			}))
+			defer server.Close()
+			Expect(metrics.Push(context.TODO(), server.Client(), server.URL, "docforge")).To(MatchError(ContainSubstring("HTTP status: 400")))
+		})
+	})
+
+	Describe("#InstrumentRoundTripper", func() {
+		It("counts the API calls by host", func() {
+			server := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+			defer server.Close()
+			host := strings.TrimPrefix(server.URL, "http://")
+			calls := metrics.APICalls.Value(host)
+			client := &http.Client{Transport: metrics.InstrumentRoundTripper(http.DefaultTransport)}
+			resp, err := client.Get(server.URL)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.Body.Close()).To(Succeed())
+			Expect(metrics.APICalls.Value(host)).To(Equal(calls + 1))
+
+			rec := httptest.NewRecorder()
+			metrics.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+			Expect(rec.Body.String()).To(ContainSubstring("docforge_api_calls_total{host=\"" + host + "\"} "))
+		})
+	})
+})
@@ -0,0 +1,66 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package httpclient
+
+import (
+	"net/http"
+	"path"
+	"strings"
+)
+
+// Headers are set on the outbound requests
+type Headers struct {
+	// UserAgent replaces the user agent of all requests when set
+	UserAgent string `mapstructure:"user-agent"`
+	// Values are the headers set on the requests to the hosts
+	Values map[string]string `mapstructure:"http-headers"`
+	// Hosts are the patterns of the hosts, like *.example.com, the header values are sent to. They are sent to no host when empty
+	Hosts []string `mapstructure:"http-headers-hosts"`
+}
+
+// RoundTripper returns a round tripper setting the headers on the requests of next. Each request, including
+// the ones following redirects, gets the header values only if its host matches the hosts
+func (h Headers) RoundTripper(next http.RoundTripper) http.RoundTripper {
+	if h.UserAgent == "" && len(h.Values) == 0 {
+		return next
+	}
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		// round trippers must not modify the request
+		req = req.Clone(req.Context())
+		if h.UserAgent != "" {
+			req.Header.Set("User-Agent", h.UserAgent)
+		}
+		if h.acceptsHost(req.URL.Hostname()) {
+			for name, value := range h.Values {
+				req.Header.Set(name, value)
+			}
+		}
+		return next.RoundTrip(req)
+	})
+}
+
+// Client returns a client sending its requests with the headers through next, http.DefaultTransport if nil
+func (h Headers) Client(next http.RoundTripper) *http.Client {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &http.Client{Transport: h.RoundTripper(next)}
+}
+
+func (h Headers) acceptsHost(host string) bool {
+	host = strings.ToLower(host)
+	for _, pattern := range h.Hosts {
+		if ok, _ := path.Match(strings.ToLower(pattern), host); ok {
+			return true
+		}
+	}
+	return false
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
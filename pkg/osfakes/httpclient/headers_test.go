@@ -0,0 +1,77 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package httpclient_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/gardener/docforge/pkg/osfakes/httpclient"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Headers", func() {
+	var (
+		server   *httptest.Server
+		received []http.Header
+	)
+
+	BeforeEach(func() {
+		received = nil
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			received = append(received, r.Header.Clone())
+			if r.URL.Path == "/redirect" {
+				// redirects to the same server by another host name
+				http.Redirect(w, r, strings.Replace(server.URL, "127.0.0.1", "localhost", 1)+"/target", http.StatusFound)
+			}
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	get := func(client httpclient.Client, url string) {
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		Expect(err).NotTo(HaveOccurred())
+		resp, err := client.Do(req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resp.Body.Close()).To(Succeed())
+	}
+
+	It("sends the user agent and the headers", func() {
+		headers := httpclient.Headers{UserAgent: "docforge-test/1.0", Values: map[string]string{"X-Api-Key": "secret"}, Hosts: []string{"127.0.0.1"}}
+		get(headers.Client(nil), server.URL)
+		Expect(received).To(HaveLen(1))
+		Expect(received[0].Get("User-Agent")).To(Equal("docforge-test/1.0"))
+		Expect(received[0].Get("X-Api-Key")).To(Equal("secret"))
+	})
+
+	It("sends the headers only to their hosts", func() {
+		headers := httpclient.Headers{UserAgent: "docforge-test/1.0", Values: map[string]string{"X-Api-Key": "secret"}, Hosts: []string{"127.0.0.*"}}
+		get(headers.Client(nil), server.URL+"/redirect")
+		Expect(received).To(HaveLen(2))
+		Expect(received[0].Get("X-Api-Key")).To(Equal("secret"))
+		Expect(received[1].Get("X-Api-Key")).To(BeEmpty())
+		Expect(received[1].Get("User-Agent")).To(Equal("docforge-test/1.0"))
+	})
+
+	It("sends the headers to no host without hosts", func() {
+		headers := httpclient.Headers{UserAgent: "docforge-test/1.0", Values: map[string]string{"X-Api-Key": "secret"}}
+		get(headers.Client(nil), server.URL+"/redirect")
+		Expect(received).To(HaveLen(2))
+		Expect(received[0].Get("X-Api-Key")).To(BeEmpty())
+		Expect(received[1].Get("X-Api-Key")).To(BeEmpty())
+		Expect(received[0].Get("User-Agent")).To(Equal("docforge-test/1.0"))
+	})
+
+	It("keeps the requests unchanged without headers", func() {
+		get(httpclient.Headers{}.Client(nil), server.URL)
+		Expect(received).To(HaveLen(1))
+		Expect(received[0].Get("User-Agent")).To(HavePrefix("Go-http-client"))
+	})
+})
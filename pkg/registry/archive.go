@@ -0,0 +1,58 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package registry
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gardener/docforge/pkg/registry/repositoryhost"
+)
+
+// archive is the content of an archive resource and the paths of its files
+type archive struct {
+	content []byte
+	files   []string
+}
+
+// readArchive reads the archive once and lists its files
+func (r *registry) readArchive(ctx context.Context, archiveURL string) (*archive, error) {
+	r.archivesMux.Lock()
+	defer r.archivesMux.Unlock()
+	if a, ok := r.archives[archiveURL]; ok {
+		return a, nil
+	}
+	rh, url, err := r.anyRepositoryHost(archiveURL)
+	if err != nil {
+		return nil, err
+	}
+	content, err := rh.Read(ctx, *url)
+	if err != nil {
+		return nil, err
+	}
+	files, err := repositoryhost.ArchiveFiles(archiveURL, content)
+	if err != nil {
+		return nil, err
+	}
+	a := &archive{content: content, files: files}
+	r.archives[archiveURL] = a
+	return a, nil
+}
+
+// resolveArchiveLink resolves a relative link from a file in an archive, which may point in or out of the archive
+func (r *registry) resolveArchiveLink(source string, relativeLink string) (string, error) {
+	url, err := repositoryhost.ArchiveFileURL(source)
+	if err != nil {
+		return "", err
+	}
+	blobURL, _, err := url.ResolveRelativeLink(relativeLink)
+	if err != nil {
+		return "", err
+	}
+	if _, err = r.ResourceURL(blobURL); err != nil {
+		return blobURL, repositoryhost.ErrResourceNotFound(fmt.Sprintf("%s with source %s", relativeLink, source))
+	}
+	return blobURL, nil
+}
@@ -6,8 +6,11 @@ package registry
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"slices"
+	"sync"
 	"time"
 
 	"github.com/gardener/docforge/pkg/osfakes/httpclient"
@@ -27,36 +30,96 @@ type Interface interface {
 	LoadRepository(ctx context.Context, resourceURL string) error
 	// Tree returns files that are present in the given url tree
 	Tree(resourceURL string) ([]string, error)
+	// ChangedFiles returns the blob URLs of the files in the repository of the resource URL changed since the base ref
+	ChangedFiles(ctx context.Context, resourceURL string, base string) ([]string, error)
+	// ListRefs returns the branches and tags of the repository of the URL whose names match the regular expression pattern
+	ListRefs(ctx context.Context, repoURL string, pattern string) ([]string, error)
 	// Read a resource content at uri into a byte array
 	Read(ctx context.Context, resourceURL string) ([]byte, error)
 	// ReadGitInfo reads the git info for a given resource URL
 	ReadGitInfo(ctx context.Context, resourceURL string) ([]byte, error)
 	// Client returns an HTTP client for accessing the given url
 	Client(url string) httpclient.Client
+	// GetEditLink returns the link for editing the given source
+	GetEditLink(source string) (string, error)
+	// GitInfoOptions returns the options for reading the git info of the given url
+	GitInfoOptions(url string) repositoryhost.GitInfoOptions
 	// ResourceURL returns a valid resource url object from a string url
 	ResourceURL(resourceURL string) (*repositoryhost.URL, error)
 	// LogRateLimits logs rate limit and remaining API calls for all resource handler backends
 	LogRateLimits(ctx context.Context)
+	// RateLimits returns the rate limits of the resource handler backends with a rate limit that the resource URLs
+	// are read from, with the number of the resource URLs each of them reads
+	RateLimits(ctx context.Context, resourceURLs []string) ([]RateLimit, error)
+}
+
+// RateLimit is the rate limit of a resource handler backend
+type RateLimit struct {
+	// Host is the name of the backend
+	Host string
+	// Remaining are the API calls remaining until Reset
+	Remaining int
+	// Reset is the time the rate limit is reset
+	Reset time.Time
+	// Resources is the number of the resource URLs read from the backend
+	Resources int
 }
 
 type registry struct {
 	repoHosts []repositoryhost.Interface
+	// client accesses the URLs no repository host accepts
+	client httpclient.Client
+	// archives caches the archives read by URL
+	archives    map[string]*archive
+	archivesMux sync.Mutex
 }
 
 // NewRegistry creates Registry object, optionally loading it with resourcerepoHosts if provided
 func NewRegistry(resourcerepoHosts ...repositoryhost.Interface) Interface {
-	return &registry{repoHosts: resourcerepoHosts}
+	return NewRegistryWithClient(http.DefaultClient, resourcerepoHosts...)
+}
+
+// NewRegistryWithClient creates Registry object accessing the URLs no resource repoHost accepts with the client
+func NewRegistryWithClient(client httpclient.Client, resourcerepoHosts ...repositoryhost.Interface) Interface {
+	return &registry{repoHosts: resourcerepoHosts, client: client, archives: map[string]*archive{}}
 }
 
 func (r *registry) Client(url string) httpclient.Client {
 	rh, _, err := r.anyRepositoryHost(url)
 	if err != nil {
-		return http.DefaultClient
+		return r.client
 	}
 	return rh.GetClient()
 }
 
+func (r *registry) GetEditLink(source string) (string, error) {
+	// files in archives are edited in the archive
+	if archiveURL, _, ok := repositoryhost.SplitArchiveURL(source); ok {
+		source = archiveURL
+	}
+	rh, url, err := r.anyRepositoryHost(source)
+	if err != nil {
+		return "", err
+	}
+	return rh.GetEditLink(*url)
+}
+
+func (r *registry) GitInfoOptions(url string) repositoryhost.GitInfoOptions {
+	rh, _, err := r.anyRepositoryHost(url)
+	if err != nil {
+		return repositoryhost.GitInfoOptions{DateLayout: repositoryhost.DateFormat}
+	}
+	return rh.GitInfoOptions()
+}
+
 func (r *registry) Tree(resourceURL string) ([]string, error) {
+	if repositoryhost.IsArchive(resourceURL) {
+		a, err := r.readArchive(context.TODO(), resourceURL)
+		if err != nil {
+			return []string{}, err
+		}
+		return a.files, nil
+	}
 	rh, url, err := r.anyRepositoryHost(resourceURL)
 	if err != nil {
 		return []string{}, err
@@ -64,7 +127,42 @@ func (r *registry) Tree(resourceURL string) ([]string, error) {
 	return rh.Tree(*url)
 }
 
+func (r *registry) ChangedFiles(ctx context.Context, resourceURL string, base string) ([]string, error) {
+	rh, url, err := r.anyRepositoryHost(resourceURL)
+	if err != nil {
+		return nil, err
+	}
+	files, err := rh.ChangedFiles(ctx, *url, base)
+	if err != nil {
+		return nil, err
+	}
+	blob, err := url.ReferenceURL().GetDifferentType("blob")
+	if err != nil {
+		return nil, err
+	}
+	changed := make([]string, 0, len(files))
+	for _, file := range files {
+		changed = append(changed, fmt.Sprintf("%s/%s", blob, file))
+	}
+	return changed, nil
+}
+
+func (r *registry) ListRefs(ctx context.Context, repoURL string, pattern string) ([]string, error) {
+	rh, err := r.acceptAnyRH(repoURL)
+	if err != nil {
+		return nil, err
+	}
+	return rh.ListRefs(ctx, repoURL, pattern)
+}
+
 func (r *registry) Read(ctx context.Context, resourceURL string) ([]byte, error) {
+	if archiveURL, file, ok := repositoryhost.SplitArchiveURL(resourceURL); ok {
+		a, err := r.readArchive(ctx, archiveURL)
+		if err != nil {
+			return []byte{}, err
+		}
+		return repositoryhost.ReadArchiveFile(archiveURL, a.content, file)
+	}
 	rh, url, err := r.anyRepositoryHost(resourceURL)
 	if err != nil {
 		return []byte{}, err
@@ -73,6 +171,9 @@ func (r *registry) Read(ctx context.Context, resourceURL string) ([]byte, error)
 }
 
 func (r *registry) ResolveRelativeLink(source string, relativeLink string) (string, error) {
+	if _, _, ok := repositoryhost.SplitArchiveURL(source); ok {
+		return r.resolveArchiveLink(source, relativeLink)
+	}
 	rh, url, err := r.anyRepositoryHost(source)
 	if err != nil {
 		return "", err
@@ -81,11 +182,19 @@ func (r *registry) ResolveRelativeLink(source string, relativeLink string) (stri
 }
 
 func (r *registry) ReadGitInfo(ctx context.Context, resourceURL string) ([]byte, error) {
+	// files in archives have the git info of the archive
+	if archiveURL, _, ok := repositoryhost.SplitArchiveURL(resourceURL); ok {
+		resourceURL = archiveURL
+	}
+	// local files have no git info
+	if rh, err := r.acceptAnyRH(resourceURL); err == nil && rh.Repositories() == nil {
+		return nil, nil
+	}
 	rh, url, err := r.githubRepositoryHost(resourceURL)
 	if err != nil {
 		return []byte{}, err
 	}
-	return repositoryhost.ReadGitInfo(ctx, rh.Repositories(), *url)
+	return repositoryhost.ReadGitInfo(ctx, rh.Repositories(), *url, rh.GitInfoOptions())
 }
 
 func (r *registry) LoadRepository(ctx context.Context, resourceURL string) error {
@@ -112,6 +221,16 @@ func (r *registry) anyRepositoryHost(resourceURL string) (repositoryhost.Interfa
 }
 
 func (r *registry) ResourceURL(resourceURL string) (*repositoryhost.URL, error) {
+	if archiveURL, file, ok := repositoryhost.SplitArchiveURL(resourceURL); ok {
+		a, err := r.readArchive(context.TODO(), archiveURL)
+		if err != nil {
+			return nil, err
+		}
+		if !slices.Contains(a.files, file) {
+			return nil, repositoryhost.ErrResourceNotFound(resourceURL)
+		}
+		return repositoryhost.ArchiveFileURL(resourceURL)
+	}
 	_, url, err := r.anyRepositoryHost(resourceURL)
 	return url, err
 }
@@ -150,10 +269,37 @@ func (r *registry) acceptGithubRH(uri string) (repositoryhost.Interface, error)
 func (r *registry) LogRateLimits(ctx context.Context) {
 	for _, repoHost := range r.repoHosts {
 		l, rr, rt, err := repoHost.GetRateLimit(ctx)
-		if err != nil && err.Error() != "not implemented" {
+		if err != nil && !errors.Is(err, repositoryhost.ErrNotImplemented) {
 			klog.Warningf("Error getting RateLimit for %s: %v\n", repoHost.Name(), err)
 		} else if l > 0 && rr > 0 {
 			klog.Infof("%s RateLimit: %d requests per hour, Remaining: %d, Reset after: %s\n", repoHost.Name(), l, rr, time.Until(rt).Round(time.Second))
 		}
 	}
 }
+
+func (r *registry) RateLimits(ctx context.Context, resourceURLs []string) ([]RateLimit, error) {
+	resources := map[repositoryhost.Interface]int{}
+	for _, resourceURL := range resourceURLs {
+		if rh, _, err := r.anyRepositoryHost(resourceURL); err == nil {
+			resources[rh]++
+		}
+	}
+	var rateLimits []RateLimit
+	for _, repoHost := range r.repoHosts {
+		count, ok := resources[repoHost]
+		if !ok {
+			continue
+		}
+		l, rr, rt, err := repoHost.GetRateLimit(ctx)
+		if errors.Is(err, repositoryhost.ErrNotImplemented) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("getting RateLimit for %s failed: %w", repoHost.Name(), err)
+		}
+		if l > 0 {
+			rateLimits = append(rateLimits, RateLimit{Host: repoHost.Name(), Remaining: rr, Reset: rt, Resources: count})
+		}
+	}
+	return rateLimits, nil
+}
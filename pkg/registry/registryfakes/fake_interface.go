@@ -14,6 +14,21 @@ import (
 )
 
 type FakeInterface struct {
+	ChangedFilesStub        func(context.Context, string, string) ([]string, error)
+	changedFilesMutex       sync.RWMutex
+	changedFilesArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+	}
+	changedFilesReturns struct {
+		result1 []string
+		result2 error
+	}
+	changedFilesReturnsOnCall map[int]struct {
+		result1 []string
+		result2 error
+	}
 	ClientStub        func(string) httpclient.Client
 	clientMutex       sync.RWMutex
 	clientArgsForCall []struct {
@@ -25,6 +40,45 @@ type FakeInterface struct {
 	clientReturnsOnCall map[int]struct {
 		result1 httpclient.Client
 	}
+	GetEditLinkStub        func(string) (string, error)
+	getEditLinkMutex       sync.RWMutex
+	getEditLinkArgsForCall []struct {
+		arg1 string
+	}
+	getEditLinkReturns struct {
+		result1 string
+		result2 error
+	}
+	getEditLinkReturnsOnCall map[int]struct {
+		result1 string
+		result2 error
+	}
+	GitInfoOptionsStub        func(string) repositoryhost.GitInfoOptions
+	gitInfoOptionsMutex       sync.RWMutex
+	gitInfoOptionsArgsForCall []struct {
+		arg1 string
+	}
+	gitInfoOptionsReturns struct {
+		result1 repositoryhost.GitInfoOptions
+	}
+	gitInfoOptionsReturnsOnCall map[int]struct {
+		result1 repositoryhost.GitInfoOptions
+	}
+	ListRefsStub        func(context.Context, string, string) ([]string, error)
+	listRefsMutex       sync.RWMutex
+	listRefsArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+	}
+	listRefsReturns struct {
+		result1 []string
+		result2 error
+	}
+	listRefsReturnsOnCall map[int]struct {
+		result1 []string
+		result2 error
+	}
 	LoadRepositoryStub        func(context.Context, string) error
 	loadRepositoryMutex       sync.RWMutex
 	loadRepositoryArgsForCall []struct {
@@ -42,6 +96,20 @@ type FakeInterface struct {
 	logRateLimitsArgsForCall []struct {
 		arg1 context.Context
 	}
+	RateLimitsStub        func(context.Context, []string) ([]registry.RateLimit, error)
+	rateLimitsMutex       sync.RWMutex
+	rateLimitsArgsForCall []struct {
+		arg1 context.Context
+		arg2 []string
+	}
+	rateLimitsReturns struct {
+		result1 []registry.RateLimit
+		result2 error
+	}
+	rateLimitsReturnsOnCall map[int]struct {
+		result1 []registry.RateLimit
+		result2 error
+	}
 	ReadStub        func(context.Context, string) ([]byte, error)
 	readMutex       sync.RWMutex
 	readArgsForCall []struct {
@@ -114,6 +182,72 @@ type FakeInterface struct {
 	invocationsMutex sync.RWMutex
 }
 
+func (fake *FakeInterface) ChangedFiles(arg1 context.Context, arg2 string, arg3 string) ([]string, error) {
+	fake.changedFilesMutex.Lock()
+	ret, specificReturn := fake.changedFilesReturnsOnCall[len(fake.changedFilesArgsForCall)]
+	fake.changedFilesArgsForCall = append(fake.changedFilesArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.ChangedFilesStub
+	fakeReturns := fake.changedFilesReturns
+	fake.recordInvocation("ChangedFiles", []interface{}{arg1, arg2, arg3})
+	fake.changedFilesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeInterface) ChangedFilesCallCount() int {
+	fake.changedFilesMutex.RLock()
+	defer fake.changedFilesMutex.RUnlock()
+	return len(fake.changedFilesArgsForCall)
+}
+
+func (fake *FakeInterface) ChangedFilesCalls(stub func(context.Context, string, string) ([]string, error)) {
+	fake.changedFilesMutex.Lock()
+	defer fake.changedFilesMutex.Unlock()
+	fake.ChangedFilesStub = stub
+}
+
+func (fake *FakeInterface) ChangedFilesArgsForCall(i int) (context.Context, string, string) {
+	fake.changedFilesMutex.RLock()
+	defer fake.changedFilesMutex.RUnlock()
+	argsForCall := fake.changedFilesArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeInterface) ChangedFilesReturns(result1 []string, result2 error) {
+	fake.changedFilesMutex.Lock()
+	defer fake.changedFilesMutex.Unlock()
+	fake.ChangedFilesStub = nil
+	fake.changedFilesReturns = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeInterface) ChangedFilesReturnsOnCall(i int, result1 []string, result2 error) {
+	fake.changedFilesMutex.Lock()
+	defer fake.changedFilesMutex.Unlock()
+	fake.ChangedFilesStub = nil
+	if fake.changedFilesReturnsOnCall == nil {
+		fake.changedFilesReturnsOnCall = make(map[int]struct {
+			result1 []string
+			result2 error
+		})
+	}
+	fake.changedFilesReturnsOnCall[i] = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeInterface) Client(arg1 string) httpclient.Client {
 	fake.clientMutex.Lock()
 	ret, specificReturn := fake.clientReturnsOnCall[len(fake.clientArgsForCall)]
@@ -175,6 +309,197 @@ func (fake *FakeInterface) ClientReturnsOnCall(i int, result1 httpclient.Client)
 	}{result1}
 }
 
+func (fake *FakeInterface) GetEditLink(arg1 string) (string, error) {
+	fake.getEditLinkMutex.Lock()
+	ret, specificReturn := fake.getEditLinkReturnsOnCall[len(fake.getEditLinkArgsForCall)]
+	fake.getEditLinkArgsForCall = append(fake.getEditLinkArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.GetEditLinkStub
+	fakeReturns := fake.getEditLinkReturns
+	fake.recordInvocation("GetEditLink", []interface{}{arg1})
+	fake.getEditLinkMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeInterface) GetEditLinkCallCount() int {
+	fake.getEditLinkMutex.RLock()
+	defer fake.getEditLinkMutex.RUnlock()
+	return len(fake.getEditLinkArgsForCall)
+}
+
+func (fake *FakeInterface) GetEditLinkCalls(stub func(string) (string, error)) {
+	fake.getEditLinkMutex.Lock()
+	defer fake.getEditLinkMutex.Unlock()
+	fake.GetEditLinkStub = stub
+}
+
+func (fake *FakeInterface) GetEditLinkArgsForCall(i int) string {
+	fake.getEditLinkMutex.RLock()
+	defer fake.getEditLinkMutex.RUnlock()
+	argsForCall := fake.getEditLinkArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeInterface) GetEditLinkReturns(result1 string, result2 error) {
+	fake.getEditLinkMutex.Lock()
+	defer fake.getEditLinkMutex.Unlock()
+	fake.GetEditLinkStub = nil
+	fake.getEditLinkReturns = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeInterface) GetEditLinkReturnsOnCall(i int, result1 string, result2 error) {
+	fake.getEditLinkMutex.Lock()
+	defer fake.getEditLinkMutex.Unlock()
+	fake.GetEditLinkStub = nil
+	if fake.getEditLinkReturnsOnCall == nil {
+		fake.getEditLinkReturnsOnCall = make(map[int]struct {
+			result1 string
+			result2 error
+		})
+	}
+	fake.getEditLinkReturnsOnCall[i] = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeInterface) GitInfoOptions(arg1 string) repositoryhost.GitInfoOptions {
+	fake.gitInfoOptionsMutex.Lock()
+	ret, specificReturn := fake.gitInfoOptionsReturnsOnCall[len(fake.gitInfoOptionsArgsForCall)]
+	fake.gitInfoOptionsArgsForCall = append(fake.gitInfoOptionsArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.GitInfoOptionsStub
+	fakeReturns := fake.gitInfoOptionsReturns
+	fake.recordInvocation("GitInfoOptions", []interface{}{arg1})
+	fake.gitInfoOptionsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeInterface) GitInfoOptionsCallCount() int {
+	fake.gitInfoOptionsMutex.RLock()
+	defer fake.gitInfoOptionsMutex.RUnlock()
+	return len(fake.gitInfoOptionsArgsForCall)
+}
+
+func (fake *FakeInterface) GitInfoOptionsCalls(stub func(string) repositoryhost.GitInfoOptions) {
+	fake.gitInfoOptionsMutex.Lock()
+	defer fake.gitInfoOptionsMutex.Unlock()
+	fake.GitInfoOptionsStub = stub
+}
+
+func (fake *FakeInterface) GitInfoOptionsArgsForCall(i int) string {
+	fake.gitInfoOptionsMutex.RLock()
+	defer fake.gitInfoOptionsMutex.RUnlock()
+	argsForCall := fake.gitInfoOptionsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeInterface) GitInfoOptionsReturns(result1 repositoryhost.GitInfoOptions) {
+	fake.gitInfoOptionsMutex.Lock()
+	defer fake.gitInfoOptionsMutex.Unlock()
+	fake.GitInfoOptionsStub = nil
+	fake.gitInfoOptionsReturns = struct {
+		result1 repositoryhost.GitInfoOptions
+	}{result1}
+}
+
+func (fake *FakeInterface) GitInfoOptionsReturnsOnCall(i int, result1 repositoryhost.GitInfoOptions) {
+	fake.gitInfoOptionsMutex.Lock()
+	defer fake.gitInfoOptionsMutex.Unlock()
+	fake.GitInfoOptionsStub = nil
+	if fake.gitInfoOptionsReturnsOnCall == nil {
+		fake.gitInfoOptionsReturnsOnCall = make(map[int]struct {
+			result1 repositoryhost.GitInfoOptions
+		})
+	}
+	fake.gitInfoOptionsReturnsOnCall[i] = struct {
+		result1 repositoryhost.GitInfoOptions
+	}{result1}
+}
+
+func (fake *FakeInterface) ListRefs(arg1 context.Context, arg2 string, arg3 string) ([]string, error) {
+	fake.listRefsMutex.Lock()
+	ret, specificReturn := fake.listRefsReturnsOnCall[len(fake.listRefsArgsForCall)]
+	fake.listRefsArgsForCall = append(fake.listRefsArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.ListRefsStub
+	fakeReturns := fake.listRefsReturns
+	fake.recordInvocation("ListRefs", []interface{}{arg1, arg2, arg3})
+	fake.listRefsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeInterface) ListRefsCallCount() int {
+	fake.listRefsMutex.RLock()
+	defer fake.listRefsMutex.RUnlock()
+	return len(fake.listRefsArgsForCall)
+}
+
+func (fake *FakeInterface) ListRefsCalls(stub func(context.Context, string, string) ([]string, error)) {
+	fake.listRefsMutex.Lock()
+	defer fake.listRefsMutex.Unlock()
+	fake.ListRefsStub = stub
+}
+
+func (fake *FakeInterface) ListRefsArgsForCall(i int) (context.Context, string, string) {
+	fake.listRefsMutex.RLock()
+	defer fake.listRefsMutex.RUnlock()
+	argsForCall := fake.listRefsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeInterface) ListRefsReturns(result1 []string, result2 error) {
+	fake.listRefsMutex.Lock()
+	defer fake.listRefsMutex.Unlock()
+	fake.ListRefsStub = nil
+	fake.listRefsReturns = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeInterface) ListRefsReturnsOnCall(i int, result1 []string, result2 error) {
+	fake.listRefsMutex.Lock()
+	defer fake.listRefsMutex.Unlock()
+	fake.ListRefsStub = nil
+	if fake.listRefsReturnsOnCall == nil {
+		fake.listRefsReturnsOnCall = make(map[int]struct {
+			result1 []string
+			result2 error
+		})
+	}
+	fake.listRefsReturnsOnCall[i] = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeInterface) LoadRepository(arg1 context.Context, arg2 string) error {
 	fake.loadRepositoryMutex.Lock()
 	ret, specificReturn := fake.loadRepositoryReturnsOnCall[len(fake.loadRepositoryArgsForCall)]
@@ -269,6 +594,76 @@ func (fake *FakeInterface) LogRateLimitsArgsForCall(i int) context.Context {
 	return argsForCall.arg1
 }
 
+func (fake *FakeInterface) RateLimits(arg1 context.Context, arg2 []string) ([]registry.RateLimit, error) {
+	var arg2Copy []string
+	if arg2 != nil {
+		arg2Copy = make([]string, len(arg2))
+		copy(arg2Copy, arg2)
+	}
+	fake.rateLimitsMutex.Lock()
+	ret, specificReturn := fake.rateLimitsReturnsOnCall[len(fake.rateLimitsArgsForCall)]
+	fake.rateLimitsArgsForCall = append(fake.rateLimitsArgsForCall, struct {
+		arg1 context.Context
+		arg2 []string
+	}{arg1, arg2Copy})
+	stub := fake.RateLimitsStub
+	fakeReturns := fake.rateLimitsReturns
+	fake.recordInvocation("RateLimits", []interface{}{arg1, arg2Copy})
+	fake.rateLimitsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeInterface) RateLimitsCallCount() int {
+	fake.rateLimitsMutex.RLock()
+	defer fake.rateLimitsMutex.RUnlock()
+	return len(fake.rateLimitsArgsForCall)
+}
+
+func (fake *FakeInterface) RateLimitsCalls(stub func(context.Context, []string) ([]registry.RateLimit, error)) {
+	fake.rateLimitsMutex.Lock()
+	defer fake.rateLimitsMutex.Unlock()
+	fake.RateLimitsStub = stub
+}
+
+func (fake *FakeInterface) RateLimitsArgsForCall(i int) (context.Context, []string) {
+	fake.rateLimitsMutex.RLock()
+	defer fake.rateLimitsMutex.RUnlock()
+	argsForCall := fake.rateLimitsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeInterface) RateLimitsReturns(result1 []registry.RateLimit, result2 error) {
+	fake.rateLimitsMutex.Lock()
+	defer fake.rateLimitsMutex.Unlock()
+	fake.RateLimitsStub = nil
+	fake.rateLimitsReturns = struct {
+		result1 []registry.RateLimit
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeInterface) RateLimitsReturnsOnCall(i int, result1 []registry.RateLimit, result2 error) {
+	fake.rateLimitsMutex.Lock()
+	defer fake.rateLimitsMutex.Unlock()
+	fake.RateLimitsStub = nil
+	if fake.rateLimitsReturnsOnCall == nil {
+		fake.rateLimitsReturnsOnCall = make(map[int]struct {
+			result1 []registry.RateLimit
+			result2 error
+		})
+	}
+	fake.rateLimitsReturnsOnCall[i] = struct {
+		result1 []registry.RateLimit
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeInterface) Read(arg1 context.Context, arg2 string) ([]byte, error) {
 	fake.readMutex.Lock()
 	ret, specificReturn := fake.readReturnsOnCall[len(fake.readArgsForCall)]
@@ -595,12 +990,22 @@ func (fake *FakeInterface) TreeReturnsOnCall(i int, result1 []string, result2 er
 func (fake *FakeInterface) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
+	fake.changedFilesMutex.RLock()
+	defer fake.changedFilesMutex.RUnlock()
 	fake.clientMutex.RLock()
 	defer fake.clientMutex.RUnlock()
+	fake.getEditLinkMutex.RLock()
+	defer fake.getEditLinkMutex.RUnlock()
+	fake.gitInfoOptionsMutex.RLock()
+	defer fake.gitInfoOptionsMutex.RUnlock()
+	fake.listRefsMutex.RLock()
+	defer fake.listRefsMutex.RUnlock()
 	fake.loadRepositoryMutex.RLock()
 	defer fake.loadRepositoryMutex.RUnlock()
 	fake.logRateLimitsMutex.RLock()
 	defer fake.logRateLimitsMutex.RUnlock()
+	fake.rateLimitsMutex.RLock()
+	defer fake.rateLimitsMutex.RUnlock()
 	fake.readMutex.RLock()
 	defer fake.readMutex.RUnlock()
 	fake.readGitInfoMutex.RLock()
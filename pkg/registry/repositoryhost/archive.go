@@ -0,0 +1,138 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package repositoryhost
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// archiveExtensions are the extensions of the archives whose files are resources
+var archiveExtensions = []string{".zip", ".tar.gz", ".tgz"}
+
+// IsArchive checks if the resource is an archive by its extension
+func IsArchive(resource string) bool {
+	for _, ext := range archiveExtensions {
+		if strings.HasSuffix(resource, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// SplitArchiveURL splits the URL of a file in an archive into the URL of the archive and the path of the file in it.
+// The URL suffix of the file is dropped
+func SplitArchiveURL(resourceURL string) (string, string, bool) {
+	segments := strings.Split(resourceURL, "/")
+	for i, segment := range segments[:len(segments)-1] {
+		if IsArchive(segment) {
+			file := strings.Join(segments[i+1:], "/")
+			if j := strings.IndexAny(file, "?#"); j >= 0 {
+				file = file[:j]
+			}
+			return strings.Join(segments[:i+1], "/"), file, file != ""
+		}
+	}
+	return "", "", false
+}
+
+// ArchiveFileURL returns the resource URL of a file in an archive, whose existence is checked by the caller
+func ArchiveFileURL(resourceURL string) (*URL, error) {
+	return new(resourceURL)
+}
+
+// ArchiveFiles returns the paths of the files in the archive. Files with paths outside of the archive are skipped
+func ArchiveFiles(archive string, content []byte) ([]string, error) {
+	files := []string{}
+	err := walkArchive(archive, content, func(file string, _ io.Reader) (bool, error) {
+		files = append(files, file)
+		return false, nil
+	})
+	return files, err
+}
+
+// ReadArchiveFile reads the file from the archive
+func ReadArchiveFile(archive string, content []byte, file string) ([]byte, error) {
+	var fileContent []byte
+	err := walkArchive(archive, content, func(name string, r io.Reader) (bool, error) {
+		if name != file {
+			return false, nil
+		}
+		var err error
+		fileContent, err = io.ReadAll(r)
+		return true, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if fileContent == nil {
+		return nil, ErrResourceNotFound(archive + "/" + file)
+	}
+	return fileContent, nil
+}
+
+// walkArchive calls visit with the regular files of the archive until it reports that it is done
+func walkArchive(archive string, content []byte, visit func(file string, r io.Reader) (bool, error)) error {
+	if strings.HasSuffix(archive, ".zip") {
+		zr, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+		if err != nil {
+			return fmt.Errorf("reading archive %s failed: %w", archive, err)
+		}
+		for _, f := range zr.File {
+			file, ok := archiveFilePath(f.Name)
+			if !ok || !f.Mode().IsRegular() {
+				continue
+			}
+			rc, err := f.Open()
+			if err != nil {
+				return fmt.Errorf("reading %s from archive %s failed: %w", f.Name, archive, err)
+			}
+			done, err := visit(file, rc)
+			rc.Close()
+			if done || err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	gr, err := gzip.NewReader(bytes.NewReader(content))
+	if err != nil {
+		return fmt.Errorf("reading archive %s failed: %w", archive, err)
+	}
+	defer gr.Close()
+	tr := tar.NewReader(gr)
+	for {
+		h, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("reading archive %s failed: %w", archive, err)
+		}
+		file, ok := archiveFilePath(h.Name)
+		if !ok || h.Typeflag != tar.TypeReg {
+			continue
+		}
+		if done, err := visit(file, tr); done || err != nil {
+			return err
+		}
+	}
+}
+
+// archiveFilePath cleans the path of an archive entry and checks that it is in the archive
+func archiveFilePath(name string) (string, bool) {
+	file := path.Clean(strings.TrimPrefix(name, "./"))
+	if path.IsAbs(file) || file == "." || file == ".." || strings.HasPrefix(file, "../") {
+		return "", false
+	}
+	return file, true
+}
@@ -0,0 +1,86 @@
+package repositoryhost_test
+
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+
+	"github.com/gardener/docforge/pkg/registry/repositoryhost"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var archiveFiles = map[string]string{
+	"README.md":        "# Docs",
+	"./guide/intro.md": "# Intro",
+	"../outside.md":    "# Outside",
+}
+
+func zipArchive() []byte {
+	var b bytes.Buffer
+	w := zip.NewWriter(&b)
+	_, err := w.Create("guide/")
+	Expect(err).NotTo(HaveOccurred())
+	for name, content := range archiveFiles {
+		f, err := w.Create(name)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = f.Write([]byte(content))
+		Expect(err).NotTo(HaveOccurred())
+	}
+	Expect(w.Close()).To(Succeed())
+	return b.Bytes()
+}
+
+func tarGzArchive() []byte {
+	var b bytes.Buffer
+	gw := gzip.NewWriter(&b)
+	w := tar.NewWriter(gw)
+	Expect(w.WriteHeader(&tar.Header{Name: "guide/", Typeflag: tar.TypeDir, Mode: 0755})).To(Succeed())
+	for name, content := range archiveFiles {
+		Expect(w.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(content))})).To(Succeed())
+		_, err := w.Write([]byte(content))
+		Expect(err).NotTo(HaveOccurred())
+	}
+	Expect(w.Close()).To(Succeed())
+	Expect(gw.Close()).To(Succeed())
+	return b.Bytes()
+}
+
+var _ = Describe("Archives", func() {
+	DescribeTable("splitting URLs of files in archives", func(resourceURL string, archive string, file string, ok bool) {
+		a, f, isInArchive := repositoryhost.SplitArchiveURL(resourceURL)
+		Expect(isInArchive).To(Equal(ok))
+		Expect(a).To(Equal(archive))
+		Expect(f).To(Equal(file))
+	},
+		Entry("file in zip", "https://github.com/gardener/docforge/blob/master/docs.zip/guide/intro.md", "https://github.com/gardener/docforge/blob/master/docs.zip", "guide/intro.md", true),
+		Entry("file in tgz with anchor", "https://github.com/gardener/docforge/blob/master/docs.tgz/intro.md#setup", "https://github.com/gardener/docforge/blob/master/docs.tgz", "intro.md", true),
+		Entry("archive itself", "https://github.com/gardener/docforge/blob/master/docs.tar.gz", "", "", false),
+		Entry("file out of archives", "https://github.com/gardener/docforge/blob/master/docs/intro.md", "", "", false),
+	)
+
+	DescribeTable("reading", func(archive string, content func() []byte) {
+		files, err := repositoryhost.ArchiveFiles(archive, content())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(files).To(ConsistOf("README.md", "guide/intro.md"))
+		intro, err := repositoryhost.ReadArchiveFile(archive, content(), "guide/intro.md")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(intro)).To(Equal("# Intro"))
+		_, err = repositoryhost.ReadArchiveFile(archive, content(), "guide/missing.md")
+		Expect(err).To(Equal(repositoryhost.ErrResourceNotFound(archive + "/guide/missing.md")))
+	},
+		Entry("zip", "docs.zip", zipArchive),
+		Entry("tar.gz", "docs.tar.gz", tarGzArchive),
+	)
+
+	It("fails reading a corrupt archive", func() {
+		_, err := repositoryhost.ArchiveFiles("docs.tgz", []byte("not gzip"))
+		Expect(err).To(MatchError(ContainSubstring("reading archive docs.tgz failed")))
+	})
+})
@@ -1,3 +1,8 @@
 package repositoryhost
 
 var NewResourceURL = new
+
+// IgnoredBy checks if a repository path is excluded by the given ignore file
+func IgnoredBy(ignoreFilePath string, content string, filePath string) bool {
+	return parseIgnoreFile(ignoreFilePath, []byte(content)).ignored(filePath)
+}
@@ -0,0 +1,49 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package repositoryhost
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/gardener/docforge/pkg/osfakes/osshim"
+)
+
+// FileSystemURL is the tree URL of the repository that file URLs are read from. Its paths are the absolute paths of the file system
+const FileSystemURL = "https://github.com/local/filesystem/tree/root"
+
+// IsFileURL checks if the link is a file:// URL
+func IsFileURL(link string) bool {
+	return strings.HasPrefix(link, "file://")
+}
+
+// FileSystemResource returns the blob or tree URL in the FileSystemURL repository of a file URL or a file path.
+// Relative file paths are relative to the working directory
+func FileSystemResource(link string, resourceType string) (string, error) {
+	filePath := link
+	if IsFileURL(link) {
+		u, err := url.Parse(link)
+		if err != nil {
+			return "", err
+		}
+		if u.Host != "" && u.Host != "localhost" {
+			return "", fmt.Errorf("file URL %s is not on the local host", link)
+		}
+		filePath = u.Path
+	}
+	absPath, err := filepath.Abs(filepath.FromSlash(filePath))
+	if err != nil {
+		return "", err
+	}
+	repository := strings.TrimSuffix(FileSystemURL, "/tree/root")
+	return fmt.Sprintf("%s/%s/root/%s", repository, resourceType, strings.TrimPrefix(filepath.ToSlash(absPath), "/")), nil
+}
+
+// NewFileSystem creates a repository host reading the resources of the FileSystemURL repository from the file system
+func NewFileSystem(os osshim.Os) (Interface, error) {
+	return NewLocalRepository(os, FileSystemURL, "/")
+}
@@ -0,0 +1,79 @@
+package repositoryhost_test
+
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/gardener/docforge/pkg/osfakes/osshim"
+	"github.com/gardener/docforge/pkg/registry/repositoryhost"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("File system", func() {
+	DescribeTable("converting file URLs", func(link string, resourceType string, expected string) {
+		resource, err := repositoryhost.FileSystemResource(link, resourceType)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resource).To(Equal(expected))
+	},
+		Entry("file URL", "file:///home/docs/intro.md", "blob", "https://github.com/local/filesystem/blob/root/home/docs/intro.md"),
+		Entry("localhost file URL", "file://localhost/home/docs", "tree", "https://github.com/local/filesystem/tree/root/home/docs"),
+		Entry("absolute path", "/home/docs/../intro.md", "blob", "https://github.com/local/filesystem/blob/root/home/intro.md"),
+	)
+
+	It("converts relative paths in the working directory", func() {
+		wd, err := os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		resource, err := repositoryhost.FileSystemResource("docs/intro.md", "blob")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resource).To(Equal("https://github.com/local/filesystem/blob/root" + filepath.ToSlash(wd) + "/docs/intro.md"))
+	})
+
+	It("fails for file URLs of other hosts", func() {
+		_, err := repositoryhost.FileSystemResource("file://server/docs/intro.md", "blob")
+		Expect(err).To(MatchError("file URL file://server/docs/intro.md is not on the local host"))
+	})
+
+	Describe("reading", func() {
+		var (
+			dir string
+			fs  repositoryhost.Interface
+		)
+		BeforeEach(func() {
+			var err error
+			dir, err = os.MkdirTemp("", "filesystem")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(os.MkdirAll(filepath.Join(dir, "docs"), 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(dir, "docs", "intro.md"), []byte("# Intro"), 0644)).To(Succeed())
+			fs, err = repositoryhost.NewFileSystem(&osshim.OsShim{})
+			Expect(err).NotTo(HaveOccurred())
+		})
+		AfterEach(func() {
+			Expect(os.RemoveAll(dir)).To(Succeed())
+		})
+
+		It("reads files and trees by file URL", func() {
+			blob, err := repositoryhost.FileSystemResource("file://"+filepath.ToSlash(dir)+"/docs/intro.md", "blob")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fs.Accept(blob)).To(BeTrue())
+			resource, err := fs.ResourceURL(blob)
+			Expect(err).NotTo(HaveOccurred())
+			content, err := fs.Read(context.TODO(), *resource)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("# Intro"))
+
+			tree, err := repositoryhost.FileSystemResource("file://"+filepath.ToSlash(dir), "tree")
+			Expect(err).NotTo(HaveOccurred())
+			resource, err = fs.ResourceURL(tree)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fs.Tree(*resource)).To(ConsistOf("docs/intro.md"))
+			Expect(fs.Repositories()).To(BeNil())
+		})
+	})
+})
@@ -0,0 +1,233 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package repositoryhost
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gardener/docforge/pkg/osfakes/httpclient"
+	"github.com/google/go-github/v43/github"
+)
+
+// gistURL matches the web URLs of gists and the raw URLs of gist files, optionally pinned to a revision, capturing
+// the host, the user, the gist id, the revision and the file name
+var gistURL = regexp.MustCompile(`^https://(gist\.github\.com|gist\.githubusercontent\.com)/([^/]+)/([0-9a-fA-F]+)(?:/raw(?:/([0-9a-f]{40}))?/([^/?#]+))?/?(?:[?#].*)?$`)
+
+//counterfeiter:generate . Gists
+
+// Gists is an interface needed for faking
+type Gists interface {
+	Get(ctx context.Context, id string) (*github.Gist, *github.Response, error)
+	GetRevision(ctx context.Context, id, sha string) (*github.Gist, *github.Response, error)
+	ListCommits(ctx context.Context, id string, opts *github.ListOptions) ([]*github.GistCommit, *github.Response, error)
+}
+
+// gists reads the files of GitHub gists through the gists API
+type gists struct {
+	gists         Gists
+	client        httpclient.Client
+	acceptedHosts []string
+
+	// mux guards read
+	mux sync.Mutex
+	// read are the gists read by id and revision
+	read map[string]*github.Gist
+}
+
+// NewGists creates a repository host reading the files of gists, identified by gist web URLs like
+// https://gist.github.com/<user>/<id> or raw gist file URLs like https://gist.githubusercontent.com/<user>/<id>/raw/<file>.
+// A web URL identifies the only file of the gist
+func NewGists(g Gists, client httpclient.Client, acceptedHosts []string) Interface {
+	return &gists{gists: g, client: client, acceptedHosts: acceptedHosts, read: map[string]*github.Gist{}}
+}
+
+// ResourceURL returns the raw URL of the gist file pinned to the revision of the gist
+func (g *gists) ResourceURL(resourceURL string) (*URL, error) {
+	components := gistURL.FindStringSubmatch(resourceURL)
+	if components == nil {
+		return nil, fmt.Errorf("%s is not a gist URL", resourceURL)
+	}
+	user, id, revision, file := components[2], components[3], components[4], components[5]
+	gist, err := g.gist(context.TODO(), id, revision)
+	if err != nil {
+		return nil, err
+	}
+	if file == "" {
+		if len(gist.Files) != 1 {
+			return nil, fmt.Errorf("gist %s has %d files, link one of them by its raw URL", resourceURL, len(gist.Files))
+		}
+		for name := range gist.Files {
+			file = string(name)
+		}
+	}
+	if _, ok := gist.Files[github.GistFilename(file)]; !ok {
+		return nil, ErrResourceNotFound(resourceURL)
+	}
+	if revision == "" {
+		revision = "HEAD"
+		commits, _, err := g.gists.ListCommits(context.TODO(), id, &github.ListOptions{PerPage: 1})
+		if err == nil && len(commits) > 0 && commits[0].GetVersion() != "" {
+			revision = commits[0].GetVersion()
+		}
+	}
+	return &URL{host: "gist.githubusercontent.com", owner: user, repo: id, resourceType: "raw", ref: revision, resourcePath: file}, nil
+}
+
+// ResolveRelativeLink resolves links to the other files of the gist
+func (g *gists) ResolveRelativeLink(source URL, relativeLink string) (string, error) {
+	file := strings.TrimPrefix(relativeLink, "./")
+	link := fmt.Sprintf("https://%s/%s/%s/%s/%s/%s", source.host, source.owner, source.repo, source.resourceType, source.ref, file)
+	if strings.Contains(file, "/") {
+		return link, ErrResourceNotFound(fmt.Sprintf("%s with source %s", relativeLink, source.String()))
+	}
+	if _, err := g.ResourceURL(link); err != nil {
+		return link, ErrResourceNotFound(fmt.Sprintf("%s with source %s", relativeLink, source.String()))
+	}
+	return link, nil
+}
+
+// LoadRepository does nothing, gists are read when their files are resolved
+func (g *gists) LoadRepository(_ context.Context, _ string) error {
+	return nil
+}
+
+// Tree fails, gists have no trees
+func (g *gists) Tree(resource URL) ([]string, error) {
+	return nil, fmt.Errorf("gist %s has no tree", resource.String())
+}
+
+// ChangedFiles reports the base as not found, so that the gist files are considered unchanged
+func (g *gists) ChangedFiles(_ context.Context, resource URL, base string) ([]string, error) {
+	return nil, ErrResourceNotFound(fmt.Sprintf("base %s of gist %s", base, resource.GetRepo()))
+}
+
+// ListRefs fails, the revisions of gists are not listed
+func (g *gists) ListRefs(_ context.Context, repoURL string, _ string) ([]string, error) {
+	return nil, fmt.Errorf("listing refs of gist %s is not supported", repoURL)
+}
+
+// Accept accepts the https links of the accepted hosts
+func (g *gists) Accept(link string) bool {
+	r, err := url.Parse(link)
+	if err != nil || r.Scheme != "https" {
+		return false
+	}
+	for _, h := range g.acceptedHosts {
+		if h == r.Host {
+			return true
+		}
+	}
+	return false
+}
+
+// Read reads the content of the gist file at the revision of the resource
+func (g *gists) Read(ctx context.Context, r URL) ([]byte, error) {
+	revision := r.GetRef()
+	if revision == "HEAD" {
+		revision = ""
+	}
+	gist, err := g.gist(ctx, r.GetRepo(), revision)
+	if err != nil {
+		return nil, err
+	}
+	file, ok := gist.Files[github.GistFilename(r.GetResourcePath())]
+	if !ok {
+		return nil, ErrResourceNotFound(r.String())
+	}
+	if file.Content != nil && len(file.GetContent()) >= file.GetSize() {
+		return []byte(file.GetContent()), nil
+	}
+	// the API truncates the content of big files, which is read from their raw URL
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, file.GetRawURL(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		err = fmt.Errorf("reading gist file %s fails with HTTP status: %d", r.String(), resp.StatusCode)
+		if IsTransientStatus(resp.StatusCode) {
+			return nil, ErrTransient{err}
+		}
+		return nil, err
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// gist reads the gist at the revision, the latest one if empty, once
+func (g *gists) gist(ctx context.Context, id string, revision string) (*github.Gist, error) {
+	key := id + "@" + revision
+	g.mux.Lock()
+	defer g.mux.Unlock()
+	if gist, ok := g.read[key]; ok {
+		return gist, nil
+	}
+	var (
+		gist *github.Gist
+		resp *github.Response
+		err  error
+	)
+	if revision == "" {
+		gist, resp, err = g.gists.Get(ctx, id)
+	} else {
+		gist, resp, err = g.gists.GetRevision(ctx, id, revision)
+	}
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return nil, ErrResourceNotFound(path.Join("gist", key))
+		}
+		var netErr net.Error
+		if (resp != nil && IsTransientStatus(resp.StatusCode)) || (errors.As(err, &netErr) && netErr.Timeout()) {
+			return nil, ErrTransient{err}
+		}
+		return nil, err
+	}
+	g.read[key] = gist
+	return gist, nil
+}
+
+// Name returns gists
+func (g *gists) Name() string {
+	return "gists"
+}
+
+// Repositories returns nil, gists have no git info
+func (g *gists) Repositories() Repositories {
+	return nil
+}
+
+// GetClient returns the HTTP client of the gists
+func (g *gists) GetClient() httpclient.Client {
+	return g.client
+}
+
+// GetEditLink returns the link for editing the gist
+func (g *gists) GetEditLink(source URL) (string, error) {
+	return fmt.Sprintf("https://gist.github.com/%s/%s/edit", source.GetOwner(), source.GetRepo()), nil
+}
+
+// GitInfoOptions returns the default options
+func (g *gists) GitInfoOptions() GitInfoOptions {
+	return GitInfoOptions{DateLayout: DateFormat}
+}
+
+// GetRateLimit is not implemented, gists count against the rate limit of the GitHub host
+func (g *gists) GetRateLimit(_ context.Context) (int, int, time.Time, error) {
+	return 0, 0, time.Time{}, ErrNotImplemented
+}
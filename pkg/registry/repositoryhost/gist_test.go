@@ -0,0 +1,155 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package repositoryhost_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gardener/docforge/pkg/registry/repositoryhost"
+	"github.com/gardener/docforge/pkg/registry/repositoryhost/repositoryhostfakes"
+	"github.com/google/go-github/v43/github"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Gists test", func() {
+	const (
+		id       = "aa5a315d61ae9438b18d"
+		latest   = "57a7f021a713b1c5a6a199b54cc514735d2d462f"
+		revision = "1f1b1b1f1b1b1f1b1b1f1b1b1f1b1b1f1b1b1f1b"
+	)
+	var (
+		fakeGists *repositoryhostfakes.FakeGists
+		gists     repositoryhost.Interface
+	)
+
+	BeforeEach(func() {
+		fakeGists = &repositoryhostfakes.FakeGists{}
+		fakeGists.GetCalls(func(_ context.Context, gistID string) (*github.Gist, *github.Response, error) {
+			switch gistID {
+			case id:
+				return &github.Gist{Files: map[github.GistFilename]github.GistFile{
+					"README.md": {Content: github.String("# Gist"), Size: github.Int(6)},
+					"notes.md":  {Content: github.String("notes"), Size: github.Int(5)},
+				}}, nil, nil
+			case "bb":
+				return &github.Gist{Files: map[github.GistFilename]github.GistFile{
+					"only.md": {Content: github.String("only"), Size: github.Int(4)},
+				}}, nil, nil
+			}
+			return nil, &github.Response{Response: &http.Response{StatusCode: http.StatusNotFound}}, errors.New("not found")
+		})
+		fakeGists.GetRevisionCalls(func(ctx context.Context, gistID string, sha string) (*github.Gist, *github.Response, error) {
+			if sha != revision {
+				return fakeGists.GetStub(ctx, gistID)
+			}
+			return &github.Gist{Files: map[github.GistFilename]github.GistFile{
+				"README.md": {Content: github.String("# Old gist"), Size: github.Int(10)},
+			}}, nil, nil
+		})
+		fakeGists.ListCommitsReturns([]*github.GistCommit{{Version: github.String(latest)}}, nil, nil)
+		gists = repositoryhost.NewGists(fakeGists, nil, []string{"gist.github.com", "gist.githubusercontent.com"})
+	})
+
+	Describe("#Accept", func() {
+		It("accepts the gist hosts", func() {
+			Expect(gists.Accept("https://gist.github.com/user/" + id)).To(BeTrue())
+			Expect(gists.Accept("https://gist.githubusercontent.com/user/" + id + "/raw/README.md")).To(BeTrue())
+		})
+
+		It("rejects other hosts", func() {
+			Expect(gists.Accept("https://github.com/gardener/docforge/blob/master/README.md")).To(BeFalse())
+			Expect(gists.Accept("http://gist.github.com/user/" + id)).To(BeFalse())
+		})
+	})
+
+	Describe("#ResourceURL", func() {
+		It("parses the web URL of a single file gist", func() {
+			u, err := gists.ResourceURL("https://gist.github.com/user/bb")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(u.String()).To(Equal("https://gist.githubusercontent.com/user/bb/raw/" + latest + "/only.md"))
+		})
+
+		It("parses the raw URL of a gist file", func() {
+			u, err := gists.ResourceURL("https://gist.githubusercontent.com/user/" + id + "/raw/notes.md")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(u.GetOwner()).To(Equal("user"))
+			Expect(u.GetRepo()).To(Equal(id))
+			Expect(u.GetRef()).To(Equal(latest))
+			Expect(u.GetResourcePath()).To(Equal("notes.md"))
+		})
+
+		It("keeps the revision of a pinned raw URL", func() {
+			u, err := gists.ResourceURL("https://gist.githubusercontent.com/user/" + id + "/raw/" + revision + "/README.md")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(u.GetRef()).To(Equal(revision))
+			Expect(fakeGists.GetRevisionCallCount()).To(Equal(1))
+		})
+
+		It("fails for the web URL of a gist with many files", func() {
+			_, err := gists.ResourceURL("https://gist.github.com/user/" + id)
+			Expect(err).To(MatchError(ContainSubstring("has 2 files")))
+		})
+
+		It("reports missing files and gists as not found", func() {
+			_, err := gists.ResourceURL("https://gist.githubusercontent.com/user/" + id + "/raw/missing.md")
+			Expect(errors.As(err, new(repositoryhost.ErrResourceNotFound))).To(BeTrue())
+			_, err = gists.ResourceURL("https://gist.github.com/user/cc")
+			Expect(errors.As(err, new(repositoryhost.ErrResourceNotFound))).To(BeTrue())
+		})
+
+		It("fails for other URLs", func() {
+			_, err := gists.ResourceURL("https://gist.github.com/user")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("#Read", func() {
+		It("reads the content of a gist file", func() {
+			u, err := gists.ResourceURL("https://gist.githubusercontent.com/user/" + id + "/raw/README.md")
+			Expect(err).NotTo(HaveOccurred())
+			content, err := gists.Read(context.TODO(), *u)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("# Gist"))
+		})
+
+		It("reads the content of a gist file at a revision", func() {
+			u, err := gists.ResourceURL("https://gist.githubusercontent.com/user/" + id + "/raw/" + revision + "/README.md")
+			Expect(err).NotTo(HaveOccurred())
+			content, err := gists.Read(context.TODO(), *u)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("# Old gist"))
+		})
+
+		It("reads the latest content when the revision is unknown", func() {
+			fakeGists.ListCommitsReturns(nil, nil, errors.New("no commits"))
+			u, err := gists.ResourceURL("https://gist.githubusercontent.com/user/" + id + "/raw/README.md")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(u.GetRef()).To(Equal("HEAD"))
+			content, err := gists.Read(context.TODO(), *u)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("# Gist"))
+			Expect(fakeGists.GetCallCount()).To(Equal(1))
+		})
+
+		It("fails for transient errors", func() {
+			fakeGists.GetRevisionReturns(nil, &github.Response{Response: &http.Response{StatusCode: http.StatusServiceUnavailable}}, errors.New("service unavailable"))
+			u, err := gists.ResourceURL("https://gist.githubusercontent.com/user/" + id + "/raw/README.md")
+			Expect(err).NotTo(HaveOccurred())
+			_, err = gists.Read(context.TODO(), *u)
+			Expect(errors.As(err, new(repositoryhost.ErrTransient))).To(BeTrue())
+		})
+	})
+
+	Describe("#GetEditLink", func() {
+		It("returns the edit link of the gist", func() {
+			u, err := gists.ResourceURL("https://gist.github.com/user/bb")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(gists.GetEditLink(*u)).To(Equal("https://gist.github.com/user/bb/edit"))
+		})
+	})
+})
@@ -0,0 +1,215 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package repositoryhost
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gardener/docforge/pkg/osfakes/httpclient"
+	"github.com/google/go-github/v43/github"
+)
+
+// graphQLCommitsCount matches the default page size of the REST commits list
+const graphQLCommitsCount = 30
+
+// GraphQLEndpoint returns the GitHub GraphQL API endpoint of a host
+func GraphQLEndpoint(host string) string {
+	if host == "github.com" {
+		return "https://api.github.com/graphql"
+	}
+	return fmt.Sprintf("https://%s/api/graphql", host)
+}
+
+type graphQLRequest struct {
+	Query string `json:"query"`
+}
+
+type graphQLResponse struct {
+	Data   map[string]*graphQLRepository `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+type graphQLRepository struct {
+	Object *struct {
+		History struct {
+			Nodes []graphQLCommit `json:"nodes"`
+		} `json:"history"`
+	} `json:"object"`
+}
+
+type graphQLCommit struct {
+	OID       string         `json:"oid"`
+	URL       string         `json:"url"`
+	Message   string         `json:"message"`
+	Author    graphQLGitUser `json:"author"`
+	Committer graphQLGitUser `json:"committer"`
+}
+
+type graphQLGitUser struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	Date  string `json:"date"`
+	User  *struct {
+		Login      string `json:"login"`
+		DatabaseID int64  `json:"databaseId"`
+		AvatarURL  string `json:"avatarUrl"`
+		URL        string `json:"url"`
+		Email      string `json:"email"`
+	} `json:"user"`
+}
+
+// ReadGitInfos reads the git info of resources hosted on the same GitHub host
+// with a single GraphQL request. The result maps each resource URL to the same
+// git info JSON as ReadGitInfo, resources without commits are mapped to nil
+// and resources that couldn't be resolved are omitted.
+func ReadGitInfos(ctx context.Context, client httpclient.Client, endpoint string, resources []URL, opts GitInfoOptions) (map[string][]byte, error) {
+	if len(resources) == 0 {
+		return map[string][]byte{}, nil
+	}
+	body, err := json.Marshal(graphQLRequest{Query: gitInfoQuery(resources)})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("graphql query to %s fails with HTTP status: %d", endpoint, resp.StatusCode)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var result graphQLResponse
+	if err = json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("invalid graphql response from %s: %w", endpoint, err)
+	}
+	if len(result.Errors) > 0 && result.Data == nil {
+		return nil, fmt.Errorf("graphql query to %s fails: %s", endpoint, result.Errors[0].Message)
+	}
+	infos := map[string][]byte{}
+	for i, r := range resources {
+		repository := result.Data[fmt.Sprintf("r%d", i)]
+		if repository == nil || repository.Object == nil {
+			continue
+		}
+		commits, err := toRepositoryCommits(repository.Object.History.Nodes)
+		if err != nil {
+			return nil, err
+		}
+		if infos[r.String()], err = marshalGitInfo(commits, r, opts); err != nil {
+			return nil, err
+		}
+	}
+	return infos, nil
+}
+
+// gitInfoQuery builds a query with one aliased commit history per resource
+func gitInfoQuery(resources []URL) string {
+	var b strings.Builder
+	b.WriteString("query {\n")
+	for i, r := range resources {
+		fmt.Fprintf(&b, "  r%d: repository(owner: %s, name: %s) {\n", i, graphQLString(r.GetOwner()), graphQLString(r.GetRepo()))
+		fmt.Fprintf(&b, "    object(expression: %s) {\n", graphQLString(r.GetRef()))
+		fmt.Fprintf(&b, "      ... on Commit { history(first: %d, path: %s) { nodes { ...commitFields } } }\n", graphQLCommitsCount, graphQLString(r.GetResourcePath()))
+		b.WriteString("    }\n  }\n")
+	}
+	b.WriteString("}\n")
+	b.WriteString(`fragment commitFields on Commit {
+  oid
+  url
+  message
+  author { name email date user { login databaseId avatarUrl url } }
+  committer { name email date user { login email } }
+}
+`)
+	return b.String()
+}
+
+func graphQLString(s string) string {
+	quoted, _ := json.Marshal(s)
+	return string(quoted)
+}
+
+// toRepositoryCommits converts GraphQL commits to the REST commits model
+func toRepositoryCommits(nodes []graphQLCommit) ([]*github.RepositoryCommit, error) {
+	commits := make([]*github.RepositoryCommit, 0, len(nodes))
+	for _, n := range nodes {
+		author, err := toCommitAuthor(n.Author)
+		if err != nil {
+			return nil, err
+		}
+		committer, err := toCommitAuthor(n.Committer)
+		if err != nil {
+			return nil, err
+		}
+		commit := &github.RepositoryCommit{
+			SHA:     github.String(n.OID),
+			HTMLURL: github.String(n.URL),
+			Commit: &github.Commit{
+				SHA:       github.String(n.OID),
+				Message:   github.String(n.Message),
+				Author:    author,
+				Committer: committer,
+			},
+		}
+		if u := n.Author.User; u != nil {
+			commit.Author = &github.User{
+				Login:     optionalString(u.Login),
+				AvatarURL: optionalString(u.AvatarURL),
+				HTMLURL:   optionalString(u.URL),
+				Type:      github.String("User"),
+			}
+			if u.DatabaseID != 0 {
+				commit.Author.ID = github.Int64(u.DatabaseID)
+			}
+		}
+		if u := n.Committer.User; u != nil {
+			commit.Committer = &github.User{
+				Login: optionalString(u.Login),
+				Email: optionalString(u.Email),
+			}
+		}
+		commits = append(commits, commit)
+	}
+	return commits, nil
+}
+
+func toCommitAuthor(u graphQLGitUser) (*github.CommitAuthor, error) {
+	date, err := time.Parse(time.RFC3339, u.Date)
+	if err != nil {
+		return nil, fmt.Errorf("invalid commit date %s: %w", u.Date, err)
+	}
+	// REST API reports commit dates in UTC
+	date = date.UTC()
+	return &github.CommitAuthor{
+		Name:  github.String(u.Name),
+		Email: github.String(u.Email),
+		Date:  &date,
+	}, nil
+}
+
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
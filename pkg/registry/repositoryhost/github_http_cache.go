@@ -8,10 +8,16 @@ package repositoryhost
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"net/url"
+	"path"
+	"regexp"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gardener/docforge/pkg/osfakes/httpclient"
@@ -26,8 +32,17 @@ type ghc struct {
 	rateLimit     RateLimitSource
 	repositories  Repositories
 	acceptedHosts []string
+	gitInfo       GitInfoOptions
 
+	// mux guards repositoryFiles, repositoryIgnoreFiles, repositoryIgnores and refLocks
+	mux             sync.RWMutex
 	repositoryFiles map[string]map[string]string
+	// repositoryIgnoreFiles are the SHAs of the ignore files of a reference by their repository paths
+	repositoryIgnoreFiles map[string]map[string]string
+	// repositoryIgnores are the rules of the ignore files of a reference read by trees
+	repositoryIgnores map[string]map[string]ignoreRules
+	// refLocks serializes loading of the same reference
+	refLocks map[string]*sync.Mutex
 }
 
 //counterfeiter:generate . RateLimitSource
@@ -43,6 +58,9 @@ type RateLimitSource interface {
 type Repositories interface {
 	ListCommits(ctx context.Context, owner, repo string, opts *github.CommitsListOptions) ([]*github.RepositoryCommit, *github.Response, error)
 	Get(ctx context.Context, owner, repo string) (*github.Repository, *github.Response, error)
+	CompareCommits(ctx context.Context, owner, repo string, base, head string, opts *github.ListOptions) (*github.CommitsComparison, *github.Response, error)
+	ListBranches(ctx context.Context, owner string, repo string, opts *github.BranchListOptions) ([]*github.Branch, *github.Response, error)
+	ListTags(ctx context.Context, owner string, repo string, opts *github.ListOptions) ([]*github.RepositoryTag, *github.Response, error)
 }
 
 //counterfeiter:generate . Git
@@ -53,16 +71,23 @@ type Git interface {
 	GetTree(ctx context.Context, owner string, repo string, sha string, recursive bool) (*github.Tree, *github.Response, error)
 }
 
-// NewGHC creates new GHC resource handler
-func NewGHC(hostName string, rateLimit RateLimitSource, repositories Repositories, git Git, client httpclient.Client, acceptedHosts []string) Interface {
+// NewGHC creates new GHC resource handler, git info of its resources is read with gitInfo options
+func NewGHC(hostName string, rateLimit RateLimitSource, repositories Repositories, git Git, client httpclient.Client, acceptedHosts []string, gitInfo GitInfoOptions) Interface {
+	if gitInfo.DateLayout == "" {
+		gitInfo.DateLayout = DateFormat
+	}
 	return &ghc{
-		hostName:        hostName,
-		client:          client,
-		git:             git,
-		rateLimit:       rateLimit,
-		repositories:    repositories,
-		acceptedHosts:   acceptedHosts,
-		repositoryFiles: map[string]map[string]string{},
+		hostName:              hostName,
+		client:                client,
+		git:                   git,
+		rateLimit:             rateLimit,
+		repositories:          repositories,
+		acceptedHosts:         acceptedHosts,
+		gitInfo:               gitInfo,
+		repositoryFiles:       map[string]map[string]string{},
+		repositoryIgnoreFiles: map[string]map[string]string{},
+		repositoryIgnores:     map[string]map[string]ignoreRules{},
+		refLocks:              map[string]*sync.Mutex{},
 	}
 }
 
@@ -72,7 +97,10 @@ func (p *ghc) LoadRepository(ctx context.Context, resourceURL string) error {
 		return err
 	}
 	refURL := resURL.ReferenceURL()
-	if _, ok := p.repositoryFiles[refURL.String()]; ok {
+	refLock := p.refLock(refURL.String())
+	refLock.Lock()
+	defer refLock.Unlock()
+	if _, ok := p.files(refURL.String()); ok {
 		return nil
 	}
 	dirContents, _, err := p.git.GetTree(ctx, resURL.GetOwner(), resURL.GetRepo(), resURL.GetRef(), true)
@@ -80,10 +108,15 @@ func (p *ghc) LoadRepository(ctx context.Context, resourceURL string) error {
 		return err
 	}
 	repoContent := map[string]string{}
+	repoIgnoreFiles := map[string]string{}
 	for _, entry := range dirContents.Entries {
 		if strings.HasPrefix(entry.GetPath(), "vendor") {
 			continue
 		}
+		if entry.GetType() == "blob" && path.Base(entry.GetPath()) == IgnoreFile {
+			// ignore files are read when a tree containing or below their directory is listed
+			repoIgnoreFiles[entry.GetPath()] = entry.GetSHA()
+		}
 		resource, err := refURL.GetDifferentType(entry.GetType())
 		if err != nil {
 			klog.Infof("failed processing %s when loading repository: %s. Skipping it", entry.GetPath(), err.Error())
@@ -92,11 +125,33 @@ func (p *ghc) LoadRepository(ctx context.Context, resourceURL string) error {
 		resourceURL := fmt.Sprintf("%s/%s", resource, entry.GetPath())
 		repoContent[resourceURL] = entry.GetSHA()
 	}
+	p.mux.Lock()
 	p.repositoryFiles[refURL.String()] = repoContent
+	p.repositoryIgnoreFiles[refURL.String()] = repoIgnoreFiles
+	p.repositoryIgnores[refURL.String()] = map[string]ignoreRules{}
+	p.mux.Unlock()
 	klog.Infof("Loading reference %s with %d entries", refURL.String(), len(repoContent))
 	return nil
 }
 
+// refLock returns the lock serializing loading of a reference
+func (p *ghc) refLock(refURL string) *sync.Mutex {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	if _, ok := p.refLocks[refURL]; !ok {
+		p.refLocks[refURL] = &sync.Mutex{}
+	}
+	return p.refLocks[refURL]
+}
+
+// files returns the loaded files of a reference
+func (p *ghc) files(refURL string) (map[string]string, bool) {
+	p.mux.RLock()
+	defer p.mux.RUnlock()
+	files, ok := p.repositoryFiles[refURL]
+	return files, ok
+}
+
 func (p *ghc) Tree(r URL) ([]string, error) {
 	if r.GetResourceType() != "tree" {
 		return nil, fmt.Errorf("expected a tree url got %s", r.String())
@@ -108,20 +163,165 @@ func (p *ghc) Tree(r URL) ([]string, error) {
 		return []string{}, err
 	}
 	filterString := filter + "/"
-	for url := range p.repositoryFiles[refURL] {
+	files, _ := p.files(refURL)
+	ignores, err := p.ignoreRules(r)
+	if err != nil {
+		return nil, err
+	}
+	for url := range files {
 		if strings.HasPrefix(url, filterString) {
-			out = append(out, strings.TrimPrefix(url, filterString))
+			file := strings.TrimPrefix(url, filterString)
+			if ignores.ignored(path.Join(r.GetResourcePath(), file)) {
+				continue
+			}
+			out = append(out, file)
 		}
 	}
 	return out, nil
 }
 
+// ignoreRules reads the ignore files in the tree directory, its subdirectories and its ancestors that weren't read
+// by another tree of the reference yet
+func (p *ghc) ignoreRules(r URL) (ignoreRules, error) {
+	refURL := r.ReferenceURL().String()
+	refLock := p.refLock(refURL)
+	refLock.Lock()
+	defer refLock.Unlock()
+	p.mux.RLock()
+	ignoreFiles := p.repositoryIgnoreFiles[refURL]
+	p.mux.RUnlock()
+	treePath := r.GetResourcePath()
+	rules := ignoreRules{}
+	for ignoreFile, sha := range ignoreFiles {
+		dir := path.Dir(ignoreFile)
+		if dir == "." {
+			dir = ""
+		}
+		if !inDir(treePath, dir) && !inDir(dir, treePath) {
+			continue
+		}
+		p.mux.RLock()
+		fileRules, ok := p.repositoryIgnores[refURL][ignoreFile]
+		p.mux.RUnlock()
+		if !ok {
+			cnt, _, err := p.git.GetBlobRaw(context.TODO(), r.GetOwner(), r.GetRepo(), sha)
+			if err != nil {
+				return nil, fmt.Errorf("reading %s of %s failed: %w", ignoreFile, refURL, err)
+			}
+			fileRules = parseIgnoreFile(ignoreFile, cnt)
+			p.mux.Lock()
+			p.repositoryIgnores[refURL][ignoreFile] = fileRules
+			p.mux.Unlock()
+		}
+		rules = rules.merge(fileRules)
+	}
+	return rules, nil
+}
+
+// inDir checks if the repository path is the directory dir or below it
+func inDir(p string, dir string) bool {
+	return dir == "" || p == dir || strings.HasPrefix(p, dir+"/")
+}
+
+// ChangedFiles compares the base ref with the ref of the resource. Renamed files are changed with both their paths
+func (p *ghc) ChangedFiles(ctx context.Context, r URL, base string) ([]string, error) {
+	files := []string{}
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		comparison, resp, err := p.repositories.CompareCommits(ctx, r.GetOwner(), r.GetRepo(), base, r.GetRef(), opts)
+		if err != nil {
+			if resp != nil && resp.StatusCode == http.StatusNotFound {
+				return nil, ErrResourceNotFound(fmt.Sprintf("base %s of %s", base, r.ReferenceURL().String()))
+			}
+			return nil, fmt.Errorf("comparing base %s with %s failed: %w", base, r.ReferenceURL().String(), err)
+		}
+		for _, file := range comparison.Files {
+			files = append(files, file.GetFilename())
+			if file.GetPreviousFilename() != "" {
+				files = append(files, file.GetPreviousFilename())
+			}
+		}
+		if resp == nil || resp.NextPage == 0 {
+			return files, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// ListRefs returns the branches, followed by the tags, of the repository whose names match the pattern
+func (p *ghc) ListRefs(ctx context.Context, repoURL string, pattern string) ([]string, error) {
+	owner, repo, err := repositoryOf(repoURL)
+	if err != nil {
+		return nil, err
+	}
+	matcher, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid refs pattern %s: %w", pattern, err)
+	}
+	refs := []string{}
+	add := func(ref string) {
+		if matcher.MatchString(ref) && !slices.Contains(refs, ref) {
+			refs = append(refs, ref)
+		}
+	}
+	branchOpts := &github.BranchListOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		branches, resp, err := p.repositories.ListBranches(ctx, owner, repo, branchOpts)
+		if err != nil {
+			return nil, listRefsError(repoURL, resp, err)
+		}
+		for _, branch := range branches {
+			add(branch.GetName())
+		}
+		if resp == nil || resp.NextPage == 0 {
+			break
+		}
+		branchOpts.Page = resp.NextPage
+	}
+	tagOpts := &github.ListOptions{PerPage: 100}
+	for {
+		tags, resp, err := p.repositories.ListTags(ctx, owner, repo, tagOpts)
+		if err != nil {
+			return nil, listRefsError(repoURL, resp, err)
+		}
+		for _, tag := range tags {
+			add(tag.GetName())
+		}
+		if resp == nil || resp.NextPage == 0 {
+			return refs, nil
+		}
+		tagOpts.Page = resp.NextPage
+	}
+}
+
+// repositoryOf returns the owner and the name of the repository of a repository URL like https://github.com/gardener/docforge
+// or of a resource URL
+func repositoryOf(repoURL string) (string, string, error) {
+	u, err := url.Parse(repoURL)
+	if err != nil {
+		return "", "", err
+	}
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(segments) < 2 || segments[0] == "" || segments[1] == "" {
+		return "", "", fmt.Errorf("%s is not a repository URL", repoURL)
+	}
+	return segments[0], strings.TrimSuffix(segments[1], ".git"), nil
+}
+
+func listRefsError(repoURL string, resp *github.Response, err error) error {
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return ErrResourceNotFound(repoURL)
+	}
+	return fmt.Errorf("listing refs of %s failed: %w", repoURL, err)
+}
+
 func (p *ghc) ResourceURL(resourceURL string) (*URL, error) {
 	resource, err := new(resourceURL)
 	if err != nil {
 		return nil, err
 	}
-	if _, ok := p.repositoryFiles[resource.ReferenceURL().String()][resource.ResourceURL()]; !ok {
+	files, _ := p.files(resource.ReferenceURL().String())
+	if _, ok := files[resource.ResourceURL()]; !ok {
 		return nil, ErrResourceNotFound(resourceURL)
 	}
 	return resource, nil
@@ -146,16 +346,25 @@ func (p *ghc) Read(ctx context.Context, r URL) ([]byte, error) {
 		return nil, fmt.Errorf("not a blob/raw url: %s", r.String())
 	}
 	refURL := r.ReferenceURL().String()
-	SHA := p.repositoryFiles[refURL][r.ResourceURL()]
+	files, _ := p.files(refURL)
+	SHA := files[r.ResourceURL()]
 	raw, resp, err := p.git.GetBlobRaw(ctx, r.GetOwner(), r.GetRepo(), SHA)
 	if err != nil {
 		if resp != nil && resp.StatusCode == http.StatusNotFound {
 			return nil, ErrResourceNotFound(r.String())
 		}
+		var netErr net.Error
+		if (resp != nil && IsTransientStatus(resp.StatusCode)) || (errors.As(err, &netErr) && netErr.Timeout()) {
+			return nil, ErrTransient{err}
+		}
 		return nil, err
 	}
 	if resp != nil && resp.StatusCode >= 400 {
-		return nil, fmt.Errorf("reading blob %s fails with HTTP status: %d", r.String(), resp.StatusCode)
+		err = fmt.Errorf("reading blob %s fails with HTTP status: %d", r.String(), resp.StatusCode)
+		if IsTransientStatus(resp.StatusCode) {
+			return nil, ErrTransient{err}
+		}
+		return nil, err
 	}
 	return raw, nil
 }
@@ -182,6 +391,14 @@ func (p *ghc) GetClient() httpclient.Client {
 	return p.client
 }
 
+func (p *ghc) GetEditLink(source URL) (string, error) {
+	return source.EditURL()
+}
+
+func (p *ghc) GitInfoOptions() GitInfoOptions {
+	return p.gitInfo
+}
+
 func (p *ghc) GetRateLimit(ctx context.Context) (int, int, time.Time, error) {
 	r, _, err := p.rateLimit.RateLimits(ctx)
 	if err != nil {
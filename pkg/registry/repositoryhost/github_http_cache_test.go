@@ -8,7 +8,9 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/gardener/docforge/pkg/osfakes/httpclient"
 	"github.com/gardener/docforge/pkg/registry/repositoryhost"
@@ -37,10 +39,15 @@ var _ = Describe("Github cache test", func() {
 		} else if s3 == "2" {
 			githubResp := &github.Response{Response: &http.Response{StatusCode: http.StatusNotFound}}
 			return nil, githubResp, errors.New("not found")
+		} else if s3 == "11" {
+			return []byte("# excluded from the documentation\ndrafts/\nsection/wip/*\n!section/wip/keep.md\n"), nil, nil
+		} else if s3 == "4" {
+			githubResp := &github.Response{Response: &http.Response{StatusCode: http.StatusServiceUnavailable}}
+			return nil, githubResp, errors.New("service unavailable")
 		}
 		return nil, nil, errors.New("wrong test file")
 	})
-	ghc := repositoryhost.NewGHC("testing", &rls, &repositories, &git, client, []string{"github.com"})
+	ghc := repositoryhost.NewGHC("testing", &rls, &repositories, &git, client, []string{"github.com"}, repositoryhost.GitInfoOptions{})
 	tree := github.Tree{
 		Entries: []*github.TreeEntry{
 			{
@@ -93,6 +100,36 @@ var _ = Describe("Github cache test", func() {
 				Type: github.String("blob"),
 				SHA:  github.String("10"),
 			},
+			{
+				Path: github.String("docs/.docforgeignore"),
+				Type: github.String("blob"),
+				SHA:  github.String("11"),
+			},
+			{
+				Path: github.String("docs/drafts"),
+				Type: github.String("tree"),
+				SHA:  github.String("12"),
+			},
+			{
+				Path: github.String("docs/drafts/draft.md"),
+				Type: github.String("blob"),
+				SHA:  github.String("13"),
+			},
+			{
+				Path: github.String("docs/section/wip"),
+				Type: github.String("tree"),
+				SHA:  github.String("14"),
+			},
+			{
+				Path: github.String("docs/section/wip/page.md"),
+				Type: github.String("blob"),
+				SHA:  github.String("15"),
+			},
+			{
+				Path: github.String("docs/section/wip/keep.md"),
+				Type: github.String("blob"),
+				SHA:  github.String("16"),
+			},
 		},
 	}
 	git.GetTreeReturns(&tree, nil, nil)
@@ -106,4 +143,135 @@ var _ = Describe("Github cache test", func() {
 		_, err = ghc.Read(context.TODO(), *resourceURl)
 		Expect(err).To(Equal(repositoryhost.ErrResourceNotFound("https://github.com/gardener/docforge/blob/master/Makefile")))
 	})
+
+	It("reading fails with transient error on 5xx HTTP status", func() {
+		resourceURl, err := ghc.ResourceURL("https://github.com/gardener/docforge/blob/master/pkg/main.go")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = ghc.Read(context.TODO(), *resourceURl)
+		Expect(err).To(MatchError(repositoryhost.ErrTransient{Err: errors.New("service unavailable")}))
+	})
+
+	It("loads a reference once when loaded concurrently", func() {
+		concurrentGit := repositoryhostfakes.FakeGit{}
+		concurrentGit.GetTreeReturns(&tree, nil, nil)
+		concurrentGHC := repositoryhost.NewGHC("testing", &rls, &repositories, &concurrentGit, client, []string{"github.com"}, repositoryhost.GitInfoOptions{})
+		var wg sync.WaitGroup
+		for _, resourceURL := range []string{"https://github.com/gardener/docforge/blob/master/README.md", "https://github.com/gardener/docforge/blob/master/docs/index.md", "https://github.com/gardener/docforge/tree/master/pkg"} {
+			wg.Add(1)
+			go func(resourceURL string) {
+				defer GinkgoRecover()
+				defer wg.Done()
+				Expect(concurrentGHC.LoadRepository(context.TODO(), resourceURL)).To(Succeed())
+			}(resourceURL)
+		}
+		wg.Wait()
+		Expect(concurrentGit.GetTreeCallCount()).To(Equal(1))
+		_, err := concurrentGHC.ResourceURL("https://github.com/gardener/docforge/blob/master/docs/section/page.md")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("reads the ignore files when a tree of their directory is listed", func() {
+		lazyGit := repositoryhostfakes.FakeGit{}
+		lazyGit.GetTreeReturns(&tree, nil, nil)
+		lazyGit.GetBlobRawReturns([]byte("drafts/\n"), nil, nil)
+		lazyGHC := repositoryhost.NewGHC("testing", &rls, &repositories, &lazyGit, client, []string{"github.com"}, repositoryhost.GitInfoOptions{})
+		Expect(lazyGHC.LoadRepository(context.TODO(), "https://github.com/gardener/docforge/blob/master/README.md")).To(Succeed())
+		Expect(lazyGit.GetBlobRawCallCount()).To(Equal(0))
+		pkgURL, err := lazyGHC.ResourceURL("https://github.com/gardener/docforge/tree/master/pkg")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = lazyGHC.Tree(*pkgURL)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(lazyGit.GetBlobRawCallCount()).To(Equal(0))
+		for _, treeURL := range []string{"https://github.com/gardener/docforge/tree/master/docs", "https://github.com/gardener/docforge/tree/master/docs/section"} {
+			resourceURL, err := lazyGHC.ResourceURL(treeURL)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = lazyGHC.Tree(*resourceURL)
+			Expect(err).NotTo(HaveOccurred())
+		}
+		Expect(lazyGit.GetBlobRawCallCount()).To(Equal(1))
+		_, _, _, sha := lazyGit.GetBlobRawArgsForCall(0)
+		Expect(sha).To(Equal("11"))
+	})
+
+	It("loads and reads the resources of a commit", func() {
+		sha := "6f1c2a1b6f6e4c3d2b1a09f8e7d6c5b4a3928170"
+		pinnedGit := repositoryhostfakes.FakeGit{}
+		pinnedGit.GetTreeReturns(&tree, nil, nil)
+		pinnedGit.GetBlobRawReturns([]byte("foo"), nil, nil)
+		pinnedGHC := repositoryhost.NewGHC("testing", &rls, &repositories, &pinnedGit, client, []string{"github.com"}, repositoryhost.GitInfoOptions{})
+		Expect(pinnedGHC.LoadRepository(context.TODO(), "https://github.com/gardener/docforge/blob/"+sha+"/README.md")).To(Succeed())
+		_, _, _, ref, _ := pinnedGit.GetTreeArgsForCall(0)
+		Expect(ref).To(Equal(sha))
+		treeURL, err := pinnedGHC.ResourceURL("https://github.com/gardener/docforge/tree/" + sha + "/docs/section")
+		Expect(err).NotTo(HaveOccurred())
+		files, err := pinnedGHC.Tree(*treeURL)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(files).To(ContainElement("page.md"))
+		resourceURL, err := pinnedGHC.ResourceURL("https://github.com/gardener/docforge/blob/" + sha + "/README.md")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resourceURL.GetRef()).To(Equal(sha))
+		content, err := pinnedGHC.Read(context.TODO(), *resourceURL)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(content)).To(Equal("foo"))
+		_, err = pinnedGHC.ResourceURL("https://github.com/gardener/docforge/blob/master/README.md")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("formats git info dates with the configured layout", func() {
+		Expect(ghc.GitInfoOptions().DateLayout).To(Equal(repositoryhost.DateFormat))
+		rfc3339GHC := repositoryhost.NewGHC("testing", &rls, &repositories, &git, client, []string{"github.com"}, repositoryhost.GitInfoOptions{DateLayout: time.RFC3339})
+		Expect(rfc3339GHC.GitInfoOptions().DateLayout).To(Equal(time.RFC3339))
+	})
+	It("lists the files changed since a base ref across comparison pages", func() {
+		comparing := repositoryhostfakes.FakeRepositories{}
+		comparing.CompareCommitsReturnsOnCall(0, &github.CommitsComparison{Files: []*github.CommitFile{
+			{Filename: github.String("docs/index.md")},
+			{Filename: github.String("docs/new.md"), PreviousFilename: github.String("docs/old.md")},
+		}}, &github.Response{NextPage: 2}, nil)
+		comparing.CompareCommitsReturnsOnCall(1, &github.CommitsComparison{Files: []*github.CommitFile{
+			{Filename: github.String("README.md")},
+		}}, &github.Response{}, nil)
+		comparingGHC := repositoryhost.NewGHC("testing", &rls, &comparing, &git, client, []string{"github.com"}, repositoryhost.GitInfoOptions{})
+		resourceURL, err := ghc.ResourceURL("https://github.com/gardener/docforge/blob/master/docs/index.md")
+		Expect(err).NotTo(HaveOccurred())
+		files, err := comparingGHC.ChangedFiles(context.TODO(), *resourceURL, "v1.0.0")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(files).To(Equal([]string{"docs/index.md", "docs/new.md", "docs/old.md", "README.md"}))
+		Expect(comparing.CompareCommitsCallCount()).To(Equal(2))
+		_, owner, repo, base, head, opts := comparing.CompareCommitsArgsForCall(1)
+		Expect([]string{owner, repo, base, head}).To(Equal([]string{"gardener", "docforge", "v1.0.0", "master"}))
+		Expect(opts.Page).To(Equal(2))
+	})
+
+	It("fails with resource not found for an unknown base ref", func() {
+		comparing := repositoryhostfakes.FakeRepositories{}
+		comparing.CompareCommitsReturns(nil, &github.Response{Response: &http.Response{StatusCode: http.StatusNotFound}}, errors.New("not found"))
+		comparingGHC := repositoryhost.NewGHC("testing", &rls, &comparing, &git, client, []string{"github.com"}, repositoryhost.GitInfoOptions{})
+		resourceURL, err := ghc.ResourceURL("https://github.com/gardener/docforge/blob/master/docs/index.md")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = comparingGHC.ChangedFiles(context.TODO(), *resourceURL, "missing")
+		Expect(errors.As(err, new(repositoryhost.ErrResourceNotFound))).To(BeTrue())
+	})
+	It("lists the branches and tags matching a pattern across pages", func() {
+		listing := repositoryhostfakes.FakeRepositories{}
+		listing.ListBranchesReturns([]*github.Branch{{Name: github.String("master")}}, &github.Response{}, nil)
+		listing.ListTagsReturnsOnCall(0, []*github.RepositoryTag{{Name: github.String("v1.0.0")}, {Name: github.String("latest")}}, &github.Response{NextPage: 2}, nil)
+		listing.ListTagsReturnsOnCall(1, []*github.RepositoryTag{{Name: github.String("v1.1.0-rc.1")}, {Name: github.String("v1.1.0")}}, &github.Response{}, nil)
+		listingGHC := repositoryhost.NewGHC("testing", &rls, &listing, &git, client, []string{"github.com"}, repositoryhost.GitInfoOptions{})
+		refs, err := listingGHC.ListRefs(context.TODO(), "https://github.com/gardener/docforge", `^v\d+\.\d+\.\d+$`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(refs).To(Equal([]string{"v1.0.0", "v1.1.0"}))
+		Expect(listing.ListTagsCallCount()).To(Equal(2))
+		_, owner, repo, opts := listing.ListTagsArgsForCall(1)
+		Expect([]string{owner, repo}).To(Equal([]string{"gardener", "docforge"}))
+		Expect(opts.Page).To(Equal(2))
+		refs, err = listingGHC.ListRefs(context.TODO(), "https://github.com/gardener/docforge", "^master$")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(refs).To(Equal([]string{"master"}))
+	})
+
+	It("fails listing refs for invalid patterns", func() {
+		_, err := ghc.ListRefs(context.TODO(), "https://github.com/gardener/docforge", "v(")
+		Expect(err).To(MatchError(ContainSubstring("invalid refs pattern v(")))
+	})
 })
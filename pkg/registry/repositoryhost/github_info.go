@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"regexp"
 	"slices"
 	"sort"
 	"strings"
@@ -13,10 +14,24 @@ import (
 )
 
 const (
-	// DateFormat defines format for LastModifiedDate & PublishDate
+	// DateFormat defines the default layout of LastModifiedDate & PublishDate
 	DateFormat = "2006-01-02 15:04:05"
 )
 
+// noreplyEmail matches GitHub noreply emails capturing the user login
+var noreplyEmail = regexp.MustCompile(`^(?:\d+\+)?([^@]+)@users\.noreply\.`)
+
+// coAuthorTrailer matches the Co-authored-by trailers of commit messages capturing the name and the email
+var coAuthorTrailer = regexp.MustCompile(`(?im)^co-authored-by:\s*(.*?)\s*<([^>]+)>\s*$`)
+
+// GitInfoOptions defines how the git info of resources is read
+type GitInfoOptions struct {
+	// DateLayout is the layout of LastModifiedDate & PublishDate, DateFormat if empty
+	DateLayout string `mapstructure:"github-info-date-layout"`
+	// ContributorsByIdentity dedups contributors by normalized identity instead of by email
+	ContributorsByIdentity bool `mapstructure:"github-info-contributors-by-identity"`
+}
+
 // GitInfo defines git resource attributes
 type GitInfo struct {
 	LastModifiedDate *string        `json:"lastmod,omitempty"`
@@ -30,19 +45,24 @@ type GitInfo struct {
 }
 
 // ReadGitInfo reads the git info for a given resource URL
-func ReadGitInfo(ctx context.Context, repositories Repositories, r URL) ([]byte, error) {
-	opts := &github.CommitsListOptions{
+func ReadGitInfo(ctx context.Context, repositories Repositories, r URL, opts GitInfoOptions) ([]byte, error) {
+	listOpts := &github.CommitsListOptions{
 		Path: r.GetResourcePath(),
 		SHA:  r.GetRef(),
 	}
-	commits, resp, err := repositories.ListCommits(ctx, r.GetOwner(), r.GetRepo(), opts)
+	commits, resp, err := repositories.ListCommits(ctx, r.GetOwner(), r.GetRepo(), listOpts)
 	if err != nil {
 		return nil, err
 	}
 	if resp != nil && resp.StatusCode >= 400 {
 		return nil, fmt.Errorf("list commits for %s fails with HTTP status: %d", r.String(), resp.StatusCode)
 	}
-	gitInfo := transform(commits)
+	return marshalGitInfo(commits, r, opts)
+}
+
+// marshalGitInfo builds the git info JSON of a resource from its commits list
+func marshalGitInfo(commits []*github.RepositoryCommit, r URL, opts GitInfoOptions) ([]byte, error) {
+	gitInfo := transform(commits, opts)
 	if gitInfo == nil {
 		return nil, nil
 	}
@@ -58,10 +78,14 @@ func ReadGitInfo(ctx context.Context, repositories Repositories, r URL) ([]byte,
 }
 
 // transform builds git.Info from a commits list
-func transform(commits []*github.RepositoryCommit) *GitInfo {
+func transform(commits []*github.RepositoryCommit, opts GitInfoOptions) *GitInfo {
 	if commits == nil {
 		return nil
 	}
+	dateLayout := opts.DateLayout
+	if dateLayout == "" {
+		dateLayout = DateFormat
+	}
 	gitInfo := &GitInfo{}
 	// skip internal commits
 	nonInternalCommits := slices.DeleteFunc(commits, isInternalCommit)
@@ -71,33 +95,99 @@ func transform(commits []*github.RepositoryCommit) *GitInfo {
 	sort.Slice(nonInternalCommits, func(i, j int) bool {
 		return nonInternalCommits[i].GetCommit().GetCommitter().GetDate().After(nonInternalCommits[j].GetCommit().GetCommitter().GetDate())
 	})
-	lastModifiedDate := nonInternalCommits[0].GetCommit().GetCommitter().GetDate().Format(DateFormat)
+	lastModifiedDate := nonInternalCommits[0].GetCommit().GetCommitter().GetDate().Format(dateLayout)
 	gitInfo.LastModifiedDate = &lastModifiedDate
 
 	webURL := nonInternalCommits[0].GetHTMLURL()
 	gitInfo.WebURL = github.String(strings.Split(webURL, "/commit/")[0])
 
-	gitInfo.PublishDate = github.String(nonInternalCommits[len(nonInternalCommits)-1].GetCommit().GetCommitter().GetDate().Format(DateFormat))
+	gitInfo.PublishDate = github.String(nonInternalCommits[len(nonInternalCommits)-1].GetCommit().GetCommitter().GetDate().Format(dateLayout))
 
 	if gitInfo.Author = getCommitAuthor(nonInternalCommits[len(nonInternalCommits)-1]); gitInfo.Author == nil {
 		klog.Warningf("cannot get commit author")
 	}
-	if len(nonInternalCommits) < 2 {
+	gitInfo.Contributors = []*github.User{}
+	if opts.ContributorsByIdentity {
+		gitInfo.Contributors = contributorsByIdentity(nonInternalCommits, gitInfo.Author)
 		return gitInfo
 	}
-	gitInfo.Contributors = []*github.User{}
 	var registered []string
 	for _, commit := range nonInternalCommits {
-		var contributor *github.User
-		if contributor = getCommitAuthor(commit); contributor == nil {
+		for _, contributor := range getCommitContributors(commit) {
+			if contributor.GetType() == "User" && contributor.GetEmail() != gitInfo.Author.GetEmail() && slices.Index(registered, contributor.GetEmail()) < 0 {
+				gitInfo.Contributors = append(gitInfo.Contributors, contributor)
+				registered = append(registered, contributor.GetEmail())
+			}
+		}
+	}
+	return gitInfo
+}
+
+// contributorsByIdentity returns the commit authors other than author, deduplicated by their normalized identities.
+// Users sharing any identity key are considered the same person
+func contributorsByIdentity(commits []*github.RepositoryCommit, author *github.User) []*github.User {
+	users := []*github.User{}
+	if author != nil {
+		users = append(users, author)
+	}
+	for _, commit := range commits {
+		users = append(users, getCommitContributors(commit)...)
+	}
+	// union the identity keys of each user
+	parent := map[string]string{}
+	var find func(key string) string
+	find = func(key string) string {
+		if p, ok := parent[key]; ok && p != key {
+			parent[key] = find(p)
+			return parent[key]
+		}
+		parent[key] = key
+		return key
+	}
+	for _, user := range users {
+		keys := identityKeys(user)
+		for i := 1; i < len(keys); i++ {
+			parent[find(keys[i])] = find(keys[0])
+		}
+	}
+	contributors := []*github.User{}
+	registered := map[string]bool{}
+	for i, user := range users {
+		keys := identityKeys(user)
+		if len(keys) == 0 {
+			continue
+		}
+		identity := find(keys[0])
+		if registered[identity] {
 			continue
 		}
-		if contributor.GetType() == "User" && contributor.GetEmail() != gitInfo.Author.GetEmail() && slices.Index(registered, contributor.GetEmail()) < 0 {
-			gitInfo.Contributors = append(gitInfo.Contributors, contributor)
-			registered = append(registered, contributor.GetEmail())
+		registered[identity] = true
+		// the author registers its identity but isn't a contributor
+		if (author == nil || i > 0) && user.GetType() == "User" {
+			contributors = append(contributors, user)
 		}
 	}
-	return gitInfo
+	return contributors
+}
+
+// identityKeys returns the normalized identities of a user: the login, if known or
+// resolvable from a GitHub noreply email, and the lowercased email falling back to the lowercased name
+func identityKeys(user *github.User) []string {
+	var keys []string
+	email := strings.ToLower(user.GetEmail())
+	login := strings.ToLower(user.GetLogin())
+	if m := noreplyEmail.FindStringSubmatch(email); m != nil && login == "" {
+		login = m[1]
+	}
+	if login != "" {
+		keys = append(keys, "login:"+login)
+	}
+	if email != "" {
+		keys = append(keys, "email:"+email)
+	} else if name := strings.ToLower(user.GetName()); name != "" {
+		keys = append(keys, "name:"+name)
+	}
+	return keys
 }
 
 func isInternalCommit(commit *github.RepositoryCommit) bool {
@@ -126,3 +216,22 @@ func getCommitAuthor(commit *github.RepositoryCommit) *github.User {
 	}
 	return nil
 }
+
+// getCommitContributors returns the author of the commit followed by the co-authors of its Co-authored-by trailers
+func getCommitContributors(commit *github.RepositoryCommit) []*github.User {
+	var contributors []*github.User
+	if author := getCommitAuthor(commit); author != nil {
+		contributors = append(contributors, author)
+	}
+	for _, m := range coAuthorTrailer.FindAllStringSubmatch(commit.GetCommit().GetMessage(), -1) {
+		coAuthor := &github.User{Name: github.String(m[1]), Email: github.String(m[2]), Type: github.String("User")}
+		if login := noreplyEmail.FindStringSubmatch(strings.ToLower(m[2])); login != nil {
+			coAuthor.Login = github.String(login[1])
+		}
+		if strings.HasSuffix(m[1], "[bot]") {
+			coAuthor.Type = github.String("Bot")
+		}
+		contributors = append(contributors, coAuthor)
+	}
+	return contributors
+}
@@ -2,6 +2,9 @@ package repositoryhost_test
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"time"
 
 	"github.com/gardener/docforge/pkg/registry/repositoryhost"
@@ -54,8 +57,220 @@ var _ = Describe("#ReadGitInfo", func() {
 	It("returns correct git info", func() {
 		resourceURl, err := repositoryhost.NewResourceURL("https://github.com/gardener/docforge/blob/master/README.md")
 		Expect(err).NotTo(HaveOccurred())
-		content, err := repositoryhost.ReadGitInfo(context.TODO(), &repositories, *resourceURl)
+		content, err := repositoryhost.ReadGitInfo(context.TODO(), &repositories, *resourceURl, repositoryhost.GitInfoOptions{})
 		Expect(err).NotTo(HaveOccurred())
 		Expect(string(content)).To(Equal("{\n  \"lastmod\": \"2024-02-07 13:11:00\",\n  \"publishdate\": \"2024-02-06 13:11:00\",\n  \"author\": {\n    \"name\": \"one\",\n    \"email\": \"one@\"\n  },\n  \"weburl\": \"bar\",\n  \"shaalias\": \"master\",\n  \"path\": \"README.md\"\n}"))
 	})
+
+	It("formats dates with the configured layout", func() {
+		resourceURl, err := repositoryhost.NewResourceURL("https://github.com/gardener/docforge/blob/master/README.md")
+		Expect(err).NotTo(HaveOccurred())
+		content, err := repositoryhost.ReadGitInfo(context.TODO(), &repositories, *resourceURl, repositoryhost.GitInfoOptions{DateLayout: time.RFC3339})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(content)).To(ContainSubstring("\"lastmod\": \"2024-02-07T13:11:00Z\",\n  \"publishdate\": \"2024-02-06T13:11:00Z\""))
+	})
+})
+
+var _ = Describe("#ReadGitInfo contributors", func() {
+	var (
+		repositories repositoryhostfakes.FakeRepositories
+		resourceURl  *repositoryhost.URL
+	)
+
+	BeforeEach(func() {
+		var err error
+		resourceURl, err = repositoryhost.NewResourceURL("https://github.com/gardener/docforge/blob/master/README.md")
+		Expect(err).NotTo(HaveOccurred())
+		commit := func(day int, login string, name string, email string) *github.RepositoryCommit {
+			date := time.Date(2024, time.February, day, 13, 11, 0, 0, time.UTC)
+			return &github.RepositoryCommit{
+				Author: &github.User{Login: github.String(login), Type: github.String("User")},
+				Commit: &github.Commit{
+					Author:    &github.CommitAuthor{Name: github.String(name), Email: github.String(email)},
+					Committer: &github.CommitAuthor{Date: &date},
+				},
+				HTMLURL: github.String("foo"),
+			}
+		}
+		repositories = repositoryhostfakes.FakeRepositories{}
+		repositories.ListCommitsReturns([]*github.RepositoryCommit{
+			commit(6, "one", "one", "one@example.com"),
+			commit(7, "jane", "Jane", "jane@example.com"),
+			commit(8, "", "Jane", "12345+jane@users.noreply.github.com"),
+			commit(9, "", "Jane", "Jane@Example.com"),
+		}, nil, nil)
+	})
+
+	It("dedups contributors by email by default", func() {
+		content, err := repositoryhost.ReadGitInfo(context.TODO(), &repositories, *resourceURl, repositoryhost.GitInfoOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		info := repositoryhost.GitInfo{}
+		Expect(json.Unmarshal(content, &info)).To(Succeed())
+		Expect(info.Contributors).To(HaveLen(3))
+	})
+
+	It("dedups contributors by normalized identity", func() {
+		content, err := repositoryhost.ReadGitInfo(context.TODO(), &repositories, *resourceURl, repositoryhost.GitInfoOptions{ContributorsByIdentity: true})
+		Expect(err).NotTo(HaveOccurred())
+		info := repositoryhost.GitInfo{}
+		Expect(json.Unmarshal(content, &info)).To(Succeed())
+		Expect(info.Author.GetLogin()).To(Equal("one"))
+		Expect(info.Contributors).To(HaveLen(1))
+		Expect(info.Contributors[0].GetEmail()).To(Equal("Jane@Example.com"))
+	})
+})
+
+var _ = Describe("#ReadGitInfo co-authors", func() {
+	var (
+		repositories repositoryhostfakes.FakeRepositories
+		resourceURl  *repositoryhost.URL
+		commits      []*github.RepositoryCommit
+	)
+
+	BeforeEach(func() {
+		var err error
+		resourceURl, err = repositoryhost.NewResourceURL("https://github.com/gardener/docforge/blob/master/README.md")
+		Expect(err).NotTo(HaveOccurred())
+		date := time.Date(2024, time.February, 6, 13, 11, 0, 0, time.UTC)
+		commits = []*github.RepositoryCommit{
+			{
+				Author: &github.User{Login: github.String("docs-bot[bot]"), Type: github.String("Bot")},
+				Commit: &github.Commit{
+					Author:    &github.CommitAuthor{Name: github.String("docs-bot[bot]"), Email: github.String("bot@example.com")},
+					Committer: &github.CommitAuthor{Date: &date},
+					Message: github.String("Update the guide\n\nCo-authored-by: Jane Doe <jane@example.com>\n" +
+						"co-authored-by: John <12345+john@users.noreply.github.com>\nCo-authored-by: renovate[bot] <renovate@example.com>\n"),
+				},
+				HTMLURL: github.String("foo"),
+			},
+		}
+	})
+
+	JustBeforeEach(func() {
+		repositories = repositoryhostfakes.FakeRepositories{}
+		repositories.ListCommitsReturns(commits, nil, nil)
+	})
+
+	It("adds the co-authors of the commit trailers to the contributors", func() {
+		content, err := repositoryhost.ReadGitInfo(context.TODO(), &repositories, *resourceURl, repositoryhost.GitInfoOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		info := repositoryhost.GitInfo{}
+		Expect(json.Unmarshal(content, &info)).To(Succeed())
+		Expect(info.Author.GetLogin()).To(Equal("docs-bot[bot]"))
+		Expect(info.Contributors).To(HaveLen(2))
+		Expect(info.Contributors[0].GetName()).To(Equal("Jane Doe"))
+		Expect(info.Contributors[0].GetEmail()).To(Equal("jane@example.com"))
+		Expect(info.Contributors[1].GetName()).To(Equal("John"))
+		Expect(info.Contributors[1].GetLogin()).To(Equal("john"))
+	})
+
+	Context("a co-author has commits", func() {
+		BeforeEach(func() {
+			date := time.Date(2024, time.February, 7, 13, 11, 0, 0, time.UTC)
+			commits = append(commits, &github.RepositoryCommit{
+				Author: &github.User{Login: github.String("john"), Type: github.String("User")},
+				Commit: &github.Commit{
+					Author:    &github.CommitAuthor{Name: github.String("John"), Email: github.String("john@example.com")},
+					Committer: &github.CommitAuthor{Date: &date},
+				},
+				HTMLURL: github.String("bar"),
+			})
+		})
+
+		It("dedups the co-authors by normalized identity", func() {
+			content, err := repositoryhost.ReadGitInfo(context.TODO(), &repositories, *resourceURl, repositoryhost.GitInfoOptions{ContributorsByIdentity: true})
+			Expect(err).NotTo(HaveOccurred())
+			info := repositoryhost.GitInfo{}
+			Expect(json.Unmarshal(content, &info)).To(Succeed())
+			Expect(info.Author.GetLogin()).To(Equal("docs-bot[bot]"))
+			Expect(info.Contributors).To(HaveLen(2))
+			Expect(info.Contributors[0].GetEmail()).To(Equal("john@example.com"))
+			Expect(info.Contributors[1].GetName()).To(Equal("Jane Doe"))
+		})
+	})
+})
+
+var _ = Describe("#ReadGitInfos", func() {
+	var (
+		server   *httptest.Server
+		requests int
+		query    string
+		response string
+		status   int
+	)
+
+	BeforeEach(func() {
+		requests = 0
+		status = http.StatusOK
+		response = `{"data": {
+			"r0": {"object": {"history": {"nodes": [
+				{"oid": "2", "url": "bar/commit/2", "message": "second", "author": {"name": "two", "email": "two@", "date": "2024-02-07T15:11:00+02:00", "user": {"login": "two"}}, "committer": {"name": "two", "email": "two@", "date": "2024-02-07T15:11:00+02:00"}},
+				{"oid": "1", "url": "foo/commit/1", "message": "first", "author": {"name": "one", "email": "one@", "date": "2024-02-06T13:11:00Z", "user": {"login": "one"}}, "committer": {"name": "one", "email": "one@", "date": "2024-02-06T13:11:00Z", "user": {"login": "one"}}}
+			]}}},
+			"r1": {"object": {"history": {"nodes": []}}},
+			"r2": {"object": null}
+		}}`
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			body := map[string]string{}
+			Expect(json.NewDecoder(r.Body).Decode(&body)).To(Succeed())
+			query = body["query"]
+			w.WriteHeader(status)
+			_, _ = w.Write([]byte(response))
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("returns the git info of all resources with a single request", func() {
+		var resources []repositoryhost.URL
+		for _, u := range []string{
+			"https://github.com/gardener/docforge/blob/master/README.md",
+			"https://github.com/gardener/docforge/blob/master/empty.md",
+			"https://github.com/gardener/docforge/blob/missing/README.md",
+		} {
+			r, err := repositoryhost.NewResourceURL(u)
+			Expect(err).NotTo(HaveOccurred())
+			resources = append(resources, *r)
+		}
+		infos, err := repositoryhost.ReadGitInfos(context.TODO(), server.Client(), server.URL, resources, repositoryhost.GitInfoOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(requests).To(Equal(1))
+		Expect(query).To(ContainSubstring(`r0: repository(owner: "gardener", name: "docforge")`))
+		Expect(query).To(ContainSubstring(`history(first: 30, path: "empty.md")`))
+		Expect(infos).To(HaveLen(2))
+		Expect(string(infos["https://github.com/gardener/docforge/blob/master/README.md"])).To(Equal("{\n  \"lastmod\": \"2024-02-07 13:11:00\",\n  \"publishdate\": \"2024-02-06 13:11:00\",\n  \"author\": {\n    \"login\": \"one\",\n    \"name\": \"one\",\n    \"email\": \"one@\",\n    \"type\": \"User\"\n  },\n  \"contributors\": [\n    {\n      \"login\": \"two\",\n      \"name\": \"two\",\n      \"email\": \"two@\",\n      \"type\": \"User\"\n    }\n  ],\n  \"weburl\": \"bar\",\n  \"shaalias\": \"master\",\n  \"path\": \"README.md\"\n}"))
+		Expect(infos).To(HaveKeyWithValue("https://github.com/gardener/docforge/blob/master/empty.md", BeNil()))
+	})
+
+	It("formats dates with the configured layout", func() {
+		r, err := repositoryhost.NewResourceURL("https://github.com/gardener/docforge/blob/master/README.md")
+		Expect(err).NotTo(HaveOccurred())
+		infos, err := repositoryhost.ReadGitInfos(context.TODO(), server.Client(), server.URL, []repositoryhost.URL{*r}, repositoryhost.GitInfoOptions{DateLayout: time.RFC3339})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(infos[r.String()])).To(ContainSubstring("\"lastmod\": \"2024-02-07T13:11:00Z\",\n  \"publishdate\": \"2024-02-06T13:11:00Z\""))
+	})
+
+	It("fails when the GraphQL endpoint is unavailable", func() {
+		status = http.StatusBadGateway
+		r, err := repositoryhost.NewResourceURL("https://github.com/gardener/docforge/blob/master/README.md")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = repositoryhost.ReadGitInfos(context.TODO(), server.Client(), server.URL, []repositoryhost.URL{*r}, repositoryhost.GitInfoOptions{})
+		Expect(err).To(MatchError(ContainSubstring("HTTP status: 502")))
+	})
+
+	It("fails on GraphQL errors without data", func() {
+		response = `{"errors": [{"message": "bad credentials"}]}`
+		r, err := repositoryhost.NewResourceURL("https://github.com/gardener/docforge/blob/master/README.md")
+		Expect(err).NotTo(HaveOccurred())
+		_, err = repositoryhost.ReadGitInfos(context.TODO(), server.Client(), server.URL, []repositoryhost.URL{*r}, repositoryhost.GitInfoOptions{})
+		Expect(err).To(MatchError(ContainSubstring("bad credentials")))
+	})
+
+	It("returns the GraphQL endpoint of a host", func() {
+		Expect(repositoryhost.GraphQLEndpoint("github.com")).To(Equal("https://api.github.com/graphql"))
+		Expect(repositoryhost.GraphQLEndpoint("github.tools.sap")).To(Equal("https://github.tools.sap/api/graphql"))
+	})
 })
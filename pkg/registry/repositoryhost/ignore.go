@@ -0,0 +1,162 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package repositoryhost
+
+import (
+	"bufio"
+	"bytes"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// IgnoreFile is the name of the files listing repository paths excluded from trees in gitignore syntax
+const IgnoreFile = ".docforgeignore"
+
+// ignoreRule is a single pattern of an ignore file
+type ignoreRule struct {
+	// base is the repository path of the directory containing the ignore file
+	base    string
+	pattern *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// ignoreRules are the rules of the ignore files in a repository ordered from the shallowest to the deepest file
+type ignoreRules []ignoreRule
+
+// parseIgnoreFile parses the content of an ignore file located at the repository path ignoreFilePath
+func parseIgnoreFile(ignoreFilePath string, content []byte) ignoreRules {
+	base := path.Dir(ignoreFilePath)
+	if base == "." {
+		base = ""
+	}
+	rules := ignoreRules{}
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		rule := ignoreRule{base: base}
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		}
+		line = strings.TrimPrefix(line, "\\")
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimSuffix(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+		// patterns without a slash match at any level below the ignore file
+		anchored := strings.Contains(line, "/")
+		line = strings.TrimPrefix(line, "/")
+		expr := globToRegexp(line)
+		if !anchored {
+			expr = "(?:.*/)?" + expr
+		}
+		pattern, err := regexp.Compile("^" + expr + "$")
+		if err != nil {
+			continue
+		}
+		rule.pattern = pattern
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// globToRegexp converts a gitignore glob to a regular expression
+func globToRegexp(glob string) string {
+	var b strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		case c == '[':
+			if end := strings.IndexByte(glob[i:], ']'); end > 0 {
+				class := glob[i+1 : i+end]
+				if strings.HasPrefix(class, "!") {
+					class = "^" + class[1:]
+				}
+				b.WriteString("[" + class + "]")
+				i += end
+				continue
+			}
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return b.String()
+}
+
+// merge adds the rules of another ignore file keeping deeper files last
+func (r ignoreRules) merge(other ignoreRules) ignoreRules {
+	merged := append(append(ignoreRules{}, r...), other...)
+	sort.SliceStable(merged, func(i, j int) bool {
+		return depth(merged[i].base) < depth(merged[j].base)
+	})
+	return merged
+}
+
+// depth returns the number of directories in a repository path
+func depth(dir string) int {
+	if dir == "" {
+		return 0
+	}
+	return strings.Count(dir, "/") + 1
+}
+
+// ignored checks if a file with the given repository path is excluded by the rules.
+// A file is excluded if it or any of its parent directories is matched, last matching rule wins
+func (r ignoreRules) ignored(filePath string) bool {
+	if path.Base(filePath) == IgnoreFile {
+		return true
+	}
+	if len(r) == 0 {
+		return false
+	}
+	segments := strings.Split(filePath, "/")
+	for i := 1; i < len(segments); i++ {
+		if r.match(strings.Join(segments[:i], "/"), true) {
+			return true
+		}
+	}
+	return r.match(filePath, false)
+}
+
+// match returns the outcome of the last rule matching the repository path
+func (r ignoreRules) match(p string, isDir bool) bool {
+	ignored := false
+	for _, rule := range r {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		rel := p
+		if rule.base != "" {
+			if !strings.HasPrefix(p, rule.base+"/") {
+				continue
+			}
+			rel = strings.TrimPrefix(p, rule.base+"/")
+		}
+		if rule.pattern.MatchString(rel) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
@@ -0,0 +1,29 @@
+package repositoryhost_test
+
+// SPDX-FileCopyrightText: 2020 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+import (
+	"github.com/gardener/docforge/pkg/registry/repositoryhost"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Ignore files", func() {
+	DescribeTable("matching repository paths", func(ignoreFilePath string, content string, filePath string, expected bool) {
+		Expect(repositoryhost.IgnoredBy(ignoreFilePath, content, filePath)).To(Equal(expected))
+	},
+		Entry("name at any level", ".docforgeignore", "draft.md", "docs/draft.md", true),
+		Entry("glob", ".docforgeignore", "*.png", "docs/images/logo.png", true),
+		Entry("anchored to the ignore file", "docs/.docforgeignore", "/index.md", "docs/section/index.md", false),
+		Entry("directory excludes its files", "docs/.docforgeignore", "section/", "docs/section/page.md", true),
+		Entry("directory only pattern does not match files", ".docforgeignore", "section/", "section", false),
+		Entry("double star", ".docforgeignore", "docs/**/internal.md", "docs/a/b/internal.md", true),
+		Entry("negation", ".docforgeignore", "*.md\n!README.md", "README.md", false),
+		Entry("comment", ".docforgeignore", "# draft.md", "draft.md", false),
+		Entry("outside the ignore file directory", "docs/.docforgeignore", "*.md", "README.md", false),
+		Entry("ignore file itself", "docs/.docforgeignore", "", "docs/.docforgeignore", true),
+	)
+})
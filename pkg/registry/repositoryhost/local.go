@@ -7,7 +7,10 @@ import (
 	"fmt"
 	"io/fs"
 	ospkg "os"
+	"os/exec"
+	"path"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"time"
 
@@ -47,6 +50,32 @@ func NewLocal(os osshim.Os, urlPrefix string, localPath string) Interface {
 	return &Local{os, urlPrefix, localPath}
 }
 
+// NewLocalRepository creates a repository host reading the repository identified by the tree URL of its
+// root e.g. https://github.com/gardener/docforge/tree/master from the local directory, without network access
+func NewLocalRepository(os osshim.Os, repositoryURL string, localPath string) (Interface, error) {
+	resource, err := new(strings.TrimSuffix(repositoryURL, "/") + "/")
+	if err != nil || resource.GetResourceType() != "tree" || resource.GetResourcePath() != "" {
+		return nil, fmt.Errorf("local repository URL %s is not the tree URL of a repository root", repositoryURL)
+	}
+	isDir, err := os.IsDir(localPath)
+	if err != nil {
+		return nil, fmt.Errorf("local repository %s can't be read: %w", localPath, err)
+	}
+	if !isDir {
+		return nil, fmt.Errorf("local repository %s is not a directory", localPath)
+	}
+	return &Local{os, fmt.Sprintf("https://%s/%s/%s", resource.GetHost(), resource.GetOwner(), resource.GetRepo()), localPath}, nil
+}
+
+// LocalRepositoryFile returns the blob URL of a file in a local repository by its path relative to the repository root
+func LocalRepositoryFile(repositoryURL string, file string) (string, error) {
+	resource, err := new(strings.TrimSuffix(repositoryURL, "/") + "/")
+	if err != nil || resource.GetResourceType() != "tree" {
+		return "", fmt.Errorf("local repository URL %s is not the tree URL of a repository root", repositoryURL)
+	}
+	return fmt.Sprintf("https://%s/%s/%s/blob/%s/%s", resource.GetHost(), resource.GetOwner(), resource.GetRepo(), resource.GetRef(), path.Clean(filepath.ToSlash(file))), nil
+}
+
 // ResourceURL returns a valid resource url object from a string url
 func (l *Local) ResourceURL(resourceURL string) (*URL, error) {
 	resource, err := new(resourceURL)
@@ -96,12 +125,116 @@ func (l *Local) Tree(resource URL) ([]string, error) {
 	dirPath := filepath.Join(l.localPath, resource.GetResourcePath())
 	files := []string{}
 	err := filepath.Walk(dirPath, func(path string, info fs.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
 		if !info.IsDir() {
 			files = append(files, strings.TrimPrefix(strings.TrimPrefix(path, dirPath), "/"))
 		}
 		return nil
 	})
-	return files, err
+	if err != nil {
+		return files, err
+	}
+	ignores, err := l.ignoreRules(resource.GetResourcePath(), files)
+	if err != nil {
+		return files, err
+	}
+	out := []string{}
+	for _, file := range files {
+		if !ignores.ignored(path.Join(resource.GetResourcePath(), filepath.ToSlash(file))) {
+			out = append(out, file)
+		}
+	}
+	return out, nil
+}
+
+// ChangedFiles runs git in the local directory to list the files changed since the base ref,
+// including uncommitted and untracked files
+func (l *Local) ChangedFiles(ctx context.Context, _ URL, base string) ([]string, error) {
+	if _, err := l.git(ctx, "rev-parse", "--git-dir"); err != nil {
+		return nil, err
+	}
+	if _, err := l.git(ctx, "rev-parse", "--verify", "--quiet", base+"^{commit}"); err != nil {
+		return nil, ErrResourceNotFound(fmt.Sprintf("base %s of %s", base, l.localPath))
+	}
+	changed, err := l.git(ctx, "diff", "-z", "--name-only", "--relative", base, "--")
+	if err != nil {
+		return nil, err
+	}
+	untracked, err := l.git(ctx, "ls-files", "-z", "--others", "--exclude-standard")
+	if err != nil {
+		return nil, err
+	}
+	return append(changed, untracked...), nil
+}
+
+// ListRefs returns the branches, followed by the tags, of the local git repository whose names match the pattern
+func (l *Local) ListRefs(ctx context.Context, _ string, pattern string) ([]string, error) {
+	matcher, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid refs pattern %s: %w", pattern, err)
+	}
+	entries, err := l.git(ctx, "for-each-ref", "--format=%(refname:short)%00", "refs/heads", "refs/tags")
+	if err != nil {
+		return nil, err
+	}
+	refs := []string{}
+	for _, entry := range entries {
+		if ref := strings.TrimSpace(entry); ref != "" && matcher.MatchString(ref) {
+			refs = append(refs, ref)
+		}
+	}
+	return refs, nil
+}
+
+// git runs a git command in the local directory and returns the NUL separated entries of its output
+func (l *Local) git(ctx context.Context, args ...string) ([]string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = l.localPath
+	out, err := cmd.Output()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil, fmt.Errorf("git %s in %s failed: %s", args[0], l.localPath, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return nil, fmt.Errorf("git %s in %s failed: %w", args[0], l.localPath, err)
+	}
+	entries := []string{}
+	for _, entry := range strings.Split(string(out), "\x00") {
+		if entry != "" {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// ignoreRules reads the ignore files in the tree directory, its subdirectories and its ancestors
+func (l *Local) ignoreRules(treePath string, files []string) (ignoreRules, error) {
+	ignoreFiles := []string{}
+	if treePath != "" {
+		for dir := path.Dir(treePath); dir != "." && dir != "/"; dir = path.Dir(dir) {
+			ignoreFiles = append(ignoreFiles, path.Join(dir, IgnoreFile))
+		}
+		ignoreFiles = append(ignoreFiles, IgnoreFile)
+	}
+	for _, file := range files {
+		if filepath.Base(file) == IgnoreFile {
+			ignoreFiles = append(ignoreFiles, path.Join(treePath, filepath.ToSlash(file)))
+		}
+	}
+	rules := ignoreRules{}
+	for _, ignoreFile := range ignoreFiles {
+		cnt, err := l.os.ReadFile(filepath.Join(l.localPath, ignoreFile))
+		if err != nil {
+			if l.os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("reading %s failed: %w", ignoreFile, err)
+		}
+		rules = rules.merge(parseIgnoreFile(ignoreFile, cnt))
+	}
+	return rules, nil
 }
 
 // Accept if the link has the same url prefix as defined
@@ -140,7 +273,17 @@ func (l *Local) GetClient() httpclient.Client {
 	return nil
 }
 
+// GetEditLink returns the edit link of the remote source mapped by the local repository
+func (l *Local) GetEditLink(source URL) (string, error) {
+	return source.EditURL()
+}
+
+// GitInfoOptions returns the default options
+func (l *Local) GitInfoOptions() GitInfoOptions {
+	return GitInfoOptions{DateLayout: DateFormat}
+}
+
 // GetRateLimit is not implemented
 func (l *Local) GetRateLimit(ctx context.Context) (int, int, time.Time, error) {
-	return 0, 0, time.Time{}, errors.New("not implemented")
+	return 0, 0, time.Time{}, ErrNotImplemented
 }
@@ -5,16 +5,117 @@ package repositoryhost_test
 // SPDX-License-Identifier: Apache-2.0
 
 import (
+	"context"
 	"embed"
 	_ "embed"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
 
+	"github.com/gardener/docforge/pkg/osfakes/osshim"
 	"github.com/gardener/docforge/pkg/registry/repositoryhost"
 	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
 )
 
-//go:embed internal/local_test/*
+//go:embed all:internal/local_test
 var repo embed.FS
 
 var _ = Describe("Local cache test", func() {
 	testRepositoryHost(repositoryhost.NewLocalTest(repo, "https://github.com/gardener/docforge", "internal/local_test"))
 })
+
+var _ = Describe("Local repository test", func() {
+	local, err := repositoryhost.NewLocalRepository(&osshim.OsShim{}, "https://github.com/gardener/docforge/tree/master", "internal/local_test")
+	It("creates the local repository", func() {
+		Expect(err).NotTo(HaveOccurred())
+	})
+	if err == nil {
+		testRepositoryHost(local)
+	}
+
+	Describe("#NewLocalRepository", func() {
+		It("accepts only the resources of the repository", func() {
+			Expect(local.Accept("https://github.com/gardener/docforge/blob/v1.0.0/README.md")).To(BeTrue())
+			Expect(local.Accept("https://github.com/gardener/other/blob/master/README.md")).To(BeFalse())
+		})
+
+		It("fails for a URL that isn't a repository root tree", func() {
+			_, err := repositoryhost.NewLocalRepository(&osshim.OsShim{}, "https://github.com/gardener/docforge/tree/master/docs", "internal/local_test")
+			Expect(err).To(MatchError("local repository URL https://github.com/gardener/docforge/tree/master/docs is not the tree URL of a repository root"))
+			_, err = repositoryhost.NewLocalRepository(&osshim.OsShim{}, "https://example.com/docs", "internal/local_test")
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("fails for a missing directory", func() {
+			_, err := repositoryhost.NewLocalRepository(&osshim.OsShim{}, "https://github.com/gardener/docforge/tree/master", "internal/local_test/missing")
+			Expect(err).To(MatchError(ContainSubstring("can't be read")))
+		})
+
+		It("fails for a file", func() {
+			_, err := repositoryhost.NewLocalRepository(&osshim.OsShim{}, "https://github.com/gardener/docforge/tree/master", "internal/local_test/README.md")
+			Expect(err).To(MatchError("local repository internal/local_test/README.md is not a directory"))
+		})
+	})
+
+	Describe("#LocalRepositoryFile", func() {
+		It("returns the blob URL of the file", func() {
+			Expect(repositoryhost.LocalRepositoryFile("https://github.com/gardener/docforge/tree/main/", "./docs/../manifest.yaml")).To(Equal("https://github.com/gardener/docforge/blob/main/manifest.yaml"))
+		})
+	})
+	Describe("#ChangedFiles", func() {
+		var dir string
+
+		git := func(args ...string) {
+			cmd := exec.Command("git", append([]string{"-c", "user.name=docforge", "-c", "user.email=docforge@example.com"}, args...)...)
+			cmd.Dir = dir
+			out, err := cmd.CombinedOutput()
+			Expect(err).NotTo(HaveOccurred(), string(out))
+		}
+		write := func(name string, content string) {
+			Expect(os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(dir, name), []byte(content), 0644)).To(Succeed())
+		}
+
+		BeforeEach(func() {
+			var err error
+			dir, err = os.MkdirTemp("", "local")
+			Expect(err).NotTo(HaveOccurred())
+			git("init", "--quiet")
+			write("README.md", "# Readme\n")
+			write("docs/intro.md", "# Intro\n")
+			write("docs/setup.md", "# Setup\n")
+			git("add", "-A")
+			git("commit", "--quiet", "-m", "base")
+			git("tag", "base")
+		})
+
+		AfterEach(func() {
+			Expect(os.RemoveAll(dir)).To(Succeed())
+		})
+
+		It("lists the committed, uncommitted and untracked changes since the base ref", func() {
+			write("docs/intro.md", "# Introduction\n")
+			git("commit", "--quiet", "-am", "change")
+			write("README.md", "# Changed\n")
+			write("docs/new page.md", "# New\n")
+			changing, err := repositoryhost.NewLocalRepository(&osshim.OsShim{}, "https://github.com/gardener/docforge/tree/master", dir)
+			Expect(err).NotTo(HaveOccurred())
+			resourceURL, err := changing.ResourceURL("https://github.com/gardener/docforge/blob/master/README.md")
+			Expect(err).NotTo(HaveOccurred())
+			files, err := changing.ChangedFiles(context.TODO(), *resourceURL, "base")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(files).To(ConsistOf("README.md", "docs/intro.md", "docs/new page.md"))
+		})
+
+		It("fails with resource not found for an unknown base ref", func() {
+			changing, err := repositoryhost.NewLocalRepository(&osshim.OsShim{}, "https://github.com/gardener/docforge/tree/master", dir)
+			Expect(err).NotTo(HaveOccurred())
+			resourceURL, err := changing.ResourceURL("https://github.com/gardener/docforge/blob/master/README.md")
+			Expect(err).NotTo(HaveOccurred())
+			_, err = changing.ChangedFiles(context.TODO(), *resourceURL, "missing")
+			Expect(errors.As(err, new(repositoryhost.ErrResourceNotFound))).To(BeTrue())
+		})
+	})
+})
@@ -8,7 +8,9 @@ package repositoryhost
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"time"
 
 	"github.com/gardener/docforge/pkg/osfakes/httpclient"
@@ -22,6 +24,29 @@ func (e ErrResourceNotFound) Error() string {
 	return fmt.Sprintf("resource %q not found", string(e))
 }
 
+// ErrNotImplemented is returned by the operations a repository host doesn't support
+var ErrNotImplemented = errors.New("not implemented")
+
+// ErrTransient indicates a failure that may succeed when retried, e.g. a timeout or a 5xx HTTP status
+type ErrTransient struct {
+	Err error
+}
+
+// Error returns the underlying error message
+func (e ErrTransient) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e ErrTransient) Unwrap() error {
+	return e.Err
+}
+
+// IsTransientStatus checks if an HTTP status code indicates a transient failure
+func IsTransientStatus(statusCode int) bool {
+	return statusCode == http.StatusTooManyRequests || statusCode >= http.StatusInternalServerError
+}
+
 // Interface does resource specific operations on a type of objects
 // identified by an uri schema that it accepts to handle
 //
@@ -35,6 +60,10 @@ type Interface interface {
 	LoadRepository(ctx context.Context, resourceURL string) error
 	// Tree returns files that are present in the given url tree
 	Tree(resource URL) ([]string, error)
+	// ChangedFiles returns the paths, relative to the repository root, of the files changed between the base ref and the ref of the resource
+	ChangedFiles(ctx context.Context, resource URL, base string) ([]string, error)
+	// ListRefs returns the branches and tags of the repository of the URL whose names match the regular expression pattern
+	ListRefs(ctx context.Context, repoURL string, pattern string) ([]string, error)
 	// Accept accepts manifests if this RepositoryHost can manage the type of resources identified by the URI scheme of uri.
 	Accept(link string) bool
 	// Read a resource content at uri into a byte array
@@ -45,6 +74,10 @@ type Interface interface {
 	Repositories() Repositories
 	// GetClient returns an HTTP client for accessing handler's resources
 	GetClient() httpclient.Client
+	// GetEditLink returns the link for editing the given source resource
+	GetEditLink(source URL) (string, error)
+	// GitInfoOptions returns the options for reading the git info of resources
+	GitInfoOptions() GitInfoOptions
 	// GetRateLimit returns rate limit and remaining API calls for the resource handler backend (e.g. GitHub RateLimit)
 	// returns negative values if RateLimit is not applicable
 	GetRateLimit(ctx context.Context) (int, int, time.Time, error)
@@ -56,6 +89,13 @@ type InitOptions struct {
 	Credentials      map[string]string `mapstructure:"github-oauth-token-map"`
 	ResourceMappings map[string]string `mapstructure:"resourceMappings"`
 	Hugo             bool              `mapstructure:"hugo"`
+	GitInfoOptions   `mapstructure:",squash"`
+	// Headers are set on the requests to the repository hosts
+	httpclient.Headers `mapstructure:",squash"`
+
+	// LocalRepository is a local directory read as the repository identified by LocalRepositoryURL
+	LocalRepository    string `mapstructure:"local-repository"`
+	LocalRepositoryURL string `mapstructure:"local-repository-url"`
 }
 
 // Credential holds repository credential data
@@ -38,6 +38,22 @@ func testRepositoryHost(ghc repositoryhost.Interface) {
 			Expect(err).NotTo(HaveOccurred())
 
 		})
+		It("should exclude files matched by ignore files", func() {
+			resourceURl, err := ghc.ResourceURL("https://github.com/gardener/docforge/tree/master/docs")
+			Expect(err).NotTo(HaveOccurred())
+			tree, err := ghc.Tree(*resourceURl)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tree).To(ContainElements("index.md", "section/page.md", "section/wip/keep.md"))
+			Expect(tree).NotTo(ContainElements("drafts/draft.md", "section/wip/page.md", ".docforgeignore"))
+		})
+		It("should exclude files matched by ignore files of parent directories", func() {
+			resourceURl, err := ghc.ResourceURL("https://github.com/gardener/docforge/tree/master/docs/section")
+			Expect(err).NotTo(HaveOccurred())
+			tree, err := ghc.Tree(*resourceURl)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tree).To(ContainElements("page.md", "wip/keep.md"))
+			Expect(tree).NotTo(ContainElement("wip/page.md"))
+		})
 	})
 
 	Describe("#ResolveRelativeLink", func() {
@@ -93,4 +109,21 @@ func testRepositoryHost(ghc repositoryhost.Interface) {
 			})
 		})
 	})
+
+	Describe("#GetEditLink", func() {
+		It("returns the edit link of a blob", func() {
+			resourceURl, err := ghc.ResourceURL("https://github.com/gardener/docforge/blob/master/docs/section/page.md")
+			Expect(err).NotTo(HaveOccurred())
+			link, err := ghc.GetEditLink(*resourceURl)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(link).To(Equal("https://github.com/gardener/docforge/edit/master/docs/section/page.md"))
+		})
+
+		It("fails for a tree", func() {
+			resourceURl, err := ghc.ResourceURL("https://github.com/gardener/docforge/tree/master/docs")
+			Expect(err).NotTo(HaveOccurred())
+			_, err = ghc.GetEditLink(*resourceURl)
+			Expect(err).To(MatchError("expected a blob url got https://github.com/gardener/docforge/tree/master/docs"))
+		})
+	})
 }
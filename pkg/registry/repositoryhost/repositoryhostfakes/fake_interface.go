@@ -25,6 +25,21 @@ type FakeInterface struct {
 	acceptReturnsOnCall map[int]struct {
 		result1 bool
 	}
+	ChangedFilesStub        func(context.Context, repositoryhost.URL, string) ([]string, error)
+	changedFilesMutex       sync.RWMutex
+	changedFilesArgsForCall []struct {
+		arg1 context.Context
+		arg2 repositoryhost.URL
+		arg3 string
+	}
+	changedFilesReturns struct {
+		result1 []string
+		result2 error
+	}
+	changedFilesReturnsOnCall map[int]struct {
+		result1 []string
+		result2 error
+	}
 	GetClientStub        func() httpclient.Client
 	getClientMutex       sync.RWMutex
 	getClientArgsForCall []struct {
@@ -35,6 +50,19 @@ type FakeInterface struct {
 	getClientReturnsOnCall map[int]struct {
 		result1 httpclient.Client
 	}
+	GetEditLinkStub        func(repositoryhost.URL) (string, error)
+	getEditLinkMutex       sync.RWMutex
+	getEditLinkArgsForCall []struct {
+		arg1 repositoryhost.URL
+	}
+	getEditLinkReturns struct {
+		result1 string
+		result2 error
+	}
+	getEditLinkReturnsOnCall map[int]struct {
+		result1 string
+		result2 error
+	}
 	GetRateLimitStub        func(context.Context) (int, int, time.Time, error)
 	getRateLimitMutex       sync.RWMutex
 	getRateLimitArgsForCall []struct {
@@ -52,6 +80,31 @@ type FakeInterface struct {
 		result3 time.Time
 		result4 error
 	}
+	GitInfoOptionsStub        func() repositoryhost.GitInfoOptions
+	gitInfoOptionsMutex       sync.RWMutex
+	gitInfoOptionsArgsForCall []struct {
+	}
+	gitInfoOptionsReturns struct {
+		result1 repositoryhost.GitInfoOptions
+	}
+	gitInfoOptionsReturnsOnCall map[int]struct {
+		result1 repositoryhost.GitInfoOptions
+	}
+	ListRefsStub        func(context.Context, string, string) ([]string, error)
+	listRefsMutex       sync.RWMutex
+	listRefsArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+	}
+	listRefsReturns struct {
+		result1 []string
+		result2 error
+	}
+	listRefsReturnsOnCall map[int]struct {
+		result1 []string
+		result2 error
+	}
 	LoadRepositoryStub        func(context.Context, string) error
 	loadRepositoryMutex       sync.RWMutex
 	loadRepositoryArgsForCall []struct {
@@ -203,6 +256,72 @@ func (fake *FakeInterface) AcceptReturnsOnCall(i int, result1 bool) {
 	}{result1}
 }
 
+func (fake *FakeInterface) ChangedFiles(arg1 context.Context, arg2 repositoryhost.URL, arg3 string) ([]string, error) {
+	fake.changedFilesMutex.Lock()
+	ret, specificReturn := fake.changedFilesReturnsOnCall[len(fake.changedFilesArgsForCall)]
+	fake.changedFilesArgsForCall = append(fake.changedFilesArgsForCall, struct {
+		arg1 context.Context
+		arg2 repositoryhost.URL
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.ChangedFilesStub
+	fakeReturns := fake.changedFilesReturns
+	fake.recordInvocation("ChangedFiles", []interface{}{arg1, arg2, arg3})
+	fake.changedFilesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeInterface) ChangedFilesCallCount() int {
+	fake.changedFilesMutex.RLock()
+	defer fake.changedFilesMutex.RUnlock()
+	return len(fake.changedFilesArgsForCall)
+}
+
+func (fake *FakeInterface) ChangedFilesCalls(stub func(context.Context, repositoryhost.URL, string) ([]string, error)) {
+	fake.changedFilesMutex.Lock()
+	defer fake.changedFilesMutex.Unlock()
+	fake.ChangedFilesStub = stub
+}
+
+func (fake *FakeInterface) ChangedFilesArgsForCall(i int) (context.Context, repositoryhost.URL, string) {
+	fake.changedFilesMutex.RLock()
+	defer fake.changedFilesMutex.RUnlock()
+	argsForCall := fake.changedFilesArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeInterface) ChangedFilesReturns(result1 []string, result2 error) {
+	fake.changedFilesMutex.Lock()
+	defer fake.changedFilesMutex.Unlock()
+	fake.ChangedFilesStub = nil
+	fake.changedFilesReturns = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeInterface) ChangedFilesReturnsOnCall(i int, result1 []string, result2 error) {
+	fake.changedFilesMutex.Lock()
+	defer fake.changedFilesMutex.Unlock()
+	fake.ChangedFilesStub = nil
+	if fake.changedFilesReturnsOnCall == nil {
+		fake.changedFilesReturnsOnCall = make(map[int]struct {
+			result1 []string
+			result2 error
+		})
+	}
+	fake.changedFilesReturnsOnCall[i] = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeInterface) GetClient() httpclient.Client {
 	fake.getClientMutex.Lock()
 	ret, specificReturn := fake.getClientReturnsOnCall[len(fake.getClientArgsForCall)]
@@ -256,6 +375,70 @@ func (fake *FakeInterface) GetClientReturnsOnCall(i int, result1 httpclient.Clie
 	}{result1}
 }
 
+func (fake *FakeInterface) GetEditLink(arg1 repositoryhost.URL) (string, error) {
+	fake.getEditLinkMutex.Lock()
+	ret, specificReturn := fake.getEditLinkReturnsOnCall[len(fake.getEditLinkArgsForCall)]
+	fake.getEditLinkArgsForCall = append(fake.getEditLinkArgsForCall, struct {
+		arg1 repositoryhost.URL
+	}{arg1})
+	stub := fake.GetEditLinkStub
+	fakeReturns := fake.getEditLinkReturns
+	fake.recordInvocation("GetEditLink", []interface{}{arg1})
+	fake.getEditLinkMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeInterface) GetEditLinkCallCount() int {
+	fake.getEditLinkMutex.RLock()
+	defer fake.getEditLinkMutex.RUnlock()
+	return len(fake.getEditLinkArgsForCall)
+}
+
+func (fake *FakeInterface) GetEditLinkCalls(stub func(repositoryhost.URL) (string, error)) {
+	fake.getEditLinkMutex.Lock()
+	defer fake.getEditLinkMutex.Unlock()
+	fake.GetEditLinkStub = stub
+}
+
+func (fake *FakeInterface) GetEditLinkArgsForCall(i int) repositoryhost.URL {
+	fake.getEditLinkMutex.RLock()
+	defer fake.getEditLinkMutex.RUnlock()
+	argsForCall := fake.getEditLinkArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeInterface) GetEditLinkReturns(result1 string, result2 error) {
+	fake.getEditLinkMutex.Lock()
+	defer fake.getEditLinkMutex.Unlock()
+	fake.GetEditLinkStub = nil
+	fake.getEditLinkReturns = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeInterface) GetEditLinkReturnsOnCall(i int, result1 string, result2 error) {
+	fake.getEditLinkMutex.Lock()
+	defer fake.getEditLinkMutex.Unlock()
+	fake.GetEditLinkStub = nil
+	if fake.getEditLinkReturnsOnCall == nil {
+		fake.getEditLinkReturnsOnCall = make(map[int]struct {
+			result1 string
+			result2 error
+		})
+	}
+	fake.getEditLinkReturnsOnCall[i] = struct {
+		result1 string
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeInterface) GetRateLimit(arg1 context.Context) (int, int, time.Time, error) {
 	fake.getRateLimitMutex.Lock()
 	ret, specificReturn := fake.getRateLimitReturnsOnCall[len(fake.getRateLimitArgsForCall)]
@@ -326,6 +509,125 @@ func (fake *FakeInterface) GetRateLimitReturnsOnCall(i int, result1 int, result2
 	}{result1, result2, result3, result4}
 }
 
+func (fake *FakeInterface) GitInfoOptions() repositoryhost.GitInfoOptions {
+	fake.gitInfoOptionsMutex.Lock()
+	ret, specificReturn := fake.gitInfoOptionsReturnsOnCall[len(fake.gitInfoOptionsArgsForCall)]
+	fake.gitInfoOptionsArgsForCall = append(fake.gitInfoOptionsArgsForCall, struct {
+	}{})
+	stub := fake.GitInfoOptionsStub
+	fakeReturns := fake.gitInfoOptionsReturns
+	fake.recordInvocation("GitInfoOptions", []interface{}{})
+	fake.gitInfoOptionsMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeInterface) GitInfoOptionsCallCount() int {
+	fake.gitInfoOptionsMutex.RLock()
+	defer fake.gitInfoOptionsMutex.RUnlock()
+	return len(fake.gitInfoOptionsArgsForCall)
+}
+
+func (fake *FakeInterface) GitInfoOptionsCalls(stub func() repositoryhost.GitInfoOptions) {
+	fake.gitInfoOptionsMutex.Lock()
+	defer fake.gitInfoOptionsMutex.Unlock()
+	fake.GitInfoOptionsStub = stub
+}
+
+func (fake *FakeInterface) GitInfoOptionsReturns(result1 repositoryhost.GitInfoOptions) {
+	fake.gitInfoOptionsMutex.Lock()
+	defer fake.gitInfoOptionsMutex.Unlock()
+	fake.GitInfoOptionsStub = nil
+	fake.gitInfoOptionsReturns = struct {
+		result1 repositoryhost.GitInfoOptions
+	}{result1}
+}
+
+func (fake *FakeInterface) GitInfoOptionsReturnsOnCall(i int, result1 repositoryhost.GitInfoOptions) {
+	fake.gitInfoOptionsMutex.Lock()
+	defer fake.gitInfoOptionsMutex.Unlock()
+	fake.GitInfoOptionsStub = nil
+	if fake.gitInfoOptionsReturnsOnCall == nil {
+		fake.gitInfoOptionsReturnsOnCall = make(map[int]struct {
+			result1 repositoryhost.GitInfoOptions
+		})
+	}
+	fake.gitInfoOptionsReturnsOnCall[i] = struct {
+		result1 repositoryhost.GitInfoOptions
+	}{result1}
+}
+
+func (fake *FakeInterface) ListRefs(arg1 context.Context, arg2 string, arg3 string) ([]string, error) {
+	fake.listRefsMutex.Lock()
+	ret, specificReturn := fake.listRefsReturnsOnCall[len(fake.listRefsArgsForCall)]
+	fake.listRefsArgsForCall = append(fake.listRefsArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.ListRefsStub
+	fakeReturns := fake.listRefsReturns
+	fake.recordInvocation("ListRefs", []interface{}{arg1, arg2, arg3})
+	fake.listRefsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeInterface) ListRefsCallCount() int {
+	fake.listRefsMutex.RLock()
+	defer fake.listRefsMutex.RUnlock()
+	return len(fake.listRefsArgsForCall)
+}
+
+func (fake *FakeInterface) ListRefsCalls(stub func(context.Context, string, string) ([]string, error)) {
+	fake.listRefsMutex.Lock()
+	defer fake.listRefsMutex.Unlock()
+	fake.ListRefsStub = stub
+}
+
+func (fake *FakeInterface) ListRefsArgsForCall(i int) (context.Context, string, string) {
+	fake.listRefsMutex.RLock()
+	defer fake.listRefsMutex.RUnlock()
+	argsForCall := fake.listRefsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeInterface) ListRefsReturns(result1 []string, result2 error) {
+	fake.listRefsMutex.Lock()
+	defer fake.listRefsMutex.Unlock()
+	fake.ListRefsStub = nil
+	fake.listRefsReturns = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeInterface) ListRefsReturnsOnCall(i int, result1 []string, result2 error) {
+	fake.listRefsMutex.Lock()
+	defer fake.listRefsMutex.Unlock()
+	fake.ListRefsStub = nil
+	if fake.listRefsReturnsOnCall == nil {
+		fake.listRefsReturnsOnCall = make(map[int]struct {
+			result1 []string
+			result2 error
+		})
+	}
+	fake.listRefsReturnsOnCall[i] = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeInterface) LoadRepository(arg1 context.Context, arg2 string) error {
 	fake.loadRepositoryMutex.Lock()
 	ret, specificReturn := fake.loadRepositoryReturnsOnCall[len(fake.loadRepositoryArgsForCall)]
@@ -757,10 +1059,18 @@ func (fake *FakeInterface) Invocations() map[string][][]interface{} {
 	defer fake.invocationsMutex.RUnlock()
 	fake.acceptMutex.RLock()
 	defer fake.acceptMutex.RUnlock()
+	fake.changedFilesMutex.RLock()
+	defer fake.changedFilesMutex.RUnlock()
 	fake.getClientMutex.RLock()
 	defer fake.getClientMutex.RUnlock()
+	fake.getEditLinkMutex.RLock()
+	defer fake.getEditLinkMutex.RUnlock()
 	fake.getRateLimitMutex.RLock()
 	defer fake.getRateLimitMutex.RUnlock()
+	fake.gitInfoOptionsMutex.RLock()
+	defer fake.gitInfoOptionsMutex.RUnlock()
+	fake.listRefsMutex.RLock()
+	defer fake.listRefsMutex.RUnlock()
 	fake.loadRepositoryMutex.RLock()
 	defer fake.loadRepositoryMutex.RUnlock()
 	fake.nameMutex.RLock()
@@ -13,6 +13,26 @@ import (
 )
 
 type FakeRepositories struct {
+	CompareCommitsStub        func(context.Context, string, string, string, string, *github.ListOptions) (*github.CommitsComparison, *github.Response, error)
+	compareCommitsMutex       sync.RWMutex
+	compareCommitsArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+		arg4 string
+		arg5 string
+		arg6 *github.ListOptions
+	}
+	compareCommitsReturns struct {
+		result1 *github.CommitsComparison
+		result2 *github.Response
+		result3 error
+	}
+	compareCommitsReturnsOnCall map[int]struct {
+		result1 *github.CommitsComparison
+		result2 *github.Response
+		result3 error
+	}
 	GetStub        func(context.Context, string, string) (*github.Repository, *github.Response, error)
 	getMutex       sync.RWMutex
 	getArgsForCall []struct {
@@ -30,6 +50,24 @@ type FakeRepositories struct {
 		result2 *github.Response
 		result3 error
 	}
+	ListBranchesStub        func(context.Context, string, string, *github.BranchListOptions) ([]*github.Branch, *github.Response, error)
+	listBranchesMutex       sync.RWMutex
+	listBranchesArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+		arg4 *github.BranchListOptions
+	}
+	listBranchesReturns struct {
+		result1 []*github.Branch
+		result2 *github.Response
+		result3 error
+	}
+	listBranchesReturnsOnCall map[int]struct {
+		result1 []*github.Branch
+		result2 *github.Response
+		result3 error
+	}
 	ListCommitsStub        func(context.Context, string, string, *github.CommitsListOptions) ([]*github.RepositoryCommit, *github.Response, error)
 	listCommitsMutex       sync.RWMutex
 	listCommitsArgsForCall []struct {
@@ -48,10 +86,100 @@ type FakeRepositories struct {
 		result2 *github.Response
 		result3 error
 	}
+	ListTagsStub        func(context.Context, string, string, *github.ListOptions) ([]*github.RepositoryTag, *github.Response, error)
+	listTagsMutex       sync.RWMutex
+	listTagsArgsForCall []struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+		arg4 *github.ListOptions
+	}
+	listTagsReturns struct {
+		result1 []*github.RepositoryTag
+		result2 *github.Response
+		result3 error
+	}
+	listTagsReturnsOnCall map[int]struct {
+		result1 []*github.RepositoryTag
+		result2 *github.Response
+		result3 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
+func (fake *FakeRepositories) CompareCommits(arg1 context.Context, arg2 string, arg3 string, arg4 string, arg5 string, arg6 *github.ListOptions) (*github.CommitsComparison, *github.Response, error) {
+	fake.compareCommitsMutex.Lock()
+	ret, specificReturn := fake.compareCommitsReturnsOnCall[len(fake.compareCommitsArgsForCall)]
+	fake.compareCommitsArgsForCall = append(fake.compareCommitsArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+		arg4 string
+		arg5 string
+		arg6 *github.ListOptions
+	}{arg1, arg2, arg3, arg4, arg5, arg6})
+	stub := fake.CompareCommitsStub
+	fakeReturns := fake.compareCommitsReturns
+	fake.recordInvocation("CompareCommits", []interface{}{arg1, arg2, arg3, arg4, arg5, arg6})
+	fake.compareCommitsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4, arg5, arg6)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2, ret.result3
+	}
+	return fakeReturns.result1, fakeReturns.result2, fakeReturns.result3
+}
+
+func (fake *FakeRepositories) CompareCommitsCallCount() int {
+	fake.compareCommitsMutex.RLock()
+	defer fake.compareCommitsMutex.RUnlock()
+	return len(fake.compareCommitsArgsForCall)
+}
+
+func (fake *FakeRepositories) CompareCommitsCalls(stub func(context.Context, string, string, string, string, *github.ListOptions) (*github.CommitsComparison, *github.Response, error)) {
+	fake.compareCommitsMutex.Lock()
+	defer fake.compareCommitsMutex.Unlock()
+	fake.CompareCommitsStub = stub
+}
+
+func (fake *FakeRepositories) CompareCommitsArgsForCall(i int) (context.Context, string, string, string, string, *github.ListOptions) {
+	fake.compareCommitsMutex.RLock()
+	defer fake.compareCommitsMutex.RUnlock()
+	argsForCall := fake.compareCommitsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5, argsForCall.arg6
+}
+
+func (fake *FakeRepositories) CompareCommitsReturns(result1 *github.CommitsComparison, result2 *github.Response, result3 error) {
+	fake.compareCommitsMutex.Lock()
+	defer fake.compareCommitsMutex.Unlock()
+	fake.CompareCommitsStub = nil
+	fake.compareCommitsReturns = struct {
+		result1 *github.CommitsComparison
+		result2 *github.Response
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeRepositories) CompareCommitsReturnsOnCall(i int, result1 *github.CommitsComparison, result2 *github.Response, result3 error) {
+	fake.compareCommitsMutex.Lock()
+	defer fake.compareCommitsMutex.Unlock()
+	fake.CompareCommitsStub = nil
+	if fake.compareCommitsReturnsOnCall == nil {
+		fake.compareCommitsReturnsOnCall = make(map[int]struct {
+			result1 *github.CommitsComparison
+			result2 *github.Response
+			result3 error
+		})
+	}
+	fake.compareCommitsReturnsOnCall[i] = struct {
+		result1 *github.CommitsComparison
+		result2 *github.Response
+		result3 error
+	}{result1, result2, result3}
+}
+
 func (fake *FakeRepositories) Get(arg1 context.Context, arg2 string, arg3 string) (*github.Repository, *github.Response, error) {
 	fake.getMutex.Lock()
 	ret, specificReturn := fake.getReturnsOnCall[len(fake.getArgsForCall)]
@@ -121,6 +249,76 @@ func (fake *FakeRepositories) GetReturnsOnCall(i int, result1 *github.Repository
 	}{result1, result2, result3}
 }
 
+func (fake *FakeRepositories) ListBranches(arg1 context.Context, arg2 string, arg3 string, arg4 *github.BranchListOptions) ([]*github.Branch, *github.Response, error) {
+	fake.listBranchesMutex.Lock()
+	ret, specificReturn := fake.listBranchesReturnsOnCall[len(fake.listBranchesArgsForCall)]
+	fake.listBranchesArgsForCall = append(fake.listBranchesArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+		arg4 *github.BranchListOptions
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.ListBranchesStub
+	fakeReturns := fake.listBranchesReturns
+	fake.recordInvocation("ListBranches", []interface{}{arg1, arg2, arg3, arg4})
+	fake.listBranchesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2, ret.result3
+	}
+	return fakeReturns.result1, fakeReturns.result2, fakeReturns.result3
+}
+
+func (fake *FakeRepositories) ListBranchesCallCount() int {
+	fake.listBranchesMutex.RLock()
+	defer fake.listBranchesMutex.RUnlock()
+	return len(fake.listBranchesArgsForCall)
+}
+
+func (fake *FakeRepositories) ListBranchesCalls(stub func(context.Context, string, string, *github.BranchListOptions) ([]*github.Branch, *github.Response, error)) {
+	fake.listBranchesMutex.Lock()
+	defer fake.listBranchesMutex.Unlock()
+	fake.ListBranchesStub = stub
+}
+
+func (fake *FakeRepositories) ListBranchesArgsForCall(i int) (context.Context, string, string, *github.BranchListOptions) {
+	fake.listBranchesMutex.RLock()
+	defer fake.listBranchesMutex.RUnlock()
+	argsForCall := fake.listBranchesArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeRepositories) ListBranchesReturns(result1 []*github.Branch, result2 *github.Response, result3 error) {
+	fake.listBranchesMutex.Lock()
+	defer fake.listBranchesMutex.Unlock()
+	fake.ListBranchesStub = nil
+	fake.listBranchesReturns = struct {
+		result1 []*github.Branch
+		result2 *github.Response
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeRepositories) ListBranchesReturnsOnCall(i int, result1 []*github.Branch, result2 *github.Response, result3 error) {
+	fake.listBranchesMutex.Lock()
+	defer fake.listBranchesMutex.Unlock()
+	fake.ListBranchesStub = nil
+	if fake.listBranchesReturnsOnCall == nil {
+		fake.listBranchesReturnsOnCall = make(map[int]struct {
+			result1 []*github.Branch
+			result2 *github.Response
+			result3 error
+		})
+	}
+	fake.listBranchesReturnsOnCall[i] = struct {
+		result1 []*github.Branch
+		result2 *github.Response
+		result3 error
+	}{result1, result2, result3}
+}
+
 func (fake *FakeRepositories) ListCommits(arg1 context.Context, arg2 string, arg3 string, arg4 *github.CommitsListOptions) ([]*github.RepositoryCommit, *github.Response, error) {
 	fake.listCommitsMutex.Lock()
 	ret, specificReturn := fake.listCommitsReturnsOnCall[len(fake.listCommitsArgsForCall)]
@@ -191,13 +389,89 @@ func (fake *FakeRepositories) ListCommitsReturnsOnCall(i int, result1 []*github.
 	}{result1, result2, result3}
 }
 
+func (fake *FakeRepositories) ListTags(arg1 context.Context, arg2 string, arg3 string, arg4 *github.ListOptions) ([]*github.RepositoryTag, *github.Response, error) {
+	fake.listTagsMutex.Lock()
+	ret, specificReturn := fake.listTagsReturnsOnCall[len(fake.listTagsArgsForCall)]
+	fake.listTagsArgsForCall = append(fake.listTagsArgsForCall, struct {
+		arg1 context.Context
+		arg2 string
+		arg3 string
+		arg4 *github.ListOptions
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.ListTagsStub
+	fakeReturns := fake.listTagsReturns
+	fake.recordInvocation("ListTags", []interface{}{arg1, arg2, arg3, arg4})
+	fake.listTagsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2, ret.result3
+	}
+	return fakeReturns.result1, fakeReturns.result2, fakeReturns.result3
+}
+
+func (fake *FakeRepositories) ListTagsCallCount() int {
+	fake.listTagsMutex.RLock()
+	defer fake.listTagsMutex.RUnlock()
+	return len(fake.listTagsArgsForCall)
+}
+
+func (fake *FakeRepositories) ListTagsCalls(stub func(context.Context, string, string, *github.ListOptions) ([]*github.RepositoryTag, *github.Response, error)) {
+	fake.listTagsMutex.Lock()
+	defer fake.listTagsMutex.Unlock()
+	fake.ListTagsStub = stub
+}
+
+func (fake *FakeRepositories) ListTagsArgsForCall(i int) (context.Context, string, string, *github.ListOptions) {
+	fake.listTagsMutex.RLock()
+	defer fake.listTagsMutex.RUnlock()
+	argsForCall := fake.listTagsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeRepositories) ListTagsReturns(result1 []*github.RepositoryTag, result2 *github.Response, result3 error) {
+	fake.listTagsMutex.Lock()
+	defer fake.listTagsMutex.Unlock()
+	fake.ListTagsStub = nil
+	fake.listTagsReturns = struct {
+		result1 []*github.RepositoryTag
+		result2 *github.Response
+		result3 error
+	}{result1, result2, result3}
+}
+
+func (fake *FakeRepositories) ListTagsReturnsOnCall(i int, result1 []*github.RepositoryTag, result2 *github.Response, result3 error) {
+	fake.listTagsMutex.Lock()
+	defer fake.listTagsMutex.Unlock()
+	fake.ListTagsStub = nil
+	if fake.listTagsReturnsOnCall == nil {
+		fake.listTagsReturnsOnCall = make(map[int]struct {
+			result1 []*github.RepositoryTag
+			result2 *github.Response
+			result3 error
+		})
+	}
+	fake.listTagsReturnsOnCall[i] = struct {
+		result1 []*github.RepositoryTag
+		result2 *github.Response
+		result3 error
+	}{result1, result2, result3}
+}
+
 func (fake *FakeRepositories) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
+	fake.compareCommitsMutex.RLock()
+	defer fake.compareCommitsMutex.RUnlock()
 	fake.getMutex.RLock()
 	defer fake.getMutex.RUnlock()
+	fake.listBranchesMutex.RLock()
+	defer fake.listBranchesMutex.RUnlock()
 	fake.listCommitsMutex.RLock()
 	defer fake.listCommitsMutex.RUnlock()
+	fake.listTagsMutex.RLock()
+	defer fake.listTagsMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
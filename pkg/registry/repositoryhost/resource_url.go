@@ -37,6 +37,14 @@ func RawURL(resourceURL string) (string, error) {
 	return fmt.Sprintf("https://%s/%s/%s/raw/%s/%s", r.host, r.owner, r.repo, r.ref, r.resourcePath), nil
 }
 
+// EditURL returns the GitHub edit URL if the resource is 'blob' or 'raw'
+func (r URL) EditURL() (string, error) {
+	if r.resourceType != "blob" && r.resourceType != "raw" {
+		return "", fmt.Errorf("expected a blob url got %s", r.String())
+	}
+	return fmt.Sprintf("https://%s/%s/%s/edit/%s/%s", r.host, r.owner, r.repo, r.ref, r.resourcePath), nil
+}
+
 // URL represents an repsource url
 type URL struct {
 	host           string
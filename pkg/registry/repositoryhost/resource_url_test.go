@@ -33,6 +33,31 @@ var _ = Describe("URL", func() {
 		})
 	})
 
+	Describe("commit pinned links", func() {
+		sha := "6f1c2a1b6f6e4c3d2b1a09f8e7d6c5b4a3928170"
+
+		BeforeEach(func() {
+			r, err = repositoryhost.NewResourceURL("https://github.com/owner/repo/blob/" + sha + "/docs/dev/local_setup.md#setup")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("keeps the commit as reference", func() {
+			Expect(r.GetRef()).To(Equal(sha))
+			Expect(r.String()).To(Equal("https://github.com/owner/repo/blob/" + sha + "/docs/dev/local_setup.md#setup"))
+			Expect(r.ReferenceURL().String()).To(Equal("https://github.com/owner/repo/tree/" + sha))
+			raw, err := repositoryhost.RawURL(r.String())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(raw).To(Equal("https://github.com/owner/repo/raw/" + sha + "/docs/dev/local_setup.md"))
+		})
+
+		It("resolves relative links at the commit", func() {
+			blob, tree, err := r.ResolveRelativeLink("../user/getting_started.md")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(blob).To(Equal("https://github.com/owner/repo/blob/" + sha + "/docs/user/getting_started.md"))
+			Expect(tree).To(Equal("https://github.com/owner/repo/tree/" + sha + "/docs/user/getting_started.md"))
+		})
+	})
+
 	Describe("#ResolveRelativeLink", func() {
 		BeforeEach(func() {
 			r, err = repositoryhost.NewResourceURL("https://github.com/owner/repo/blob/master/docs/dev/local_setup.md")
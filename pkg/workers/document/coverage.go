@@ -0,0 +1,89 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package document
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+
+	"github.com/gardener/docforge/pkg/manifest"
+	"github.com/gardener/docforge/pkg/metrics"
+	"k8s.io/klog/v2"
+)
+
+// CoverageStatus is the outcome of processing a document node
+type CoverageStatus string
+
+const (
+	// CoverageWritten marks document nodes that produced output
+	CoverageWritten CoverageStatus = "written"
+	// CoverageEmpty marks document nodes with no content
+	CoverageEmpty CoverageStatus = "empty"
+	// CoverageFailed marks document nodes that failed processing
+	CoverageFailed CoverageStatus = "failed"
+	// CoverageSkipped marks document nodes that were not scheduled for processing
+	CoverageSkipped CoverageStatus = "skipped"
+)
+
+// CoverageEntry is the outcome of processing a document node
+type CoverageEntry struct {
+	Node   string         `json:"node"`
+	Status CoverageStatus `json:"status"`
+	Reason string         `json:"reason,omitempty"`
+}
+
+// Coverage collects which document nodes produced output
+type Coverage struct {
+	mux     sync.Mutex
+	entries []CoverageEntry
+}
+
+func (c *Coverage) add(node *manifest.Node, status CoverageStatus, reason string) {
+	if node.Type != "file" {
+		return
+	}
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	c.entries = append(c.entries, CoverageEntry{node.NodePath(), status, reason})
+	metrics.DocumentsProcessed.Inc(string(status))
+}
+
+// Entries returns the coverage entries sorted by node path
+func (c *Coverage) Entries() []CoverageEntry {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+	entries := append([]CoverageEntry{}, c.entries...)
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Node < entries[j].Node })
+	return entries
+}
+
+// Counts returns the number of document nodes per status
+func (c *Coverage) Counts() map[CoverageStatus]int {
+	counts := map[CoverageStatus]int{}
+	for _, e := range c.Entries() {
+		counts[e.Status]++
+	}
+	return counts
+}
+
+// Report returns the coverage report as JSON
+func (c *Coverage) Report() ([]byte, error) {
+	return json.MarshalIndent(struct {
+		Counts  map[CoverageStatus]int `json:"counts"`
+		Entries []CoverageEntry        `json:"entries"`
+	}{c.Counts(), c.Entries()}, "", "  ")
+}
+
+// LogCoverage logs the coverage counts and the document nodes that didn't produce output
+func (c *Coverage) LogCoverage() {
+	counts := c.Counts()
+	klog.Infof("Document nodes written: %d, empty: %d, failed: %d, skipped: %d\n", counts[CoverageWritten], counts[CoverageEmpty], counts[CoverageFailed], counts[CoverageSkipped])
+	for _, e := range c.Entries() {
+		if e.Status != CoverageWritten {
+			klog.V(6).Infof("document node %s %s: %s\n", e.Node, e.Status, e.Reason)
+		}
+	}
+}
@@ -8,14 +8,21 @@ import (
 	"bytes"
 	"context"
 	"crypto/md5"
+	"encoding/base64"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"mime"
 	"net/url"
 	"path"
+	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/gardener/docforge/cmd/hugo"
+	"github.com/gardener/docforge/pkg/buildstate"
 	"github.com/gardener/docforge/pkg/manifest"
 	"github.com/gardener/docforge/pkg/registry"
 	"github.com/gardener/docforge/pkg/registry/repositoryhost"
@@ -27,6 +34,8 @@ import (
 	"github.com/gardener/docforge/pkg/writers"
 	"github.com/yuin/goldmark"
 	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/renderer"
 	"k8s.io/klog/v2"
 )
 
@@ -41,9 +50,43 @@ type Worker struct {
 
 	resourcesRoot string
 
-	repositoryhosts    registry.Interface
-	hugo               hugo.Hugo
-	skipLinkValidation bool
+	repositoryhosts      registry.Interface
+	hugo                 hugo.Hugo
+	skipLinkValidation   bool
+	normalizeLineEndings bool
+	languageAliases      map[string]string
+	frontmatterOverride  bool
+	sourceFrontmatter    bool
+	codeSnippets         bool
+	editURLKey           string
+	transformer          *Transformer
+	definitionLists      markdown.DefinitionListForm
+	headingStyle         markdown.HeadingStyle
+	listMarkers          markdown.ListMarkers
+	basePath             string
+	inlineImagesMaxSize  int
+	state                *buildstate.State
+	// documentTimeout bounds the processing of a document, unbounded when 0
+	documentTimeout time.Duration
+	// inlineSVGsMaxSize is the size in bytes up to which SVG images are inlined as svg elements, disabled when 0
+	inlineSVGsMaxSize int
+	// inaccessibleLinks is the treatment of links to inaccessible resources of repositories, unchecked when empty
+	inaccessibleLinks InaccessibleLinkPolicy
+	linkStatuses      *linkStatuses
+	// renderWorkers is the number of sources of a document read and rendered in parallel, sequentially when up to 1
+	renderWorkers int
+	// destinationPath is the relative path from the resources download dir to the destination, where the resources of nodes
+	// with a resources root are downloaded
+	destinationPath string
+	// badgeHosts are the patterns of hosts, or of hosts and paths, of dynamic images like badges, which are kept absolute
+	badgeHosts []string
+	// lintRules are the rules the markdown sources are checked with, unchecked when empty
+	lintRules LintRules
+	// titleRules define how the titles of hugo documents without a frontmatter title are derived from node names
+	titleRules frontmatter.TitleRules
+
+	coverage *Coverage
+	llms     *LLMsIndex
 }
 
 // docContent defines a document content
@@ -53,19 +96,112 @@ type docContent struct {
 	docURI string
 }
 
-// NewDocumentWorker creates Worker objects
-func NewDocumentWorker(resourcesRoot string, downloader resourcedownloader.Interface, validator linkvalidator.Interface, linkResolver linkresolver.Interface, rh registry.Interface, hugo hugo.Hugo, writer writers.Writer, skipLinkValidation bool) *Worker {
-	return &Worker{
-		markdown.New(),
-		linkResolver,
-		downloader,
-		validator,
-		writer,
-		resourcesRoot,
-		rh,
-		hugo,
-		skipLinkValidation,
+// NewDocumentWorker creates Worker objects, failing for invalid options
+func NewDocumentWorker(resourcesRoot string, downloader resourcedownloader.Interface, validator linkvalidator.Interface, linkResolver linkresolver.Interface, rh registry.Interface, hugo hugo.Hugo, writer writers.Writer, options Options) (*Worker, error) {
+	transformer, err := NewTransformer(options.ContentTransforms)
+	if err != nil {
+		return nil, err
+	}
+	form := markdown.DefinitionListForm(options.DefinitionLists)
+	if form != "" && form != markdown.DefinitionListMarkdown && form != markdown.DefinitionListHTML {
+		return nil, fmt.Errorf("definition lists can be rendered as %s or %s, not as %s", markdown.DefinitionListMarkdown, markdown.DefinitionListHTML, options.DefinitionLists)
+	}
+	style := markdown.HeadingStyle(options.HeadingStyle)
+	if style != "" && style != markdown.HeadingATX && style != markdown.HeadingSetext && style != markdown.HeadingPreserve {
+		return nil, fmt.Errorf("headings can be rendered as %s, %s or %s, not as %s", markdown.HeadingATX, markdown.HeadingSetext, markdown.HeadingPreserve, options.HeadingStyle)
+	}
+	var markers markdown.ListMarkers
+	switch options.BulletListMarker {
+	case "":
+	case "-", "*", "+":
+		markers.Bullet = options.BulletListMarker[0]
+	default:
+		return nil, fmt.Errorf("bullet list marker can be -, * or +, not %s", options.BulletListMarker)
+	}
+	switch options.OrderedListDelimiter {
+	case "":
+	case ".", ")":
+		markers.Delimiter = options.OrderedListDelimiter[0]
+	default:
+		return nil, fmt.Errorf("ordered list delimiter can be . or ), not %s", options.OrderedListDelimiter)
+	}
+	markers.Numbering = markdown.ListNumbering(options.OrderedListNumbering)
+	if markers.Numbering != "" && markers.Numbering != markdown.ListNumberingPreserve && markers.Numbering != markdown.ListNumberingRenumber {
+		return nil, fmt.Errorf("ordered lists can be numbered with %s or %s, not with %s", markdown.ListNumberingPreserve, markdown.ListNumberingRenumber, options.OrderedListNumbering)
+	}
+	linkPolicy := InaccessibleLinkPolicy(options.InaccessibleLinks)
+	if linkPolicy != "" && linkPolicy != InaccessibleLinksError && linkPolicy != InaccessibleLinksWarn && linkPolicy != InaccessibleLinksDrop {
+		return nil, fmt.Errorf("links to inaccessible resources can be treated with %s, %s or %s, not with %s", InaccessibleLinksError, InaccessibleLinksWarn, InaccessibleLinksDrop, options.InaccessibleLinks)
+	}
+	for _, pattern := range options.BadgeHosts {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid badge host pattern %s: %w", pattern, err)
+		}
+	}
+	lintRules, err := NewLintRules(options.LintRules)
+	if err != nil {
+		return nil, err
+	}
+	titleRules, err := frontmatter.NewTitleRules(options.TitleCase, options.TitleAcronyms, options.TitleReplacements)
+	if err != nil {
+		return nil, err
 	}
+	if u, err := url.Parse(options.BasePath); err != nil || u.Scheme != "" || u.Host != "" {
+		return nil, fmt.Errorf("base path must be a path like /docs, not %s", options.BasePath)
+	}
+	md := markdown.New()
+	if form != "" {
+		md = markdown.New(extension.DefinitionList)
+	}
+	destinationPath, err := filepath.Rel(filepath.Join(string(filepath.Separator), options.ResourcesDownloadPath), string(filepath.Separator))
+	if err != nil {
+		destinationPath = ""
+	}
+	return &Worker{
+		markdown:             md,
+		linkresolver:         linkResolver,
+		downloader:           downloader,
+		validator:            validator,
+		writer:               writer,
+		resourcesRoot:        resourcesRoot,
+		repositoryhosts:      rh,
+		hugo:                 hugo,
+		skipLinkValidation:   options.SkipLinkValidation,
+		normalizeLineEndings: !options.KeepLineEndings,
+		languageAliases:      options.CodeLanguageAliases,
+		frontmatterOverride:  !options.KeepDocumentFrontmatter,
+		sourceFrontmatter:    options.SourceFrontmatter,
+		codeSnippets:         options.CodeSnippets,
+		editURLKey:           options.EditURLKey,
+		transformer:          transformer,
+		definitionLists:      form,
+		headingStyle:         style,
+		listMarkers:          markers,
+		basePath:             options.BasePath,
+		inlineImagesMaxSize:  options.InlineImagesMaxSize,
+		state:                options.State,
+		documentTimeout:      options.DocumentTimeout,
+		inlineSVGsMaxSize:    options.InlineSVGsMaxSize,
+		inaccessibleLinks:    linkPolicy,
+		linkStatuses:         &linkStatuses{statuses: map[string]int{}},
+		renderWorkers:        options.RenderWorkers,
+		destinationPath:      filepath.ToSlash(destinationPath),
+		badgeHosts:           options.BadgeHosts,
+		lintRules:            lintRules,
+		titleRules:           titleRules,
+		coverage:             &Coverage{},
+		llms:                 &LLMsIndex{},
+	}, nil
+}
+
+// Coverage returns the coverage of the processed document nodes
+func (d *Worker) Coverage() *Coverage {
+	return d.coverage
+}
+
+// LLMsIndex returns the llms.txt index of the written document nodes
+func (d *Worker) LLMsIndex() *LLMsIndex {
+	return d.llms
 }
 
 var (
@@ -79,49 +215,182 @@ var (
 
 // ProcessNode processes a node and writes its content
 func (d *Worker) ProcessNode(ctx context.Context, node *manifest.Node) error {
+	if written, ok := d.state.WrittenNode(node); ok {
+		return d.resumeNode(node, written)
+	}
 	var cnt []byte
-	if node.HasContent() {
+	worker := d
+	var downloads *recordingDownloader
+	if d.state != nil {
+		// the downloads of the document are recorded, so that a resumed build schedules them again
+		downloads = &recordingDownloader{Interface: d.downloader}
+		recording := *d
+		recording.downloader = downloads
+		worker = &recording
+	}
+	if node.ExternalURL != "" {
+		// nodes linking to an external URL have no sources to read
+		stub, err := externalURLStub(node, d.hugo.Enabled)
+		if err != nil {
+			d.coverage.add(node, CoverageFailed, err.Error())
+			return err
+		}
+		cnt = stub
+	} else if node.HasContent() {
 		// Process the node
-		bytesBuff := bufPool.Get().(*bytes.Buffer)
-		defer bufPool.Put(bytesBuff)
-		bytesBuff.Reset()
-		if err := d.process(ctx, bytesBuff, node); err != nil {
+		bytesBuff, err := worker.processWithTimeout(ctx, node)
+		if errors.Is(err, errDocumentTimeout) {
+			klog.Warningf("processing document node %s timed out after %s\n", node.NodePath(), d.documentTimeout)
+			d.coverage.add(node, CoverageFailed, fmt.Sprintf("timed out after %s", d.documentTimeout))
+			return nil
+		}
+		if err != nil {
+			d.coverage.add(node, CoverageFailed, err.Error())
 			return err
 		}
+		if d.documentTimeout == 0 {
+			defer bufPool.Put(bytesBuff)
+		}
 		if bytesBuff.Len() == 0 {
 			klog.Warningf("document node processing halted: no content assigned to document node %s/%s", node.Path, node.Name())
+			d.coverage.add(node, CoverageEmpty, "no content assigned")
 			return nil
 		}
 		cnt = bytesBuff.Bytes()
 	}
 	if err := d.writer.Write(node.Name(), node.Path, cnt, node, d.hugo.IndexFileNames); err != nil {
+		d.coverage.add(node, CoverageFailed, err.Error())
+		return err
+	}
+	status, reason := CoverageWritten, ""
+	if cnt == nil && len(node.Frontmatter) == 0 {
+		status, reason = CoverageEmpty, "no source or frontmatter"
+	}
+	written := buildstate.Node{Status: string(status), Reason: reason}
+	entry, indexed := llmsEntry{}, false
+	if status == CoverageWritten {
+		if entry, indexed = d.llms.entry(node, cnt, d.basePath, d.hugo); indexed {
+			written.Title, written.Link, written.Content = entry.title, entry.link, entry.content
+		}
+	}
+	if downloads != nil {
+		written.Downloads = downloads.scheduled()
+	}
+	if err := d.state.RecordNode(node, written); err != nil {
+		d.coverage.add(node, CoverageFailed, err.Error())
+		return err
+	}
+	d.coverage.add(node, status, reason)
+	if indexed {
+		d.llms.add(entry)
+	}
+	return nil
+}
+
+// resumeNode reports the document node written by the resumed build as it was written, and schedules its downloads
+// again, so that the resources they reference are reported and kept or downloaded if missing
+func (d *Worker) resumeNode(node *manifest.Node, written buildstate.Node) error {
+	for _, download := range written.Downloads {
+		if err := d.downloader.Schedule(download.Source, download.Target, download.Document); err != nil {
+			d.coverage.add(node, CoverageFailed, err.Error())
+			return err
+		}
+	}
+	d.coverage.add(node, CoverageStatus(written.Status), written.Reason)
+	if written.Link != "" {
+		d.llms.add(llmsEntry{path: node.NodePath(), title: written.Title, link: written.Link, content: written.Content})
+	}
+	return nil
+}
+
+// recordingDownloader records the downloads scheduled for a document
+type recordingDownloader struct {
+	resourcedownloader.Interface
+	mux       sync.Mutex
+	downloads []buildstate.Download
+}
+
+func (r *recordingDownloader) Schedule(source string, target string, document string) error {
+	if err := r.Interface.Schedule(source, target, document); err != nil {
 		return err
 	}
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.downloads = append(r.downloads, buildstate.Download{Source: source, Target: target, Document: document})
 	return nil
 }
 
+// scheduled returns the recorded downloads
+func (r *recordingDownloader) scheduled() []buildstate.Download {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	return slices.Clone(r.downloads)
+}
+
+var errDocumentTimeout = errors.New("document timeout")
+
+// processWithTimeout processes the node within the document timeout. The processing is canceled when the timeout passes,
+// and abandoned if it doesn't return, so that a stuck source doesn't stall the other documents
+func (d *Worker) processWithTimeout(ctx context.Context, n *manifest.Node) (*bytes.Buffer, error) {
+	if d.documentTimeout == 0 {
+		b := bufPool.Get().(*bytes.Buffer)
+		b.Reset()
+		if err := d.process(ctx, b, n); err != nil {
+			bufPool.Put(b)
+			return nil, err
+		}
+		return b, nil
+	}
+	timeoutCtx, cancel := context.WithTimeout(ctx, d.documentTimeout)
+	defer cancel()
+	// an abandoned processing may still write the buffer, so it isn't pooled
+	b := &bytes.Buffer{}
+	done := make(chan error, 1)
+	go func() {
+		done <- d.process(timeoutCtx, b, n)
+	}()
+	var err error
+	select {
+	case err = <-done:
+	case <-timeoutCtx.Done():
+		err = timeoutCtx.Err()
+	}
+	if err != nil && ctx.Err() == nil && errors.Is(timeoutCtx.Err(), context.DeadlineExceeded) {
+		return nil, errDocumentTimeout
+	}
+	return b, err
+}
+
 func (d *Worker) process(ctx context.Context, b *bytes.Buffer, n *manifest.Node) error {
 	// manifest.Node content by priority
-	var fullContent []*docContent
 	nodePath := n.NodePath()
+	sources, sourceTypes := n.MultiSource, make([]string, len(n.MultiSource))
+	for i := range sourceTypes {
+		sourceTypes[i] = "multiSource"
+	}
 	if len(n.Source) > 0 {
-		nc, err := d.processSource(ctx, "source", n.Source, nodePath)
-		if err != nil {
-			return err
-		}
-		fullContent = append(fullContent, nc)
+		sources = append([]string{n.Source}, sources...)
+		sourceTypes = append([]string{"source"}, sourceTypes...)
 	}
-	for _, src := range n.MultiSource {
-		nc, err := d.processSource(ctx, "multiSource", src, nodePath)
-		if err != nil {
-			return err
-		}
-		fullContent = append(fullContent, nc)
+	fullContent := make([]*docContent, len(sources))
+	if err := inOrder(len(sources), d.renderWorkers, func(i int) (err error) {
+		fullContent[i], err = d.processSource(ctx, sourceTypes[i], sources[i], nodePath)
+		return err
+	}); err != nil {
+		return err
 	}
 	if len(fullContent) == 0 {
 		klog.Warningf("empty content for node %s\n", nodePath)
 		return nil
 	}
+	for _, cnt := range fullContent {
+		if cnt.docAst == nil {
+			continue
+		}
+		if err := d.lintSource(nodePath, cnt); err != nil {
+			return err
+		}
+	}
 
 	if fullContent[0].docAst != nil && fullContent[0].docAst.Kind() == ast.KindDocument {
 		firstDoc := fullContent[0].docAst.(*ast.Document)
@@ -132,24 +401,73 @@ func (d *Worker) process(ctx context.Context, b *bytes.Buffer, n *manifest.Node)
 			}
 		}
 		frontmatter.MoveMultiSourceFrontmatterToTopDocument(docs)
-		frontmatter.MergeDocumentAndNodeFrontmatter(firstDoc, n)
-		frontmatter.ComputeNodeTitle(firstDoc, n, d.hugo.IndexFileNames, d.hugo.Enabled)
-	}
-	for _, cnt := range fullContent {
-		lrt := linkResolverTask{
-			*d,
-			n,
-			cnt.docURI,
-		}
-		if strings.HasSuffix(cnt.docURI, ".md") {
-			rnd := markdown.NewLinkModifierRenderer(markdown.WithLinkResolver(lrt.resolveLink))
-			if err := rnd.Render(b, cnt.docCnt, cnt.docAst); err != nil {
-				return err
+		frontmatter.MergeDocumentAndNodeFrontmatter(firstDoc, n, d.frontmatterOverride)
+		if d.sourceFrontmatter {
+			source, err := d.repositoryhosts.ResourceURL(fullContent[0].docURI)
+			if err != nil {
+				return fmt.Errorf("resolving source of node %s failed: %w", nodePath, err)
+			}
+			frontmatter.AddSourceFrontmatter(firstDoc, source)
+		}
+		if d.editURLKey != "" {
+			editURL, err := d.repositoryhosts.GetEditLink(fullContent[0].docURI)
+			if err != nil {
+				return fmt.Errorf("computing edit link of node %s failed: %w", nodePath, err)
 			}
-		} else {
-			b.Write(cnt.docCnt)
+			frontmatter.AddEditURL(firstDoc, d.editURLKey, editURL)
+		}
+		frontmatter.ComputeNodeTitle(firstDoc, n, d.hugo.IndexFileNames, d.hugo.Enabled, d.titleRules)
+	}
+	rendered := make([]bytes.Buffer, len(fullContent))
+	if err := inOrder(len(fullContent), d.renderWorkers, func(i int) error {
+		return d.render(ctx, &rendered[i], n, fullContent[i])
+	}); err != nil {
+		return err
+	}
+	for i := range rendered {
+		b.Write(rendered[i].Bytes())
+	}
+	return nil
+}
+
+// render writes the content of a source of the node to b
+func (d *Worker) render(ctx context.Context, b *bytes.Buffer, n *manifest.Node, cnt *docContent) error {
+	// links are resolved relative to the content source, not to the node's first source
+	lrt := linkResolverTask{
+		*d,
+		ctx,
+		n,
+		cnt.docURI,
+		nil,
+	}
+	if isMarkdown(cnt.docURI) {
+		lrt.anchors = markdown.Anchors(cnt.docAst, cnt.docCnt)
+		opts := []renderer.Option{markdown.WithLinkResolver(lrt.resolveLink), markdown.WithLanguageAliases(d.languageAliases)}
+		if d.definitionLists != "" {
+			opts = append(opts, markdown.WithDefinitionLists(d.definitionLists))
+		}
+		if d.headingStyle != "" {
+			opts = append(opts, markdown.WithHeadingStyle(d.headingStyle))
+		}
+		if d.listMarkers != (markdown.ListMarkers{}) {
+			opts = append(opts, markdown.WithListMarkers(d.listMarkers))
+		}
+		if d.codeSnippets {
+			opts = append(opts, markdown.WithSnippetReader(func(file string) ([]byte, error) {
+				return lrt.readSnippet(ctx, file)
+			}))
 		}
+		if d.inlineSVGsMaxSize > 0 {
+			opts = append(opts, markdown.WithImageInliner(lrt.inlineSVG))
+		}
+		rnd := markdown.NewLinkModifierRenderer(opts...)
+		if err := rnd.Render(b, cnt.docCnt, cnt.docAst); err != nil {
+			return err
+		}
+	} else {
+		b.Write(cnt.docCnt)
 	}
+
 	return nil
 }
 
@@ -159,8 +477,12 @@ func (d *Worker) processSource(ctx context.Context, sourceType string, source st
 	if err != nil {
 		return nil, fmt.Errorf("reading %s %s from node %s failed: %w", sourceType, source, nodePath, err)
 	}
+	if d.normalizeLineEndings {
+		content = normalizeLineEndings(content)
+	}
+	content = d.transformer.Transform(source, content)
 	dc = &docContent{docCnt: content, docURI: source}
-	if strings.HasSuffix(source, ".md") {
+	if isMarkdown(source) {
 		dc.docAst, err = markdown.Parse(d.markdown, content)
 		if err != nil {
 			return nil, fmt.Errorf("fail to parse %s %s from node %s: %w", sourceType, source, nodePath, err)
@@ -169,10 +491,24 @@ func (d *Worker) processSource(ctx context.Context, sourceType string, source st
 	return dc, nil
 }
 
+// isMarkdown checks if the source is a markdown document by its extension
+func isMarkdown(source string) bool {
+	return strings.HasSuffix(source, ".md") || strings.HasSuffix(source, ".markdown")
+}
+
+// normalizeLineEndings converts CRLF and CR line endings to LF
+func normalizeLineEndings(content []byte) []byte {
+	content = bytes.ReplaceAll(content, []byte("\r\n"), []byte("\n"))
+	return bytes.ReplaceAll(content, []byte("\r"), []byte("\n"))
+}
+
 type linkResolverTask struct {
 	Worker
+	ctx    context.Context
 	node   *manifest.Node
 	source string
+	// anchors of the source document, anchor-only links are checked against
+	anchors map[string]struct{}
 }
 
 // DownloadURLName create resource name that will be dowloaded from a resource link
@@ -200,11 +536,18 @@ func (d *linkResolverTask) resolveLink(dest string, isEmbeddable bool) (string,
 		return dest, nil
 	}
 	if isEmbeddable {
+		if url.IsAbs() && isBadge(url, d.badgeHosts) {
+			// badges are dynamic images that are neither downloaded nor inlined
+			return dest, nil
+		}
 		return d.resolveEmbededLink(dest, d.source)
 	}
 	// handle non-embeded links
 	if url.IsAbs() {
 		if _, err = d.repositoryhosts.ResourceURL(dest); err != nil {
+			if link, handled, err := d.inaccessibleLink(dest); handled {
+				return link, err
+			}
 			// absolute link that is not referencing any documentation page
 			if !d.node.SkipValidation && !d.skipLinkValidation {
 				d.validator.ValidateLink(dest, d.source)
@@ -212,7 +555,41 @@ func (d *linkResolverTask) resolveLink(dest string, isEmbeddable bool) (string,
 			return dest, nil
 		}
 	}
-	return d.linkresolver.ResolveResourceLink(dest, d.node, d.source)
+	link, err := d.linkresolver.ResolveResourceLink(dest, d.node, d.source)
+	if err == nil && strings.HasPrefix(dest, "#") {
+		d.checkAnchor(dest, link)
+	}
+	return link, err
+}
+
+// checkAnchor warns about an anchor-only link whose anchor, or the one it is redirected to, is missing in the document
+func (d *linkResolverTask) checkAnchor(dest string, link string) {
+	_, anchor, _ := strings.Cut(link, "#")
+	if anchor == "" || d.anchors == nil {
+		return
+	}
+	if _, ok := d.anchors[strings.ToLower(anchor)]; !ok {
+		klog.Warningf("anchor %s in source %s of node %s is missing, add an anchor redirect if its section was renamed\n", dest, d.source, d.node.NodePath())
+	}
+}
+
+// readSnippet reads a file referenced by a fenced code block relative to the task source
+func (d *linkResolverTask) readSnippet(ctx context.Context, file string) ([]byte, error) {
+	var err error
+	if repositoryhost.IsRelative(file) {
+		file, err = d.repositoryhosts.ResolveRelativeLink(d.source, file)
+		if err != nil {
+			return nil, err
+		}
+	}
+	content, err := d.repositoryhosts.Read(ctx, file)
+	if err != nil {
+		return nil, err
+	}
+	if d.normalizeLineEndings {
+		content = normalizeLineEndings(content)
+	}
+	return content, nil
 }
 
 func (d *linkResolverTask) resolveEmbededLink(link string, source string) (string, error) {
@@ -228,13 +605,119 @@ func (d *linkResolverTask) resolveEmbededLink(link string, source string) (strin
 	// link has format of a resource url
 	resourceURL, err := d.repositoryhosts.ResourceURL(link)
 	if err != nil {
+		if link, handled, err := d.inaccessibleLink(link); handled {
+			return link, err
+		}
 		// convert urls from not referenced repository  to raw
 		return repositoryhost.RawURL(link)
 	}
+	if d.inlineImagesMaxSize > 0 {
+		if dataURI, ok := d.inlineImage(resourceURL); ok {
+			return dataURI, nil
+		}
+	}
 	// download urls from referenced repositories
-	downloadResourceName := DownloadURLName(*resourceURL)
+	downloadResourceName := path.Join(d.node.ResourcesPath, DownloadURLName(*resourceURL))
+	websitePath := path.Join(d.resourcesRoot, downloadResourceName)
+	if d.node.ResourcesRoot != "" {
+		// the resource is downloaded under the resources root of the node in the destination
+		websitePath = path.Join(d.node.ResourcesRoot, downloadResourceName)
+		downloadResourceName = path.Join(d.destinationPath, websitePath)
+	}
 	if err = d.downloader.Schedule(link, downloadResourceName, source); err != nil {
 		return link, err
 	}
-	return "/" + path.Join(d.hugo.BaseURL, d.resourcesRoot, downloadResourceName), nil
+	return linkresolver.WebsiteLink(d.basePath, d.hugo.BaseURL, websitePath), nil
+}
+
+// inlineSVG returns the markup of an SVG image not larger than the inline SVGs max size.
+// Other images, and SVG images that can't be read or parsed, are left to link resolving
+func (d *linkResolverTask) inlineSVG(dest string) ([]byte, error) {
+	link := dest
+	if u, err := url.Parse(link); err == nil && u.IsAbs() && isBadge(u, d.badgeHosts) {
+		return nil, nil
+	}
+	if repositoryhost.IsRelative(link) {
+		var err error
+		if link, err = d.repositoryhosts.ResolveRelativeLink(d.source, link); err != nil {
+			return nil, nil
+		}
+	} else if !repositoryhost.IsResourceURL(link) {
+		return nil, nil
+	}
+	resourceURL, err := d.repositoryhosts.ResourceURL(link)
+	if err != nil || !strings.EqualFold(path.Ext(resourceURL.GetResourcePath()), ".svg") {
+		return nil, nil
+	}
+	content, err := d.repositoryhosts.Read(d.ctx, resourceURL.ResourceURL())
+	if err != nil || len(content) > d.inlineSVGsMaxSize {
+		return nil, nil
+	}
+	markup, err := resourcedownloader.InlineSVGMarkup(content)
+	if err != nil {
+		klog.Warningf("SVG %s referenced in %s is downloaded as it can't be inlined: %v\n", link, d.source, err)
+		return nil, nil
+	}
+	return markup, nil
+}
+
+// isBadge checks if an image link matches a badge host pattern. Patterns without / match the host,
+// the others match the host and the path, e.g. github.com/*/*/actions/workflows/*/badge.svg
+func isBadge(link *url.URL, badgeHosts []string) bool {
+	host := strings.ToLower(link.Host)
+	for _, pattern := range badgeHosts {
+		name := host
+		if strings.Contains(pattern, "/") {
+			name = host + link.Path
+		}
+		if ok, _ := path.Match(strings.ToLower(pattern), name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// inlineImage returns the base64 data URI of an image resource not larger than the inline images max size
+func (d *linkResolverTask) inlineImage(resourceURL *repositoryhost.URL) (string, bool) {
+	mediaType := mime.TypeByExtension(strings.ToLower(path.Ext(resourceURL.GetResourcePath())))
+	if !strings.HasPrefix(mediaType, "image/") {
+		return "", false
+	}
+	content, err := d.repositoryhosts.Read(d.ctx, resourceURL.ResourceURL())
+	if err != nil || len(content) > d.inlineImagesMaxSize {
+		// images failing to read are scheduled for download, reporting missing ones
+		return "", false
+	}
+	return "data:" + mediaType + ";base64," + base64.StdEncoding.EncodeToString(content), true
+}
+
+// inOrder calls f for the indexes up to n with at most workers calls in parallel and returns the error of the
+// lowest index. With up to 1 worker the calls are sequential and stop at the first error
+func inOrder(n int, workers int, f func(i int) error) error {
+	if workers <= 1 || n < 2 {
+		for i := 0; i < n; i++ {
+			if err := f(i); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	errs := make([]error, n)
+	slots := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(i int) {
+			defer func() { <-slots; wg.Done() }()
+			errs[i] = f(i)
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
@@ -7,21 +7,32 @@ package document_test
 import (
 	"context"
 	"embed"
+	"encoding/base64"
 	"fmt"
+	"net/http"
 	"testing"
+	"time"
 
 	_ "embed"
 
 	"github.com/gardener/docforge/cmd/hugo"
 	"github.com/gardener/docforge/pkg/manifest"
+	"github.com/gardener/docforge/pkg/osfakes/httpclient/httpclientfakes"
 	"github.com/gardener/docforge/pkg/registry"
+	"github.com/gardener/docforge/pkg/registry/registryfakes"
 	"github.com/gardener/docforge/pkg/registry/repositoryhost"
 	"github.com/gardener/docforge/pkg/workers/document"
+	"github.com/gardener/docforge/pkg/workers/document/markdown"
+	"github.com/gardener/docforge/pkg/workers/linkresolver"
 	"github.com/gardener/docforge/pkg/workers/linkresolver/linkresolverfakes"
+	"github.com/gardener/docforge/pkg/workers/linkvalidator"
 	"github.com/gardener/docforge/pkg/workers/linkvalidator/linkvalidatorfakes"
+	"github.com/gardener/docforge/pkg/workers/resourcedownloader"
 	"github.com/gardener/docforge/pkg/workers/resourcedownloader/downloaderfakes"
+	"github.com/gardener/docforge/pkg/writers"
 	"github.com/gardener/docforge/pkg/writers/writersfakes"
 	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
 )
 
@@ -33,9 +44,58 @@ func TestJobs(t *testing.T) {
 //go:embed tests/*
 var manifests embed.FS
 
+// parallelSources are the sources of a node rendered in parallel
+var parallelSources = []string{
+	"https://github.com/gardener/docforge/blob/master/target.md",
+	"https://github.com/gardener/docforge/blob/master/target2.md",
+	"https://github.com/gardener/docforge/blob/master/target3.html",
+	"https://github.com/gardener/docforge/blob/master/definitions.md",
+	"https://github.com/gardener/docforge/blob/master/target2.md",
+	"https://github.com/gardener/docforge/blob/master/icons.md",
+	"https://github.com/gardener/docforge/blob/master/target.md",
+}
+
+// documentWorker creates a document worker with the options, failing the test for invalid options
+func documentWorker(downloader resourcedownloader.Interface, validator linkvalidator.Interface, linkResolver linkresolver.Interface, rh registry.Interface, hugo hugo.Hugo, writer writers.Writer, options document.Options) *document.Worker {
+	worker, err := document.NewDocumentWorker("__resources", downloader, validator, linkResolver, rh, hugo, writer, options)
+	ExpectWithOffset(1, err).NotTo(HaveOccurred())
+	return worker
+}
+
+// renderingWorker creates a document worker rendering the sources of a document with renderWorkers in parallel
+func renderingWorker(w *writersfakes.FakeWriter, renderWorkers int) *document.Worker {
+	registry := registry.NewRegistry(repositoryhost.NewLocalTest(manifests, "https://github.com/gardener/docforge", "tests"))
+	lrf := &linkresolverfakes.FakeInterface{}
+	lrf.ResolveResourceLinkCalls(func(s1 string, n *manifest.Node, s2 string) (string, error) {
+		return s1, nil
+	})
+	return documentWorker(&downloaderfakes.FakeInterface{}, &linkvalidatorfakes.FakeInterface{}, lrf, registry, hugo.Hugo{Enabled: true, PrettyURLs: true, BaseURL: "baseURL"}, w, document.Options{RenderWorkers: renderWorkers})
+}
+
+func BenchmarkProcessNode(b *testing.B) {
+	node := &manifest.Node{
+		FileType: manifest.FileType{File: "node", MultiSource: parallelSources},
+		Type:     "file",
+		Path:     "one",
+	}
+	for _, renderWorkers := range []int{1, 4} {
+		b.Run(fmt.Sprintf("%d render workers", renderWorkers), func(b *testing.B) {
+			dw := renderingWorker(&writersfakes.FakeWriter{}, renderWorkers)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := dw.ProcessNode(context.TODO(), node); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
 var _ = Describe("Document resolving", func() {
 	var (
 		dw *document.Worker
+		// inaccessibleWorker recreates dw with a policy for links to inaccessible resources
+		inaccessibleWorker func(policy document.InaccessibleLinkPolicy)
 
 		w *writersfakes.FakeWriter
 	)
@@ -43,6 +103,7 @@ var _ = Describe("Document resolving", func() {
 		registry := registry.NewRegistry(repositoryhost.NewLocalTest(manifests, "https://github.com/gardener/docforge", "tests"))
 		hugo := hugo.Hugo{
 			Enabled:        true,
+			PrettyURLs:     true,
 			BaseURL:        "baseURL",
 			IndexFileNames: []string{"readme.md", "readme", "read.me", "index.md", "index"},
 		}
@@ -53,7 +114,7 @@ var _ = Describe("Document resolving", func() {
 			return s1, nil
 		})
 		w = &writersfakes.FakeWriter{}
-		dw = document.NewDocumentWorker("__resources", df, vf, lrf, registry, hugo, w, false)
+		dw = documentWorker(df, vf, lrf, registry, hugo, w, document.Options{})
 	})
 
 	Context("#ProcessNode", func() {
@@ -82,6 +143,29 @@ var _ = Describe("Document resolving", func() {
 			Expect(node).To(Equal(nodegot))
 		})
 
+		It("renders the sources in parallel into the same content as sequentially", func() {
+			node := &manifest.Node{
+				FileType: manifest.FileType{File: "node", MultiSource: parallelSources},
+				Type:     "file",
+				Path:     "one",
+			}
+			sequential := renderingWorker(w, 1)
+			Expect(sequential.ProcessNode(context.TODO(), node)).To(Succeed())
+			parallel := renderingWorker(w, 4)
+			for i := 0; i < 10; i++ {
+				Expect(parallel.ProcessNode(context.TODO(), node)).To(Succeed())
+			}
+			Expect(w.WriteCallCount()).To(Equal(11))
+			_, _, expected, _, _ := w.WriteArgsForCall(0)
+			target, err := manifests.ReadFile("tests/expected_target.md")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(expected)).To(HavePrefix(string(target)))
+			for i := 1; i < 11; i++ {
+				_, _, cnt, _, _ := w.WriteArgsForCall(i)
+				Expect(string(cnt)).To(Equal(string(expected)))
+			}
+		})
+
 		It("returns correct single source content", func() {
 			node := &manifest.Node{
 				FileType: manifest.FileType{
@@ -103,5 +187,577 @@ var _ = Describe("Document resolving", func() {
 			Expect(node).To(Equal(nodegot))
 		})
 
+		It("downloads the resources of nodes with a resources path under that path", func() {
+			df := &downloaderfakes.FakeInterface{}
+			dw = documentWorker(df, &linkvalidatorfakes.FakeInterface{}, &linkresolverfakes.FakeInterface{}, registry.NewRegistry(repositoryhost.NewLocalTest(manifests, "https://github.com/gardener/docforge", "tests")), hugo.Hugo{Enabled: true, PrettyURLs: true, BaseURL: "baseURL"}, w, document.Options{})
+			node := &manifest.Node{
+				FileType: manifest.FileType{
+					File:   "node",
+					Source: "https://github.com/gardener/docforge/blob/master/target2.md",
+				},
+				Type:          "file",
+				Path:          "one",
+				ResourcesPath: "guides",
+			}
+			Expect(dw.ProcessNode(context.TODO(), node)).To(Succeed())
+			Expect(df.ScheduleCallCount()).To(BeNumerically(">", 0))
+			source, target, _ := df.ScheduleArgsForCall(0)
+			Expect(source).To(Equal("https://github.com/gardener/docforge/blob/master/images/gardener-docforge-logo.png"))
+			Expect(target).To(Equal("guides/gardener-docforge-logo_051125.png"))
+			_, _, cnt, _, _ := w.WriteArgsForCall(0)
+			Expect(string(cnt)).To(ContainSubstring("![test3](/baseURL/__resources/guides/gardener-docforge-logo_051125.png)"))
+		})
+
+		It("downloads the resources of nodes with a resources root under that root in the destination", func() {
+			df := &downloaderfakes.FakeInterface{}
+			dw = documentWorker(df, &linkvalidatorfakes.FakeInterface{}, &linkresolverfakes.FakeInterface{}, registry.NewRegistry(repositoryhost.NewLocalTest(manifests, "https://github.com/gardener/docforge", "tests")), hugo.Hugo{Enabled: true, PrettyURLs: true, BaseURL: "baseURL"}, w, document.Options{ResourcesDownloadPath: "static/__resources"})
+			guide := &manifest.Node{
+				FileType:      manifest.FileType{File: "guide", Source: "https://github.com/gardener/docforge/blob/master/target2.md"},
+				Type:          "file",
+				Path:          "guides",
+				ResourcesRoot: "guides",
+				ResourcesPath: "images",
+			}
+			other := &manifest.Node{
+				FileType: manifest.FileType{File: "other", Source: "https://github.com/gardener/docforge/blob/master/target2.md"},
+				Type:     "file",
+				Path:     "one",
+			}
+			Expect(dw.ProcessNode(context.TODO(), guide)).To(Succeed())
+			Expect(dw.ProcessNode(context.TODO(), other)).To(Succeed())
+			Expect(df.ScheduleCallCount()).To(BeNumerically(">", 1))
+			_, target, _ := df.ScheduleArgsForCall(0)
+			Expect(target).To(Equal("../../guides/images/gardener-docforge-logo_051125.png"))
+			_, _, cnt, _, _ := w.WriteArgsForCall(0)
+			Expect(string(cnt)).To(ContainSubstring("![test3](/baseURL/guides/images/gardener-docforge-logo_051125.png)"))
+			// both nodes schedule the same resources
+			_, target, _ = df.ScheduleArgsForCall(df.ScheduleCallCount() / 2)
+			Expect(target).To(Equal("gardener-docforge-logo_051125.png"))
+			_, _, cnt, _, _ = w.WriteArgsForCall(1)
+			Expect(string(cnt)).To(ContainSubstring("![test3](/baseURL/__resources/gardener-docforge-logo_051125.png)"))
+		})
+
+		It("normalizes CRLF line endings to LF", func() {
+			node := &manifest.Node{
+				FileType: manifest.FileType{
+					File:   "node",
+					Source: "https://github.com/gardener/docforge/blob/master/crlf.md",
+				},
+				Type: "file",
+				Path: "one",
+			}
+			err := dw.ProcessNode(context.TODO(), node)
+			Expect(err).ToNot(HaveOccurred())
+			_, _, cnt, _, _ := w.WriteArgsForCall(0)
+			expected, err := manifests.ReadFile("tests/expected_crlf.md")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(cnt)).To(Equal(string(expected)))
+		})
+
+		It("merges manifest frontmatter into the document frontmatter", func() {
+			node := &manifest.Node{
+				FileType: manifest.FileType{
+					File:   "node",
+					Source: "https://github.com/gardener/docforge/blob/master/target.md",
+				},
+				Frontmatter: map[string]interface{}{"title": "Manifest Title", "categories": []interface{}{"docs"}},
+				Type:        "file",
+				Path:        "one",
+			}
+			err := dw.ProcessNode(context.TODO(), node)
+			Expect(err).ToNot(HaveOccurred())
+			_, _, cnt, _, _ := w.WriteArgsForCall(0)
+			Expect(string(cnt)).To(ContainSubstring("title: Manifest Title\n"))
+			Expect(string(cnt)).To(ContainSubstring("categories:\n    - docs\n"))
+			Expect(string(cnt)).NotTo(ContainSubstring("testedFile1"))
+		})
+
+		It("keeps the document frontmatter on conflicts when manifest frontmatter doesn't override", func() {
+			registry := registry.NewRegistry(repositoryhost.NewLocalTest(manifests, "https://github.com/gardener/docforge", "tests"))
+			lrf := &linkresolverfakes.FakeInterface{}
+			lrf.ResolveResourceLinkCalls(func(s1 string, n *manifest.Node, s2 string) (string, error) {
+				return s1, nil
+			})
+			dw = documentWorker(&downloaderfakes.FakeInterface{}, &linkvalidatorfakes.FakeInterface{}, lrf, registry, hugo.Hugo{}, w, document.Options{KeepDocumentFrontmatter: true})
+			node := &manifest.Node{
+				FileType: manifest.FileType{
+					File:   "node",
+					Source: "https://github.com/gardener/docforge/blob/master/target.md",
+				},
+				Frontmatter: map[string]interface{}{"title": "Manifest Title", "categories": []interface{}{"docs"}},
+				Type:        "file",
+				Path:        "one",
+			}
+			err := dw.ProcessNode(context.TODO(), node)
+			Expect(err).ToNot(HaveOccurred())
+			_, _, cnt, _, _ := w.WriteArgsForCall(0)
+			Expect(string(cnt)).To(ContainSubstring("title: testedFile1\n"))
+			Expect(string(cnt)).To(ContainSubstring("categories:\n    - docs\n"))
+			Expect(string(cnt)).NotTo(ContainSubstring("Manifest Title"))
+		})
+
+		DescribeTable("renders definition lists in the configured form", func(form markdown.DefinitionListForm, expected string) {
+			registry := registry.NewRegistry(repositoryhost.NewLocalTest(manifests, "https://github.com/gardener/docforge", "tests"))
+			dw = documentWorker(&downloaderfakes.FakeInterface{}, &linkvalidatorfakes.FakeInterface{}, &linkresolverfakes.FakeInterface{}, registry, hugo.Hugo{}, w, document.Options{KeepDocumentFrontmatter: true, DefinitionLists: string(form)})
+			node := &manifest.Node{
+				FileType: manifest.FileType{File: "node", Source: "https://github.com/gardener/docforge/blob/master/definitions.md"},
+				Type:     "file",
+				Path:     "one",
+			}
+			Expect(dw.ProcessNode(context.TODO(), node)).To(Succeed())
+			_, _, cnt, _, _ := w.WriteArgsForCall(0)
+			Expect(string(cnt)).To(HaveSuffix(expected))
+		},
+			Entry("not parsed", markdown.DefinitionListForm(""), "Term\n: Definition\n"),
+			Entry("markdown", markdown.DefinitionListMarkdown, "Term\n: Definition\n"),
+			Entry("html", markdown.DefinitionListHTML, "<dl>\n<dt>\n\nTerm\n\n</dt>\n<dd>\n\nDefinition\n\n</dd>\n</dl>\n"),
+		)
+
+		DescribeTable("adds source frontmatter from the first source", func(node *manifest.Node) {
+			registry := registry.NewRegistry(repositoryhost.NewLocalTest(manifests, "https://github.com/gardener/docforge", "tests"))
+			lrf := &linkresolverfakes.FakeInterface{}
+			lrf.ResolveResourceLinkCalls(func(s1 string, n *manifest.Node, s2 string) (string, error) {
+				return s1, nil
+			})
+			dw = documentWorker(&downloaderfakes.FakeInterface{}, &linkvalidatorfakes.FakeInterface{}, lrf, registry, hugo.Hugo{}, w, document.Options{SourceFrontmatter: true})
+			err := dw.ProcessNode(context.TODO(), node)
+			Expect(err).ToNot(HaveOccurred())
+			_, _, cnt, _, _ := w.WriteArgsForCall(0)
+			Expect(string(cnt)).To(ContainSubstring("source_url: https://github.com/gardener/docforge/blob/master/target.md\n"))
+			Expect(string(cnt)).To(ContainSubstring("source_repo: https://github.com/gardener/docforge\n"))
+			Expect(string(cnt)).To(ContainSubstring("source_path: target.md\n"))
+			Expect(string(cnt)).To(ContainSubstring("source_ref: master\n"))
+		},
+			Entry("single source", &manifest.Node{
+				FileType: manifest.FileType{File: "node", Source: "https://github.com/gardener/docforge/blob/master/target.md"},
+				Type:     "file",
+				Path:     "one",
+			}),
+			Entry("multiSource", &manifest.Node{
+				FileType: manifest.FileType{File: "node", MultiSource: []string{"https://github.com/gardener/docforge/blob/master/target.md", "https://github.com/gardener/docforge/blob/master/target2.md"}},
+				Type:     "file",
+				Path:     "one",
+			}),
+		)
+
+		It("adds the edit link of the source under the configured key", func() {
+			registry := registry.NewRegistry(repositoryhost.NewLocalTest(manifests, "https://github.com/gardener/docforge", "tests"))
+			lrf := &linkresolverfakes.FakeInterface{}
+			lrf.ResolveResourceLinkCalls(func(s1 string, n *manifest.Node, s2 string) (string, error) {
+				return s1, nil
+			})
+			dw = documentWorker(&downloaderfakes.FakeInterface{}, &linkvalidatorfakes.FakeInterface{}, lrf, registry, hugo.Hugo{}, w, document.Options{EditURLKey: "editURL"})
+			node := &manifest.Node{
+				FileType: manifest.FileType{File: "node", Source: "https://github.com/gardener/docforge/blob/master/target.md"},
+				Type:     "file",
+				Path:     "one",
+			}
+			err := dw.ProcessNode(context.TODO(), node)
+			Expect(err).ToNot(HaveOccurred())
+			_, _, cnt, _, _ := w.WriteArgsForCall(0)
+			Expect(string(cnt)).To(ContainSubstring("editURL: https://github.com/gardener/docforge/edit/master/target.md\n"))
+		})
+
+		It("doesn't add source frontmatter by default", func() {
+			node := &manifest.Node{
+				FileType: manifest.FileType{File: "node", Source: "https://github.com/gardener/docforge/blob/master/target.md"},
+				Type:     "file",
+				Path:     "one",
+			}
+			err := dw.ProcessNode(context.TODO(), node)
+			Expect(err).ToNot(HaveOccurred())
+			_, _, cnt, _, _ := w.WriteArgsForCall(0)
+			Expect(string(cnt)).NotTo(ContainSubstring("source_url"))
+		})
+
+		It("resolves relative links of included content against the included source", func() {
+			registry := registry.NewRegistry(repositoryhost.NewLocalTest(manifests, "https://github.com/gardener/docforge", "tests"))
+			hugo := hugo.Hugo{
+				Enabled:    true,
+				PrettyURLs: true,
+				BaseURL:    "baseURL",
+			}
+			node := &manifest.Node{
+				FileType: manifest.FileType{
+					File:        "node",
+					MultiSource: []string{"https://github.com/gardener/docforge/blob/master/target.md", "https://github.com/gardener/docforge/blob/master/snippets/snippet.md"},
+				},
+				Type: "file",
+				Path: "one",
+			}
+			other := &manifest.Node{
+				FileType: manifest.FileType{
+					File:   "other.md",
+					Source: "https://github.com/gardener/docforge/blob/master/snippets/other.md",
+				},
+				Type: "file",
+				Path: "one",
+			}
+			lr := &linkresolver.LinkResolver{
+				Repositoryhosts: registry,
+				Hugo:            hugo,
+				SourceToNode: map[string][]*manifest.Node{
+					node.MultiSource[0]: {node},
+					node.MultiSource[1]: {node},
+					other.Source:        {other},
+				},
+			}
+			dw = documentWorker(&downloaderfakes.FakeInterface{}, &linkvalidatorfakes.FakeInterface{}, lr, registry, hugo, w, document.Options{})
+			err := dw.ProcessNode(context.TODO(), node)
+			Expect(err).ToNot(HaveOccurred())
+			_, _, cnt, _, _ := w.WriteArgsForCall(0)
+			snippet, err := manifests.ReadFile("tests/expected_snippet.md")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(cnt)).To(HaveSuffix(string(snippet)))
+		})
+
+		It("inlines code snippets referenced by fenced code blocks", func() {
+			registry := registry.NewRegistry(repositoryhost.NewLocalTest(manifests, "https://github.com/gardener/docforge", "tests"))
+			node := &manifest.Node{
+				FileType: manifest.FileType{File: "node", Source: "https://github.com/gardener/docforge/blob/master/snippets/code.md"},
+				Type:     "file",
+				Path:     "one",
+			}
+			dw = documentWorker(&downloaderfakes.FakeInterface{}, &linkvalidatorfakes.FakeInterface{}, &linkresolverfakes.FakeInterface{}, registry, hugo.Hugo{}, w, document.Options{CodeSnippets: true})
+			err := dw.ProcessNode(context.TODO(), node)
+			Expect(err).ToNot(HaveOccurred())
+			_, _, cnt, _, _ := w.WriteArgsForCall(0)
+			Expect(string(cnt)).To(HaveSuffix("```yaml\ndata:\n  key: value\n```\n"))
+		})
+
+		It("inlines code snippets referenced by snippet directives", func() {
+			registry := registry.NewRegistry(repositoryhost.NewLocalTest(manifests, "https://github.com/gardener/docforge", "tests"))
+			node := &manifest.Node{
+				FileType: manifest.FileType{File: "node", Source: "https://github.com/gardener/docforge/blob/master/snippets/directive.md"},
+				Type:     "file",
+				Path:     "one",
+			}
+			dw = documentWorker(&downloaderfakes.FakeInterface{}, &linkvalidatorfakes.FakeInterface{}, &linkresolverfakes.FakeInterface{}, registry, hugo.Hugo{}, w, document.Options{CodeSnippets: true})
+			err := dw.ProcessNode(context.TODO(), node)
+			Expect(err).ToNot(HaveOccurred())
+			_, _, cnt, _, _ := w.WriteArgsForCall(0)
+			Expect(string(cnt)).To(HaveSuffix("# Code\n\n```yaml\ndata:\n  key: value\n```\n"))
+		})
+
+		It("applies content transforms to the documents with matching sources only", func() {
+			transforms := []document.ContentTransform{
+				{Source: `/target\.md$`, Find: `# Tested markdown file (\d)`, Replace: "# Transformed file $1"},
+				{Source: `/target\.md$`, Find: `Transformed`, Replace: "Rewritten"},
+			}
+			registry := registry.NewRegistry(repositoryhost.NewLocalTest(manifests, "https://github.com/gardener/docforge", "tests"))
+			node := &manifest.Node{
+				FileType: manifest.FileType{
+					File:        "node",
+					MultiSource: []string{"https://github.com/gardener/docforge/blob/master/target.md", "https://github.com/gardener/docforge/blob/master/target2.md"},
+				},
+				Type: "file",
+				Path: "one",
+			}
+			dw = documentWorker(&downloaderfakes.FakeInterface{}, &linkvalidatorfakes.FakeInterface{}, &linkresolverfakes.FakeInterface{}, registry, hugo.Hugo{}, w, document.Options{SkipLinkValidation: true, ContentTransforms: transforms})
+			Expect(dw.ProcessNode(context.TODO(), node)).To(Succeed())
+			_, _, cnt, _, _ := w.WriteArgsForCall(0)
+			Expect(string(cnt)).To(ContainSubstring("# Rewritten file 1\n"))
+			Expect(string(cnt)).To(ContainSubstring("# Tested markdown file 2\n"))
+			Expect(string(cnt)).NotTo(ContainSubstring("# Tested markdown file 1\n"))
+		})
+
+		It("fails to create a transformer with an invalid pattern", func() {
+			_, err := document.NewTransformer([]document.ContentTransform{{Source: ".*", Find: "("}})
+			Expect(err).To(MatchError(ContainSubstring("invalid find pattern of content transform 0")))
+		})
+
+		It("writes a stub for nodes with an external url without reading sources", func() {
+			rf := &registryfakes.FakeInterface{}
+			node := &manifest.Node{
+				FileType:    manifest.FileType{File: "slack.md", ExternalURL: "https://gardener-cloud.slack.com"},
+				Frontmatter: map[string]interface{}{"weight": 3},
+				Type:        "file",
+				Path:        "community",
+			}
+			dw = documentWorker(&downloaderfakes.FakeInterface{}, &linkvalidatorfakes.FakeInterface{}, &linkresolverfakes.FakeInterface{}, rf, hugo.Hugo{Enabled: true, PrettyURLs: true}, w, document.Options{})
+			err := dw.ProcessNode(context.TODO(), node)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rf.ReadCallCount()).To(Equal(0))
+			name, path, cnt, _, _ := w.WriteArgsForCall(0)
+			Expect(name).To(Equal("slack.md"))
+			Expect(path).To(Equal("community"))
+			Expect(string(cnt)).To(Equal("---\nexternalURL: https://gardener-cloud.slack.com\nmanualLink: https://gardener-cloud.slack.com\ntitle: slack\nweight: 3\n---\n\n[slack](https://gardener-cloud.slack.com)\n"))
+		})
+	})
+
+	Context("links to inaccessible resources", func() {
+		var (
+			client *httpclientfakes.FakeClient
+			vf     *linkvalidatorfakes.FakeInterface
+			df     *downloaderfakes.FakeInterface
+			node   *manifest.Node
+		)
+		BeforeEach(func() {
+			rf := &registryfakes.FakeInterface{}
+			rf.ReadReturns([]byte("See the [private docs](https://github.com/owner/private/blob/master/docs.md).\n\n![logo](https://github.com/owner/private/blob/master/logo.png)\n"), nil)
+			rf.ResourceURLCalls(func(link string) (*repositoryhost.URL, error) {
+				return nil, repositoryhost.ErrResourceNotFound(link)
+			})
+			client = &httpclientfakes.FakeClient{}
+			client.DoReturns(&http.Response{StatusCode: http.StatusForbidden}, nil)
+			rf.ClientReturns(client)
+			vf = &linkvalidatorfakes.FakeInterface{}
+			df = &downloaderfakes.FakeInterface{}
+			node = &manifest.Node{FileType: manifest.FileType{File: "doc.md", Source: "https://github.com/gardener/docforge/blob/master/doc.md"}, Type: "file", Path: "one"}
+			newWorker := func(policy document.InaccessibleLinkPolicy) {
+				dw = documentWorker(df, vf, &linkresolverfakes.FakeInterface{}, rf, hugo.Hugo{}, w, document.Options{InaccessibleLinks: string(policy)})
+			}
+			newWorker("")
+			inaccessibleWorker = newWorker
+		})
+
+		It("validates the links without a policy", func() {
+			Expect(dw.ProcessNode(context.TODO(), node)).To(Succeed())
+			Expect(client.DoCallCount()).To(Equal(0))
+			Expect(vf.ValidateLinkCallCount()).To(Equal(1))
+			_, _, cnt, _, _ := w.WriteArgsForCall(0)
+			Expect(string(cnt)).To(ContainSubstring("[private docs](https://github.com/owner/private/blob/master/docs.md)"))
+			Expect(string(cnt)).To(ContainSubstring("![logo](https://github.com/owner/private/raw/master/logo.png)"))
+		})
+
+		It("fails the documents with the error policy", func() {
+			inaccessibleWorker(document.InaccessibleLinksError)
+			err := dw.ProcessNode(context.TODO(), node)
+			Expect(err).To(MatchError(ContainSubstring("has link https://github.com/owner/private/blob/master/docs.md to an inaccessible resource: HTTP Status 403")))
+			Expect(w.WriteCallCount()).To(Equal(0))
+		})
+
+		It("keeps the absolute links with the warn-and-keep-absolute policy", func() {
+			inaccessibleWorker(document.InaccessibleLinksWarn)
+			Expect(dw.ProcessNode(context.TODO(), node)).To(Succeed())
+			Expect(client.DoCallCount()).To(Equal(2))
+			req := client.DoArgsForCall(0)
+			Expect(req.Method).To(Equal(http.MethodHead))
+			Expect(vf.ValidateLinkCallCount()).To(Equal(0))
+			_, _, cnt, _, _ := w.WriteArgsForCall(0)
+			Expect(string(cnt)).To(ContainSubstring("[private docs](https://github.com/owner/private/blob/master/docs.md)"))
+			Expect(string(cnt)).To(ContainSubstring("![logo](https://github.com/owner/private/blob/master/logo.png)"))
+		})
+
+		It("drops the links with the drop policy", func() {
+			inaccessibleWorker(document.InaccessibleLinksDrop)
+			Expect(dw.ProcessNode(context.TODO(), node)).To(Succeed())
+			Expect(vf.ValidateLinkCallCount()).To(Equal(0))
+			_, _, cnt, _, _ := w.WriteArgsForCall(0)
+			Expect(string(cnt)).To(Equal("See the private docs.\n\n"))
+		})
+
+		It("handles accessible links as without a policy", func() {
+			client.DoReturns(&http.Response{StatusCode: http.StatusOK}, nil)
+			inaccessibleWorker(document.InaccessibleLinksDrop)
+			Expect(dw.ProcessNode(context.TODO(), node)).To(Succeed())
+			Expect(vf.ValidateLinkCallCount()).To(Equal(1))
+			_, _, cnt, _, _ := w.WriteArgsForCall(0)
+			Expect(string(cnt)).To(ContainSubstring("[private docs](https://github.com/owner/private/blob/master/docs.md)"))
+		})
+	})
+
+	Context("#Coverage", func() {
+		It("collects the outcome of processing document nodes", func() {
+			nodes := []*manifest.Node{
+				{FileType: manifest.FileType{File: "good.md", Source: "https://github.com/gardener/docforge/blob/master/target.md"}, Type: "file", Path: "one"},
+				{FileType: manifest.FileType{File: "empty.md", Source: "https://github.com/gardener/docforge/blob/master/empty.html"}, Type: "file", Path: "one"},
+				{FileType: manifest.FileType{File: "missing.md", Source: "https://github.com/gardener/docforge/blob/master/missing.md"}, Type: "file", Path: "one"},
+				{DirType: manifest.DirType{Dir: "one"}, Type: "dir", Path: "."},
+			}
+			for _, node := range nodes {
+				_ = dw.ProcessNode(context.TODO(), node)
+			}
+			coverage := dw.Coverage()
+			Expect(coverage.Counts()).To(Equal(map[document.CoverageStatus]int{
+				document.CoverageWritten: 1,
+				document.CoverageEmpty:   1,
+				document.CoverageFailed:  1,
+			}))
+			entries := coverage.Entries()
+			Expect(entries).To(HaveLen(3))
+			Expect(entries[0].Node).To(Equal("one/empty.md"))
+			Expect(entries[0].Reason).To(Equal("no content assigned"))
+			Expect(entries[2].Node).To(Equal("one/missing.md"))
+			Expect(entries[2].Status).To(Equal(document.CoverageFailed))
+			Expect(entries[2].Reason).To(ContainSubstring("missing.md"))
+		})
+
+		It("reports documents timing out and processes the others", func() {
+			rf := &registryfakes.FakeInterface{}
+			rf.ReadStub = func(ctx context.Context, source string) ([]byte, error) {
+				if source == "https://github.com/gardener/docforge/blob/master/slow.md" {
+					<-ctx.Done()
+					return nil, ctx.Err()
+				}
+				return []byte("# Fast\n"), nil
+			}
+			dw = documentWorker(&downloaderfakes.FakeInterface{}, &linkvalidatorfakes.FakeInterface{}, &linkresolverfakes.FakeInterface{}, rf, hugo.Hugo{}, w, document.Options{DocumentTimeout: 50 * time.Millisecond})
+			nodes := []*manifest.Node{
+				{FileType: manifest.FileType{File: "slow.md", Source: "https://github.com/gardener/docforge/blob/master/slow.md"}, Type: "file", Path: "one"},
+				{FileType: manifest.FileType{File: "fast.md", Source: "https://github.com/gardener/docforge/blob/master/fast.md"}, Type: "file", Path: "one"},
+			}
+			for _, node := range nodes {
+				Expect(dw.ProcessNode(context.TODO(), node)).To(Succeed())
+			}
+			Expect(w.WriteCallCount()).To(Equal(1))
+			name, _, cnt, _, _ := w.WriteArgsForCall(0)
+			Expect(name).To(Equal("fast.md"))
+			Expect(string(cnt)).To(ContainSubstring("# Fast"))
+			entries := dw.Coverage().Entries()
+			Expect(entries).To(HaveLen(2))
+			Expect(entries[1].Node).To(Equal("one/slow.md"))
+			Expect(entries[1].Status).To(Equal(document.CoverageFailed))
+			Expect(entries[1].Reason).To(Equal("timed out after 50ms"))
+		})
+	})
+
+	Context("#LLMsIndex", func() {
+		It("lists the titles and links of the written documents", func() {
+			dw.LLMsIndex().Full = true
+			nodes := []*manifest.Node{
+				{FileType: manifest.FileType{File: "second-page.md", Source: "https://github.com/gardener/docforge/blob/master/target2.md"}, Type: "file", Path: "one"},
+				{FileType: manifest.FileType{File: "first.md", Source: "https://github.com/gardener/docforge/blob/master/target.md"}, Type: "file", Path: "one"},
+				{FileType: manifest.FileType{File: "missing.md", Source: "https://github.com/gardener/docforge/blob/master/missing.md"}, Type: "file", Path: "one"},
+			}
+			for _, node := range nodes {
+				_ = dw.ProcessNode(context.TODO(), node)
+			}
+			Expect(string(dw.LLMsIndex().Index("Docs"))).To(Equal("# Docs\n\n- [testedFile1](/baseURL/one/first/)\n- [Second Page](/baseURL/one/second-page/)\n"))
+			full := string(dw.LLMsIndex().FullIndex("Docs"))
+			Expect(full).To(HavePrefix("# Docs\n\n## [testedFile1](/baseURL/one/first/)\n\n# Tested markdown file 1\n"))
+			Expect(full).To(ContainSubstring("\n## [Second Page](/baseURL/one/second-page/)\n\n# Tested markdown file 2\n"))
+			Expect(full).NotTo(ContainSubstring("title:"))
+		})
+	})
+
+	DescribeTable("inlines images up to the max size", func(maxSize int, inlined bool) {
+		df := &downloaderfakes.FakeInterface{}
+		dw = documentWorker(df, &linkvalidatorfakes.FakeInterface{}, &linkresolverfakes.FakeInterface{}, registry.NewRegistry(repositoryhost.NewLocalTest(manifests, "https://github.com/gardener/docforge", "tests")), hugo.Hugo{Enabled: true, PrettyURLs: true}, w, document.Options{InlineImagesMaxSize: maxSize})
+		node := &manifest.Node{FileType: manifest.FileType{File: "node", Source: "https://github.com/gardener/docforge/blob/master/target2.md"}, Type: "file", Path: "one"}
+		Expect(dw.ProcessNode(context.TODO(), node)).To(Succeed())
+		_, _, cnt, _, _ := w.WriteArgsForCall(0)
+		if inlined {
+			logo, err := manifests.ReadFile("tests/images/gardener-docforge-logo.png")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(cnt)).To(ContainSubstring("![test3](data:image/png;base64," + base64.StdEncoding.EncodeToString(logo) + ")"))
+			Expect(df.ScheduleCallCount()).To(Equal(0))
+		} else {
+			Expect(string(cnt)).To(ContainSubstring("![test3](/__resources/gardener-docforge-logo_051125.png)"))
+			Expect(df.ScheduleCallCount()).To(Equal(2))
+		}
+	},
+		Entry("under the max size", 20000, true),
+		Entry("over the max size", 1000, false),
+	)
+
+	It("inlines SVG images up to the max size without their scripts", func() {
+		df := &downloaderfakes.FakeInterface{}
+		dw = documentWorker(df, &linkvalidatorfakes.FakeInterface{}, &linkresolverfakes.FakeInterface{}, registry.NewRegistry(repositoryhost.NewLocalTest(manifests, "https://github.com/gardener/docforge", "tests")), hugo.Hugo{Enabled: true, PrettyURLs: true}, w, document.Options{InlineSVGsMaxSize: 1000})
+		node := &manifest.Node{FileType: manifest.FileType{File: "icons.md", Source: "https://github.com/gardener/docforge/blob/master/icons.md"}, Type: "file", Path: "one"}
+		Expect(dw.ProcessNode(context.TODO(), node)).To(Succeed())
+		_, _, cnt, _, _ := w.WriteArgsForCall(0)
+		svg := `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 16 16"> <circle cx="8" cy="8" r="8"></circle> </svg>`
+		Expect(string(cnt)).To(ContainSubstring(svg + " Small\n"))
+		Expect(string(cnt)).To(ContainSubstring("<p>" + svg + "</p>"))
+		Expect(string(cnt)).To(ContainSubstring("![large](/__resources/large_"))
+		Expect(df.ScheduleCallCount()).To(Equal(1))
+		link, _, _ := df.ScheduleArgsForCall(0)
+		Expect(link).To(Equal("https://github.com/gardener/docforge/blob/master/images/large.svg"))
+	})
+
+	It("keeps the links to badges absolute", func() {
+		df := &downloaderfakes.FakeInterface{}
+		dw = documentWorker(df, &linkvalidatorfakes.FakeInterface{}, &linkresolverfakes.FakeInterface{}, registry.NewRegistry(repositoryhost.NewLocalTest(manifests, "https://github.com/gardener/docforge", "tests")), hugo.Hugo{Enabled: true, PrettyURLs: true}, w, document.Options{InlineImagesMaxSize: 20000, InlineSVGsMaxSize: 1000, BadgeHosts: []string{"*.shields.io", "github.com/*/*/actions/workflows/*/badge.svg"}})
+		node := &manifest.Node{FileType: manifest.FileType{File: "badges.md", Source: "https://github.com/gardener/docforge/blob/master/badges.md"}, Type: "file", Path: "one"}
+		Expect(dw.ProcessNode(context.TODO(), node)).To(Succeed())
+		_, _, cnt, _, _ := w.WriteArgsForCall(0)
+		Expect(string(cnt)).To(ContainSubstring("[![build](https://github.com/gardener/docforge/actions/workflows/build.yaml/badge.svg)]"))
+		Expect(string(cnt)).To(ContainSubstring("![license](https://img.shields.io/badge/license-Apache--2.0-blue.svg)"))
+		Expect(string(cnt)).To(ContainSubstring("![logo](data:image/png;base64,"))
+		Expect(df.ScheduleCallCount()).To(Equal(0))
+	})
+
+	It("downloads the images of other hosts than the badge hosts", func() {
+		df := &downloaderfakes.FakeInterface{}
+		dw = documentWorker(df, &linkvalidatorfakes.FakeInterface{}, &linkresolverfakes.FakeInterface{}, registry.NewRegistry(repositoryhost.NewLocalTest(manifests, "https://github.com/gardener/docforge", "tests")), hugo.Hugo{Enabled: true, PrettyURLs: true}, w, document.Options{BadgeHosts: []string{"*.shields.io"}})
+		node := &manifest.Node{FileType: manifest.FileType{File: "badges.md", Source: "https://github.com/gardener/docforge/blob/master/badges.md"}, Type: "file", Path: "one"}
+		Expect(dw.ProcessNode(context.TODO(), node)).To(Succeed())
+		_, _, cnt, _, _ := w.WriteArgsForCall(0)
+		Expect(string(cnt)).To(ContainSubstring("![license](https://img.shields.io/badge/license-Apache--2.0-blue.svg)"))
+		Expect(string(cnt)).To(ContainSubstring("![logo](/__resources/gardener-docforge-logo_051125.png)"))
+		Expect(df.ScheduleCallCount()).To(Equal(1))
+		link, _, _ := df.ScheduleArgsForCall(0)
+		Expect(link).To(Equal("https://github.com/gardener/docforge/blob/master/images/gardener-docforge-logo.png"))
+	})
+
+	Context("linting markdown documents", func() {
+		var (
+			node   *manifest.Node
+			source []byte
+		)
+		BeforeEach(func() {
+			node = &manifest.Node{FileType: manifest.FileType{File: "lint.md", Source: "https://github.com/gardener/docforge/blob/master/lint.md"}, Type: "file", Path: "one"}
+			var err error
+			source, err = manifests.ReadFile("tests/lint.md")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("reports the violations of the rules with their lines", func() {
+			rules, err := document.NewLintRules(map[string]string{document.LintMissingHeading: "warning", document.LintImageAlt: "error", document.LintBareURL: "warning"})
+			Expect(err).NotTo(HaveOccurred())
+			doc, err := markdown.Parse(markdown.New(), source)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(rules.Lint(doc, source)).To(Equal([]document.LintViolation{
+				{Rule: document.LintMissingHeading, Severity: document.LintWarning, Line: 1, Message: "document has no top-level heading"},
+				{Rule: document.LintBareURL, Severity: document.LintWarning, Line: 7, Message: "URL https://github.com/gardener/docforge is not written as a link"},
+				{Rule: document.LintImageAlt, Severity: document.LintError, Line: 9, Message: "image images/gardener-docforge-logo.png has no alt text"},
+			}))
+		})
+
+		It("fails the documents violating rules with error severity", func() {
+			rules := map[string]string{document.LintImageAlt: "error", document.LintBareURL: "error"}
+			dw = documentWorker(&downloaderfakes.FakeInterface{}, &linkvalidatorfakes.FakeInterface{}, &linkresolverfakes.FakeInterface{}, registry.NewRegistry(repositoryhost.NewLocalTest(manifests, "https://github.com/gardener/docforge", "tests")), hugo.Hugo{Enabled: true, PrettyURLs: true}, w, document.Options{LintRules: rules})
+			err := dw.ProcessNode(context.TODO(), node)
+			Expect(err).To(MatchError(ContainSubstring("line 7: URL https://github.com/gardener/docforge is not written as a link (bare-url)\nline 9: image images/gardener-docforge-logo.png has no alt text (image-alt)")))
+			Expect(w.WriteCallCount()).To(Equal(0))
+		})
+
+		It("writes the documents violating rules with warning severity", func() {
+			rules := map[string]string{document.LintMissingHeading: "warning"}
+			dw = documentWorker(&downloaderfakes.FakeInterface{}, &linkvalidatorfakes.FakeInterface{}, &linkresolverfakes.FakeInterface{}, registry.NewRegistry(repositoryhost.NewLocalTest(manifests, "https://github.com/gardener/docforge", "tests")), hugo.Hugo{Enabled: true, PrettyURLs: true}, w, document.Options{LintRules: rules})
+			Expect(dw.ProcessNode(context.TODO(), node)).To(Succeed())
+			Expect(w.WriteCallCount()).To(Equal(1))
+		})
+
+		It("rejects unknown rules and severities", func() {
+			_, err := document.NewLintRules(map[string]string{"line-length": "error"})
+			Expect(err).To(MatchError("lint rule can be missing-heading, image-alt or bare-url, not line-length"))
+			_, err = document.NewLintRules(map[string]string{document.LintBareURL: "info"})
+			Expect(err).To(MatchError("lint rule bare-url can have severity warning or error, not info"))
+		})
+	})
+
+	Context("served under a base path", func() {
+		It("prefixes the links to resources and the llms.txt links", func() {
+			df := &downloaderfakes.FakeInterface{}
+			dw = documentWorker(df, &linkvalidatorfakes.FakeInterface{}, &linkresolverfakes.FakeInterface{}, registry.NewRegistry(repositoryhost.NewLocalTest(manifests, "https://github.com/gardener/docforge", "tests")), hugo.Hugo{Enabled: true, PrettyURLs: true}, w, document.Options{BasePath: "/docs/"})
+			node := &manifest.Node{FileType: manifest.FileType{File: "second-page.md", Source: "https://github.com/gardener/docforge/blob/master/target2.md"}, Type: "file", Path: "one"}
+			Expect(dw.ProcessNode(context.TODO(), node)).To(Succeed())
+			_, _, cnt, _, _ := w.WriteArgsForCall(0)
+			Expect(string(cnt)).To(ContainSubstring("![test3](/docs/__resources/gardener-docforge-logo_051125.png)"))
+			Expect(string(dw.LLMsIndex().Index("Docs"))).To(Equal("# Docs\n\n- [Second Page](/docs/one/second-page/)\n"))
+		})
 	})
+
+	DescribeTable("rejects invalid options", func(options document.Options, expected string) {
+		_, err := document.NewDocumentWorker("__resources", &downloaderfakes.FakeInterface{}, &linkvalidatorfakes.FakeInterface{}, &linkresolverfakes.FakeInterface{}, &registryfakes.FakeInterface{}, hugo.Hugo{}, w, options)
+		Expect(err).To(MatchError(expected))
+	},
+		Entry("definition lists", document.Options{DefinitionLists: "table"}, "definition lists can be rendered as markdown or html, not as table"),
+		Entry("bullet list marker", document.Options{BulletListMarker: "#"}, "bullet list marker can be -, * or +, not #"),
+		Entry("inaccessible links", document.Options{InaccessibleLinks: "ignore"}, "links to inaccessible resources can be treated with error, warn-and-keep-absolute or drop, not with ignore"),
+		Entry("base path", document.Options{BasePath: "https://example.com/docs"}, "base path must be a path like /docs, not https://example.com/docs"),
+		Entry("title case", document.Options{TitleCase: "upper"}, "title case can be title, sentence or as-is, not upper"),
+	)
 })
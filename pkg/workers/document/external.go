@@ -0,0 +1,43 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package document
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/gardener/docforge/pkg/manifest"
+	"gopkg.in/yaml.v3"
+)
+
+// externalURLStub returns the content of a node that only links to its external URL.
+// The URL is added to the node frontmatter as externalURL and for hugo also as manualLink,
+// which makes themes like Docsy link the menu entry of the node directly to the URL
+func externalURLStub(node *manifest.Node, hugoEnabled bool) ([]byte, error) {
+	fm := map[string]interface{}{}
+	for k, v := range node.Frontmatter {
+		fm[k] = v
+	}
+	title, _ := fm["title"].(string)
+	if title == "" {
+		title = strings.TrimSuffix(node.Name(), ".md")
+		fm["title"] = title
+	}
+	fm["externalURL"] = node.ExternalURL
+	if _, ok := fm["manualLink"]; hugoEnabled && !ok {
+		fm["manualLink"] = node.ExternalURL
+	}
+	out, err := yaml.Marshal(fm)
+	if err != nil {
+		return nil, fmt.Errorf("marshaling frontmatter of node %s failed: %w", node.NodePath(), err)
+	}
+	b := bytes.Buffer{}
+	b.WriteString("---\n")
+	b.Write(out)
+	b.WriteString("---\n\n")
+	fmt.Fprintf(&b, "[%s](%s)\n", title, node.ExternalURL)
+	return b.Bytes(), nil
+}
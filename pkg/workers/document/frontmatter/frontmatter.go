@@ -9,8 +9,7 @@ import (
 	"strings"
 
 	"github.com/gardener/docforge/pkg/manifest"
-	"golang.org/x/text/cases"
-	"golang.org/x/text/language"
+	"github.com/gardener/docforge/pkg/registry/repositoryhost"
 )
 
 //go:generate go run github.com/maxbrunsfeld/counterfeiter/v6 -generate -header ../../../../license_prefix.txt
@@ -38,12 +37,17 @@ func MoveMultiSourceFrontmatterToTopDocument(dc []NodeMeta) {
 	dc[0].SetMeta(aggregated)
 }
 
-// MergeDocumentAndNodeFrontmatter merges frontmatter from document and node object
-func MergeDocumentAndNodeFrontmatter(nodeAst NodeMeta, node *manifest.Node) {
+// MergeDocumentAndNodeFrontmatter merges frontmatter from document and node object.
+// Node frontmatter overrides document frontmatter for conflicting keys when nodeOverrides is true,
+// otherwise the document frontmatter is kept. Aliases are always merged.
+func MergeDocumentAndNodeFrontmatter(nodeAst NodeMeta, node *manifest.Node, nodeOverrides bool) {
 	if nodeAst == nil || node == nil {
 		return
 	}
 	docFrontmatter := nodeAst.Meta()
+	if docFrontmatter == nil {
+		docFrontmatter = map[string]interface{}{}
+	}
 	for k, v := range node.Frontmatter {
 		if k == "aliases" && docFrontmatter["aliases"] != nil {
 			asArray1, _ := docFrontmatter["aliases"].([]interface{})
@@ -53,18 +57,57 @@ func MergeDocumentAndNodeFrontmatter(nodeAst NodeMeta, node *manifest.Node) {
 
 			}
 			docFrontmatter["aliases"] = asArray2
-		} else {
+		} else if _, ok := docFrontmatter[k]; !ok || nodeOverrides {
+			docFrontmatter[k] = v
+		}
+	}
+	nodeAst.SetMeta(docFrontmatter)
+}
+
+// AddSourceFrontmatter adds the `source_url`, `source_repo`, `source_path` and `source_ref`
+// keys describing the origin of the document, keeping keys already set in the frontmatter
+func AddSourceFrontmatter(nodeAst NodeMeta, source *repositoryhost.URL) {
+	if nodeAst == nil || source == nil {
+		return
+	}
+	docFrontmatter := nodeAst.Meta()
+	if docFrontmatter == nil {
+		docFrontmatter = map[string]interface{}{}
+	}
+	sourceFrontmatter := map[string]interface{}{
+		"source_url":  source.String(),
+		"source_repo": fmt.Sprintf("https://%s/%s/%s", source.GetHost(), source.GetOwner(), source.GetRepo()),
+		"source_path": source.GetResourcePath(),
+		"source_ref":  source.GetRef(),
+	}
+	for k, v := range sourceFrontmatter {
+		if _, ok := docFrontmatter[k]; !ok {
 			docFrontmatter[k] = v
 		}
 	}
 	nodeAst.SetMeta(docFrontmatter)
 }
 
+// AddEditURL adds the edit link of the document source under the given key, keeping a key already set in the frontmatter
+func AddEditURL(nodeAst NodeMeta, key string, editURL string) {
+	if nodeAst == nil || key == "" || editURL == "" {
+		return
+	}
+	docFrontmatter := nodeAst.Meta()
+	if docFrontmatter == nil {
+		docFrontmatter = map[string]interface{}{}
+	}
+	if _, ok := docFrontmatter[key]; !ok {
+		docFrontmatter[key] = editURL
+	}
+	nodeAst.SetMeta(docFrontmatter)
+}
+
 // ComputeNodeTitle Determines node title from its name or its parent name if
 // it is eligible to be index file, and then normalizes either
-// as a title - removing `-`, `_`, `.md` and converting to title
-// case.
-func ComputeNodeTitle(nodeAst NodeMeta, node *manifest.Node, IndexFileNames []string, hugoEnabled bool) {
+// as a title - removing `-`, `_`, `.md` and applying the title
+// rules.
+func ComputeNodeTitle(nodeAst NodeMeta, node *manifest.Node, IndexFileNames []string, hugoEnabled bool, rules TitleRules) {
 	if !hugoEnabled || nodeAst == nil {
 		return
 	}
@@ -80,10 +123,7 @@ func ComputeNodeTitle(nodeAst NodeMeta, node *manifest.Node, IndexFileNames []st
 		// root index node
 		title = "Root"
 	}
-	title = strings.TrimSuffix(title, ".md")
-	title = strings.ReplaceAll(title, "_", " ")
-	title = strings.ReplaceAll(title, "-", " ")
-	title = cases.Title(language.English).String(title)
+	title = rules.Title(title)
 	if _, ok := docFrontmatter["title"]; !ok {
 		docFrontmatter["title"] = title
 	}
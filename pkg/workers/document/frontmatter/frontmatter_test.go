@@ -18,6 +18,7 @@ import (
 	"github.com/gardener/docforge/pkg/workers/document/frontmatter/frontmatterfakes"
 
 	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
 )
 
@@ -78,7 +79,7 @@ var _ = Describe("Document frontmatter", func() {
 		BeforeEach(func() {
 			r := registry.NewRegistry(repositoryhost.NewLocalTest(manifests, "https://github.com/gardener/docforge", "tests"))
 			contentFileFormats := []string{".md"}
-			nodes, err = manifest.ResolveManifest("https://github.com/gardener/docforge/blob/master/frontmatter.yaml", r, contentFileFormats)
+			nodes, err = manifest.ResolveManifest("https://github.com/gardener/docforge/blob/master/frontmatter.yaml", r, contentFileFormats, manifest.Options{})
 			Expect(err).NotTo(HaveOccurred())
 			Expect(len(nodes)).To(Equal(3))
 			Expect(nodes[1].Name()).To(Equal("foo.md"))
@@ -103,14 +104,14 @@ var _ = Describe("Document frontmatter", func() {
 		It("doesn't change anything if node is nil", func() {
 			node = nil
 
-			frontmatter.MergeDocumentAndNodeFrontmatter(nodeAst, node)
+			frontmatter.MergeDocumentAndNodeFrontmatter(nodeAst, node, true)
 			Expect(nodeAst.SetMetaCallCount()).To(Equal(0))
 
 		})
 		It("doesn't change anything if node has no frontmatter", func() {
 			node = nodes[1]
 
-			frontmatter.MergeDocumentAndNodeFrontmatter(nodeAst, node)
+			frontmatter.MergeDocumentAndNodeFrontmatter(nodeAst, node, true)
 
 			setMeta := nodeAst.SetMetaArgsForCall(0)
 			Expect(reflect.DeepEqual(setMeta, map[string]interface{}{
@@ -130,7 +131,7 @@ var _ = Describe("Document frontmatter", func() {
 		It("aliases get merged and node overrides all other", func() {
 			node = nodes[2]
 
-			frontmatter.MergeDocumentAndNodeFrontmatter(nodeAst, node)
+			frontmatter.MergeDocumentAndNodeFrontmatter(nodeAst, node, true)
 
 			setMeta := nodeAst.SetMetaArgsForCall(0)
 			Expect(reflect.DeepEqual(setMeta, map[string]interface{}{
@@ -149,6 +150,76 @@ var _ = Describe("Document frontmatter", func() {
 				"baz": "node_bazVal",
 			})).To(Equal(true))
 		})
+		It("aliases get merged and document keeps conflicting keys when node doesn't override", func() {
+			node = nodes[2]
+
+			frontmatter.MergeDocumentAndNodeFrontmatter(nodeAst, node, false)
+
+			setMeta := nodeAst.SetMetaArgsForCall(0)
+			Expect(reflect.DeepEqual(setMeta, map[string]interface{}{
+				"foo": "file_fooVal",
+				"aliases": []interface{}{
+					"node_alias1",
+					"node_alias2",
+					"file_alias1",
+					"file_alias2",
+				},
+				"bar": "file_barVal",
+				"barArray": []interface{}{
+					"file_bar1",
+					"file_bar2",
+					"file_bar3",
+				},
+				"baz": "node_bazVal",
+			})).To(Equal(true))
+		})
+		It("sets node frontmatter on document without frontmatter", func() {
+			node = nodes[2]
+			nodeAst.MetaReturns(nil)
+
+			frontmatter.MergeDocumentAndNodeFrontmatter(nodeAst, node, true)
+
+			setMeta := nodeAst.SetMetaArgsForCall(0)
+			Expect(setMeta).To(HaveKeyWithValue("bar", "node_barVal"))
+			Expect(setMeta).To(HaveKeyWithValue("baz", "node_bazVal"))
+		})
+	})
+	Context("#AddSourceFrontmatter", func() {
+		It("adds the source keys keeping existing ones", func() {
+			nodeAst := &frontmatterfakes.FakeNodeMeta{}
+			nodeAst.MetaReturns(map[string]interface{}{"source_url": "custom"})
+			r := registry.NewRegistry(repositoryhost.NewLocalTest(manifests, "https://github.com/gardener/docforge", "tests"))
+			source, err := r.ResourceURL("https://github.com/gardener/docforge/blob/master/frontmatter.yaml")
+			Expect(err).NotTo(HaveOccurred())
+
+			frontmatter.AddSourceFrontmatter(nodeAst, source)
+
+			setMeta := nodeAst.SetMetaArgsForCall(0)
+			Expect(setMeta).To(HaveKeyWithValue("source_url", "custom"))
+			Expect(setMeta).To(HaveKeyWithValue("source_repo", "https://github.com/gardener/docforge"))
+			Expect(setMeta).To(HaveKeyWithValue("source_path", "frontmatter.yaml"))
+			Expect(setMeta).To(HaveKeyWithValue("source_ref", "master"))
+		})
+	})
+	Context("#AddEditURL", func() {
+		It("adds the edit link under the given key", func() {
+			nodeAst := &frontmatterfakes.FakeNodeMeta{}
+			nodeAst.MetaReturns(map[string]interface{}{"title": "Page"})
+
+			frontmatter.AddEditURL(nodeAst, "editURL", "https://github.com/gardener/docforge/edit/master/page.md")
+
+			setMeta := nodeAst.SetMetaArgsForCall(0)
+			Expect(setMeta).To(HaveKeyWithValue("title", "Page"))
+			Expect(setMeta).To(HaveKeyWithValue("editURL", "https://github.com/gardener/docforge/edit/master/page.md"))
+		})
+		It("keeps an edit link set in the frontmatter", func() {
+			nodeAst := &frontmatterfakes.FakeNodeMeta{}
+			nodeAst.MetaReturns(map[string]interface{}{"edit": "custom"})
+
+			frontmatter.AddEditURL(nodeAst, "edit", "https://github.com/gardener/docforge/edit/master/page.md")
+
+			Expect(nodeAst.SetMetaArgsForCall(0)).To(HaveKeyWithValue("edit", "custom"))
+		})
 	})
 	Context("#ComputeNodeTitle", func() {
 		var (
@@ -162,7 +233,7 @@ var _ = Describe("Document frontmatter", func() {
 		BeforeEach(func() {
 			r := registry.NewRegistry(repositoryhost.NewLocalTest(manifests, "https://github.com/gardener/docforge", "tests"))
 			contentFileFormats := []string{".md"}
-			nodes, err = manifest.ResolveManifest("https://github.com/gardener/docforge/blob/master/titles.yaml", r, contentFileFormats)
+			nodes, err = manifest.ResolveManifest("https://github.com/gardener/docforge/blob/master/titles.yaml", r, contentFileFormats, manifest.Options{})
 			Expect(err).NotTo(HaveOccurred())
 			Expect(len(nodes)).To(Equal(6))
 			Expect(nodes[1].Name()).To(Equal("file_node-1.md"))
@@ -175,7 +246,7 @@ var _ = Describe("Document frontmatter", func() {
 		Context("top level node", func() {
 			It("removes _,- and .md in the general case", func() {
 				node = nodes[1]
-				frontmatter.ComputeNodeTitle(nodeAst, node, indexFileNames, hugoEnabled)
+				frontmatter.ComputeNodeTitle(nodeAst, node, indexFileNames, hugoEnabled, frontmatter.TitleRules{})
 				setMeta := nodeAst.SetMetaArgsForCall(0)
 				Expect(setMeta).To(Equal(map[string]interface{}{
 					"title": "File Node 1",
@@ -183,7 +254,7 @@ var _ = Describe("Document frontmatter", func() {
 			})
 			It("has title Root if file is index", func() {
 				node = nodes[2]
-				frontmatter.ComputeNodeTitle(nodeAst, node, indexFileNames, hugoEnabled)
+				frontmatter.ComputeNodeTitle(nodeAst, node, indexFileNames, hugoEnabled, frontmatter.TitleRules{})
 				setMeta := nodeAst.SetMetaArgsForCall(0)
 				Expect(setMeta).To(Equal(map[string]interface{}{
 					"title": "Root",
@@ -192,7 +263,7 @@ var _ = Describe("Document frontmatter", func() {
 			Context("node with parent", func() {
 				It("removes _,- and .md in the general case", func() {
 					node = nodes[4]
-					frontmatter.ComputeNodeTitle(nodeAst, node, indexFileNames, hugoEnabled)
+					frontmatter.ComputeNodeTitle(nodeAst, node, indexFileNames, hugoEnabled, frontmatter.TitleRules{})
 					setMeta := nodeAst.SetMetaArgsForCall(0)
 					Expect(setMeta).To(Equal(map[string]interface{}{
 						"title": "File Node 2",
@@ -200,7 +271,7 @@ var _ = Describe("Document frontmatter", func() {
 				})
 				It("uses parents name if file is index", func() {
 					node = nodes[5]
-					frontmatter.ComputeNodeTitle(nodeAst, node, indexFileNames, hugoEnabled)
+					frontmatter.ComputeNodeTitle(nodeAst, node, indexFileNames, hugoEnabled, frontmatter.TitleRules{})
 					setMeta := nodeAst.SetMetaArgsForCall(0)
 					Expect(setMeta).To(Equal(map[string]interface{}{
 						"title": "Parent Dir",
@@ -209,6 +280,39 @@ var _ = Describe("Document frontmatter", func() {
 			})
 
 		})
+		It("applies the title rules", func() {
+			rules, err := frontmatter.NewTitleRules("sentence", []string{"NODE"}, nil)
+			Expect(err).NotTo(HaveOccurred())
+			frontmatter.ComputeNodeTitle(nodeAst, nodes[5], indexFileNames, hugoEnabled, rules)
+			setMeta := nodeAst.SetMetaArgsForCall(0)
+			Expect(setMeta).To(Equal(map[string]interface{}{
+				"title": "Parent dir",
+			}))
+			frontmatter.ComputeNodeTitle(nodeAst, nodes[4], indexFileNames, hugoEnabled, rules)
+			setMeta = nodeAst.SetMetaArgsForCall(1)
+			Expect(setMeta).To(Equal(map[string]interface{}{
+				"title": "File NODE 2",
+			}))
+		})
+	})
+	Context("#TitleRules", func() {
+		It("rejects unknown cases", func() {
+			_, err := frontmatter.NewTitleRules("upper", nil, nil)
+			Expect(err).To(MatchError(ContainSubstring("title case can be title, sentence or as-is, not upper")))
+		})
+		DescribeTable("deriving titles",
+			func(titleCase string, acronyms []string, replacements map[string]string, name string, title string) {
+				rules, err := frontmatter.NewTitleRules(titleCase, acronyms, replacements)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(rules.Title(name)).To(Equal(title))
+			},
+			Entry("title case by default", "", nil, nil, "getting_started-guide.md", "Getting Started Guide"),
+			Entry("title case preserving acronyms", "title", []string{"API", "gRPC"}, nil, "api-reference_for-grpc.md", "API Reference For gRPC"),
+			Entry("sentence case", "sentence", nil, nil, "Getting-Started_Guide.md", "Getting started guide"),
+			Entry("sentence case preserving acronyms", "sentence", []string{"API", "CLI"}, nil, "cli_and-api-usage.md", "CLI and API usage"),
+			Entry("as-is case", "as-is", nil, nil, "getting-Started_guide.md", "getting Started guide"),
+			Entry("replacements", "sentence", []string{"API"}, map[string]string{"K8S": "Kubernetes", "howto": "How-To"}, "howto-use-k8s-api.md", "How-To use Kubernetes API"),
+		)
 	})
 
 })
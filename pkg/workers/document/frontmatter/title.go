@@ -0,0 +1,94 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package frontmatter
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+)
+
+// TitleCase defines the case of the titles derived from node names
+type TitleCase string
+
+const (
+	// TitleCaseTitle capitalizes all words, like File Node Title
+	TitleCaseTitle TitleCase = "title"
+	// TitleCaseSentence capitalizes the first word and lowercases the others, like File node title
+	TitleCaseSentence TitleCase = "sentence"
+	// TitleCaseAsIs keeps the words as they are written in the node name, like file Node title
+	TitleCaseAsIs TitleCase = "as-is"
+)
+
+// TitleRules defines how titles are derived from node names
+type TitleRules struct {
+	// Case is the case of the words, title by default
+	Case TitleCase
+	// Acronyms are words written as listed, like API or gRPC, regardless of the case
+	Acronyms map[string]string
+	// Replacements are words replaced by the mapped text, like k8s by Kubernetes, regardless of the case
+	Replacements map[string]string
+}
+
+// NewTitleRules validates the case and indexes the acronyms and replacements by their lowercase words
+func NewTitleRules(titleCase string, acronyms []string, replacements map[string]string) (TitleRules, error) {
+	rules := TitleRules{
+		Case:         TitleCase(titleCase),
+		Acronyms:     map[string]string{},
+		Replacements: map[string]string{},
+	}
+	if rules.Case == "" {
+		rules.Case = TitleCaseTitle
+	}
+	if rules.Case != TitleCaseTitle && rules.Case != TitleCaseSentence && rules.Case != TitleCaseAsIs {
+		return TitleRules{}, fmt.Errorf("title case can be %s, %s or %s, not %s", TitleCaseTitle, TitleCaseSentence, TitleCaseAsIs, titleCase)
+	}
+	for _, acronym := range acronyms {
+		rules.Acronyms[strings.ToLower(acronym)] = acronym
+	}
+	for word, replacement := range replacements {
+		rules.Replacements[strings.ToLower(word)] = replacement
+	}
+	return rules, nil
+}
+
+// Title derives a title from a node name: removes `.md`, replaces `-` and `_` with spaces and applies the
+// replacements, acronyms and case to the words
+func (rules TitleRules) Title(name string) string {
+	name = strings.TrimSuffix(name, ".md")
+	name = strings.ReplaceAll(name, "_", " ")
+	name = strings.ReplaceAll(name, "-", " ")
+	caser := cases.Title(language.English)
+	lower := cases.Lower(language.English)
+	words := strings.Split(name, " ")
+	first := true
+	for i, word := range words {
+		if word == "" {
+			continue
+		}
+		key := strings.ToLower(word)
+		if replacement, ok := rules.Replacements[key]; ok {
+			words[i] = replacement
+		} else if acronym, ok := rules.Acronyms[key]; ok {
+			words[i] = acronym
+		} else {
+			switch rules.Case {
+			case TitleCaseAsIs:
+			case TitleCaseSentence:
+				if first {
+					words[i] = caser.String(word)
+				} else {
+					words[i] = lower.String(word)
+				}
+			default:
+				words[i] = caser.String(word)
+			}
+		}
+		first = false
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,92 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package document
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/gardener/docforge/pkg/registry/repositoryhost"
+	"github.com/gardener/docforge/pkg/workers/document/markdown"
+	"k8s.io/klog/v2"
+)
+
+// InaccessibleLinkPolicy defines the treatment of links to resources of repositories that respond with HTTP status
+// 401, 403 or 404, like the resources of private repositories
+type InaccessibleLinkPolicy string
+
+const (
+	// InaccessibleLinksError fails the documents with links to inaccessible resources
+	InaccessibleLinksError InaccessibleLinkPolicy = "error"
+	// InaccessibleLinksWarn keeps the absolute links to inaccessible resources with a warning
+	InaccessibleLinksWarn InaccessibleLinkPolicy = "warn-and-keep-absolute"
+	// InaccessibleLinksDrop replaces the links to inaccessible resources with their text and removes such images
+	InaccessibleLinksDrop InaccessibleLinkPolicy = "drop"
+)
+
+// linkStatuses caches the HTTP status codes of the checked links
+type linkStatuses struct {
+	mux      sync.Mutex
+	statuses map[string]int
+}
+
+// inaccessibleLink applies the inaccessible links policy to an absolute link to a resource of a repository that isn't
+// resolved by the repository hosts. It returns false when there is no policy or the resource is accessible
+func (d *linkResolverTask) inaccessibleLink(link string) (string, bool, error) {
+	if d.inaccessibleLinks == "" || !repositoryhost.IsResourceURL(link) {
+		return link, false, nil
+	}
+	status := d.linkStatus(link)
+	if status != http.StatusUnauthorized && status != http.StatusForbidden && status != http.StatusNotFound {
+		return link, false, nil
+	}
+	switch d.inaccessibleLinks {
+	case InaccessibleLinksError:
+		return link, true, fmt.Errorf("%s has link %s to an inaccessible resource: HTTP Status %d", d.source, link, status)
+	case InaccessibleLinksDrop:
+		klog.Warningf("dropping link %s to an inaccessible resource from source %s: HTTP Status %d\n", link, d.source, status)
+		return link, true, markdown.ErrDropLink
+	}
+	klog.Warningf("keeping link %s to an inaccessible resource from source %s: HTTP Status %d\n", link, d.source, status)
+	return link, true, nil
+}
+
+// linkStatus returns the HTTP status code of a HEAD request to the link, or 0 if the request fails
+func (d *linkResolverTask) linkStatus(link string) int {
+	u, err := url.Parse(link)
+	if err != nil {
+		return 0
+	}
+	u.RawQuery, u.Fragment = "", ""
+	key := u.String()
+	d.linkStatuses.mux.Lock()
+	status, ok := d.linkStatuses.statuses[key]
+	d.linkStatuses.mux.Unlock()
+	if ok {
+		return status
+	}
+	ctx, cancel := context.WithTimeout(d.ctx, 30*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, key, nil)
+	if err != nil {
+		return 0
+	}
+	resp, err := d.repositoryhosts.Client(key).Do(req)
+	if err != nil {
+		klog.Warningf("failed to check the access to %s from source %s: %v\n", link, d.source, err)
+		return 0
+	}
+	if resp.Body != nil {
+		resp.Body.Close()
+	}
+	d.linkStatuses.mux.Lock()
+	d.linkStatuses.statuses[key] = resp.StatusCode
+	d.linkStatuses.mux.Unlock()
+	return resp.StatusCode
+}
@@ -8,9 +8,11 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/gardener/docforge/cmd/hugo"
 	"github.com/gardener/docforge/pkg/manifest"
+	"github.com/gardener/docforge/pkg/metrics"
 	"github.com/gardener/docforge/pkg/registry"
 	"github.com/gardener/docforge/pkg/workers/linkresolver"
 	"github.com/gardener/docforge/pkg/workers/linkvalidator"
@@ -29,25 +31,41 @@ type documentScheduler struct {
 // Processor represents document processor
 type Processor interface {
 	ProcessNode(node *manifest.Node) bool
+	Coverage() *Coverage
+	LLMsIndex() *LLMsIndex
 }
 
 // New creates a new Worker
-func New(workerCount int, failFast bool, wg *sync.WaitGroup, structure []*manifest.Node, resourcesRoot string, downloadJob resourcedownloader.Interface, validator linkvalidator.Interface, rhs registry.Interface, hugo hugo.Hugo, writer writers.Writer, skipLinkValidation bool) (Processor, taskqueue.QueueController, error) {
+func New(workerCount int, failFast bool, wg *sync.WaitGroup, structure []*manifest.Node, resourcesRoot string, downloadJob resourcedownloader.Interface, validator linkvalidator.Interface, rhs registry.Interface, hugo hugo.Hugo, writer writers.Writer, options Options) (Processor, taskqueue.QueueController, error) {
 	lr := &linkresolver.LinkResolver{
-		Repositoryhosts: rhs,
-		Hugo:            hugo,
-		SourceToNode:    make(map[string][]*manifest.Node),
+		Repositoryhosts:   rhs,
+		Hugo:              hugo,
+		SourceToNode:      make(map[string][]*manifest.Node),
+		Redirects:         options.LinkRedirects,
+		MaxRedirectDepth:  options.MaxRedirectDepth,
+		AnchorRedirects:   options.AnchorRedirects,
+		HostAliases:       options.HostAliases,
+		AbsoluteLinkRepos: options.AbsoluteLinkRepos,
+		BasePath:          options.BasePath,
+	}
+	if len(options.RelativeVersionLinks) > 0 {
+		lr.RelativeVersionLink = linkresolver.SameVersionRule(options.RelativeVersionLinks)
 	}
 	for _, node := range structure {
 		if node.Source != "" {
-			lr.SourceToNode[node.Source] = append(lr.SourceToNode[node.Source], node)
+			source := lr.SourceKey(node.Source)
+			lr.SourceToNode[source] = append(lr.SourceToNode[source], node)
 		} else if len(node.MultiSource) > 0 {
 			for _, s := range node.MultiSource {
-				lr.SourceToNode[s] = append(lr.SourceToNode[s], node)
+				source := lr.SourceKey(s)
+				lr.SourceToNode[source] = append(lr.SourceToNode[source], node)
 			}
 		}
 	}
-	worker := NewDocumentWorker(resourcesRoot, downloadJob, validator, lr, rhs, hugo, writer, skipLinkValidation)
+	worker, err := NewDocumentWorker(resourcesRoot, downloadJob, validator, lr, rhs, hugo, writer, options)
+	if err != nil {
+		return nil, nil, err
+	}
 	queue, err := taskqueue.New("Document", workerCount, worker.execute, failFast, wg)
 	if err != nil {
 		return nil, nil, err
@@ -63,6 +81,7 @@ func (ds *documentScheduler) ProcessNode(node *manifest.Node) bool {
 	added := ds.queue.AddTask(node)
 	if !added {
 		klog.Warningf("scheduling document write failed for node %v\n", node)
+		ds.coverage.add(node, CoverageSkipped, "scheduling document write failed")
 	}
 	return added
 }
@@ -72,5 +91,8 @@ func (w *Worker) execute(ctx context.Context, task interface{}) error {
 	if !ok {
 		return fmt.Errorf("incorrect document work task: %T", task)
 	}
+	if node.Type == "file" {
+		defer metrics.DocumentDuration.ObserveSince(time.Now())
+	}
 	return w.ProcessNode(ctx, node)
 }
@@ -0,0 +1,146 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package document
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/yuin/goldmark/ast"
+	"k8s.io/klog/v2"
+)
+
+// LintSeverity defines the treatment of the violations of a lint rule
+type LintSeverity string
+
+const (
+	// LintWarning logs the violations of a rule as warnings
+	LintWarning LintSeverity = "warning"
+	// LintError fails the documents that violate a rule
+	LintError LintSeverity = "error"
+)
+
+const (
+	// LintMissingHeading is violated by documents without a top-level heading
+	LintMissingHeading = "missing-heading"
+	// LintImageAlt is violated by images without alt text
+	LintImageAlt = "image-alt"
+	// LintBareURL is violated by URLs written as plain text instead of links
+	LintBareURL = "bare-url"
+)
+
+// LintRules are the severities of the lint rules markdown documents are checked with, by rule
+type LintRules map[string]LintSeverity
+
+// NewLintRules validates the rules and their severities
+func NewLintRules(rules map[string]string) (LintRules, error) {
+	lintRules := LintRules{}
+	for rule, severity := range rules {
+		if rule != LintMissingHeading && rule != LintImageAlt && rule != LintBareURL {
+			return nil, fmt.Errorf("lint rule can be %s, %s or %s, not %s", LintMissingHeading, LintImageAlt, LintBareURL, rule)
+		}
+		if s := LintSeverity(severity); s != LintWarning && s != LintError {
+			return nil, fmt.Errorf("lint rule %s can have severity %s or %s, not %s", rule, LintWarning, LintError, severity)
+		}
+		lintRules[rule] = LintSeverity(severity)
+	}
+	return lintRules, nil
+}
+
+// LintViolation is a violation of a lint rule at a line of a source
+type LintViolation struct {
+	Rule     string
+	Severity LintSeverity
+	Line     int
+	Message  string
+}
+
+func (v LintViolation) String() string {
+	return fmt.Sprintf("line %d: %s (%s)", v.Line, v.Message, v.Rule)
+}
+
+// Lint returns the violations of the rules by the parsed markdown source, ordered by line
+func (rules LintRules) Lint(doc ast.Node, source []byte) []LintViolation {
+	if len(rules) == 0 || doc == nil {
+		return nil
+	}
+	var (
+		violations []LintViolation
+		heading    bool
+	)
+	report := func(rule string, offset int, format string, args ...interface{}) {
+		if severity, ok := rules[rule]; ok {
+			violations = append(violations, LintViolation{rule, severity, lineOf(source, offset), fmt.Sprintf(format, args...)})
+		}
+	}
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n := n.(type) {
+		case *ast.Heading:
+			heading = heading || n.Level == 1
+		case *ast.Image:
+			if len(n.Text(source)) == 0 {
+				report(LintImageAlt, inlineOffset(n, source, n.Destination), "image %s has no alt text", n.Destination)
+			}
+		case *ast.AutoLink:
+			url := n.URL(source)
+			offset := inlineOffset(n, source, n.Label(source))
+			// autolinks in angle brackets are written as links on purpose
+			if n.AutoLinkType == ast.AutoLinkURL && (offset == 0 || source[offset-1] != '<') {
+				report(LintBareURL, offset, "URL %s is not written as a link", url)
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+	if !heading {
+		report(LintMissingHeading, 0, "document has no top-level heading")
+	}
+	sort.SliceStable(violations, func(i, j int) bool { return violations[i].Line < violations[j].Line })
+	return violations
+}
+
+// lintSource logs the violations of the lint rules by a markdown source and fails for the ones with error severity
+func (d *Worker) lintSource(nodePath string, cnt *docContent) error {
+	var errs []string
+	for _, v := range d.lintRules.Lint(cnt.docAst, cnt.docCnt) {
+		if v.Severity == LintError {
+			errs = append(errs, v.String())
+			continue
+		}
+		klog.Warningf("lint %s of node %s at %s\n", cnt.docURI, nodePath, v)
+	}
+	if len(errs) > 0 {
+		return errors.New("lint " + cnt.docURI + " failed:\n" + strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
+// inlineOffset returns the offset of the text of an inline node in the source, found in the lines of its block,
+// or the offset of the block when the text isn't found
+func inlineOffset(n ast.Node, source []byte, text []byte) int {
+	block := n.Parent()
+	for block != nil && block.Type() != ast.TypeBlock {
+		block = block.Parent()
+	}
+	if block == nil || block.Lines().Len() == 0 {
+		return 0
+	}
+	lines := block.Lines()
+	start, stop := lines.At(0).Start, lines.At(lines.Len()-1).Stop
+	if i := bytes.Index(source[start:stop], text); len(text) > 0 && i >= 0 {
+		return start + i
+	}
+	return start
+}
+
+// lineOf returns the line number of the offset in the source
+func lineOf(source []byte, offset int) int {
+	return bytes.Count(source[:offset], []byte("\n")) + 1
+}
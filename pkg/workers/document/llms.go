@@ -0,0 +1,106 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package document
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gardener/docforge/cmd/hugo"
+	"github.com/gardener/docforge/pkg/manifest"
+	"github.com/gardener/docforge/pkg/workers/linkresolver"
+	"gopkg.in/yaml.v3"
+)
+
+// LLMsIndex collects the written documents for an llms.txt index
+type LLMsIndex struct {
+	// Full keeps the document content for llms-full.txt
+	Full    bool
+	mux     sync.Mutex
+	entries []llmsEntry
+}
+
+type llmsEntry struct {
+	path    string
+	title   string
+	link    string
+	content []byte
+}
+
+// entry returns the entry of the written document node, if it has content
+func (l *LLMsIndex) entry(node *manifest.Node, content []byte, basePath string, hugo hugo.Hugo) (llmsEntry, bool) {
+	if node.Type != "file" || len(content) == 0 {
+		return llmsEntry{}, false
+	}
+	fm, body := splitFrontmatter(content)
+	title, _ := fm["title"].(string)
+	if title == "" {
+		title = strings.TrimSuffix(node.Name(), ".md")
+	}
+	link := linkresolver.WebsiteLink(basePath, hugo.BaseURL, strings.ToLower(node.NodePath()))
+	if hugo.Enabled {
+		link = linkresolver.HugoLink(basePath, hugo, node)
+	}
+	entry := llmsEntry{path: node.NodePath(), title: title, link: link}
+	if l.Full {
+		entry.content = append([]byte{}, body...)
+	}
+	return entry, true
+}
+
+func (l *LLMsIndex) add(entry llmsEntry) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	l.entries = append(l.entries, entry)
+}
+
+func (l *LLMsIndex) sorted() []llmsEntry {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+	entries := append([]llmsEntry{}, l.entries...)
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].path < entries[j].path })
+	return entries
+}
+
+// Index returns the llms.txt content listing the titles and links of the written documents
+func (l *LLMsIndex) Index(title string) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "# %s\n\n", title)
+	for _, e := range l.sorted() {
+		fmt.Fprintf(&b, "- [%s](%s)\n", e.title, e.link)
+	}
+	return b.Bytes()
+}
+
+// FullIndex returns the llms-full.txt content concatenating the written documents
+func (l *LLMsIndex) FullIndex(title string) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "# %s\n", title)
+	for _, e := range l.sorted() {
+		fmt.Fprintf(&b, "\n## [%s](%s)\n\n", e.title, e.link)
+		b.Write(bytes.TrimSpace(e.content))
+		b.WriteString("\n")
+	}
+	return b.Bytes()
+}
+
+// splitFrontmatter splits the document content into frontmatter and body
+func splitFrontmatter(content []byte) (map[string]interface{}, []byte) {
+	if !bytes.HasPrefix(content, []byte("---\n")) {
+		return nil, content
+	}
+	end := bytes.Index(content[4:], []byte("\n---\n"))
+	if end < 0 {
+		return nil, content
+	}
+	fm := map[string]interface{}{}
+	if err := yaml.Unmarshal(content[4:4+end], &fm); err != nil {
+		return nil, content
+	}
+	return fm, content[4+end+5:]
+}
@@ -0,0 +1,78 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package markdown
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/text"
+)
+
+// htmlAnchor matches the id and name attributes of HTML elements, which are link targets as well
+var htmlAnchor = regexp.MustCompile(`(?i)\s(?:id|name)\s*=\s*["']([^"']+)["']`)
+
+// Anchors returns the anchors of a document: the ids GitHub and Hugo generate for its headings and the ids and
+// names of its HTML elements. They are lowercase, as links to anchors are normalized to lowercase
+func Anchors(doc ast.Node, source []byte) map[string]struct{} {
+	anchors := map[string]struct{}{}
+	if doc == nil {
+		return anchors
+	}
+	_ = ast.Walk(doc, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering {
+			return ast.WalkContinue, nil
+		}
+		switch n.Kind() {
+		case ast.KindHeading:
+			id := HeadingID(string(n.Text(source)))
+			// duplicate headings get the suffixes -1, -2, ...
+			unique := id
+			for i := 1; ; i++ {
+				if _, ok := anchors[unique]; !ok {
+					break
+				}
+				unique = fmt.Sprintf("%s-%d", id, i)
+			}
+			anchors[unique] = struct{}{}
+			return ast.WalkSkipChildren, nil
+		case ast.KindHTMLBlock, ast.KindRawHTML:
+			var b strings.Builder
+			var lines *text.Segments
+			if raw, ok := n.(*ast.RawHTML); ok {
+				lines = raw.Segments
+			} else {
+				lines = n.Lines()
+			}
+			for i := 0; i < lines.Len(); i++ {
+				segment := lines.At(i)
+				b.Write(segment.Value(source))
+			}
+			for _, match := range htmlAnchor.FindAllStringSubmatch(b.String(), -1) {
+				anchors[strings.ToLower(match[1])] = struct{}{}
+			}
+		}
+		return ast.WalkContinue, nil
+	})
+	return anchors
+}
+
+// HeadingID returns the id of a heading with the text, generated like GitHub and Hugo do: lowercase, with spaces
+// replaced by - and without the punctuation other than - and _
+func HeadingID(text string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(text)) {
+		switch {
+		case unicode.IsLetter(r), unicode.IsDigit(r), r == '-', r == '_':
+			b.WriteRune(r)
+		case r == ' ':
+			b.WriteRune('-')
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,29 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package markdown_test
+
+import (
+	"github.com/gardener/docforge/pkg/workers/document/markdown"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Anchors", func() {
+	It("returns the ids of the headings and the HTML anchors", func() {
+		md := []byte("# Getting Started!\n\n## The `docforge` CLI\n\nSetup\n-----\n\n## Setup\n\n<a name=\"Legacy\"></a>\n\nSee <span id='inline'>here</span>.\n")
+		doc, err := markdown.Parse(markdown.New(), md)
+		Expect(err).NotTo(HaveOccurred())
+		anchors := markdown.Anchors(doc, md)
+		Expect(anchors).To(HaveLen(6))
+		for _, anchor := range []string{"getting-started", "the-docforge-cli", "setup", "setup-1", "legacy", "inline"} {
+			Expect(anchors).To(HaveKey(anchor))
+		}
+	})
+
+	It("generates heading ids like GitHub", func() {
+		Expect(markdown.HeadingID("  Configure the `--hugo` flag (optional)  ")).To(Equal("configure-the---hugo-flag-optional"))
+		Expect(markdown.HeadingID("snake_case & Ümlaut")).To(Equal("snake_case--ümlaut"))
+	})
+})
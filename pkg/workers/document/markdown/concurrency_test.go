@@ -0,0 +1,62 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package markdown_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/gardener/docforge/pkg/workers/document/markdown"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/yuin/goldmark"
+)
+
+var _ = Describe("Concurrency", func() {
+	It("parses and renders documents concurrently with a shared parser", func() {
+		render := func(md goldmark.Markdown, i int) (string, error) {
+			source := []byte(fmt.Sprintf("---\ntitle: doc %d\n---\n\n# Doc %d\n\nSee [next](./doc%d.md) and https://example.com/%d.\n\n| a | b |\n|---|---|\n| %d | ![img](./img%d.png) |\n\n```yml\nkey: %d\n```\n", i, i, i+1, i, i, i, i))
+			doc, err := markdown.Parse(md, source)
+			if err != nil {
+				return "", err
+			}
+			var b bytes.Buffer
+			rnd := markdown.NewLinkModifierRenderer(
+				markdown.WithLinkResolver(func(dest string, _ bool) (string, error) { return strings.TrimSuffix(dest, ".md"), nil }),
+				markdown.WithLanguageAliases(map[string]string{"yml": "yaml"}),
+			)
+			err = rnd.Render(&b, source, doc)
+			return b.String(), err
+		}
+		const documents = 64
+		expected := make([]string, documents)
+		for i := range expected {
+			var err error
+			expected[i], err = render(markdown.New(), i)
+			Expect(err).NotTo(HaveOccurred())
+		}
+		actual := make([]string, documents)
+		errs := make([]error, documents)
+		// the shared parser is initialized lazily by the first concurrent parse
+		md := markdown.New()
+		var wg sync.WaitGroup
+		for i := 0; i < documents; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				actual[i], errs[i] = render(md, i)
+			}(i)
+		}
+		wg.Wait()
+		for i := range actual {
+			Expect(errs[i]).NotTo(HaveOccurred())
+			Expect(actual[i]).To(Equal(expected[i]))
+		}
+		Expect(actual[7]).To(ContainSubstring("[next](./doc8)"))
+		Expect(actual[7]).To(ContainSubstring("```yaml\nkey: 7\n```"))
+	})
+})
@@ -7,11 +7,17 @@ package markdown
 import (
 	"bufio"
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
+	"path"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/yuin/goldmark/ast"
 	extast "github.com/yuin/goldmark/extension/ast"
@@ -35,6 +41,17 @@ var (
 	fence = regexp.MustCompile("^ {0,3}```.*")
 	// defines a mermaid link
 	mermaidLink = regexp.MustCompile(`(^\s*click +[^"]+ +")([^"]+)(".*)`)
+	// defines a fence block info file attribute e.g. {file="path#L1-L10"}
+	snippetFile = regexp.MustCompile(`\{[^}]*\bfile="([^"#]+)(?:#L(\d+)(?:-L(\d+))?)?"[^}]*\}`)
+	// defines a snippet directive HTML comment e.g. <!-- snippet: path#L1-L10 -->
+	snippetDirective = regexp.MustCompile(`^\s*<!--\s*snippet:\s*([^\s#]+)(?:#L(\d+)(?:-L(\d+))?)?\s*-->\s*$`)
+	// languages of the fenced code blocks of snippet directives by file extension
+	snippetLanguages = map[string]string{
+		".go": "go", ".yaml": "yaml", ".yml": "yaml", ".json": "json", ".toml": "toml", ".sh": "bash", ".bash": "bash",
+		".py": "python", ".js": "javascript", ".ts": "typescript", ".java": "java", ".rs": "rust", ".rb": "ruby",
+		".c": "c", ".h": "c", ".cpp": "cpp", ".proto": "protobuf", ".sql": "sql", ".xml": "xml", ".html": "html",
+		".css": "css", ".md": "markdown", ".tf": "hcl", ".mk": "makefile", ".dockerfile": "dockerfile",
+	}
 	// GFM autolink extensions
 	http  = regexp.MustCompile(`^https?://(?:[a-zA-Z\d\-_]+\.)*[a-zA-Z\d\-]+\.[a-zA-Z\d\-]+[^ <]*$`)
 	www   = regexp.MustCompile(`^www\.(?:[a-zA-Z\d\-_]+\.)*[a-zA-Z\d\-]+\.[a-zA-Z\d\-]+[^ <]*$`)
@@ -46,6 +63,10 @@ var (
 // isEmbeddable - if true, raw destination required
 type ResolveLink func(dest string, isEmbeddable bool) (string, error)
 
+// ErrDropLink is returned by a ResolveLink with the original destination to drop a link. Links are replaced by their text
+// and images are removed. Links that can't be dropped, like autolinks and link reference definitions, keep the destination
+var ErrDropLink = errors.New("link dropped")
+
 // resolveSame implements markdown.ResolveLink - the result is the same as input
 // used if WithLinkResolver option is not set
 func resolveSame(dest string, _ bool) (string, error) {
@@ -68,6 +89,162 @@ func WithLinkResolver(linkResolver ResolveLink) renderer.Option {
 	return &withLinkResolver{linkResolver}
 }
 
+// LanguageAliases is an option name used in WithLanguageAliases.
+const optLanguageAliases renderer.OptionName = "LanguageAliases"
+
+type withLanguageAliases struct {
+	value map[string]string
+}
+
+func (o *withLanguageAliases) SetConfig(c *renderer.Config) {
+	c.Options[optLanguageAliases] = o.value
+}
+
+// WithLanguageAliases is a functional option that allow you to set aliases for fenced code block languages to the renderer.
+func WithLanguageAliases(aliases map[string]string) renderer.Option {
+	return &withLanguageAliases{aliases}
+}
+
+// ReadSnippet type defines function for reading the content of a file referenced by a fenced code block
+// file - file path as given in the fence block info
+type ReadSnippet func(file string) ([]byte, error)
+
+// SnippetReader is an option name used in WithSnippetReader.
+const optSnippetReader renderer.OptionName = "SnippetReader"
+
+type withSnippetReader struct {
+	value ReadSnippet
+}
+
+func (o *withSnippetReader) SetConfig(c *renderer.Config) {
+	c.Options[optSnippetReader] = o.value
+}
+
+// WithSnippetReader is a functional option that allow you to set the ReadSnippet used to inline
+// files referenced by fenced code blocks e.g. ```go {file="path#L1-L10"}
+func WithSnippetReader(snippetReader ReadSnippet) renderer.Option {
+	return &withSnippetReader{snippetReader}
+}
+
+// InlineImage type defines function for inlining images
+// dest - original image destination
+// It returns the markup replacing the image, or nil when the image is kept
+type InlineImage func(dest string) ([]byte, error)
+
+// ImageInliner is an option name used in WithImageInliner.
+const optImageInliner renderer.OptionName = "ImageInliner"
+
+type withImageInliner struct {
+	value InlineImage
+}
+
+func (o *withImageInliner) SetConfig(c *renderer.Config) {
+	c.Options[optImageInliner] = o.value
+}
+
+// WithImageInliner is a functional option that allow you to set the InlineImage used to replace
+// markdown images and HTML img tags by markup, e.g. the content of SVG files
+func WithImageInliner(imageInliner InlineImage) renderer.Option {
+	return &withImageInliner{imageInliner}
+}
+
+// inlinedAttribute is the attribute of images replaced by the markup of the image inliner
+var inlinedAttribute = []byte("docforge-inlined")
+
+// DefinitionListForm is the form definition lists are rendered in
+type DefinitionListForm string
+
+const (
+	// DefinitionListMarkdown renders definition lists as markdown, like they are written
+	DefinitionListMarkdown DefinitionListForm = "markdown"
+	// DefinitionListHTML renders definition lists as HTML dl elements with markdown terms and descriptions
+	DefinitionListHTML DefinitionListForm = "html"
+)
+
+// DefinitionLists is an option name used in WithDefinitionLists.
+const optDefinitionLists renderer.OptionName = "DefinitionLists"
+
+type withDefinitionLists struct {
+	value DefinitionListForm
+}
+
+func (o *withDefinitionLists) SetConfig(c *renderer.Config) {
+	c.Options[optDefinitionLists] = o.value
+}
+
+// WithDefinitionLists is a functional option that allow you to set the form definition lists are rendered in.
+// Definition lists are parsed only by parsers created with the extension.DefinitionList extension and
+// are rendered as markdown when the option is not set
+func WithDefinitionLists(form DefinitionListForm) renderer.Option {
+	return &withDefinitionLists{form}
+}
+
+// ListMarkers are the markers list items are rendered with. Zero markers keep the markers of the source
+type ListMarkers struct {
+	// Bullet is the marker of bullet list items, one of - * +
+	Bullet byte
+	// Delimiter follows the number of ordered list items, one of . )
+	Delimiter byte
+	// Numbering of ordered list items. Every item gets the start number of its list when empty
+	Numbering ListNumbering
+}
+
+// ListNumbering is how ordered list items are numbered
+type ListNumbering string
+
+const (
+	// ListNumberingPreserve keeps the numbers of the source
+	ListNumberingPreserve ListNumbering = "preserve"
+	// ListNumberingRenumber numbers the items sequentially from the start number of their list
+	ListNumberingRenumber ListNumbering = "renumber"
+)
+
+// ListMarkers is an option name used in WithListMarkers.
+const optListMarkers renderer.OptionName = "ListMarkers"
+
+type withListMarkers struct {
+	value ListMarkers
+}
+
+func (o *withListMarkers) SetConfig(c *renderer.Config) {
+	c.Options[optListMarkers] = o.value
+}
+
+// WithListMarkers is a functional option that allow you to set the markers list items are rendered with.
+// List items keep the markers of the source when the option is not set
+func WithListMarkers(markers ListMarkers) renderer.Option {
+	return &withListMarkers{markers}
+}
+
+// HeadingStyle is the style headings are rendered in
+type HeadingStyle string
+
+const (
+	// HeadingATX renders headings as ATX headings e.g. # Heading, joining the lines of multiline headings
+	HeadingATX HeadingStyle = "atx"
+	// HeadingSetext renders level 1 and 2 headings as Setext headings underlined with === or ---
+	HeadingSetext HeadingStyle = "setext"
+	// HeadingPreserve renders headings in the style they are written in
+	HeadingPreserve HeadingStyle = "preserve"
+)
+
+// HeadingStyle is an option name used in WithHeadingStyle.
+const optHeadingStyle renderer.OptionName = "HeadingStyle"
+
+type withHeadingStyle struct {
+	value HeadingStyle
+}
+
+func (o *withHeadingStyle) SetConfig(c *renderer.Config) {
+	c.Options[optHeadingStyle] = o.value
+}
+
+// WithHeadingStyle is a functional option that allow you to set the style headings are rendered in.
+// Headings are rendered as ATX headings when the option is not set
+func WithHeadingStyle(style HeadingStyle) renderer.Option {
+	return &withHeadingStyle{style}
+}
+
 // A linkModifierRenderer struct is an implementation of renderer.Renderer interface.
 type linkModifierRenderer struct {
 	config *renderer.Config
@@ -103,6 +280,24 @@ func (l *linkModifierRenderer) Render(w io.Writer, source []byte, node ast.Node)
 		markers:      make([]int, 0, 5),
 		emphasis:     make([]byte, 0, 5),
 	}
+	if form, ok := l.config.Options[optDefinitionLists]; ok {
+		r.definitionLists = form.(DefinitionListForm)
+	}
+	if markers, ok := l.config.Options[optListMarkers]; ok {
+		r.listMarkers = markers.(ListMarkers)
+	}
+	if style, ok := l.config.Options[optHeadingStyle]; ok {
+		r.headingStyle = style.(HeadingStyle)
+	}
+	if aliases, ok := l.config.Options[optLanguageAliases]; ok {
+		r.languageAliases = aliases.(map[string]string)
+	}
+	if snippetReader, ok := l.config.Options[optSnippetReader]; ok {
+		r.snippetReader = snippetReader.(ReadSnippet)
+	}
+	if imageInliner, ok := l.config.Options[optImageInliner]; ok {
+		r.imageInliner = imageInliner.(InlineImage)
+	}
 	writer, ok := w.(*bytes.Buffer)
 	if ok {
 		r.writer = writer
@@ -133,6 +328,10 @@ func (l *linkModifierRenderer) Render(w io.Writer, source []byte, node ast.Node)
 			return r.renderTextBlock(node, entering)
 		case ast.KindThematicBreak:
 			return r.renderThematicBreak(node, entering)
+		case KindLinkReferenceDefinitions:
+			return r.renderLinkReferenceDefinitions(node, entering)
+		case KindMathBlock:
+			return r.renderMathBlock(node, entering)
 		// commonmark inlines
 		case ast.KindAutoLink:
 			return r.renderAutoLink(node, entering)
@@ -148,6 +347,8 @@ func (l *linkModifierRenderer) Render(w io.Writer, source []byte, node ast.Node)
 			return r.renderRawHTML(node, entering)
 		case ast.KindText, ast.KindString:
 			return r.renderText(node, entering)
+		case KindMath:
+			return r.renderMath(node, entering)
 		// GFM extension blocks
 		case extast.KindTable:
 			return r.renderTable(node, entering)
@@ -157,11 +358,28 @@ func (l *linkModifierRenderer) Render(w io.Writer, source []byte, node ast.Node)
 			return r.renderTableRow(node, entering)
 		case extast.KindTableCell:
 			return r.renderTableCell(node, entering)
+		// Footnote extension blocks
+		case extast.KindFootnoteList:
+			return r.renderFootnoteList(node, entering)
+		case extast.KindFootnote:
+			return r.renderFootnote(node, entering)
+		// Definition list extension blocks
+		case extast.KindDefinitionList:
+			return r.renderDefinitionList(node, entering)
+		case extast.KindDefinitionTerm:
+			return r.renderDefinitionTerm(node, entering)
+		case extast.KindDefinitionDescription:
+			return r.renderDefinitionDescription(node, entering)
 		// GFM extension inlines
 		case extast.KindTaskCheckBox:
 			return r.renderTaskCheckBox(node, entering)
 		case extast.KindStrikethrough:
 			return r.renderStrikethrough(node, entering)
+		// Footnote extension inlines
+		case extast.KindFootnoteLink:
+			return r.renderFootnoteLink(node, entering)
+		case extast.KindFootnoteBacklink:
+			return ast.WalkSkipChildren, nil
 		default:
 			return ast.WalkContinue, nil
 		}
@@ -174,13 +392,22 @@ func (l *linkModifierRenderer) Render(w io.Writer, source []byte, node ast.Node)
 
 // Renderer holds document source, buffer writer, info for indents and some nodes for rendering a markdown
 type Renderer struct {
-	source       []byte
-	writer       *bytes.Buffer
-	linkResolver ResolveLink
-	indents      []byte
-	markers      []int
-	emphasis     []byte
-	table        bool
+	source          []byte
+	writer          *bytes.Buffer
+	linkResolver    ResolveLink
+	languageAliases map[string]string
+	snippetReader   ReadSnippet
+	imageInliner    InlineImage
+	indents         []byte
+	markers         []int
+	emphasis        []byte
+	// links are the writer offsets of the links and images being rendered
+	links           []int
+	table           bool
+	definitionLists DefinitionListForm
+	headingStyle    HeadingStyle
+	atxHeading      bool
+	listMarkers     ListMarkers
 }
 
 // --------------------------- Node Renders
@@ -241,7 +468,7 @@ func (r *Renderer) renderListItem(node ast.Node, entering bool) (ast.WalkStatus,
 	if entering {
 		n := node.(*ast.ListItem)
 		r.blockSeparator(n)
-		listMarker := buildListMarker(n)
+		listMarker := r.buildListMarker(n)
 		_, _ = r.writer.Write(listMarker)
 		r.markers = append(r.markers, len(listMarker))
 		r.indents = append(r.indents, bytes.Repeat([]byte{' '}, len(listMarker))...)
@@ -256,17 +483,19 @@ func (r *Renderer) renderListItem(node ast.Node, entering bool) (ast.WalkStatus,
 
 func (r *Renderer) renderHeading(node ast.Node, entering bool) (ast.WalkStatus, error) {
 	n := node.(*ast.Heading)
-	atx := true // defaults to ATX headings
-	if n.Lines().Len() > 1 && n.Level <= 2 {
-		atx = false // multiline heading -> use Setext headings
-	}
+	atx := r.isATXHeading(n)
 	if entering {
+		if !atx && n.PreviousSibling() != nil && n.PreviousSibling().Kind() == ast.KindParagraph {
+			n.SetBlankPreviousLines(true) // otherwise the paragraph becomes part of the Setext heading
+		}
 		r.blockSeparator(n)
 		if atx {
 			_, _ = r.writer.Write(bytes.Repeat([]byte{'#'}, n.Level))
 			_ = r.writer.WriteByte(' ')
 		}
+		r.atxHeading = atx
 	} else {
+		r.atxHeading = false
 		if !atx {
 			r.newLine(true)
 			if n.Level == 1 {
@@ -279,6 +508,25 @@ func (r *Renderer) renderHeading(node ast.Node, entering bool) (ast.WalkStatus,
 	return ast.WalkContinue, nil
 }
 
+// isATXHeading checks if the heading is rendered as ATX heading. Only non-empty level 1 and 2 headings
+// can be Setext headings
+func (r *Renderer) isATXHeading(n *ast.Heading) bool {
+	if n.Level > 2 || n.Lines().Len() == 0 {
+		return true
+	}
+	switch r.headingStyle {
+	case HeadingSetext:
+		return false
+	case HeadingPreserve:
+		// ATX headings are single lines starting with up to 3 spaces of indentation and #
+		start := n.Lines().At(0).Start
+		lineStart := bytes.LastIndexByte(r.source[:start], '\n') + 1
+		return bytes.HasPrefix(bytes.TrimLeft(r.source[lineStart:start], " "), []byte{'#'})
+	default:
+		return true
+	}
+}
+
 func (r *Renderer) renderFencedCodeBlock(n ast.Node, entering bool) (ast.WalkStatus, error) {
 	if entering {
 		buf := bufPool.Get().(*bytes.Buffer)
@@ -286,21 +534,27 @@ func (r *Renderer) renderFencedCodeBlock(n ast.Node, entering bool) (ast.WalkSta
 		buf.Reset()
 		indents := len(r.indents) > 0
 		var fb byte = '`'
-		segments := n.Lines()
-		for _, l := range segments.Sliced(0, segments.Len()) {
-			if fence.Match(l.Value(r.source)) {
+		lines, err := r.codeBlockLines(n)
+		if err != nil {
+			return ast.WalkStop, err
+		}
+		for _, l := range lines {
+			if fence.Match(l) {
 				fb = '~'
 			}
 			if indents {
 				_, _ = buf.Write(r.indents)
 			}
-			_, _ = buf.Write(l.Value(r.source))
+			_, _ = buf.Write(l)
 		}
 		r.blockSeparator(n)
 		_, _ = r.writer.Write([]byte{fb, fb, fb})
 		if n.Kind() == ast.KindFencedCodeBlock {
 			fn := n.(*ast.FencedCodeBlock)
 			language := fn.Language(r.source)
+			if alias, ok := r.languageAliases[string(language)]; ok && language != nil {
+				language = []byte(alias)
+			}
 			if language != nil {
 				_, _ = r.writer.Write(language)
 				if bytes.Equal(language, []byte("mermaid")) {
@@ -334,8 +588,98 @@ func (r *Renderer) renderFencedCodeBlock(n ast.Node, entering bool) (ast.WalkSta
 	return ast.WalkSkipChildren, nil
 }
 
+// codeBlockLines returns the lines of a code block, or the referenced snippet lines
+// if the fence block info has a file attribute and a snippet reader is set
+func (r *Renderer) codeBlockLines(n ast.Node) ([][]byte, error) {
+	var lines [][]byte
+	if fn, ok := n.(*ast.FencedCodeBlock); ok && r.snippetReader != nil && fn.Info != nil {
+		if m := snippetFile.FindSubmatch(fn.Info.Segment.Value(r.source)); m != nil {
+			return r.readSnippetLines(string(m[1]), string(m[2]), string(m[3]))
+		}
+	}
+	segments := n.Lines()
+	for _, l := range segments.Sliced(0, segments.Len()) {
+		lines = append(lines, l.Value(r.source))
+	}
+	return lines, nil
+}
+
+// readSnippetLines reads the snippet file and returns the lines in the range [from, to],
+// all lines if from is empty and the single line from if to is empty
+func (r *Renderer) readSnippetLines(file string, from string, to string) ([][]byte, error) {
+	cnt, err := r.snippetReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("reading snippet %s failed: %w", file, err)
+	}
+	lines := bytes.SplitAfter(cnt, []byte{'\n'})
+	if len(lines[len(lines)-1]) == 0 {
+		lines = lines[:len(lines)-1]
+	}
+	if from == "" {
+		return lines, nil
+	}
+	start, _ := strconv.Atoi(from)
+	end := start
+	if to != "" {
+		end, _ = strconv.Atoi(to)
+	}
+	if start < 1 || end < start || end > len(lines) {
+		return nil, fmt.Errorf("line range L%d-L%d is out of snippet %s with %d lines", start, end, file, len(lines))
+	}
+	return lines[start-1 : end], nil
+}
+
+// renderSnippetDirective renders the lines referenced by a snippet directive as a fenced code block
+// with the language of the file extension
+func (r *Renderer) renderSnippetDirective(n ast.Node, file string, from string, to string) (ast.WalkStatus, error) {
+	lines, err := r.readSnippetLines(file, from, to)
+	if err != nil {
+		return ast.WalkStop, err
+	}
+	buf := bufPool.Get().(*bytes.Buffer)
+	defer bufPool.Put(buf)
+	buf.Reset()
+	indents := len(r.indents) > 0
+	var fb byte = '`'
+	for _, l := range lines {
+		if fence.Match(l) {
+			fb = '~'
+		}
+		if indents {
+			_, _ = buf.Write(r.indents)
+		}
+		_, _ = buf.Write(l)
+	}
+	r.blockSeparator(n)
+	_, _ = r.writer.Write([]byte{fb, fb, fb})
+	language := snippetLanguages[strings.ToLower(path.Ext(file))]
+	if alias, ok := r.languageAliases[language]; ok && language != "" {
+		language = alias
+	}
+	_, _ = r.writer.WriteString(language)
+	r.newLine(false)
+	if buf.Len() > 0 {
+		// ensure buffer ends with '\n'
+		if buf.Bytes()[buf.Len()-1] != '\n' {
+			buf.WriteByte('\n')
+		}
+		_, _ = r.writer.Write(buf.Bytes())
+	}
+	if indents {
+		_, _ = r.writer.Write(r.indents)
+	}
+	_, _ = r.writer.Write([]byte{fb, fb, fb})
+	return ast.WalkSkipChildren, nil
+}
+
 func (r *Renderer) renderHTMLBlock(node ast.Node, entering bool) (ast.WalkStatus, error) {
 	n := node.(*ast.HTMLBlock)
+	if entering && r.snippetReader != nil && n.HTMLBlockType == ast.HTMLBlockType2 && n.Lines().Len() == 1 {
+		line := n.Lines().At(0)
+		if m := snippetDirective.FindSubmatch(line.Value(r.source)); m != nil {
+			return r.renderSnippetDirective(n, string(m[1]), string(m[2]), string(m[3]))
+		}
+	}
 	if entering {
 		r.blockSeparator(n)
 		// HTMLBlockType 6 & 7 may contain links and images
@@ -389,12 +733,19 @@ func (r *Renderer) renderThematicBreak(node ast.Node, entering bool) (ast.WalkSt
 	n := node.(*ast.ThematicBreak)
 	if entering {
 		r.blockSeparator(n)
+		ch := byte('*') // as '-' could be Setext heading 2 use '*'
 		if n.HasBlankPreviousLines() {
-			_, _ = r.writer.Write([]byte{'-', '-', '-'})
-		} else {
-			// as '-' could be Setext heading 2 use '*'
-			_, _ = r.writer.Write([]byte{'*', '*', '*'})
+			ch = '-'
 		}
+		if li, ok := n.Parent().(*ast.ListItem); ok && n.PreviousSibling() == nil && r.listMarker(li.Parent().(*ast.List)) == ch {
+			// a break repeating the bullet of its list item makes the whole line a thematic break
+			if ch == '*' {
+				ch = '-'
+			} else {
+				ch = '*'
+			}
+		}
+		_, _ = r.writer.Write([]byte{ch, ch, ch})
 	}
 	return ast.WalkSkipChildren, nil
 }
@@ -418,7 +769,7 @@ func (r *Renderer) renderAutoLink(node ast.Node, entering bool) (ast.WalkStatus,
 		}
 		if n.AutoLinkType == ast.AutoLinkURL {
 			dest, err := r.linkResolver(string(label), false)
-			if err != nil {
+			if err != nil && !errors.Is(err, ErrDropLink) {
 				return ast.WalkStop, err
 			}
 			_, _ = r.writer.Write([]byte(dest))
@@ -487,12 +838,25 @@ func (r *Renderer) renderEmphasis(node ast.Node, entering bool) (ast.WalkStatus,
 
 func (r *Renderer) renderLink(node ast.Node, entering bool) (ast.WalkStatus, error) {
 	if entering {
+		r.links = append(r.links, r.writer.Len())
 		_ = r.writer.WriteByte('[')
 	} else {
 		n := node.(*ast.Link)
+		start := r.links[len(r.links)-1]
+		r.links = r.links[:len(r.links)-1]
 		_ = r.writer.WriteByte(']')
+		if r.writeReference(n) {
+			return ast.WalkContinue, nil
+		}
 		_ = r.writer.WriteByte('(')
 		dest, err := r.linkResolver(string(n.Destination), false)
+		if errors.Is(err, ErrDropLink) {
+			// keep the text between [ and ](
+			text := bytes.Clone(r.writer.Bytes()[start+1 : r.writer.Len()-2])
+			r.writer.Truncate(start)
+			_, _ = r.writer.Write(text)
+			return ast.WalkContinue, nil
+		}
 		if err != nil {
 			return ast.WalkStop, err
 		}
@@ -521,13 +885,37 @@ func (r *Renderer) renderLink(node ast.Node, entering bool) (ast.WalkStatus, err
 
 func (r *Renderer) renderImage(node ast.Node, entering bool) (ast.WalkStatus, error) {
 	if entering {
+		if _, ok := node.Attribute(referenceAttribute); !ok && r.imageInliner != nil {
+			markup, err := r.imageInliner(string(node.(*ast.Image).Destination))
+			if err != nil {
+				return ast.WalkStop, err
+			}
+			if markup != nil {
+				_, _ = r.writer.Write(markup)
+				node.SetAttribute(inlinedAttribute, true)
+				return ast.WalkSkipChildren, nil
+			}
+		}
+		r.links = append(r.links, r.writer.Len())
 		_ = r.writer.WriteByte('!')
 		_ = r.writer.WriteByte('[')
 	} else {
+		if _, ok := node.Attribute(inlinedAttribute); ok {
+			return ast.WalkContinue, nil
+		}
 		n := node.(*ast.Image)
+		start := r.links[len(r.links)-1]
+		r.links = r.links[:len(r.links)-1]
 		_ = r.writer.WriteByte(']')
+		if r.writeReference(n) {
+			return ast.WalkContinue, nil
+		}
 		_ = r.writer.WriteByte('(')
 		dest, err := r.linkResolver(string(n.Destination), true)
+		if errors.Is(err, ErrDropLink) {
+			r.writer.Truncate(start)
+			return ast.WalkContinue, nil
+		}
 		if err != nil {
 			return ast.WalkStop, err
 		}
@@ -554,6 +942,51 @@ func (r *Renderer) renderImage(node ast.Node, entering bool) (ast.WalkStatus, er
 	return ast.WalkContinue, nil
 }
 
+// writeReference writes the label of links and images referencing a preserved link reference definition
+func (r *Renderer) writeReference(n ast.Node) bool {
+	suffix, ok := n.Attribute(referenceAttribute)
+	if !ok {
+		return false
+	}
+	_, _ = r.writer.Write(suffix.([]byte))
+	return true
+}
+
+func (r *Renderer) renderLinkReferenceDefinitions(node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if !entering {
+		return ast.WalkContinue, nil
+	}
+	n := node.(*LinkReferenceDefinitions)
+	r.blockSeparator(n)
+	for i, def := range n.Definitions {
+		if i > 0 {
+			r.newLine(true)
+		}
+		_ = r.writer.WriteByte('[')
+		_, _ = r.writer.Write(def.Label)
+		_, _ = r.writer.Write([]byte("]: "))
+		dest, err := r.linkResolver(string(def.Destination), def.Image)
+		if err != nil && !errors.Is(err, ErrDropLink) {
+			return ast.WalkStop, err
+		}
+		if dest == "" || wrapLinkDestination([]byte(dest)) {
+			dest = "<" + dest + ">"
+		}
+		_, _ = r.writer.Write([]byte(dest))
+		if def.Title != nil {
+			q := getLinkTitleWrapper(def.Title)
+			_ = r.writer.WriteByte(' ')
+			_ = r.writer.WriteByte(q)
+			r.writeContent(def.Title)
+			if q == '(' {
+				q = ')'
+			}
+			_ = r.writer.WriteByte(q)
+		}
+	}
+	return ast.WalkSkipChildren, nil
+}
+
 func (r *Renderer) renderRawHTML(node ast.Node, entering bool) (ast.WalkStatus, error) {
 	if entering {
 		n := node.(*ast.RawHTML)
@@ -577,6 +1010,21 @@ func (r *Renderer) renderRawHTML(node ast.Node, entering bool) (ast.WalkStatus,
 	return ast.WalkSkipChildren, nil
 }
 
+func (r *Renderer) renderMath(node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		_, _ = r.writer.Write(node.(*Math).Segment.Value(r.source))
+	}
+	return ast.WalkSkipChildren, nil
+}
+
+func (r *Renderer) renderMathBlock(n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		r.blockSeparator(n)
+		r.writeSegments(r.writer, n.Lines(), len(r.indents) > 0)
+	}
+	return ast.WalkSkipChildren, nil
+}
+
 func (r *Renderer) renderText(node ast.Node, entering bool) (ast.WalkStatus, error) {
 	if entering {
 		n := node.(*ast.Text)
@@ -588,7 +1036,9 @@ func (r *Renderer) renderText(node ast.Node, entering bool) (ast.WalkStatus, err
 		}
 		_, _ = r.writer.Write(txt)
 		indents := len(r.indents) > 0
-		if n.HardLineBreak() {
+		if r.atxHeading && (n.HardLineBreak() || n.SoftLineBreak()) {
+			_ = r.writer.WriteByte(' ') // ATX headings are single lines
+		} else if n.HardLineBreak() {
 			_ = r.writer.WriteByte(' ')
 			_ = r.writer.WriteByte(' ')
 			r.newLine(indents)
@@ -625,8 +1075,12 @@ func (r *Renderer) renderTableHeader(node ast.Node, entering bool) (ast.WalkStat
 		// close headers cells
 		_ = r.writer.WriteByte('|')
 		r.newLine(len(r.indents) > 0)
-		// write alignments
-		for _, a := range n.Alignments {
+		// write alignments, one per header cell as the delimiter row must match the header row
+		for i := 0; i < n.ChildCount(); i++ {
+			a := extast.AlignNone
+			if i < len(n.Alignments) {
+				a = n.Alignments[i]
+			}
 			_ = r.writer.WriteByte('|')
 			align := []byte(" --- ")
 			switch a {
@@ -663,6 +1117,100 @@ func (r *Renderer) renderTableCell(_ ast.Node, entering bool) (ast.WalkStatus, e
 	return ast.WalkContinue, nil
 }
 
+// Footnote extension blocks
+
+func (r *Renderer) renderFootnoteList(n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		// footnotes are moved to the end of the document by the parser
+		n.SetBlankPreviousLines(true)
+		r.blockSeparator(n)
+	}
+	return ast.WalkContinue, nil
+}
+
+func (r *Renderer) renderFootnote(node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		n := node.(*extast.Footnote)
+		r.blockSeparator(n)
+		_, _ = r.writer.Write([]byte("[^"))
+		_, _ = r.writer.Write(n.Ref)
+		_, _ = r.writer.Write([]byte("]: "))
+		// footnote continuation blocks are indented
+		r.indents = append(r.indents, ' ', ' ', ' ', ' ')
+	} else {
+		r.indents = r.indents[:len(r.indents)-4]
+	}
+	return ast.WalkContinue, nil
+}
+
+// Definition list extension blocks
+
+func (r *Renderer) renderDefinitionList(n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		// the first term would continue a preceding paragraph
+		n.SetBlankPreviousLines(true)
+		r.blockSeparator(n)
+		if r.definitionLists == DefinitionListHTML {
+			_, _ = r.writer.Write([]byte("<dl>"))
+		}
+	} else if r.definitionLists == DefinitionListHTML {
+		r.newLine(true)
+		_, _ = r.writer.Write([]byte("</dl>"))
+		// the HTML block ends with a blank line
+		if n.NextSibling() != nil {
+			n.NextSibling().SetBlankPreviousLines(true)
+		}
+	}
+	return ast.WalkContinue, nil
+}
+
+func (r *Renderer) renderDefinitionTerm(n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if r.definitionLists == DefinitionListHTML {
+		r.definitionListElement("dt", entering)
+		return ast.WalkContinue, nil
+	}
+	if entering {
+		r.blockSeparator(n)
+		// a term following a description would continue its last paragraph
+		if n.PreviousSibling() != nil && n.PreviousSibling().Kind() == extast.KindDefinitionDescription && !n.HasBlankPreviousLines() {
+			r.newLine(true)
+		}
+	}
+	return ast.WalkContinue, nil
+}
+
+func (r *Renderer) renderDefinitionDescription(n ast.Node, entering bool) (ast.WalkStatus, error) {
+	if r.definitionLists == DefinitionListHTML {
+		r.definitionListElement("dd", entering)
+		return ast.WalkContinue, nil
+	}
+	if entering {
+		r.blockSeparator(n)
+		_, _ = r.writer.Write([]byte(": "))
+		// description continuation blocks are indented
+		r.indents = append(r.indents, ' ', ' ')
+	} else {
+		r.indents = r.indents[:len(r.indents)-2]
+	}
+	return ast.WalkContinue, nil
+}
+
+// definitionListElement writes the tag of a dl child element. Its content is separated by blank lines,
+// so that it is parsed as markdown
+func (r *Renderer) definitionListElement(tag string, entering bool) {
+	if cnt := r.writer.Bytes(); len(cnt) > 0 && cnt[len(cnt)-1] != '\n' {
+		r.newLine(true)
+	}
+	if entering {
+		_, _ = fmt.Fprintf(r.writer, "<%s>", tag)
+		r.newLine(true)
+		r.newLine(true)
+	} else {
+		r.newLine(true)
+		_, _ = fmt.Fprintf(r.writer, "</%s>", tag)
+	}
+}
+
 // GFM extension inlines
 
 func (r *Renderer) renderTaskCheckBox(node ast.Node, entering bool) (ast.WalkStatus, error) {
@@ -686,6 +1234,18 @@ func (r *Renderer) renderStrikethrough(_ ast.Node, entering bool) (ast.WalkStatu
 	return ast.WalkContinue, nil
 }
 
+// Footnote extension inlines
+
+func (r *Renderer) renderFootnoteLink(node ast.Node, entering bool) (ast.WalkStatus, error) {
+	if entering {
+		n := node.(*extast.FootnoteLink)
+		_, _ = r.writer.Write([]byte("[^"))
+		_, _ = r.writer.Write(footnoteRef(n))
+		_ = r.writer.WriteByte(']')
+	}
+	return ast.WalkSkipChildren, nil
+}
+
 // ---------------------------
 
 func (r *Renderer) newLine(indents bool) {
@@ -771,10 +1331,17 @@ func (r *Renderer) modifyHTMLTags(source []byte, target io.Writer) (bool, error)
 			return modified, nil // end of tokens
 		}
 		t := z.Token()
+		var inlined []byte
+		dropped := false
 		if "a" == t.Data {
 			for i, a := range t.Attr {
 				if a.Key == "href" {
 					dest, err := r.linkResolver(a.Val, false)
+					if errors.Is(err, ErrDropLink) {
+						t.Attr = slices.Delete(t.Attr, i, i+1)
+						modified = true
+						break
+					}
 					if err != nil {
 						return modified, err
 					}
@@ -788,7 +1355,21 @@ func (r *Renderer) modifyHTMLTags(source []byte, target io.Writer) (bool, error)
 		} else if "img" == t.Data {
 			for i, a := range t.Attr {
 				if a.Key == "src" {
+					if r.imageInliner != nil {
+						markup, err := r.imageInliner(a.Val)
+						if err != nil {
+							return modified, err
+						}
+						if markup != nil {
+							inlined = markup
+							break
+						}
+					}
 					dest, err := r.linkResolver(a.Val, true)
+					if errors.Is(err, ErrDropLink) {
+						dropped = true
+						break
+					}
 					if err != nil {
 						return modified, err
 					}
@@ -800,6 +1381,15 @@ func (r *Renderer) modifyHTMLTags(source []byte, target io.Writer) (bool, error)
 				}
 			}
 		}
+		if dropped {
+			modified = true
+			continue
+		}
+		if inlined != nil {
+			_, _ = target.Write(inlined)
+			modified = true
+			continue
+		}
 		_, _ = target.Write([]byte(t.String()))
 	}
 }
@@ -818,9 +1408,10 @@ func (r *Renderer) modifyMermaid(source []byte, target *bytes.Buffer) (bool, err
 			if "." == strings.TrimSpace(dest) {
 				dest = "."
 			} else {
-				dest, err = r.linkResolver(dest, false)
-				if err != nil {
-					return modified, err
+				var rErr error
+				dest, rErr = r.linkResolver(dest, false)
+				if rErr != nil && !errors.Is(rErr, ErrDropLink) {
+					return modified, rErr
 				}
 			}
 			modified = true
@@ -837,18 +1428,12 @@ func (r *Renderer) modifyMermaid(source []byte, target *bytes.Buffer) (bool, err
 	}
 }
 
+// calcEmphasisChar returns the delimiter char of the emphasis n and the text of n. The char n has in the source
+// is kept when it can be recovered, so rebuilds of the same source render the same delimiters. Otherwise '*' is
+// used, unless n contains an unescaped '*' or directly nests a '*' emphasis in the same level 1 emphasis. Then
+// '_' is used if n isn't adjacent to word characters, as '_' doesn't delimit intraword emphasis in GFM
 func (r *Renderer) calcEmphasisChar(n ast.Node) (ch byte, txt []byte) {
 	ch = '*' // default char
-	// check if first emphasis child determines the char
-	if n.Kind() == ast.KindEmphasis && n.FirstChild() != nil && n.FirstChild().Kind() == ast.KindEmphasis {
-		if n.(*ast.Emphasis).Level == 1 && n.FirstChild().(*ast.Emphasis).Level == 1 {
-			cch, _ := r.calcEmphasisChar(n.FirstChild())
-			if cch == '*' {
-				ch = '_' // handle nested <em> case
-			}
-			return
-		}
-	}
 	// get node text
 	for c := n.FirstChild(); c != nil; c = c.NextSibling() {
 		switch c.Kind() {
@@ -860,21 +1445,80 @@ func (r *Renderer) calcEmphasisChar(n ast.Node) (ch byte, txt []byte) {
 			txt = append(txt, t...)
 		}
 	}
-	// determine char
-	if n.Kind() == ast.KindEmphasis {
-		for i, b := range txt {
-			if b == '*' {
-				if i-1 >= 0 && txt[i-1] == '\\' {
-					continue
-				}
-				ch = '_' // unescaped asterisk -> switch to underscore
-				break
+	if n.Kind() != ast.KindEmphasis {
+		return
+	}
+	if sch, ok := r.sourceEmphasisChar(n); ok {
+		ch = sch
+		return
+	}
+	if r.isIntraword(n) {
+		return
+	}
+	// check if first emphasis child determines the char
+	if n.FirstChild() != nil && n.FirstChild().Kind() == ast.KindEmphasis {
+		if n.(*ast.Emphasis).Level == 1 && n.FirstChild().(*ast.Emphasis).Level == 1 {
+			if cch, _ := r.calcEmphasisChar(n.FirstChild()); cch == '*' {
+				ch = '_' // handle nested <em> case
 			}
+			return
+		}
+	}
+	for i, b := range txt {
+		if b == '*' {
+			if i-1 >= 0 && txt[i-1] == '\\' {
+				continue
+			}
+			ch = '_' // unescaped asterisk -> switch to underscore
+			break
 		}
 	}
 	return
 }
 
+// sourceEmphasisChar recovers the delimiter char of the emphasis n from the delimiter run preceding the text
+// its first descendants start with
+func (r *Renderer) sourceEmphasisChar(n ast.Node) (byte, bool) {
+	level := 0
+	c := n
+	for ; c != nil && c.Kind() == ast.KindEmphasis; c = c.FirstChild() {
+		level += c.(*ast.Emphasis).Level
+	}
+	t, ok := c.(*ast.Text)
+	if !ok {
+		return 0, false
+	}
+	start := t.Segment.Start - level
+	if start < 0 || t.Segment.Start > len(r.source) {
+		return 0, false
+	}
+	ch := r.source[start]
+	if ch != '*' && ch != '_' {
+		return 0, false
+	}
+	for _, b := range r.source[start : start+n.(*ast.Emphasis).Level] {
+		if b != ch {
+			return 0, false
+		}
+	}
+	return ch, true
+}
+
+// isIntraword checks if the emphasis n is preceded or followed by a word character
+func (r *Renderer) isIntraword(n ast.Node) bool {
+	if t, ok := n.PreviousSibling().(*ast.Text); ok {
+		if rn, _ := utf8.DecodeLastRune(t.Text(r.source)); unicode.IsLetter(rn) || unicode.IsDigit(rn) {
+			return true
+		}
+	}
+	if t, ok := n.NextSibling().(*ast.Text); ok {
+		if rn, _ := utf8.DecodeRune(t.Text(r.source)); unicode.IsLetter(rn) || unicode.IsDigit(rn) {
+			return true
+		}
+	}
+	return false
+}
+
 // if text could become different AST element additional indents must be added
 func (r *Renderer) additionalIndents(text []byte, n *ast.Text) {
 	// if there is a new line or intended new line before the text
@@ -909,12 +1553,70 @@ func breakBlockquoteLazyContinuation(n ast.Node) {
 }
 
 // build ListItem marker
-func buildListMarker(n *ast.ListItem) []byte {
+func (r *Renderer) buildListMarker(n *ast.ListItem) []byte {
 	p := n.Parent().(*ast.List)
 	if p.IsOrdered() {
-		return []byte(fmt.Sprintf("%d%c ", p.Start, p.Marker))
+		return []byte(fmt.Sprintf("%d%c ", r.listItemNumber(n), r.listMarker(p)))
 	}
-	return []byte{p.Marker, ' '}
+	return []byte{r.listMarker(p), ' '}
+}
+
+// listItemNumber returns the number of the ordered list item. Preserved numbers that can't be
+// recovered from the source follow the number of the previous item
+func (r *Renderer) listItemNumber(n *ast.ListItem) int {
+	p := n.Parent().(*ast.List)
+	if r.listMarkers.Numbering == "" || n.PreviousSibling() == nil {
+		return p.Start
+	}
+	if r.listMarkers.Numbering == ListNumberingPreserve {
+		if number, ok := r.sourceListItemNumber(n); ok {
+			return number
+		}
+	}
+	return r.listItemNumber(n.PreviousSibling().(*ast.ListItem)) + 1
+}
+
+// sourceListItemNumber recovers the number of the ordered list item from the marker preceding
+// its first line in the source
+func (r *Renderer) sourceListItemNumber(n *ast.ListItem) (int, bool) {
+	c := n.FirstChild()
+	if c == nil || (c.Kind() != ast.KindParagraph && c.Kind() != ast.KindTextBlock) || c.Lines().Len() == 0 {
+		return 0, false
+	}
+	start := c.Lines().At(0).Start
+	lineStart := bytes.LastIndexByte(r.source[:start], '\n') + 1
+	prefix := bytes.TrimRight(r.source[lineStart:start], " \t")
+	if len(prefix) == 0 || prefix[len(prefix)-1] != n.Parent().(*ast.List).Marker {
+		return 0, false
+	}
+	prefix = prefix[:len(prefix)-1]
+	i := len(prefix)
+	for i > 0 && prefix[i-1] >= '0' && prefix[i-1] <= '9' {
+		i--
+	}
+	number, err := strconv.Atoi(string(prefix[i:]))
+	return number, err == nil
+}
+
+// listMarker returns the bullet or the delimiter of the list items. Adjacent lists of the same type
+// are separate lists only if their markers differ, so a list following one with the normalized marker
+// gets another one
+func (r *Renderer) listMarker(l *ast.List) byte {
+	marker, markers := r.listMarkers.Bullet, "-*+"
+	if l.IsOrdered() {
+		marker, markers = r.listMarkers.Delimiter, ".)"
+	}
+	if marker == 0 {
+		return l.Marker
+	}
+	if prev, ok := l.PreviousSibling().(*ast.List); ok && prev.IsOrdered() == l.IsOrdered() && r.listMarker(prev) == marker {
+		for i := range markers {
+			if markers[i] != marker {
+				return markers[i]
+			}
+		}
+	}
+	return marker
 }
 
 func isClassicAutolink(link []byte, cnt []byte, trail []byte) bool {
@@ -1013,6 +1715,25 @@ func wrapLinkDestination(dest []byte) bool {
 	return (lp-elp)-(rp-elp) != 0
 }
 
+// footnoteRef returns the label of the footnote a footnote link refers to
+func footnoteRef(n *extast.FootnoteLink) []byte {
+	var doc ast.Node = n
+	for doc.Parent() != nil {
+		doc = doc.Parent()
+	}
+	for c := doc.LastChild(); c != nil; c = c.PreviousSibling() {
+		if c.Kind() != extast.KindFootnoteList {
+			continue
+		}
+		for fn := c.FirstChild(); fn != nil; fn = fn.NextSibling() {
+			if f := fn.(*extast.Footnote); f.Index == n.Index {
+				return f.Ref
+			}
+		}
+	}
+	return []byte(strconv.Itoa(n.Index))
+}
+
 func nextIsLineBreak(next ast.Node, source []byte) bool {
 	if next != nil && next.Kind() == ast.KindText {
 		n := next.(*ast.Text)
@@ -1025,27 +1746,11 @@ func nextIsLineBreak(next ast.Node, source []byte) bool {
 	return false
 }
 
-// escape pipes in code span when table scope
+// escape pipes in code span when table scope. The parser unescapes all pipes in code spans
+// of table cells, so a pipe preceded by a backslash is a literal backslash and must be escaped too
 func escapePipes(t []byte) []byte {
-	var et []byte
-	idx := 0
-	for i, b := range t {
-		if b == '|' {
-			if i > 0 && t[i-1] == '\\' {
-				continue
-			}
-			if i == 0 {
-				et = append(et, '\\', '|')
-			} else {
-				et = append(et, t[idx:i]...)
-				et = append(et, '\\', '|')
-			}
-			idx = i + 1
-		}
-	}
-	if idx > 0 {
-		et = append(et, t[idx:]...)
-		return et
+	if bytes.IndexByte(t, '|') == -1 {
+		return t
 	}
-	return t
+	return bytes.ReplaceAll(t, []byte{'|'}, []byte{'\\', '|'})
 }
@@ -11,13 +11,17 @@ import (
 	"github.com/gardener/docforge/pkg/workers/document/markdown"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"github.com/yuin/goldmark"
 	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/extension"
 	"github.com/yuin/goldmark/renderer"
+	"github.com/yuin/goldmark/text"
 )
 
 var _ = Describe("Links modifier", func() {
 	var (
 		lr  *linkResolver
+		gm  goldmark.Markdown
 		rnd renderer.Renderer
 		md  string
 		doc ast.Node
@@ -27,12 +31,13 @@ var _ = Describe("Links modifier", func() {
 	)
 	BeforeEach(func() {
 		lr = &linkResolver{}
+		gm = markdown.New()
 		rnd = markdown.NewLinkModifierRenderer(markdown.WithLinkResolver(lr.fakeLink))
 		md = "## Heading level 2\n\nI really like using Markdown.\n"
 		exp = md
 	})
 	JustBeforeEach(func() {
-		doc, err = markdown.Parse(markdown.New(), []byte(md))
+		doc, err = markdown.Parse(gm, []byte(md))
 		Expect(err).NotTo(HaveOccurred())
 		Expect(doc).NotTo(BeNil())
 		buf = &bytes.Buffer{}
@@ -112,13 +117,49 @@ var _ = Describe("Links modifier", func() {
 		Context("reference link", func() {
 			BeforeEach(func() {
 				md = "link:\n[foo][bar]\n\n[bar]: /url \"title\"\n"
-				exp = "link:\n[foo](https://fake.com \"title\")\n\n"
+				exp = "link:\n[foo][bar]\n\n[bar]: https://fake.com \"title\"\n"
 			})
-			It("modifies reference link", func() {
+			It("modifies the reference definition", func() {
 				Expect(err).NotTo(HaveOccurred())
 				Expect(buf.Bytes()).To(Equal([]byte(exp)))
 			})
 		})
+		Context("reference links with shared definitions", func() {
+			BeforeEach(func() {
+				lr.dst = "/docs/guide/"
+				md = "See the [guide][docs], [Docs][] and [docs].\n\n- also [the guide][DOCS]\n\n[docs]: ./guide.md 'Guide'\n[unused]: https://example.com\n\nEnd.\n"
+				exp = "See the [guide][docs], [Docs][] and [docs].\n\n- also [the guide][DOCS]\n\n[docs]: /docs/guide/ \"Guide\"\n[unused]: /docs/guide/\n\nEnd.\n"
+			})
+			It("preserves the references and round-trips", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(buf.String()).To(Equal(exp))
+				doc, err = markdown.Parse(markdown.New(), buf.Bytes())
+				Expect(err).NotTo(HaveOccurred())
+				rendered := &bytes.Buffer{}
+				Expect(rnd.Render(rendered, buf.Bytes(), doc)).To(Succeed())
+				Expect(rendered.String()).To(Equal(exp))
+			})
+		})
+		Context("reference link with multi-line definition", func() {
+			BeforeEach(func() {
+				md = "[foo]\n\n[foo]: /url\n  \"title\"\n"
+				exp = "[foo](https://fake.com \"title\")\n\n"
+			})
+			It("inlines the reference link", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(buf.String()).To(Equal(exp))
+			})
+		})
+		Context("reference link with formatted text", func() {
+			BeforeEach(func() {
+				md = "[*foo*][bar]\n\n[bar]: /url\n"
+				exp = "[*foo*](https://fake.com)\n\n[bar]: https://fake.com\n"
+			})
+			It("inlines the reference link", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(buf.String()).To(Equal(exp))
+			})
+		})
 		Context("URL in brackets", func() {
 			BeforeEach(func() {
 				lr.dst = "https://fake.com"
@@ -140,6 +181,48 @@ var _ = Describe("Links modifier", func() {
 			})
 		})
 	})
+	When("Render markdown with dropped links", func() {
+		BeforeEach(func() {
+			lr.dst = "https://github.com/owner/private/blob/master/docs.md"
+			lr.err = markdown.ErrDropLink
+			md = "See [the **private** docs](https://github.com/owner/private/blob/master/docs.md) and ![logo](logo.png \"Logo\").\n"
+			exp = "See the **private** docs and .\n"
+		})
+		It("keeps the text of links and removes images", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(buf.Bytes()).To(Equal([]byte(exp)))
+		})
+		Context("link with image", func() {
+			BeforeEach(func() {
+				md = "[![logo](logo.png) docs](https://github.com/owner/private)\n"
+				exp = " docs\n"
+			})
+			It("drops both", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(buf.Bytes()).To(Equal([]byte(exp)))
+			})
+		})
+		Context("HTML links and images", func() {
+			BeforeEach(func() {
+				md = "<a href=\"https://github.com/owner/private\" title=\"Private\">docs</a> <img src=\"logo.png\"/>\n"
+				exp = "<a title=\"Private\">docs</a> \n"
+			})
+			It("removes the href and the images", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(buf.Bytes()).To(Equal([]byte(exp)))
+			})
+		})
+		Context("reference definition", func() {
+			BeforeEach(func() {
+				md = "[docs][private]\n\n[private]: https://github.com/owner/private/blob/master/docs.md\n"
+				exp = md
+			})
+			It("keeps the destination", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(buf.Bytes()).To(Equal([]byte(exp)))
+			})
+		})
+	})
 	When("Render markdown with images", func() {
 		BeforeEach(func() {
 			lr.dst = "https://fake.com"
@@ -153,13 +236,23 @@ var _ = Describe("Links modifier", func() {
 		Context("reference image", func() {
 			BeforeEach(func() {
 				md = "image:\n![foo][bar]\n\n[bar]: /url\n"
-				exp = "image:\n![foo](https://fake.com)\n\n"
+				exp = "image:\n![foo][bar]\n\n[bar]: https://fake.com\n"
 			})
-			It("modifies reference image", func() {
+			It("modifies the reference definition", func() {
 				Expect(err).NotTo(HaveOccurred())
 				Expect(buf.Bytes()).To(Equal([]byte(exp)))
 			})
 		})
+		Context("definition referenced by a link and an image", func() {
+			BeforeEach(func() {
+				md = "[foo] ![foo]\n\n[foo]: /url\n"
+				exp = "[foo](https://fake.com) ![foo](https://fake.com)\n"
+			})
+			It("inlines the references", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(buf.String()).To(Equal(exp))
+			})
+		})
 		Context("image resolve error", func() {
 			BeforeEach(func() {
 				lr.err = errors.New("fake-error")
@@ -230,14 +323,375 @@ var _ = Describe("Links modifier", func() {
 			})
 		})
 	})
+	When("Render markdown with fenced code blocks languages aliases", func() {
+		BeforeEach(func() {
+			rnd = markdown.NewLinkModifierRenderer(markdown.WithLinkResolver(lr.fakeLink), markdown.WithLanguageAliases(map[string]string{"yml": "yaml", "sh": "bash"}))
+			md = "yml:\n```yml\nkey: value\n  indented: [a, b]\n```\n\nno alias:\n```go\nfmt.Println()\n```\n"
+			exp = "yml:\n```yaml\nkey: value\n  indented: [a, b]\n```\n\nno alias:\n```go\nfmt.Println()\n```\n"
+		})
+		It("normalizes the languages and preserves the content", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(buf.String()).To(Equal(exp))
+		})
+	})
+	When("Render markdown with footnotes", func() {
+		BeforeEach(func() {
+			lr.dst = "https://fake.com"
+			md = "Text[^1] with notes[^note] and again[^1].\n\n[^1]: First note.\n[^note]: See [docs](./docs.md).\n\n    Second paragraph.\n\nAfter.\n"
+			exp = "Text[^1] with notes[^note] and again[^1].\n\nAfter.\n\n[^1]: First note.\n[^note]: See [docs](https://fake.com).\n    \n    Second paragraph.\n"
+		})
+		It("writes the references and the definitions with resolved links", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(buf.String()).To(Equal(exp))
+			Expect(lr.resolved).To(Equal([]string{"./docs.md"}))
+		})
+		Context("undefined footnote reference", func() {
+			BeforeEach(func() {
+				md = "Undefined[^x] reference.\n"
+				exp = md
+			})
+			It("keeps the reference text", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(buf.String()).To(Equal(exp))
+			})
+		})
+		Context("footnote link resolve error", func() {
+			BeforeEach(func() {
+				lr.err = errors.New("fake-error")
+			})
+			It("fails to render document", func() {
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("fake-error"))
+			})
+		})
+	})
+	When("Render markdown with tables", func() {
+		BeforeEach(func() {
+			md = "| left | center | right |\n|:-----|:------:|------:|\n| 1 | 2 | 3 |\n"
+			exp = "| left | center | right |\n| :-- | :-: | --: |\n| 1 | 2 | 3 |\n"
+		})
+		It("preserves the column alignments", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(buf.String()).To(Equal(exp))
+		})
+		Context("header only table", func() {
+			BeforeEach(func() {
+				md = "| left | center | right | none |\n|:-|:-:|-:|-|\n"
+				exp = "| left | center | right | none |\n| :-- | :-: | --: | --- |\n"
+			})
+			It("writes the alignments row", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(buf.String()).To(Equal(exp))
+			})
+		})
+		Context("cells with escaped pipes", func() {
+			BeforeEach(func() {
+				md = "| a \\| b | `c \\| d` |\n|:-:|--:|\n| `e\\\\|f` | g \\| h |\n"
+				exp = "| a \\| b | `c \\| d` |\n| :-: | --: |\n| `e\\\\|f` | g \\| h |\n"
+			})
+			It("keeps the pipes escaped and the columns count", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(buf.String()).To(Equal(exp))
+				doc, err = markdown.Parse(markdown.New(), buf.Bytes())
+				Expect(err).NotTo(HaveOccurred())
+				rendered := &bytes.Buffer{}
+				Expect(rnd.Render(rendered, buf.Bytes(), doc)).To(Succeed())
+				Expect(rendered.String()).To(Equal(exp))
+			})
+		})
+	})
+	When("Render markdown with definition lists", func() {
+		BeforeEach(func() {
+			lr.dst = "https://fake.com"
+			gm = markdown.New(extension.DefinitionList)
+			md = "Intro:\n\nTerm\n: Definition with [docs](./docs.md).\n\nAfter.\n"
+			exp = "Intro:\n\nTerm\n: Definition with [docs](https://fake.com).\n\nAfter.\n"
+		})
+		It("writes the term and the definition with resolved links", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(buf.String()).To(Equal(exp))
+			Expect(lr.resolved).To(Equal([]string{"./docs.md"}))
+		})
+		Context("terms with multiple definitions", func() {
+			BeforeEach(func() {
+				md = "First *term*\n: One\n: Two\n  continued\n\nSecond\nThird\n\n:   Loose\n\n    paragraph\n"
+				exp = "First *term*\n: One\n: Two\n  continued\n\nSecond\nThird\n\n: Loose\n  \n  paragraph\n"
+			})
+			It("keeps the definitions of every term", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(buf.String()).To(Equal(exp))
+				doc, err = markdown.Parse(gm, buf.Bytes())
+				Expect(err).NotTo(HaveOccurred())
+				rendered := &bytes.Buffer{}
+				Expect(rnd.Render(rendered, buf.Bytes(), doc)).To(Succeed())
+				Expect(rendered.String()).To(Equal(exp))
+			})
+		})
+		Context("definitions with blocks and definition lists in containers", func() {
+			BeforeEach(func() {
+				md = "Term\n: Definition\n  - item one\n  - item two\n\nCode\n:   Definition\n\n        code block\n\n- list\n\n  Nested\n  : In list\n\n> Quoted\n> : In quote\n"
+				exp = "Term\n: Definition\n  - item one\n  - item two\n\nCode\n: Definition\n  \n  ```\n  code block\n  ```\n\n- list\n  \n  Nested\n  : In list\n\n> Quoted\n> : In quote\n"
+			})
+			It("keeps the structure", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(buf.String()).To(Equal(exp))
+				html, rendered := &bytes.Buffer{}, &bytes.Buffer{}
+				Expect(gm.Convert([]byte(md), html)).To(Succeed())
+				Expect(gm.Convert(buf.Bytes(), rendered)).To(Succeed())
+				Expect(rendered.String()).To(Equal(html.String()))
+			})
+		})
+		Context("rendered as HTML", func() {
+			BeforeEach(func() {
+				rnd = markdown.NewLinkModifierRenderer(markdown.WithLinkResolver(lr.fakeLink), markdown.WithDefinitionLists(markdown.DefinitionListHTML))
+				md = "Term\n: One\n: Two\nAfter.\n"
+				exp = "<dl>\n<dt>\n\nTerm\n\n</dt>\n<dd>\n\nOne\n\n</dd>\n<dd>\n\nTwo\nAfter.\n\n</dd>\n</dl>\n"
+			})
+			It("writes dl elements with markdown content", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(buf.String()).To(Equal(exp))
+			})
+		})
+		Context("parser without the extension", func() {
+			BeforeEach(func() {
+				gm = markdown.New()
+				md = "Term\n: Definition\n"
+				exp = md
+			})
+			It("keeps the text", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(buf.String()).To(Equal(exp))
+			})
+		})
+	})
+	When("Render markdown with lists", func() {
+		BeforeEach(func() {
+			md = "- a\n  * b\n    + c\n- d\n\n* e\n\n3) f\n4) g\n\n1. h\n\n- ***\n"
+			exp = "- a\n  * b\n    + c\n- d\n\n* e\n\n3) f\n3) g\n\n1. h\n\n- ***\n"
+		})
+		It("keeps the list markers", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(buf.String()).To(Equal(exp))
+		})
+		Context("normalized markers", func() {
+			BeforeEach(func() {
+				rnd = markdown.NewLinkModifierRenderer(markdown.WithLinkResolver(lr.fakeLink), markdown.WithListMarkers(markdown.ListMarkers{Bullet: '*', Delimiter: '.'}))
+				exp = "* a\n  * b\n    * c\n* d\n\n- e\n\n3. f\n3. g\n\n1) h\n\n* ---\n"
+			})
+			It("converts the markers and keeps adjacent lists and start numbers", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(buf.String()).To(Equal(exp))
+			})
+		})
+	})
+	When("Render markdown with ordered lists", func() {
+		BeforeEach(func() {
+			md = "8. a\n1. b\n   1. c\n   1. d\n\n   text\n5. e\n\n> 1) f\n> 1) g\n"
+			exp = "8. a\n8. b\n   1. c\n   1. d\n   \n   text\n8. e\n\n> 1) f\n> 1) g\n"
+		})
+		It("numbers the items with the start of their list", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(buf.String()).To(Equal(exp))
+		})
+		Context("preserve numbering", func() {
+			BeforeEach(func() {
+				rnd = markdown.NewLinkModifierRenderer(markdown.WithLinkResolver(lr.fakeLink), markdown.WithListMarkers(markdown.ListMarkers{Numbering: markdown.ListNumberingPreserve}))
+				md = "8. a\n1. b\n   1. c\n   1. d\n\n   text\n5. e\n\n> 1) f\n> 3) g\n"
+				exp = "8. a\n1. b\n   1. c\n   1. d\n   \n   text\n5. e\n\n> 1) f\n> 3) g\n"
+			})
+			It("keeps the numbers of the source", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(buf.String()).To(Equal(exp))
+			})
+		})
+		Context("renumber", func() {
+			BeforeEach(func() {
+				rnd = markdown.NewLinkModifierRenderer(markdown.WithLinkResolver(lr.fakeLink), markdown.WithListMarkers(markdown.ListMarkers{Numbering: markdown.ListNumberingRenumber}))
+				md = "8. a\n1. b\n   1. c\n   1. d\n\n   text\n5. e\n   - f\n\n> 1) g\n> 1) h\n"
+				exp = "8. a\n9. b\n   1. c\n   2. d\n   \n   text\n10. e\n    - f\n\n> 1) g\n> 2) h\n"
+			})
+			It("numbers the items sequentially and indents their content after the marker", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(buf.String()).To(Equal(exp))
+			})
+		})
+	})
+	When("Render markdown with headings", func() {
+		BeforeEach(func() {
+			md = "# Title\n\nIntro\n---\n\nLong\ntitle\n===\nText\n\n### Deep\n"
+			exp = "# Title\n\n## Intro\n\n# Long title\nText\n\n### Deep\n"
+		})
+		It("renders ATX headings", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(buf.String()).To(Equal(exp))
+		})
+		Context("setext style", func() {
+			BeforeEach(func() {
+				rnd = markdown.NewLinkModifierRenderer(markdown.WithLinkResolver(lr.fakeLink), markdown.WithHeadingStyle(markdown.HeadingSetext))
+				md = "Text\n# Title\n\nIntro\n---\n\nLong\ntitle\n===\n\n### Deep\n"
+				exp = "Text\n\nTitle\n===\n\nIntro\n---\n\nLong\ntitle\n===\n\n### Deep\n"
+			})
+			It("renders level 1 and 2 headings as Setext headings", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(buf.String()).To(Equal(exp))
+			})
+		})
+		Context("preserve style", func() {
+			BeforeEach(func() {
+				rnd = markdown.NewLinkModifierRenderer(markdown.WithLinkResolver(lr.fakeLink), markdown.WithHeadingStyle(markdown.HeadingPreserve))
+				md = "# Title\n\n  ## Indented\n\nIntro\n---\n\nLong\ntitle\n===\n\n### Deep\n"
+				exp = "# Title\n\n## Indented\n\nIntro\n---\n\nLong\ntitle\n===\n\n### Deep\n"
+			})
+			It("renders the headings in their source style", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(buf.String()).To(Equal(exp))
+			})
+		})
+	})
+	When("Render markdown with emphasis", func() {
+		BeforeEach(func() {
+			md = "*a* _b_ **c** __d__ *_e_* _*f*_ ***g*** ___h___ **_i_** *j **k** l*\nfoo*bar*baz foo**bar**baz\n_a * b_ *a \\* b* _a\\*b_\n"
+			exp = md
+		})
+		It("keeps the source delimiters", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(buf.String()).To(Equal(exp))
+		})
+		Context("delimiters that can't be recovered", func() {
+			BeforeEach(func() {
+				lr.dst = "https://fake.com"
+				md = "_[a](b)_ *_[a](b)_* _[a*b](c)_\n"
+				exp = "*[a](https://fake.com)* _*[a](https://fake.com)*_ _[a*b](https://fake.com)_\n"
+			})
+			It("uses the default delimiters", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(buf.String()).To(Equal(exp))
+			})
+			It("doesn't use underscores adjacent to word characters", func() {
+				source := []byte("foo a*b")
+				emphasis := ast.NewEmphasis(1)
+				emphasis.AppendChild(emphasis, ast.NewTextSegment(text.NewSegment(4, 7)))
+				paragraph := ast.NewParagraph()
+				paragraph.AppendChild(paragraph, ast.NewTextSegment(text.NewSegment(0, 3)))
+				paragraph.AppendChild(paragraph, emphasis)
+				document := ast.NewDocument()
+				document.AppendChild(document, paragraph)
+				buf.Reset()
+				Expect(rnd.Render(buf, source, document)).To(Succeed())
+				Expect(buf.String()).To(Equal("foo*a*b*\n"))
+			})
+		})
+	})
+	When("Render markdown with math", func() {
+		BeforeEach(func() {
+			lr.dst = "https://fake.com"
+			md = "Inline $a_1 * b_2 * c_{i}$ and $_x_ + \\{y\\}$, [docs](./docs.md) $x^*$ and $$e^{i\\pi} = -1$$.\nCosts $5 and [more](./more.md) $10.\n"
+			exp = "Inline $a_1 * b_2 * c_{i}$ and $_x_ + \\{y\\}$, [docs](https://fake.com) $x^*$ and $$e^{i\\pi} = -1$$.\nCosts $5 and [more](https://fake.com) $10.\n"
+		})
+		It("passes the inline math through verbatim", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(buf.String()).To(Equal(exp))
+			Expect(lr.resolved).To(Equal([]string{"./docs.md", "./more.md"}))
+		})
+		Context("display math block", func() {
+			BeforeEach(func() {
+				md = "Text:\n$$\n|x| = \\begin{cases} x_1 & x > 0 \\\\\n|---|\n\n* a [b](c) *\n\\end{cases}\n$$\n\n> $$\n> a | b\n> $$\n"
+				exp = md
+			})
+			It("passes the block through verbatim", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(buf.String()).To(Equal(exp))
+				Expect(lr.resolved).To(BeEmpty())
+			})
+		})
+	})
+	When("Render markdown with fenced code blocks referencing snippet files", func() {
+		var snippets map[string]string
+		BeforeEach(func() {
+			snippets = map[string]string{"main.go": "package main\n\nfunc main() {\n\tfmt.Println()\n}\n"}
+			readSnippet := func(file string) ([]byte, error) {
+				if cnt, ok := snippets[file]; ok {
+					return []byte(cnt), nil
+				}
+				return nil, errors.New("not found")
+			}
+			rnd = markdown.NewLinkModifierRenderer(markdown.WithLinkResolver(lr.fakeLink), markdown.WithSnippetReader(readSnippet))
+			md = "range:\n```go {file=\"main.go#L3-L5\"}\n```\n\nline:\n```go {file=\"main.go#L1\"}\nreplaced\n```\n\nfile:\n```go {file=\"main.go\"}\n```\n"
+			exp = "range:\n```go\nfunc main() {\n\tfmt.Println()\n}\n```\n\nline:\n```go\npackage main\n```\n\nfile:\n```go\npackage main\n\nfunc main() {\n\tfmt.Println()\n}\n```\n"
+		})
+		It("inlines the referenced line ranges", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(buf.String()).To(Equal(exp))
+		})
+		Context("line range out of the snippet", func() {
+			BeforeEach(func() {
+				md = "```go {file=\"main.go#L4-L10\"}\n```\n"
+			})
+			It("fails to render document", func() {
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("line range L4-L10 is out of snippet main.go with 5 lines"))
+			})
+		})
+		Context("missing snippet file", func() {
+			BeforeEach(func() {
+				md = "```go {file=\"missing.go#L1-L2\"}\n```\n"
+			})
+			It("fails to render document", func() {
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("reading snippet missing.go failed"))
+			})
+		})
+		Context("snippet directives", func() {
+			BeforeEach(func() {
+				md = "range:\n\n<!-- snippet: main.go#L3-L5 -->\n\nline:\n<!--snippet:main.go#L1-->\n\n> quoted:\n> <!-- snippet: main.go#L1 -->\n\n<!-- not a snippet: main.go -->\n"
+				exp = "range:\n\n```go\nfunc main() {\n\tfmt.Println()\n}\n```\n\nline:\n```go\npackage main\n```\n\n> quoted:\n> ```go\n> package main\n> ```\n\n<!-- not a snippet: main.go -->\n"
+			})
+			It("inlines the referenced line ranges into fenced code blocks", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(buf.String()).To(Equal(exp))
+			})
+			Context("file without known language", func() {
+				BeforeEach(func() {
+					snippets["NOTICE"] = "Copyright\n"
+					md = "<!-- snippet: NOTICE -->\n"
+					exp = "```\nCopyright\n```\n"
+				})
+				It("inlines the file into a fenced code block without language", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(buf.String()).To(Equal(exp))
+				})
+			})
+			Context("line range out of the snippet", func() {
+				BeforeEach(func() {
+					md = "<!-- snippet: main.go#L2-L8 -->\n"
+				})
+				It("fails to render document", func() {
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(ContainSubstring("line range L2-L8 is out of snippet main.go with 5 lines"))
+				})
+			})
+		})
+	})
+	When("Render markdown with snippet directives without snippet reader", func() {
+		BeforeEach(func() {
+			md = "<!-- snippet: main.go#L1-L2 -->\n"
+			exp = md
+		})
+		It("keeps the directives", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(buf.String()).To(Equal(exp))
+		})
+	})
 })
 
 type linkResolver struct {
-	dst string
-	err error
+	dst      string
+	err      error
+	resolved []string
 }
 
-// implements markdown.ResolveLink and fakes the result
-func (lr *linkResolver) fakeLink(_ string, _ bool) (string, error) {
+// implements markdown.ResolveLink, records the resolved links and fakes the result
+func (lr *linkResolver) fakeLink(link string, _ bool) (string, error) {
+	lr.resolved = append(lr.resolved, link)
 	return lr.dst, lr.err
 }
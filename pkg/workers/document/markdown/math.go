@@ -0,0 +1,139 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package markdown
+
+import (
+	"bytes"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// KindMath is the NodeKind of Math nodes
+var KindMath = ast.NewNodeKind("Math")
+
+// Math is an inline math span e.g. $a_1$ or $$a_1$$, passed through verbatim
+type Math struct {
+	ast.BaseInline
+	// Segment is the source of the span, including its delimiters
+	Segment text.Segment
+}
+
+// Kind implements ast.Node.Kind
+func (n *Math) Kind() ast.NodeKind {
+	return KindMath
+}
+
+// Dump implements ast.Node.Dump
+func (n *Math) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, map[string]string{"Math": string(n.Segment.Value(source))}, nil)
+}
+
+// KindMathBlock is the NodeKind of MathBlock nodes
+var KindMathBlock = ast.NewNodeKind("MathBlock")
+
+// MathBlock is a display math block starting and ending with a $$ line, passed through verbatim
+type MathBlock struct {
+	ast.BaseBlock
+	closed bool
+}
+
+// Kind implements ast.Node.Kind
+func (n *MathBlock) Kind() ast.NodeKind {
+	return KindMathBlock
+}
+
+// Dump implements ast.Node.Dump
+func (n *MathBlock) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, nil, nil)
+}
+
+// IsRaw implements ast.Node.IsRaw, the lines of math blocks are not parsed
+func (n *MathBlock) IsRaw() bool {
+	return true
+}
+
+var mathDelimiter = []byte("$$")
+
+// mathInlineParser parses math spans on a single line. Like in pandoc, the opening $ must be followed
+// by a non-space and the closing $ must be preceded by a non-space and not followed by a digit,
+// so that amounts like $5 and $10 are text
+type mathInlineParser struct{}
+
+func (p *mathInlineParser) Trigger() []byte {
+	return []byte{'$'}
+}
+
+func (p *mathInlineParser) Parse(_ ast.Node, block text.Reader, _ parser.Context) ast.Node {
+	line, segment := block.PeekLine()
+	delimiter := mathDelimiter[:1]
+	if bytes.HasPrefix(line, mathDelimiter) {
+		delimiter = mathDelimiter
+	}
+	start := len(delimiter)
+	if len(line) <= start || util.IsSpace(line[start]) {
+		return nil
+	}
+	for i := start + 1; i < len(line); i++ {
+		switch {
+		case line[i] == '\\':
+			i++
+		case bytes.HasPrefix(line[i:], delimiter) && !util.IsSpace(line[i-1]):
+			stop := i + len(delimiter)
+			if stop < len(line) && (line[stop] == '$' || (line[stop] >= '0' && line[stop] <= '9')) {
+				continue
+			}
+			block.Advance(stop)
+			return &Math{Segment: segment.WithStop(segment.Start + stop)}
+		}
+	}
+	return nil
+}
+
+// mathBlockParser parses display math blocks from a line starting with $$ to a line ending with $$
+type mathBlockParser struct{}
+
+func (p *mathBlockParser) Trigger() []byte {
+	return []byte{'$'}
+}
+
+func (p *mathBlockParser) Open(_ ast.Node, reader text.Reader, pc parser.Context) (ast.Node, parser.State) {
+	line, segment := reader.PeekLine()
+	pos := pc.BlockOffset()
+	if pos < 0 || !bytes.HasPrefix(line[pos:], mathDelimiter) || bytes.Contains(line[pos+len(mathDelimiter):], mathDelimiter) {
+		return nil, parser.NoChildren
+	}
+	node := &MathBlock{}
+	node.Lines().Append(segment)
+	reader.Advance(segment.Len() - 1)
+	return node, parser.NoChildren
+}
+
+func (p *mathBlockParser) Continue(node ast.Node, reader text.Reader, _ parser.Context) parser.State {
+	n := node.(*MathBlock)
+	if n.closed {
+		return parser.Close
+	}
+	line, segment := reader.PeekLine()
+	if line == nil {
+		return parser.Close
+	}
+	n.Lines().Append(segment)
+	reader.Advance(segment.Len() - 1)
+	n.closed = bytes.HasSuffix(bytes.TrimRight(line, " \t\r\n"), mathDelimiter)
+	return parser.Continue | parser.NoChildren
+}
+
+func (p *mathBlockParser) Close(_ ast.Node, _ text.Reader, _ parser.Context) {}
+
+func (p *mathBlockParser) CanInterruptParagraph() bool {
+	return true
+}
+
+func (p *mathBlockParser) CanAcceptIndentedLine() bool {
+	return false
+}
@@ -13,21 +13,37 @@ import (
 	"github.com/yuin/goldmark/extension"
 	"github.com/yuin/goldmark/parser"
 	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
 )
 
-// New creates a markdown parser
-func New() goldmark.Markdown {
+// New creates a markdown parser with optional extensions e.g. extension.DefinitionList.
+// The parser is safe for concurrent use, as it is initialized once and every Parse call has its own context
+func New(optional ...goldmark.Extender) goldmark.Markdown {
 	// extends Linkify regex by excluding trailing whitespaces and punctuations `[^\s<?!.,:*_~]`
 	urlRgx := regexp.MustCompile(`^(?:http|https|ftp)://[-a-zA-Z0-9@:%._\+~#=]{1,256}\.[a-z]+(?::\d+)?(?:[/#?][-a-zA-Z0-9@:%_+.~#$!?&/=\(\);,'">\^{}\[\]` + "`" + `]*)?[^\s<?!.,:*_~]`)
-	// parser extension for GitHub Flavored Markdown & Frontmatter support
+	// parser extension for GitHub Flavored Markdown, Footnotes & Frontmatter support
 	extensions := []goldmark.Extender{
 		extension.GFM,
+		extension.Footnote,
 		meta.Meta,
 	}
-	return goldmark.New(goldmark.WithExtensions(extensions...), goldmark.WithParserOptions(extension.WithLinkifyURLRegexp(urlRgx)))
+	extensions = append(extensions, optional...)
+	// link reference definitions are recorded before the goldmark link reference paragraph transformer with priority 100 removes them
+	references := []parser.Option{
+		parser.WithParagraphTransformers(util.Prioritized(&referenceParagraphTransformer{}, 99)),
+		parser.WithASTTransformers(util.Prioritized(&referenceASTTransformer{}, 100)),
+	}
+	// math is passed through verbatim, display math blocks are parsed before fenced code blocks with priority 700
+	math := []parser.Option{
+		parser.WithBlockParsers(util.Prioritized(&mathBlockParser{}, 690)),
+		parser.WithInlineParsers(util.Prioritized(&mathInlineParser{}, 90)),
+	}
+	options := append(append(references, math...), extension.WithLinkifyURLRegexp(urlRgx))
+	return goldmark.New(goldmark.WithExtensions(extensions...), goldmark.WithParserOptions(options...))
 }
 
-// Parse markdown content and returns AST node or error
+// Parse markdown content and returns AST node or error.
+// The returned AST is owned by the caller and must be rendered with a renderer created per document
 func Parse(markdown goldmark.Markdown, source []byte) (ast.Node, error) {
 	reader := text.NewReader(source)
 	context := parser.NewContext()
@@ -0,0 +1,211 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package markdown
+
+import (
+	"bytes"
+	"regexp"
+
+	"github.com/yuin/goldmark/ast"
+	"github.com/yuin/goldmark/parser"
+	"github.com/yuin/goldmark/text"
+	"github.com/yuin/goldmark/util"
+)
+
+// KindLinkReferenceDefinitions is the NodeKind of LinkReferenceDefinitions nodes
+var KindLinkReferenceDefinitions = ast.NewNodeKind("LinkReferenceDefinitions")
+
+// LinkReferenceDefinition is a link reference definition e.g. [label]: /url "title"
+type LinkReferenceDefinition struct {
+	Label       []byte
+	Destination []byte
+	Title       []byte
+	// Image is true when the definition is referenced by images
+	Image bool
+}
+
+// LinkReferenceDefinitions is a block of link reference definitions preserved in the document
+type LinkReferenceDefinitions struct {
+	ast.BaseBlock
+	Definitions []*LinkReferenceDefinition
+	// paragraph is the paragraph the definitions are parsed from and lines is its lines count before parsing
+	paragraph *ast.Paragraph
+	lines     int
+}
+
+// Kind implements ast.Node.Kind
+func (n *LinkReferenceDefinitions) Kind() ast.NodeKind {
+	return KindLinkReferenceDefinitions
+}
+
+// Dump implements ast.Node.Dump
+func (n *LinkReferenceDefinitions) Dump(source []byte, level int) {
+	ast.DumpHelper(n, source, level, nil, nil)
+}
+
+// referenceAttribute is the attribute of links and images referencing a preserved definition.
+// Its value is the source following the link text e.g. [label] or [] and empty for shortcut references
+var referenceAttribute = []byte("docforge-reference")
+
+// single line link reference definitions, with the label as group
+var linkReferenceDefinition = regexp.MustCompile(`^ {0,3}\[((?:[^\[\]\\]|\\.)*[^\s\[\]\\](?:[^\[\]\\]|\\.)*)\]:[ \t]*\S`)
+
+// referenceParagraphTransformer records the leading single line link reference definitions of
+// paragraphs before they are removed by the goldmark parser
+type referenceParagraphTransformer struct{}
+
+func (t *referenceParagraphTransformer) Transform(node *ast.Paragraph, reader text.Reader, _ parser.Context) {
+	lines := node.Lines()
+	block := &LinkReferenceDefinitions{paragraph: node, lines: lines.Len()}
+	for i := 0; i < lines.Len(); i++ {
+		line := lines.At(i)
+		m := linkReferenceDefinition.FindSubmatch(line.Value(reader.Source()))
+		if m == nil {
+			break
+		}
+		block.Definitions = append(block.Definitions, &LinkReferenceDefinition{Label: m[1]})
+	}
+	if len(block.Definitions) > 0 {
+		node.Parent().InsertBefore(node.Parent(), node, block)
+	}
+}
+
+// referenceASTTransformer keeps the recorded definitions parsed by goldmark exactly as recorded and marks
+// the links and images referencing them. Documents whose definitions can't be preserved are left as parsed,
+// so that their references are inlined when rendered
+type referenceASTTransformer struct{}
+
+func (t *referenceASTTransformer) Transform(doc *ast.Document, reader text.Reader, pc parser.Context) {
+	var blocks []*LinkReferenceDefinitions
+	_ = ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if block, ok := node.(*LinkReferenceDefinitions); ok && entering {
+			blocks = append(blocks, block)
+		}
+		return ast.WalkContinue, nil
+	})
+	definitions := map[string]*LinkReferenceDefinition{}
+	for _, block := range blocks {
+		if !block.parsed(pc) {
+			removeBlocks(blocks)
+			return
+		}
+		for _, def := range block.Definitions {
+			if _, ok := definitions[util.ToLinkReference(def.Label)]; !ok {
+				definitions[util.ToLinkReference(def.Label)] = def
+			}
+		}
+	}
+	if len(definitions) == 0 {
+		return
+	}
+	references := map[ast.Node]*LinkReferenceDefinition{}
+	linked := map[*LinkReferenceDefinition]bool{}
+	_ = ast.Walk(doc, func(node ast.Node, entering bool) (ast.WalkStatus, error) {
+		if !entering || (node.Kind() != ast.KindLink && node.Kind() != ast.KindImage) {
+			return ast.WalkContinue, nil
+		}
+		label, suffix, ok := referenceLabel(node, reader.Source())
+		if !ok {
+			return ast.WalkContinue, nil
+		}
+		def, ok := definitions[util.ToLinkReference(label)]
+		if !ok {
+			return ast.WalkContinue, nil
+		}
+		node.SetAttribute(referenceAttribute, suffix)
+		references[node] = def
+		if node.Kind() == ast.KindImage {
+			def.Image = true
+		} else {
+			linked[def] = true
+		}
+		return ast.WalkContinue, nil
+	})
+	// definitions referenced by links and images resolve differently, so they are inlined
+	for node, def := range references {
+		if def.Image && linked[def] {
+			node.RemoveAttributes()
+		}
+	}
+	for _, block := range blocks {
+		kept := block.Definitions[:0]
+		for _, def := range block.Definitions {
+			if definitions[util.ToLinkReference(def.Label)] == def && !(def.Image && linked[def]) {
+				kept = append(kept, def)
+			}
+		}
+		block.Definitions = kept
+		if len(kept) == 0 {
+			block.Parent().RemoveChild(block.Parent(), block)
+		}
+	}
+}
+
+// parsed checks if goldmark parsed exactly the recorded definitions and sets their destinations and titles.
+// The remains of paragraphs consisting of definitions only are removed
+func (n *LinkReferenceDefinitions) parsed(pc parser.Context) bool {
+	remaining := n.lines
+	next := n.NextSibling()
+	if next == n.paragraph {
+		remaining = n.paragraph.Lines().Len()
+	} else if next != nil && next.Kind() == ast.KindTextBlock && next.Lines().Len() == 0 {
+		remaining = 0
+	}
+	if n.lines-remaining != len(n.Definitions) {
+		return false
+	}
+	for _, def := range n.Definitions {
+		ref, ok := pc.Reference(util.ToLinkReference(def.Label))
+		if !ok {
+			return false
+		}
+		def.Destination = ref.Destination()
+		def.Title = ref.Title()
+	}
+	if remaining == 0 && next != nil {
+		n.SetBlankPreviousLines(next.HasBlankPreviousLines())
+		next.Parent().RemoveChild(next.Parent(), next)
+	}
+	return true
+}
+
+func removeBlocks(blocks []*LinkReferenceDefinitions) {
+	for _, block := range blocks {
+		block.Parent().RemoveChild(block.Parent(), block)
+	}
+}
+
+// referenceLabel returns the label of a full, collapsed or shortcut reference link or image and the
+// source following its text. Links whose text doesn't start and end with plain text are not supported
+func referenceLabel(node ast.Node, source []byte) ([]byte, []byte, bool) {
+	first, ok := node.FirstChild().(*ast.Text)
+	if !ok {
+		return nil, nil, false
+	}
+	last, ok := node.LastChild().(*ast.Text)
+	if !ok {
+		return nil, nil, false
+	}
+	stop := last.Segment.Stop
+	if stop >= len(source) || source[stop] != ']' {
+		return nil, nil, false
+	}
+	linkText := source[first.Segment.Start:stop]
+	rest := source[stop+1:]
+	switch {
+	case bytes.HasPrefix(rest, []byte("[]")):
+		return linkText, []byte("[]"), true
+	case bytes.HasPrefix(rest, []byte("[")):
+		end := bytes.IndexAny(rest[1:], "[]")
+		if end == -1 || rest[end+1] != ']' {
+			return nil, nil, false
+		}
+		return rest[1 : end+1], rest[:end+2], true
+	case bytes.HasPrefix(rest, []byte("(")):
+		return nil, nil, false
+	default:
+		return linkText, []byte{}, true
+	}
+}
@@ -76,7 +76,7 @@ func (tc *spec) executeSpecTest(t *testing.T) {
 	// Goldmark Markdown with GFM extensions
 	var gm = goldmark.New(goldmark.WithRendererOptions(gmhtml.WithUnsafe()), goldmark.WithExtensions(extension.GFM))
 	// Link modifier renderer
-	var lmr = markdown.NewLinkModifierRenderer()
+	var lmr = markdown.NewLinkModifierRenderer(markdown.WithHeadingStyle(markdown.HeadingPreserve))
 	var (
 		doc ast.Node
 		err error
@@ -0,0 +1,80 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package document
+
+import (
+	"time"
+
+	"github.com/gardener/docforge/pkg/buildstate"
+)
+
+// Options configures the processing of documents. The zero value of each field is its default
+type Options struct {
+	// SkipLinkValidation skips the validation of the links of the documents
+	SkipLinkValidation bool
+	// LinkRedirects maps the links of moved documents to their new locations
+	LinkRedirects map[string]string
+	// MaxRedirectDepth bounds the chains of link redirects, unbounded when 0. Redirect loops fail in any case
+	MaxRedirectDepth int
+	// AnchorRedirects maps the anchors of renamed sections of all documents to their new anchors
+	AnchorRedirects map[string]string
+	// HostAliases maps hosts to the host their links are resolved against
+	HostAliases map[string]string
+	// AbsoluteLinkRepos are the hosts, owners or repositories whose links are kept absolute
+	AbsoluteLinkRepos []string
+	// RelativeVersionLinks are the versions whose links to documents of the same version are written relative
+	RelativeVersionLinks []string
+	// BasePath is the path the bundle is served under, like /docs
+	BasePath string
+	// KeepLineEndings keeps the CRLF and CR line endings of the sources instead of normalizing them to LF
+	KeepLineEndings bool
+	// CodeLanguageAliases maps the languages of fenced code blocks to the languages they are rendered with
+	CodeLanguageAliases map[string]string
+	// KeepDocumentFrontmatter keeps the document frontmatter for keys the node frontmatter also sets, instead of
+	// overriding it
+	KeepDocumentFrontmatter bool
+	// SourceFrontmatter adds frontmatter keys describing the document source
+	SourceFrontmatter bool
+	// CodeSnippets inlines the files referenced by fenced code blocks and snippet directives
+	CodeSnippets bool
+	// EditURLKey is the frontmatter key of the edit URL of the documents, not set when empty
+	EditURLKey string
+	// ContentTransforms are the rewrites of the content of the documents with matching sources
+	ContentTransforms []ContentTransform
+	// DefinitionLists is the form definition lists are rendered in, markdown or html, not parsed when empty
+	DefinitionLists string
+	// HeadingStyle is the style headings are rendered in, atx (default), setext or preserve
+	HeadingStyle string
+	// BulletListMarker is the marker of bullet list items, -, * or +, kept when empty
+	BulletListMarker string
+	// OrderedListDelimiter is the delimiter of ordered list items, . or ), kept when empty
+	OrderedListDelimiter string
+	// OrderedListNumbering is the numbering of ordered list items, preserve or renumber
+	OrderedListNumbering string
+	// InlineImagesMaxSize is the size in bytes up to which images are inlined as data URIs, disabled when 0
+	InlineImagesMaxSize int
+	// InlineSVGsMaxSize is the size in bytes up to which SVG images are inlined as svg elements, disabled when 0
+	InlineSVGsMaxSize int
+	// BadgeHosts are the patterns of hosts, or of hosts and paths, of dynamic images like badges, which are kept absolute
+	BadgeHosts []string
+	// InaccessibleLinks is the treatment of links to inaccessible resources, error, warn or drop, unchecked when empty
+	InaccessibleLinks string
+	// ResourcesDownloadPath is the path of the downloaded resources relative to the destination
+	ResourcesDownloadPath string
+	// State records the written documents of a build so that a failed build can be resumed, not recorded when nil
+	State *buildstate.State
+	// DocumentTimeout bounds the processing of each document, unbounded when 0
+	DocumentTimeout time.Duration
+	// RenderWorkers is the number of sources of a document read and rendered in parallel, sequentially when up to 1
+	RenderWorkers int
+	// LintRules are the severities, warning or error, of the rules the markdown documents are checked with, by rule
+	LintRules map[string]string
+	// TitleCase is the case, title (default), sentence or as-is, of the titles derived from node names
+	TitleCase string
+	// TitleAcronyms are the words kept as listed in the titles derived from node names
+	TitleAcronyms []string
+	// TitleReplacements maps words of node names to the text replacing them in the derived titles
+	TitleReplacements map[string]string
+}
@@ -0,0 +1,64 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package document
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// ContentTransform is a find and replace of the content of documents with sources matching a pattern
+type ContentTransform struct {
+	// Source is the pattern of the source URLs of the transformed documents
+	Source string `mapstructure:"source"`
+	// Find is the pattern of the replaced content
+	Find string `mapstructure:"find"`
+	// Replace is the replacement of the Find matches. It can reference their groups as $1 or ${name}
+	Replace string `mapstructure:"replace"`
+}
+
+type contentTransform struct {
+	source  *regexp.Regexp
+	find    *regexp.Regexp
+	replace []byte
+}
+
+// Transformer applies content transforms to the documents in the order of their configuration
+type Transformer struct {
+	transforms []contentTransform
+}
+
+// NewTransformer creates a Transformer from the content transforms configuration
+func NewTransformer(transforms []ContentTransform) (*Transformer, error) {
+	t := &Transformer{}
+	for i, transform := range transforms {
+		source, err := regexp.Compile(transform.Source)
+		if err != nil {
+			return nil, fmt.Errorf("invalid source pattern of content transform %d: %w", i, err)
+		}
+		if transform.Find == "" {
+			return nil, fmt.Errorf("content transform %d has no find pattern", i)
+		}
+		find, err := regexp.Compile(transform.Find)
+		if err != nil {
+			return nil, fmt.Errorf("invalid find pattern of content transform %d: %w", i, err)
+		}
+		t.transforms = append(t.transforms, contentTransform{source, find, []byte(transform.Replace)})
+	}
+	return t, nil
+}
+
+// Transform applies the transforms matching the source to its content
+func (t *Transformer) Transform(source string, content []byte) []byte {
+	if t == nil {
+		return content
+	}
+	for _, transform := range t.transforms {
+		if transform.source.MatchString(source) {
+			content = transform.find.ReplaceAll(content, transform.replace)
+		}
+	}
+	return content
+}
@@ -7,12 +7,16 @@ package githubinfo
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"reflect"
+	"sync"
+	"time"
 
 	"github.com/gardener/docforge/pkg/manifest"
 	"github.com/gardener/docforge/pkg/registry"
+	"github.com/gardener/docforge/pkg/registry/repositoryhost"
 	"github.com/gardener/docforge/pkg/writers"
 	"k8s.io/klog/v2"
 )
@@ -21,8 +25,16 @@ import (
 type Worker struct {
 	registry registry.Interface
 	writer   writers.Writer
+
+	// mux guards prefetched and lastModified
+	mux          sync.RWMutex
+	prefetched   map[string][]byte
+	lastModified map[*manifest.Node]time.Time
 }
 
+// GraphQLBatchSize is the maximum number of resources queried in a single GraphQL request
+var GraphQLBatchSize = 50
+
 // NewGithubWorker creates new Worker object
 func NewGithubWorker(registry registry.Interface, writer writers.Writer) (*Worker, error) {
 	if registry == nil || reflect.ValueOf(registry).IsNil() {
@@ -32,8 +44,10 @@ func NewGithubWorker(registry registry.Interface, writer writers.Writer) (*Worke
 		return nil, errors.New("invalid argument: writer is nil")
 	}
 	return &Worker{
-		registry,
-		writer,
+		registry:     registry,
+		writer:       writer,
+		prefetched:   map[string][]byte{},
+		lastModified: map[*manifest.Node]time.Time{},
 	}, nil
 }
 
@@ -57,11 +71,12 @@ func (w *Worker) WriteGithubInfo(ctx context.Context, node *manifest.Node) error
 	for _, s := range sources {
 		klog.V(6).Infof("reading git info for %s\n", s)
 		// read github info
-		if info, err = w.registry.ReadGitInfo(ctx, s); err != nil {
+		if info, err = w.readGitInfo(ctx, s); err != nil {
 			return fmt.Errorf("failed to read git info for %s: %v", s, err)
 		}
 		if info != nil {
 			b.Write(info)
+			w.recordLastModified(node, s, info)
 		}
 	}
 	nodePath := node.Path
@@ -71,3 +86,91 @@ func (w *Worker) WriteGithubInfo(ctx context.Context, node *manifest.Node) error
 	}
 	return nil
 }
+
+// Prefetch reads the git info of the nodes sources with batched GitHub GraphQL queries.
+// Sources that couldn't be prefetched are read with the REST API on write.
+func (w *Worker) Prefetch(ctx context.Context, nodes []*manifest.Node) {
+	batches := map[string][]repositoryhost.URL{}
+	sources := map[string][]string{}
+	var hosts []string
+	for _, node := range nodes {
+		for _, s := range append([]string{node.Source}, node.MultiSource...) {
+			if s == "" {
+				continue
+			}
+			r, err := w.registry.ResourceURL(s)
+			if err != nil || r == nil {
+				continue
+			}
+			if _, ok := sources[r.String()]; ok {
+				sources[r.String()] = append(sources[r.String()], s)
+				continue
+			}
+			sources[r.String()] = []string{s}
+			if _, ok := batches[r.GetHost()]; !ok {
+				hosts = append(hosts, r.GetHost())
+			}
+			batches[r.GetHost()] = append(batches[r.GetHost()], *r)
+		}
+	}
+	for _, host := range hosts {
+		resources := batches[host]
+		client := w.registry.Client(resources[0].String())
+		opts := w.registry.GitInfoOptions(resources[0].String())
+		for start := 0; start < len(resources); start += GraphQLBatchSize {
+			end := min(start+GraphQLBatchSize, len(resources))
+			infos, err := repositoryhost.ReadGitInfos(ctx, client, repositoryhost.GraphQLEndpoint(host), resources[start:end], opts)
+			if err != nil {
+				klog.Warningf("batched git info read from %s failed, falling back to REST: %v\n", host, err)
+				break
+			}
+			w.mux.Lock()
+			for r, info := range infos {
+				for _, s := range sources[r] {
+					w.prefetched[s] = info
+				}
+			}
+			w.mux.Unlock()
+		}
+	}
+}
+
+// LastModified returns the latest last modified date of the sources of the node, as read with their git info
+func (w *Worker) LastModified(node *manifest.Node) (time.Time, bool) {
+	w.mux.RLock()
+	defer w.mux.RUnlock()
+	lastModified, ok := w.lastModified[node]
+	return lastModified, ok
+}
+
+// recordLastModified records the last modified date of the git info of a source of the node if it's the latest
+func (w *Worker) recordLastModified(node *manifest.Node, source string, info []byte) {
+	var gitInfo repositoryhost.GitInfo
+	if err := json.Unmarshal(info, &gitInfo); err != nil || gitInfo.LastModifiedDate == nil {
+		return
+	}
+	layout := w.registry.GitInfoOptions(source).DateLayout
+	if layout == "" {
+		layout = repositoryhost.DateFormat
+	}
+	lastModified, err := time.Parse(layout, *gitInfo.LastModifiedDate)
+	if err != nil {
+		klog.Warningf("parsing the last modified date of %s failed: %v\n", source, err)
+		return
+	}
+	w.mux.Lock()
+	defer w.mux.Unlock()
+	if lastModified.After(w.lastModified[node]) {
+		w.lastModified[node] = lastModified
+	}
+}
+
+func (w *Worker) readGitInfo(ctx context.Context, source string) ([]byte, error) {
+	w.mux.RLock()
+	info, ok := w.prefetched[source]
+	w.mux.RUnlock()
+	if ok {
+		return info, nil
+	}
+	return w.registry.ReadGitInfo(ctx, source)
+}
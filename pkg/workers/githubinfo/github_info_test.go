@@ -7,9 +7,15 @@ package githubinfo_test
 import (
 	"context"
 	"errors"
+	"io"
+	"net/http"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gardener/docforge/pkg/manifest"
+	"github.com/gardener/docforge/pkg/osfakes/httpclient/httpclientfakes"
+	"github.com/gardener/docforge/pkg/osfakes/osshim/osshimfakes"
 	"github.com/gardener/docforge/pkg/registry/registryfakes"
 	"github.com/gardener/docforge/pkg/registry/repositoryhost"
 	"github.com/gardener/docforge/pkg/workers/githubinfo"
@@ -104,6 +110,28 @@ var _ = Describe("Executing WriteGithubInfo", func() {
 		})
 	})
 
+	Context("git info with last modified dates", func() {
+		BeforeEach(func() {
+			registry.ReadGitInfoCalls(func(ctx context.Context, s string) ([]byte, error) {
+				switch s {
+				case "https://github.com/gardener/docforge/blob/feature/A.md":
+					return []byte(`{"lastmod": "2024-02-07 13:11:00"}`), nil
+				case "https://github.com/gardener/docforge/blob/feature/B.md":
+					return []byte(`{"lastmod": "2024-02-06 13:11:00"}`), nil
+				}
+				return nil, nil
+			})
+		})
+		It("records the latest last modified date of the sources", func() {
+			Expect(err).NotTo(HaveOccurred())
+			lastModified, ok := worker.LastModified(taskNode)
+			Expect(ok).To(BeTrue())
+			Expect(lastModified).To(Equal(time.Date(2024, 2, 7, 13, 11, 0, 0, time.UTC)))
+			_, ok = worker.LastModified(&manifest.Node{})
+			Expect(ok).To(BeFalse())
+		})
+	})
+
 	Context("write fails", func() {
 		BeforeEach(func() {
 			writer.WriteReturns(errors.New("fake_write_err"))
@@ -125,3 +153,66 @@ var _ = Describe("Executing WriteGithubInfo", func() {
 		Expect(string(content)).To(Equal("repoHost1 source_content\nrepoHost2 multi_source_content\nrepoHost2 multi_source_content 2\n"))
 	})
 })
+
+var _ = Describe("Prefetching github info", func() {
+	var (
+		registry *registryfakes.FakeInterface
+		client   *httpclientfakes.FakeClient
+		writer   *writersfakes.FakeWriter
+		worker   *githubinfo.Worker
+		ctx      context.Context
+		node     *manifest.Node
+	)
+
+	BeforeEach(func() {
+		registry = &registryfakes.FakeInterface{}
+		client = &httpclientfakes.FakeClient{}
+		writer = &writersfakes.FakeWriter{}
+		ctx = context.Background()
+		local := repositoryhost.NewLocal(&osshimfakes.FakeOs{}, "https://github.com/gardener/docforge", "")
+		registry.ResourceURLCalls(local.ResourceURL)
+		registry.ClientReturns(client)
+		registry.ReadGitInfoReturns([]byte("rest\n"), nil)
+		node = &manifest.Node{
+			Type: "file",
+			FileType: manifest.FileType{
+				File:        "README.md",
+				Source:      "https://github.com/gardener/docforge/blob/master/README.md",
+				MultiSource: []string{"https://github.com/gardener/docforge/blob/master/missing.md"},
+			},
+		}
+		var err error
+		worker, err = githubinfo.NewGithubWorker(registry, writer)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("uses batched results and reads the rest with REST", func() {
+		client.DoReturns(&http.Response{
+			StatusCode: http.StatusOK,
+			Body: io.NopCloser(strings.NewReader(`{"data": {"r0": {"object": {"history": {"nodes": [
+				{"oid": "1", "url": "foo/commit/1", "message": "first", "author": {"name": "one", "email": "one@", "date": "2024-02-06T13:11:00Z"}, "committer": {"name": "one", "email": "one@", "date": "2024-02-06T13:11:00Z"}}
+			]}}}, "r1": {"object": null}}}`)),
+		}, nil)
+		worker.Prefetch(ctx, []*manifest.Node{node})
+		Expect(client.DoCallCount()).To(Equal(1))
+		req := client.DoArgsForCall(0)
+		Expect(req.URL.String()).To(Equal("https://api.github.com/graphql"))
+
+		Expect(worker.WriteGithubInfo(ctx, node)).To(Succeed())
+		Expect(registry.ReadGitInfoCallCount()).To(Equal(1))
+		_, s := registry.ReadGitInfoArgsForCall(0)
+		Expect(s).To(Equal("https://github.com/gardener/docforge/blob/master/missing.md"))
+		_, _, content, _, _ := writer.WriteArgsForCall(0)
+		Expect(string(content)).To(HavePrefix("{\n  \"lastmod\": \"2024-02-06 13:11:00\""))
+		Expect(string(content)).To(HaveSuffix("}rest\n"))
+	})
+
+	It("falls back to REST when GraphQL is unavailable", func() {
+		client.DoReturns(&http.Response{StatusCode: http.StatusUnauthorized, Body: io.NopCloser(strings.NewReader(""))}, nil)
+		worker.Prefetch(ctx, []*manifest.Node{node})
+		Expect(worker.WriteGithubInfo(ctx, node)).To(Succeed())
+		Expect(registry.ReadGitInfoCallCount()).To(Equal(2))
+		_, _, content, _, _ := writer.WriteArgsForCall(0)
+		Expect(string(content)).To(Equal("rest\nrest\n"))
+	})
+})
@@ -8,6 +8,7 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/gardener/docforge/pkg/manifest"
 	"github.com/gardener/docforge/pkg/registry"
@@ -23,6 +24,10 @@ type GitHubInfo interface {
 	// WriteGitHubInfo writes GitHub info for an manifest.Node in a separate goroutine
 	// returns true if the task was added for processing, false if it was skipped
 	WriteGitHubInfo(node *manifest.Node) bool
+	// Prefetch reads the GitHub infos of nodes with batched GraphQL queries
+	Prefetch(ctx context.Context, nodes []*manifest.Node)
+	// LastModified returns the last modified date of the sources of a node whose GitHub info was written
+	LastModified(node *manifest.Node) (time.Time, bool)
 }
 
 type gitHubInfo struct {
@@ -7,6 +7,7 @@ package linkresolver
 import (
 	"cmp"
 	"fmt"
+	"net/url"
 	"path"
 	"path/filepath"
 	"slices"
@@ -35,6 +36,33 @@ type LinkResolver struct {
 	Repositoryhosts registry.Interface
 	SourceToNode    map[string][]*manifest.Node
 	Hugo            hugo.Hugo
+	// Redirects maps moved resource URLs to their new location
+	Redirects map[string]string
+	// MaxRedirectDepth is the maximum number of chained redirects followed for a link, unbounded when 0
+	MaxRedirectDepth int
+	// AnchorRedirects maps the anchors of renamed sections of all documents to their new anchors. The anchor
+	// redirects of the linked document take precedence
+	AnchorRedirects map[string]string
+	// HostAliases maps host variants to their canonical host, e.g. www.github.com to github.com
+	HostAliases map[string]string
+	// AbsoluteLinkRepos lists hosts, owners or repositories (e.g. github.com/gardener/docforge) whose links stay absolute
+	AbsoluteLinkRepos []string
+	// BasePath is the sub-directory the website is served under, e.g. /docs
+	BasePath string
+	// RelativeVersionLink decides if links between documents of versions are relative to the linking document
+	// instead of pinning the version of the linked document. Links are pinned when nil
+	RelativeVersionLink VersionRule
+}
+
+// VersionRule decides if a link from a document of version from to a document of version to is relative
+type VersionRule func(from string, to string) bool
+
+// SameVersionRule links documents of the same version relatively, so that the links stay valid when the version is
+// served under another path, e.g. latest. It applies to the given versions, or to all versions if they include *
+func SameVersionRule(versions []string) VersionRule {
+	return func(from string, to string) bool {
+		return from != "" && from == to && (slices.Contains(versions, "*") || slices.Contains(versions, from))
+	}
 }
 
 // ResolveResourceLink resolves resource link from a given source
@@ -45,21 +73,28 @@ func (l *LinkResolver) ResolveResourceLink(resourceLink string, node *manifest.N
 		// making resourceLink to be resourceURL
 		resourceLink, err = l.Repositoryhosts.ResolveRelativeLink(source, resourceLink)
 		if err != nil {
-			if _, ok := err.(repositoryhost.ErrResourceNotFound); ok {
+			if _, ok := err.(repositoryhost.ErrResourceNotFound); ok && !l.isRedirected(resourceLink) {
 				klog.Warningf("failed to validate absolute link for %s from source %s: %v\n", resourceLink, source, err)
 				// don't process broken link and don't return error
 				return resourceLink, nil
+			} else if !ok {
+				return resourceLink, err
 			}
-			return resourceLink, err
 		}
 	}
+	resourceLink, err := l.followRedirects(l.CanonicalURL(resourceLink))
+	if err != nil {
+		return resourceLink, fmt.Errorf("error when redirecting resource link %s in %s : %w", resourceLink, source, err)
+	}
 	destinationResource, err := l.Repositoryhosts.ResourceURL(resourceLink)
 	if err != nil {
 		return resourceLink, fmt.Errorf("error when parsing resource link %s in %s : %w", resourceLink, source, err)
 	}
-	destinationResourceURL := destinationResource.ResourceURL()
+	if l.keepAbsolute(destinationResource) {
+		return resourceLink, nil
+	}
 	// check if link refers to a node
-	nl, ok := l.SourceToNode[destinationResourceURL]
+	nl, ok := l.SourceToNode[l.SourceKey(destinationResource.ResourceURL())]
 	if !ok {
 		return resourceLink, nil
 	}
@@ -69,10 +104,173 @@ func (l *LinkResolver) ResolveResourceLink(resourceLink string, node *manifest.N
 		relPathBetweenNodeAndB, _ := filepath.Rel(node.Path, b.NodePath())
 		return cmp.Compare(strings.Count(relPathBetweenNodeAndA, "/"), strings.Count(relPathBetweenNodeAndB, "/"))
 	})
-	// construct destination from node path
-	websiteLink := strings.ToLower(destinationNode.NodePath())
+	suffix := l.redirectAnchor(normalizeAnchor(destinationResource.GetResourceSuffix()), destinationNode)
+	if l.RelativeVersionLink != nil && l.RelativeVersionLink(node.Version(), destinationNode.Version()) {
+		return relativeLink(l.websiteLink("", "", node), l.websiteLink("", "", destinationNode)) + suffix, nil
+	}
+	return l.websiteLink(l.BasePath, l.Hugo.BaseURL, destinationNode) + suffix, nil
+}
+
+// websiteLink constructs the link of the node from its path
+func (l *LinkResolver) websiteLink(basePath string, baseURL string, node *manifest.Node) string {
 	if l.Hugo.Enabled {
-		websiteLink = strings.ToLower(destinationNode.HugoPrettyPath())
+		h := l.Hugo
+		h.BaseURL = baseURL
+		return HugoLink(basePath, h, node)
+	}
+	return WebsiteLink(basePath, baseURL, strings.ToLower(node.NodePath())) + "/"
+}
+
+// relativeLink returns the root-relative link to as a link relative to the page at the root-relative link from
+func relativeLink(from string, to string) string {
+	fromDir := from
+	if !strings.HasSuffix(from, "/") {
+		fromDir = path.Dir(from)
+	}
+	rel, err := filepath.Rel(fromDir, to)
+	if err != nil {
+		return to
+	}
+	rel = filepath.ToSlash(rel)
+	if strings.HasSuffix(to, "/") {
+		rel += "/"
+	}
+	return rel
+}
+
+// HugoLink returns the root-relative link of the page Hugo renders for the node. Section files are the pages of their dir.
+// With pretty URLs pages are linked as dirs, otherwise as .html files and sections as the index.html of their dir
+func HugoLink(basePath string, hugo hugo.Hugo, node *manifest.Node) string {
+	section := node.Name() == "_index.md" || slices.Contains(hugo.IndexFileNames, node.Name())
+	switch {
+	case section && hugo.PrettyURLs:
+		return WebsiteLink(basePath, hugo.BaseURL, strings.ToLower(node.Path)) + "/"
+	case section:
+		return WebsiteLink(basePath, hugo.BaseURL, path.Join(strings.ToLower(node.Path), "index.html"))
+	case hugo.PrettyURLs:
+		return WebsiteLink(basePath, hugo.BaseURL, strings.ToLower(node.HugoPrettyPath())) + "/"
+	default:
+		return WebsiteLink(basePath, hugo.BaseURL, strings.ToLower(strings.TrimSuffix(node.NodePath(), ".md"))+".html")
+	}
+}
+
+// WebsiteLink returns the root-relative link of the website path served under the base path and the Hugo base URL.
+// When the base URL has a scheme and a host, the link is an absolute URL with them
+func WebsiteLink(basePath string, baseURL string, websitePath string) string {
+	u, err := url.Parse(baseURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return path.Join("/", basePath, baseURL, websitePath)
+	}
+	u.Path = path.Join("/", basePath, u.Path, websitePath)
+	u.RawPath = ""
+	return u.String()
+}
+
+// normalizeAnchor lowercases the anchor of a link suffix, as heading ids are generated in lowercase
+func normalizeAnchor(suffix string) string {
+	query, anchor, found := strings.Cut(suffix, "#")
+	if !found {
+		return suffix
+	}
+	return query + "#" + strings.ToLower(anchor)
+}
+
+// redirectAnchor replaces the anchor of a link suffix with its new anchor when the section it refers to was renamed
+func (l *LinkResolver) redirectAnchor(suffix string, node *manifest.Node) string {
+	query, anchor, found := strings.Cut(suffix, "#")
+	if !found || anchor == "" {
+		return suffix
+	}
+	target, ok := lookupAnchor(node.AnchorRedirects, anchor)
+	if !ok {
+		if target, ok = lookupAnchor(l.AnchorRedirects, anchor); !ok {
+			return suffix
+		}
+	}
+	return query + "#" + strings.ToLower(strings.TrimPrefix(target, "#"))
+}
+
+// lookupAnchor returns the new anchor of the anchor, ignoring the case and the # prefix of the redirected anchors
+func lookupAnchor(redirects map[string]string, anchor string) (string, bool) {
+	for old, target := range redirects {
+		if strings.EqualFold(strings.TrimPrefix(old, "#"), anchor) {
+			return target, true
+		}
+	}
+	return "", false
+}
+
+// keepAbsolute checks if the resource is in a host, owner or repository whose links stay absolute
+func (l *LinkResolver) keepAbsolute(resource *repositoryhost.URL) bool {
+	location := []string{resource.GetHost(), resource.GetOwner(), resource.GetRepo()}
+	for _, repo := range l.AbsoluteLinkRepos {
+		repo = strings.TrimPrefix(strings.TrimPrefix(repo, "https://"), "http://")
+		segments := strings.Split(strings.Trim(repo, "/"), "/")
+		if len(segments) > len(location) {
+			continue
+		}
+		if slices.EqualFunc(segments, location[:len(segments)], strings.EqualFold) {
+			return true
+		}
+	}
+	return false
+}
+
+// CanonicalURL lowercases the host of an absolute link and replaces it with its canonical host
+func (l *LinkResolver) CanonicalURL(link string) string {
+	u, err := url.Parse(link)
+	if err != nil || u.Host == "" {
+		return link
+	}
+	host := strings.ToLower(u.Host)
+	if canonical, ok := l.HostAliases[host]; ok {
+		host = canonical
+	}
+	if host == u.Host {
+		return link
+	}
+	u.Host = host
+	return u.String()
+}
+
+// SourceKey returns the key of a source in SourceToNode.
+// It is the canonical URL of the source without query, fragment and trailing slash
+func (l *LinkResolver) SourceKey(source string) string {
+	key, _ := splitLinkSuffix(l.CanonicalURL(source))
+	return strings.TrimSuffix(key, "/")
+}
+
+// isRedirected checks if there is a redirect for the link, ignoring its query and fragment
+func (l *LinkResolver) isRedirected(link string) bool {
+	target, _ := splitLinkSuffix(link)
+	_, ok := l.Redirects[target]
+	return ok
+}
+
+// followRedirects follows the chain of redirects for the link up to MaxRedirectDepth, keeping its query and fragment.
+// Unbounded chains fail only on loops
+func (l *LinkResolver) followRedirects(link string) (string, error) {
+	target, suffix := splitLinkSuffix(link)
+	visited := map[string]struct{}{target: {}}
+	for depth := 0; ; depth++ {
+		next, ok := l.Redirects[target]
+		if !ok {
+			return target + suffix, nil
+		}
+		if l.MaxRedirectDepth > 0 && depth >= l.MaxRedirectDepth {
+			return link, fmt.Errorf("max redirect depth %d exceeded", l.MaxRedirectDepth)
+		}
+		if _, ok := visited[next]; ok {
+			return link, fmt.Errorf("redirect loop detected at %s", next)
+		}
+		visited[next] = struct{}{}
+		target = next
+	}
+}
+
+func splitLinkSuffix(link string) (string, string) {
+	if i := strings.IndexAny(link, "?#"); i >= 0 {
+		return link[:i], link[i:]
 	}
-	return fmt.Sprintf("/%s/%s", path.Join(l.Hugo.BaseURL, websiteLink), destinationResource.GetResourceSuffix()), nil
+	return link, ""
 }
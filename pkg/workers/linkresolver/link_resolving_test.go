@@ -6,6 +6,7 @@ package linkresolver_test
 
 import (
 	"embed"
+	"strings"
 	"testing"
 
 	_ "embed"
@@ -40,12 +41,13 @@ var _ = Describe("Document link resolving", func() {
 			registry := registry.NewRegistry(repositoryhost.NewLocalTest(manifests, "https://github.com/gardener/docforge", "tests"))
 			linkResolver.Repositoryhosts = registry
 			linkResolver.Hugo = hugo.Hugo{
-				Enabled: true,
-				BaseURL: "baseURL",
+				Enabled:    true,
+				PrettyURLs: true,
+				BaseURL:    "baseURL",
 			}
 			linkResolver.SourceToNode = make(map[string][]*manifest.Node)
 			contentFileFormats := []string{".md"}
-			nodes, err := manifest.ResolveManifest("https://github.com/gardener/docforge/blob/master/baseline.yaml", linkResolver.Repositoryhosts, contentFileFormats)
+			nodes, err := manifest.ResolveManifest("https://github.com/gardener/docforge/blob/master/baseline.yaml", linkResolver.Repositoryhosts, contentFileFormats, manifest.Options{})
 			Expect(err).NotTo(HaveOccurred())
 			for _, node := range nodes {
 				if node.Source != "" {
@@ -90,6 +92,100 @@ var _ = Describe("Document link resolving", func() {
 			Expect(newLink).To(Equal("/baseURL/two/internal/"))
 		})
 
+		Context("redirecting anchors", func() {
+			BeforeEach(func() {
+				node.AnchorRedirects = map[string]string{"Old-Section": "new-section"}
+				linkResolver.AnchorRedirects = map[string]string{"#old-section": "global-section", "removed": "#Kept"}
+			})
+
+			It("redirects the anchors of the linked document", func() {
+				newLink, err := linkResolver.ResolveResourceLink("#old-section", node, source)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(newLink).To(Equal("/baseURL/one/node/#new-section"))
+			})
+
+			It("redirects the anchors of all documents", func() {
+				newLink, err := linkResolver.ResolveResourceLink("clickhere.md?a=b#Old-Section", node, source)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(newLink).To(Equal("/baseURL/one/internal/linked/?a=b#global-section"))
+				newLink, err = linkResolver.ResolveResourceLink("#removed", node, source)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(newLink).To(Equal("/baseURL/one/node/#kept"))
+			})
+
+			It("keeps the other anchors", func() {
+				newLink, err := linkResolver.ResolveResourceLink("#anchor", node, source)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(newLink).To(Equal("/baseURL/one/node/#anchor"))
+			})
+		})
+
+		Context("linking to sections", func() {
+			var readme string
+			BeforeEach(func() {
+				linkResolver.Hugo.IndexFileNames = []string{"readme.md", "README.md"}
+				readme = "https://github.com/gardener/docforge/blob/master/non-page.md"
+				linkResolver.SourceToNode[readme] = []*manifest.Node{{FileType: manifest.FileType{File: "README.md", Source: readme}, Type: "file", Path: "three/guides"}}
+			})
+
+			It("links the dirs of sections with pretty URLs", func() {
+				newLink, err := linkResolver.ResolveResourceLink(readme+"#Setup", node, source)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(newLink).To(Equal("/baseURL/three/guides/#setup"))
+			})
+
+			It("links the index.html of sections with ugly URLs", func() {
+				linkResolver.Hugo.PrettyURLs = false
+				newLink, err := linkResolver.ResolveResourceLink("https://github.com/gardener/docforge/blob/master/docs/_index.md", node, source)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(newLink).To(Equal("/baseURL/two/internal/index.html"))
+				newLink, err = linkResolver.ResolveResourceLink(readme+"#Setup", node, source)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(newLink).To(Equal("/baseURL/three/guides/index.html#setup"))
+				newLink, err = linkResolver.ResolveResourceLink("clickhere.md#anchor", node, source)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(newLink).To(Equal("/baseURL/one/internal/linked.html#anchor"))
+			})
+		})
+
+		Context("linking between versions", func() {
+			BeforeEach(func() {
+				versions := map[string]string{"one": "v2", "two": "v1"}
+				for _, nodes := range linkResolver.SourceToNode {
+					for _, n := range nodes {
+						n.Frontmatter = map[string]interface{}{"version": versions[strings.Split(n.Path, "/")[0]]}
+					}
+				}
+				linkResolver.RelativeVersionLink = linkresolver.SameVersionRule([]string{"v2"})
+			})
+
+			It("links documents of the same version relatively", func() {
+				newLink, err := linkResolver.ResolveResourceLink("clickhere.md#Anchor", node, source)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(newLink).To(Equal("../internal/linked/#anchor"))
+			})
+
+			It("links documents of the same version relatively with ugly URLs", func() {
+				linkResolver.Hugo.PrettyURLs = false
+				newLink, err := linkResolver.ResolveResourceLink("clickhere.md", node, source)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(newLink).To(Equal("internal/linked.html"))
+			})
+
+			It("pins the version of documents of other versions", func() {
+				newLink, err := linkResolver.ResolveResourceLink("https://github.com/gardener/docforge/blob/master/docs/_index.md", node, source)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(newLink).To(Equal("/baseURL/two/internal/"))
+			})
+
+			It("pins the version of documents of versions out of the rule", func() {
+				linkResolver.RelativeVersionLink = linkresolver.SameVersionRule([]string{"v1"})
+				newLink, err := linkResolver.ResolveResourceLink("clickhere.md", node, source)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(newLink).To(Equal("/baseURL/one/internal/linked/"))
+			})
+		})
+
 		It("Resolves non-page resource links correctly", func() {
 			newLink, err := linkResolver.ResolveResourceLink("./non-page.md", node, source)
 			Expect(err).ToNot(HaveOccurred())
@@ -100,5 +196,219 @@ var _ = Describe("Document link resolving", func() {
 			_, err := linkResolver.ResolveResourceLink("https://gitlab.com/gardener/docforge/blob/master/README.md", node, source)
 			Expect(err.Error()).To(ContainSubstring("no sutiable repository host"))
 		})
+
+		It("Resolves absolute link with anchor to a node", func() {
+			newLink, err := linkResolver.ResolveResourceLink("https://github.com/gardener/docforge/blob/master/clickhere.md?a=b#My-Section", node, source)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(newLink).To(Equal("/baseURL/one/internal/linked/?a=b#my-section"))
+		})
+
+		It("Resolves absolute link with anchor to a node from another host", func() {
+			linkResolver.Repositoryhosts = registry.NewRegistry(
+				repositoryhost.NewLocalTest(manifests, "https://github.com/gardener/docforge", "tests"),
+				repositoryhost.NewLocalTest(manifests, "https://github.tools.sap/org/repo", "tests"),
+			)
+			enterpriseSource := "https://github.tools.sap/org/repo/blob/master/target.md"
+			linkResolver.SourceToNode[enterpriseSource] = []*manifest.Node{{FileType: manifest.FileType{File: "enterprise.md", Source: enterpriseSource}, Type: "file", Path: "three"}}
+			newLink, err := linkResolver.ResolveResourceLink("https://github.tools.sap/org/repo/blob/master/target.md#Section", node, source)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(newLink).To(Equal("/baseURL/three/enterprise/#section"))
+		})
+
+		Context("with source variants", func() {
+			It("Resolves a link with a trailing slash", func() {
+				newLink, err := linkResolver.ResolveResourceLink("https://github.com/gardener/docforge/blob/master/clickhere.md/", node, source)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(newLink).To(Equal("/baseURL/one/internal/linked/"))
+			})
+
+			It("Resolves a link with a query string", func() {
+				newLink, err := linkResolver.ResolveResourceLink("https://github.com/gardener/docforge/blob/master/clickhere.md?plain=1", node, source)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(newLink).To(Equal("/baseURL/one/internal/linked/?plain=1"))
+			})
+
+			It("Resolves a link with differing host case", func() {
+				newLink, err := linkResolver.ResolveResourceLink("https://GitHub.com/gardener/docforge/blob/master/clickhere.md", node, source)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(newLink).To(Equal("/baseURL/one/internal/linked/"))
+			})
+
+			It("Normalizes source keys", func() {
+				Expect(linkResolver.SourceKey("https://GitHub.com/gardener/docforge/tree/master/docs/?a=b#c")).To(Equal("https://github.com/gardener/docforge/tree/master/docs"))
+			})
+		})
+
+		Context("with absolute link repos", func() {
+			var enterpriseSource string
+
+			BeforeEach(func() {
+				linkResolver.Repositoryhosts = registry.NewRegistry(
+					repositoryhost.NewLocalTest(manifests, "https://github.com/gardener/docforge", "tests"),
+					repositoryhost.NewLocalTest(manifests, "https://github.tools.sap/org/repo", "tests"),
+				)
+				enterpriseSource = "https://github.tools.sap/org/repo/blob/master/target.md"
+				linkResolver.SourceToNode[enterpriseSource] = []*manifest.Node{{FileType: manifest.FileType{File: "enterprise.md", Source: enterpriseSource}, Type: "file", Path: "three"}}
+				linkResolver.AbsoluteLinkRepos = []string{"https://github.tools.sap/org/repo"}
+			})
+
+			It("Keeps links to nodes of listed repos absolute", func() {
+				newLink, err := linkResolver.ResolveResourceLink(enterpriseSource+"#Section", node, source)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(newLink).To(Equal(enterpriseSource + "#Section"))
+			})
+
+			It("Localizes links to nodes of other repos", func() {
+				newLink, err := linkResolver.ResolveResourceLink("clickhere.md", node, source)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(newLink).To(Equal("/baseURL/one/internal/linked/"))
+			})
+
+			It("Keeps links to nodes of listed hosts absolute", func() {
+				linkResolver.AbsoluteLinkRepos = []string{"GitHub.com"}
+				newLink, err := linkResolver.ResolveResourceLink("clickhere.md", node, source)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(newLink).To(Equal("https://github.com/gardener/docforge/blob/master/clickhere.md"))
+			})
+		})
+
+		Context("with host aliases", func() {
+			BeforeEach(func() {
+				linkResolver.HostAliases = map[string]string{"www.github.com": "github.com"}
+			})
+
+			It("Resolves host variants to the same node", func() {
+				canonicalLink, err := linkResolver.ResolveResourceLink("https://github.com/gardener/docforge/blob/master/clickhere.md", node, source)
+				Expect(err).ToNot(HaveOccurred())
+				aliasLink, err := linkResolver.ResolveResourceLink("https://www.github.com/gardener/docforge/blob/master/clickhere.md#anchor", node, source)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(canonicalLink).To(Equal("/baseURL/one/internal/linked/"))
+				Expect(aliasLink).To(Equal("/baseURL/one/internal/linked/#anchor"))
+			})
+
+			It("Lowercases links with hosts without alias", func() {
+				Expect(linkResolver.CanonicalURL("https://GitHub.Example.com/a/b")).To(Equal("https://github.example.com/a/b"))
+				Expect(linkResolver.CanonicalURL("https://WWW.github.com/a/b?c=d")).To(Equal("https://github.com/a/b?c=d"))
+			})
+		})
+
+		Context("with redirects", func() {
+			BeforeEach(func() {
+				linkResolver.MaxRedirectDepth = 2
+				linkResolver.Redirects = map[string]string{
+					"https://github.com/gardener/docforge/blob/master/moved.md":       "https://github.com/gardener/docforge/blob/master/moved-again.md",
+					"https://github.com/gardener/docforge/blob/master/moved-again.md": "https://github.com/gardener/docforge/blob/master/clickhere.md",
+					"https://github.com/gardener/docforge/blob/master/loop-a.md":      "https://github.com/gardener/docforge/blob/master/loop-b.md",
+					"https://github.com/gardener/docforge/blob/master/loop-b.md":      "https://github.com/gardener/docforge/blob/master/loop-a.md",
+				}
+			})
+
+			It("Follows a redirect chain within the limit", func() {
+				newLink, err := linkResolver.ResolveResourceLink("moved.md#anchor", node, source)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(newLink).To(Equal("/baseURL/one/internal/linked/#anchor"))
+			})
+
+			It("Fails when the redirect chain exceeds the limit", func() {
+				linkResolver.MaxRedirectDepth = 1
+				_, err := linkResolver.ResolveResourceLink("moved.md", node, source)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("max redirect depth 1 exceeded"))
+			})
+
+			It("Follows a redirect chain of any length when the depth is 0", func() {
+				linkResolver.MaxRedirectDepth = 0
+				newLink, err := linkResolver.ResolveResourceLink("moved.md#anchor", node, source)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(newLink).To(Equal("/baseURL/one/internal/linked/#anchor"))
+				_, err = linkResolver.ResolveResourceLink("loop-a.md", node, source)
+				Expect(err).To(MatchError(ContainSubstring("redirect loop detected")))
+			})
+
+			It("Detects redirect loops", func() {
+				linkResolver.MaxRedirectDepth = 10
+				_, err := linkResolver.ResolveResourceLink("loop-a.md", node, source)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("redirect loop detected"))
+			})
+		})
+
+		Context("with a base path", func() {
+			BeforeEach(func() {
+				linkResolver.BasePath = "/docs/"
+				linkResolver.Hugo.BaseURL = ""
+			})
+
+			It("Prefixes document links", func() {
+				newLink, err := linkResolver.ResolveResourceLink("clickhere.md#anchor", node, source)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(newLink).To(Equal("/docs/one/internal/linked/#anchor"))
+			})
+
+			It("Prefixes section links", func() {
+				newLink, err := linkResolver.ResolveResourceLink("https://github.com/gardener/docforge/blob/master/docs/_index.md", node, source)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(newLink).To(Equal("/docs/two/internal/"))
+			})
+
+			It("Prefixes links before the base URL", func() {
+				linkResolver.Hugo.BaseURL = "baseURL"
+				newLink, err := linkResolver.ResolveResourceLink("#anchor", node, source)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(newLink).To(Equal("/docs/baseURL/one/node/#anchor"))
+			})
+
+			It("Keeps links that aren't resolved to nodes", func() {
+				newLink, err := linkResolver.ResolveResourceLink("./non-page.md", node, source)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(newLink).To(Equal("https://github.com/gardener/docforge/blob/master/non-page.md"))
+			})
+		})
+	})
+
+	Context("#WebsiteLink", func() {
+		It("joins the base path, the base URL and the website path", func() {
+			Expect(linkresolver.WebsiteLink("/docs/", "", "__resources/logo.png")).To(Equal("/docs/__resources/logo.png"))
+			Expect(linkresolver.WebsiteLink("docs", "site", "one/node")).To(Equal("/docs/site/one/node"))
+			Expect(linkresolver.WebsiteLink("", "", "one/node")).To(Equal("/one/node"))
+		})
+
+		It("keeps the scheme and host of an absolute base URL", func() {
+			Expect(linkresolver.WebsiteLink("/docs", "https://example.com/site/", "one/node")).To(Equal("https://example.com/docs/site/one/node"))
+		})
+	})
+
+	Context("#Redirects", func() {
+		var nodes []*manifest.Node
+
+		BeforeEach(func() {
+			nodes = []*manifest.Node{
+				{DirType: manifest.DirType{Dir: "guides"}, Type: "dir", Path: ".", Frontmatter: map[string]interface{}{"aliases": []interface{}{"/old/guides"}}},
+				{FileType: manifest.FileType{File: "_index.md"}, Type: "file", Path: "guides", Frontmatter: map[string]interface{}{"aliases": []interface{}{"/old/guides/"}}},
+				{FileType: manifest.FileType{File: "setup.md"}, Type: "file", Path: "guides", Frontmatter: map[string]interface{}{"aliases": []interface{}{"/old/guides/setup/", "/install"}}},
+				{FileType: manifest.FileType{File: "intro.md"}, Type: "file", Path: "guides"},
+			}
+		})
+
+		It("redirects the aliases of the files to their Hugo links", func() {
+			redirects := linkresolver.Redirects(nodes, "", hugo.Hugo{Enabled: true, PrettyURLs: true, BaseURL: "https://example.com"})
+			Expect(string(redirects)).To(Equal("/old/guides/ /guides/ 301\n/old/guides/setup/ /guides/setup/ 301\n/install /guides/setup/ 301\n"))
+		})
+
+		It("redirects under the base path", func() {
+			redirects := linkresolver.Redirects(nodes, "/docs", hugo.Hugo{})
+			Expect(string(redirects)).To(Equal("/docs/old/guides/ /docs/guides/_index.md/ 301\n/docs/old/guides/setup/ /docs/guides/setup.md/ 301\n/docs/install /docs/guides/setup.md/ 301\n"))
+		})
+	})
+
+	Context("#RobotsTxt", func() {
+		It("allows everything without disallowed paths", func() {
+			Expect(string(linkresolver.RobotsTxt(nil, "", hugo.Hugo{}))).To(Equal("User-agent: *\nDisallow:\n"))
+		})
+
+		It("disallows the paths under the base path and references the sitemap", func() {
+			robots := linkresolver.RobotsTxt([]string{"/drafts/", "/internal"}, "/docs", hugo.Hugo{Enabled: true, BaseURL: "https://example.com"})
+			Expect(string(robots)).To(Equal("User-agent: *\nDisallow: /docs/drafts/\nDisallow: /docs/internal\n\nSitemap: https://example.com/docs/sitemap.xml\n"))
+		})
 	})
 })
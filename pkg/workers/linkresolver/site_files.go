@@ -0,0 +1,58 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package linkresolver
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/gardener/docforge/cmd/hugo"
+	"github.com/gardener/docforge/pkg/manifest"
+)
+
+// Redirects returns the content of a _redirects file of Netlify or Cloudflare Pages that redirects the aliases of the
+// file nodes permanently to their links
+func Redirects(nodes []*manifest.Node, basePath string, hugo hugo.Hugo) []byte {
+	var b bytes.Buffer
+	l := &LinkResolver{Hugo: hugo}
+	for _, node := range nodes {
+		if node.Type != "file" {
+			continue
+		}
+		aliases, _ := node.Frontmatter["aliases"].([]interface{})
+		for _, a := range aliases {
+			alias := fmt.Sprintf("%s", a)
+			from := WebsiteLink(basePath, "", alias)
+			if strings.HasSuffix(alias, "/") && from != "/" {
+				from += "/"
+			}
+			fmt.Fprintf(&b, "%s %s 301\n", from, l.websiteLink(basePath, "", node))
+		}
+	}
+	return b.Bytes()
+}
+
+// RobotsTxt returns the content of a robots.txt disallowing the paths for all user agents. The sitemap Hugo generates
+// is referenced when the base URL has a scheme and a host
+func RobotsTxt(disallow []string, basePath string, hugo hugo.Hugo) []byte {
+	var b bytes.Buffer
+	b.WriteString("User-agent: *\n")
+	if len(disallow) == 0 {
+		b.WriteString("Disallow:\n")
+	}
+	for _, p := range disallow {
+		disallowed := WebsiteLink(basePath, "", p)
+		if strings.HasSuffix(p, "/") && disallowed != "/" {
+			disallowed += "/"
+		}
+		fmt.Fprintf(&b, "Disallow: %s\n", disallowed)
+	}
+	if u, err := url.Parse(hugo.BaseURL); hugo.Enabled && err == nil && u.Scheme != "" && u.Host != "" {
+		fmt.Fprintf(&b, "\nSitemap: %s\n", WebsiteLink(basePath, hugo.BaseURL, "sitemap.xml"))
+	}
+	return b.Bytes()
+}
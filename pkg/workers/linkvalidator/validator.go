@@ -18,11 +18,27 @@ import (
 	"sync"
 	"time"
 
+	"github.com/gardener/docforge/pkg/metrics"
 	"github.com/gardener/docforge/pkg/osfakes/httpclient"
 	"github.com/gardener/docforge/pkg/registry"
 	"k8s.io/klog/v2"
 )
 
+// ErrBrokenLink indicates a link that has to be fixed in the document source
+type ErrBrokenLink struct {
+	Err error
+}
+
+// Error returns the underlying error message
+func (e ErrBrokenLink) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e ErrBrokenLink) Unwrap() error {
+	return e.Err
+}
+
 // ValidatorWorker holds nessesary objects ti validate URl
 type ValidatorWorker struct {
 	repository    registry.Interface
@@ -62,7 +78,8 @@ func (v *ValidatorWorker) Validate(ctx context.Context, LinkDestination string,
 		return nil
 	}
 	if slices.Contains(v.hostsToReport, LinkURL.Host) {
-		return fmt.Errorf("%s has link %s with host to report", ContentSourcePath, LinkDestination)
+		metrics.ValidationFailures.Inc()
+		return ErrBrokenLink{fmt.Errorf("%s has link %s with host to report", ContentSourcePath, LinkDestination)}
 	}
 	// unify links destination by excluding query, fragment & user info
 	u := &url.URL{
@@ -86,6 +103,7 @@ func (v *ValidatorWorker) Validate(ctx context.Context, LinkDestination string,
 		return fmt.Errorf("failed to prepare HEAD validation request: %v", err)
 	}
 	if resp, err = doValidation(req, client); err != nil && !errors.Is(err, context.DeadlineExceeded) {
+		metrics.ValidationFailures.Inc()
 		klog.Warningf("failed to validate absolute link for %s from source %s: %v\n", LinkDestination, ContentSourcePath, err)
 	} else if errors.Is(err, context.DeadlineExceeded) || (resp.StatusCode >= 400 && resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusUnauthorized) {
 		// on error status code different from authorization errors
@@ -96,8 +114,10 @@ func (v *ValidatorWorker) Validate(ctx context.Context, LinkDestination string,
 			return fmt.Errorf("failed to prepare GET validation request: %v", err)
 		}
 		if resp, err = doValidation(req, client); err != nil {
+			metrics.ValidationFailures.Inc()
 			klog.Warningf("failed to validate absolute link for %s from source %s: %v\n", LinkDestination, ContentSourcePath, err)
 		} else if resp.StatusCode >= 400 && resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusUnauthorized {
+			metrics.ValidationFailures.Inc()
 			klog.Warningf("failed to validate absolute link for %s from source %s: %v\n", LinkDestination, ContentSourcePath, fmt.Errorf("HTTP Status %s", resp.Status))
 		}
 	}
@@ -70,6 +70,15 @@ var _ = Describe("Executing Validate", func() {
 			Expect(httpClient.DoCallCount()).To(Equal(0))
 		})
 	})
+	Context("host to report", func() {
+		BeforeEach(func() {
+			hostToReport = []string{"repoHost"}
+		})
+		It("fails with broken link error", func() {
+			Expect(err).To(MatchError(linkvalidator.ErrBrokenLink{Err: errors.New("fake_path has link https://repoHost/fake_link with host to report")}))
+			Expect(httpClient.DoCallCount()).To(Equal(0))
+		})
+	})
 	// FContext("url is not valid", func() {
 	// 	BeforeEach(func() {
 	// 		Expect(err).NotTo(HaveOccurred())
@@ -11,6 +11,26 @@ import (
 )
 
 type FakeInterface struct {
+	IntegrityStub        func() map[string]string
+	integrityMutex       sync.RWMutex
+	integrityArgsForCall []struct {
+	}
+	integrityReturns struct {
+		result1 map[string]string
+	}
+	integrityReturnsOnCall map[int]struct {
+		result1 map[string]string
+	}
+	ResourcesStub        func() []resourcedownloader.Resource
+	resourcesMutex       sync.RWMutex
+	resourcesArgsForCall []struct {
+	}
+	resourcesReturns struct {
+		result1 []resourcedownloader.Resource
+	}
+	resourcesReturnsOnCall map[int]struct {
+		result1 []resourcedownloader.Resource
+	}
 	ScheduleStub        func(string, string, string) error
 	scheduleMutex       sync.RWMutex
 	scheduleArgsForCall []struct {
@@ -28,6 +48,112 @@ type FakeInterface struct {
 	invocationsMutex sync.RWMutex
 }
 
+func (fake *FakeInterface) Integrity() map[string]string {
+	fake.integrityMutex.Lock()
+	ret, specificReturn := fake.integrityReturnsOnCall[len(fake.integrityArgsForCall)]
+	fake.integrityArgsForCall = append(fake.integrityArgsForCall, struct {
+	}{})
+	stub := fake.IntegrityStub
+	fakeReturns := fake.integrityReturns
+	fake.recordInvocation("Integrity", []interface{}{})
+	fake.integrityMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeInterface) IntegrityCallCount() int {
+	fake.integrityMutex.RLock()
+	defer fake.integrityMutex.RUnlock()
+	return len(fake.integrityArgsForCall)
+}
+
+func (fake *FakeInterface) IntegrityCalls(stub func() map[string]string) {
+	fake.integrityMutex.Lock()
+	defer fake.integrityMutex.Unlock()
+	fake.IntegrityStub = stub
+}
+
+func (fake *FakeInterface) IntegrityReturns(result1 map[string]string) {
+	fake.integrityMutex.Lock()
+	defer fake.integrityMutex.Unlock()
+	fake.IntegrityStub = nil
+	fake.integrityReturns = struct {
+		result1 map[string]string
+	}{result1}
+}
+
+func (fake *FakeInterface) IntegrityReturnsOnCall(i int, result1 map[string]string) {
+	fake.integrityMutex.Lock()
+	defer fake.integrityMutex.Unlock()
+	fake.IntegrityStub = nil
+	if fake.integrityReturnsOnCall == nil {
+		fake.integrityReturnsOnCall = make(map[int]struct {
+			result1 map[string]string
+		})
+	}
+	fake.integrityReturnsOnCall[i] = struct {
+		result1 map[string]string
+	}{result1}
+}
+
+func (fake *FakeInterface) Resources() []resourcedownloader.Resource {
+	fake.resourcesMutex.Lock()
+	ret, specificReturn := fake.resourcesReturnsOnCall[len(fake.resourcesArgsForCall)]
+	fake.resourcesArgsForCall = append(fake.resourcesArgsForCall, struct {
+	}{})
+	stub := fake.ResourcesStub
+	fakeReturns := fake.resourcesReturns
+	fake.recordInvocation("Resources", []interface{}{})
+	fake.resourcesMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeInterface) ResourcesCallCount() int {
+	fake.resourcesMutex.RLock()
+	defer fake.resourcesMutex.RUnlock()
+	return len(fake.resourcesArgsForCall)
+}
+
+func (fake *FakeInterface) ResourcesCalls(stub func() []resourcedownloader.Resource) {
+	fake.resourcesMutex.Lock()
+	defer fake.resourcesMutex.Unlock()
+	fake.ResourcesStub = stub
+}
+
+func (fake *FakeInterface) ResourcesReturns(result1 []resourcedownloader.Resource) {
+	fake.resourcesMutex.Lock()
+	defer fake.resourcesMutex.Unlock()
+	fake.ResourcesStub = nil
+	fake.resourcesReturns = struct {
+		result1 []resourcedownloader.Resource
+	}{result1}
+}
+
+func (fake *FakeInterface) ResourcesReturnsOnCall(i int, result1 []resourcedownloader.Resource) {
+	fake.resourcesMutex.Lock()
+	defer fake.resourcesMutex.Unlock()
+	fake.ResourcesStub = nil
+	if fake.resourcesReturnsOnCall == nil {
+		fake.resourcesReturnsOnCall = make(map[int]struct {
+			result1 []resourcedownloader.Resource
+		})
+	}
+	fake.resourcesReturnsOnCall[i] = struct {
+		result1 []resourcedownloader.Resource
+	}{result1}
+}
+
 func (fake *FakeInterface) Schedule(arg1 string, arg2 string, arg3 string) error {
 	fake.scheduleMutex.Lock()
 	ret, specificReturn := fake.scheduleReturnsOnCall[len(fake.scheduleArgsForCall)]
@@ -94,6 +220,10 @@ func (fake *FakeInterface) ScheduleReturnsOnCall(i int, result1 error) {
 func (fake *FakeInterface) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
+	fake.integrityMutex.RLock()
+	defer fake.integrityMutex.RUnlock()
+	fake.resourcesMutex.RLock()
+	defer fake.resourcesMutex.RUnlock()
 	fake.scheduleMutex.RLock()
 	defer fake.scheduleMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
@@ -22,6 +22,10 @@ import (
 type Interface interface {
 	// Schedule is a typesafe wrapper for enqueuing download tasks. An error is returned if scheduling fails.
 	Schedule(source string, target string, document string) error
+	// Integrity returns the sha256 subresource integrity of the downloaded resources by target, nil if not computed
+	Integrity() map[string]string
+	// Resources returns the downloaded resources
+	Resources() []Resource
 }
 
 type downloadScheduler struct {
@@ -30,8 +34,8 @@ type downloadScheduler struct {
 }
 
 // New create a DownloadScheduler to schedule download resources
-func New(workerCount int, failFast bool, wg *sync.WaitGroup, registry registry.Interface, writer writers.Writer) (Interface, taskqueue.QueueController, error) {
-	dWorker, err := NewDownloader(registry, writer)
+func New(workerCount int, failFast bool, wg *sync.WaitGroup, registry registry.Interface, writer writers.Writer, options Options) (Interface, taskqueue.QueueController, error) {
+	dWorker, err := NewDownloader(registry, writer, options)
 	if err != nil {
 		return nil, nil, err
 	}
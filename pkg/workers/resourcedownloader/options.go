@@ -0,0 +1,23 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package resourcedownloader
+
+import (
+	"github.com/gardener/docforge/pkg/buildstate"
+)
+
+// Options configure the download of resources
+type Options struct {
+	// Retries is the number of retries with exponential backoff of downloads failing with transient errors
+	Retries int
+	// Integrity computes the sha256 subresource integrity of the downloaded resources
+	Integrity bool
+	// SanitizeSVG strips scripts from downloaded SVG resources
+	SanitizeSVG bool
+	// State records the downloaded resources of the build, nil if not recorded
+	State *buildstate.State
+	// HostDownloads is the maximum number of concurrent downloads from a host, unlimited if not positive
+	HostDownloads int
+}
@@ -6,11 +6,20 @@ package resourcedownloader
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"net/url"
+	"path"
 	"reflect"
+	"strings"
 	"sync"
+	"time"
 
+	"github.com/gardener/docforge/pkg/buildstate"
+	"github.com/gardener/docforge/pkg/metrics"
 	"github.com/gardener/docforge/pkg/registry"
 	"github.com/gardener/docforge/pkg/registry/repositoryhost"
 	"github.com/gardener/docforge/pkg/writers"
@@ -23,31 +32,92 @@ type ResourceDownloadWorker struct {
 	writer   writers.Writer
 	// lock for accessing the downloadedResources map
 	mux sync.Mutex
-	// map with downloaded resources
+	// map with downloaded resources by target
 	downloadedResources map[string]struct{}
+	// retries is the number of retries of downloads failing with transient errors
+	retries int
+	// RetryBackoff is the delay before the first retry, doubled on each next retry
+	RetryBackoff time.Duration
+	// integrity maps the targets of downloaded resources to their sha256 subresource integrity, nil if not computed
+	integrity map[string]string
+	// resources are the downloaded resources by source
+	resources map[string]Resource
+	// references are the documents referencing a resource by source
+	references map[string][]string
+	// sanitizeSVG strips scripts from downloaded SVG resources
+	sanitizeSVG bool
+	// state records the downloaded resources of the build, nil if not recorded
+	state *buildstate.State
+	// hostDownloads is the maximum number of concurrent downloads from a host, unlimited if not positive
+	hostDownloads int
+	// hostSlots limit the concurrent downloads by host
+	hostSlots map[string]chan struct{}
 }
 
 // NewDownloader creates new downloader
-func NewDownloader(registry registry.Interface, writer writers.Writer) (*ResourceDownloadWorker, error) {
+func NewDownloader(registry registry.Interface, writer writers.Writer, options Options) (*ResourceDownloadWorker, error) {
 	if registry == nil || reflect.ValueOf(registry).IsNil() {
 		return nil, errors.New("invalid argument: reader is nil")
 	}
 	if writer == nil || reflect.ValueOf(writer).IsNil() {
 		return nil, errors.New("invalid argument: writer is nil")
 	}
-	return &ResourceDownloadWorker{
+	d := &ResourceDownloadWorker{
 		registry:            registry,
 		writer:              writer,
 		downloadedResources: make(map[string]struct{}),
-	}, nil
+		retries:             options.Retries,
+		RetryBackoff:        time.Second,
+		resources:           map[string]Resource{},
+		references:          map[string][]string{},
+		sanitizeSVG:         options.SanitizeSVG,
+		state:               options.State,
+		hostDownloads:       options.HostDownloads,
+		hostSlots:           map[string]chan struct{}{},
+	}
+	if options.Integrity {
+		d.integrity = map[string]string{}
+	}
+	return d, nil
+}
+
+// Integrity returns the sha256 subresource integrity of the downloaded resources by target,
+// nil if the downloader doesn't compute it
+func (d *ResourceDownloadWorker) Integrity() map[string]string {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+	if d.integrity == nil {
+		return nil
+	}
+	integrity := make(map[string]string, len(d.integrity))
+	for target, hash := range d.integrity {
+		integrity[target] = hash
+	}
+	return integrity
 }
 
 // Download downloads source as target
 func (d *ResourceDownloadWorker) Download(ctx context.Context, source string, target string, document string) error {
-	if !d.shouldDownload(source) {
+	d.reference(source, document)
+	if !d.shouldDownload(target) {
 		return nil
 	}
-	if err := d.download(ctx, source, target); err != nil {
+	if resource, ok := d.state.DownloadedResource(target); ok {
+		klog.V(6).Infof("skipping download of %s downloaded as %s by the resumed build\n", source, target)
+		return d.downloaded(source, target, resource.SHA256, resource.Size)
+	}
+	err := d.download(ctx, source, target)
+	for attempt := 1; attempt <= d.retries && errors.As(err, &repositoryhost.ErrTransient{}); attempt++ {
+		backoff := d.RetryBackoff << (attempt - 1)
+		klog.Warningf("retrying download of %s in %s (attempt %d/%d): %v\n", source, backoff, attempt, d.retries, err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		err = d.download(ctx, source, target)
+	}
+	if err != nil {
 		dErr := fmt.Errorf("downloading %s as %s from document %s failed: %v", source, target, document, err)
 		if _, ok := err.(repositoryhost.ErrResourceNotFound); ok {
 			// for missing resources just log warning
@@ -59,14 +129,14 @@ func (d *ResourceDownloadWorker) Download(ctx context.Context, source string, ta
 	return nil
 }
 
-// shouldDownload checks whether a download task for the same Source is being processed
-func (d *ResourceDownloadWorker) shouldDownload(Source string) bool {
+// shouldDownload checks whether a download task for the same Target is being processed
+func (d *ResourceDownloadWorker) shouldDownload(Target string) bool {
 	d.mux.Lock()
 	defer d.mux.Unlock()
-	if _, ok := d.downloadedResources[Source]; ok {
+	if _, ok := d.downloadedResources[Target]; ok {
 		return false
 	}
-	d.downloadedResources[Source] = struct{}{}
+	d.downloadedResources[Target] = struct{}{}
 	return true
 }
 
@@ -75,12 +145,69 @@ func (d *ResourceDownloadWorker) download(ctx context.Context, Source string, Ta
 	if err != nil {
 		return err
 	}
+	release, err := d.acquireHost(ctx, Source)
+	if err != nil {
+		return err
+	}
 	blob, err := d.registry.Read(ctx, reosurceURL.ResourceURL())
+	release()
 	if err != nil {
 		return err
 	}
-	if err = d.writer.Write(Target, "", blob, nil, nil); err != nil {
+	if d.sanitizeSVG && isSVG(Target, blob) {
+		if blob, err = stripSVGScripts(blob); err != nil {
+			return err
+		}
+	}
+	dir, name := path.Split(Target)
+	if err = d.writer.Write(name, strings.TrimSuffix(dir, "/"), blob, nil, nil); err != nil {
+		return err
+	}
+	metrics.ResourcesDownloaded.Inc()
+	sum := sha256.Sum256(blob)
+	hash := hex.EncodeToString(sum[:])
+	if err = d.state.RecordResource(buildstate.Resource{Source: Source, Target: Target, SHA256: hash, Size: len(blob)}); err != nil {
 		return err
 	}
+	return d.downloaded(Source, Target, hash, len(blob))
+}
+
+// downloaded adds the resource downloaded as target with content of the hex encoded sha256 hash and size to the
+// downloaded resources and their integrity
+func (d *ResourceDownloadWorker) downloaded(source string, target string, hash string, size int) error {
+	sum, err := hex.DecodeString(hash)
+	if err != nil {
+		return fmt.Errorf("invalid sha256 hash %s of %s: %w", hash, target, err)
+	}
+	d.mux.Lock()
+	defer d.mux.Unlock()
+	if d.integrity != nil {
+		d.integrity[target] = "sha256-" + base64.StdEncoding.EncodeToString(sum)
+	}
+	d.resources[source] = Resource{Source: source, Path: target, SHA256: hash, Size: size}
 	return nil
 }
+
+// acquireHost waits for a free download slot of the source host and returns the function releasing it
+func (d *ResourceDownloadWorker) acquireHost(ctx context.Context, source string) (func(), error) {
+	if d.hostDownloads <= 0 {
+		return func() {}, nil
+	}
+	host := source
+	if u, err := url.Parse(source); err == nil && u.Host != "" {
+		host = u.Host
+	}
+	d.mux.Lock()
+	slots, ok := d.hostSlots[host]
+	if !ok {
+		slots = make(chan struct{}, d.hostDownloads)
+		d.hostSlots[host] = slots
+	}
+	d.mux.Unlock()
+	select {
+	case slots <- struct{}{}:
+		return func() { <-slots }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -8,10 +8,21 @@ import (
 	"context"
 	"embed"
 	_ "embed"
+	"encoding/xml"
 	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/gardener/docforge/pkg/buildstate"
+	"github.com/gardener/docforge/pkg/manifest"
 	"github.com/gardener/docforge/pkg/registry"
+	"github.com/gardener/docforge/pkg/registry/registryfakes"
 	"github.com/gardener/docforge/pkg/registry/repositoryhost"
 	"github.com/gardener/docforge/pkg/workers/resourcedownloader"
 	"github.com/gardener/docforge/pkg/writers/writersfakes"
@@ -34,10 +45,12 @@ var _ = Describe("Executing Download", func() {
 		writer *writersfakes.FakeWriter
 		worker *resourcedownloader.ResourceDownloadWorker
 
-		ctx      context.Context
-		source   string
-		target   string
-		document string
+		ctx         context.Context
+		source      string
+		target      string
+		document    string
+		integrity   bool
+		sanitizeSVG bool
 	)
 
 	BeforeEach(func() {
@@ -48,10 +61,12 @@ var _ = Describe("Executing Download", func() {
 		source = "https://github.com/gardener/docforge/blob/master/README.md"
 		target = "fake_target"
 		document = "fake_document"
+		integrity = false
+		sanitizeSVG = false
 	})
 
 	JustBeforeEach(func() {
-		worker, err = resourcedownloader.NewDownloader(r, writer)
+		worker, err = resourcedownloader.NewDownloader(r, writer, resourcedownloader.Options{Integrity: integrity, SanitizeSVG: sanitizeSVG})
 		Expect(worker).NotTo(BeNil())
 		Expect(err).NotTo(HaveOccurred())
 
@@ -69,6 +84,87 @@ var _ = Describe("Executing Download", func() {
 		})
 	})
 
+	Context("target is in a subdirectory", func() {
+		BeforeEach(func() {
+			target = "guides/images/fake_target"
+		})
+		It("writes the resource in the subdirectory", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(writer.WriteCallCount()).To(Equal(1))
+			name, path, _, _, _ := writer.WriteArgsForCall(0)
+			Expect(path).To(Equal("guides/images"))
+			Expect(name).To(Equal("fake_target"))
+		})
+
+		Context("and the source is downloaded to another target", func() {
+			JustBeforeEach(func() {
+				Expect(err).NotTo(HaveOccurred())
+				err = worker.Download(ctx, source, "fake_target", document)
+			})
+			It("writes the resource in both locations", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(writer.WriteCallCount()).To(Equal(2))
+				name, path, _, _, _ := writer.WriteArgsForCall(1)
+				Expect(path).To(Equal(""))
+				Expect(name).To(Equal("fake_target"))
+			})
+		})
+	})
+
+	Context("SVG is downloaded", func() {
+		BeforeEach(func() {
+			source = "https://github.com/gardener/docforge/blob/master/unsafe.svg"
+			target = "unsafe_123456.svg"
+		})
+		It("writes the content unchanged", func() {
+			Expect(err).NotTo(HaveOccurred())
+			content, _ := repo.ReadFile("test/unsafe.svg")
+			_, _, written, _, _ := writer.WriteArgsForCall(0)
+			Expect(written).To(Equal(content))
+		})
+
+		Context("with sanitization", func() {
+			BeforeEach(func() {
+				sanitizeSVG = true
+			})
+			It("strips scripts and keeps the rest of the SVG", func() {
+				Expect(err).NotTo(HaveOccurred())
+				_, _, written, _, _ := writer.WriteArgsForCall(0)
+				Expect(string(written)).To(Equal(`<?xml version="1.0" encoding="UTF-8"?>
+<svg xmlns="http://www.w3.org/2000/svg" xmlns:xlink="http://www.w3.org/1999/xlink" width="10" height="10">
+  <!-- logo -->
+  
+  <a><rect width="10" height="10" fill="#fff"></rect></a>
+  <a href="https://gardener.cloud"><text x="1" y="5">A &amp; B</text></a>
+</svg>
+`))
+				Expect(xml.Unmarshal(written, new(interface{}))).To(Succeed())
+			})
+
+			Context("target without .svg extension", func() {
+				BeforeEach(func() {
+					target = "unsafe_123456"
+				})
+				It("detects the SVG by its content", func() {
+					Expect(err).NotTo(HaveOccurred())
+					_, _, written, _, _ := writer.WriteArgsForCall(0)
+					Expect(string(written)).NotTo(ContainSubstring("alert"))
+				})
+			})
+		})
+	})
+
+	Context("non-SVG is downloaded with sanitization", func() {
+		BeforeEach(func() {
+			sanitizeSVG = true
+		})
+		It("writes the content unchanged", func() {
+			Expect(err).NotTo(HaveOccurred())
+			_, _, written, _, _ := writer.WriteArgsForCall(0)
+			Expect(string(written)).To(Equal("readme content"))
+		})
+	})
+
 	Context("no repo host for source repoHost2://fake_source", func() {
 		BeforeEach(func() {
 			source = "repoHost2://fake_source"
@@ -107,5 +203,165 @@ var _ = Describe("Executing Download", func() {
 		Expect(path).To(Equal(""))
 		Expect(name).To(Equal("fake_target"))
 		Expect(string(content)).To(Equal("readme content"))
+		Expect(worker.Integrity()).To(BeNil())
+	})
+
+	Context("integrity is computed", func() {
+		BeforeEach(func() {
+			integrity = true
+		})
+		It("records the sha256 of the downloaded content", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(worker.Integrity()).To(Equal(map[string]string{"fake_target": "sha256-DeCtRIG42Vubm5zCvqr6rUKo0E3Ly5FJXIzSB82v5Zo="}))
+		})
+	})
+})
+
+var _ = Describe("Retrying download", func() {
+	var (
+		r      *registryfakes.FakeInterface
+		writer *writersfakes.FakeWriter
+		worker *resourcedownloader.ResourceDownloadWorker
+		source string
+	)
+
+	BeforeEach(func() {
+		local := registry.NewRegistry(repositoryhost.NewLocalTest(repo, "https://github.com/gardener/docforge", "test"))
+		r = &registryfakes.FakeInterface{}
+		r.ResourceURLCalls(local.ResourceURL)
+		writer = &writersfakes.FakeWriter{}
+		source = "https://github.com/gardener/docforge/blob/master/README.md"
+	})
+
+	JustBeforeEach(func() {
+		var err error
+		worker, err = resourcedownloader.NewDownloader(r, writer, resourcedownloader.Options{Retries: 2})
+		Expect(err).NotTo(HaveOccurred())
+		worker.RetryBackoff = time.Millisecond
+	})
+
+	It("succeeds after transient failure", func() {
+		r.ReadReturnsOnCall(0, nil, repositoryhost.ErrTransient{Err: errors.New("reading blob fails with HTTP status: 503")})
+		r.ReadReturnsOnCall(1, []byte("readme content"), nil)
+		Expect(worker.Download(context.TODO(), source, "target", "document")).To(Succeed())
+		Expect(r.ReadCallCount()).To(Equal(2))
+		Expect(writer.WriteCallCount()).To(Equal(1))
+	})
+
+	It("fails when retries are exhausted", func() {
+		r.ReadReturns(nil, repositoryhost.ErrTransient{Err: errors.New("reading blob fails with HTTP status: 503")})
+		err := worker.Download(context.TODO(), source, "target", "document")
+		Expect(err).To(MatchError(ContainSubstring("HTTP status: 503")))
+		Expect(r.ReadCallCount()).To(Equal(3))
+		Expect(writer.WriteCallCount()).To(Equal(0))
+	})
+
+	It("doesn't retry permanent failures", func() {
+		r.ReadReturns(nil, repositoryhost.ErrResourceNotFound(source))
+		Expect(worker.Download(context.TODO(), source, "target", "document")).To(Succeed())
+		Expect(r.ReadCallCount()).To(Equal(1))
+	})
+})
+
+var _ = Describe("Limiting downloads per host", func() {
+	It("keeps the concurrent downloads from a host within the limit of the workers", func() {
+		var (
+			mux               sync.Mutex
+			active, maxActive int
+		)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			mux.Lock()
+			active++
+			if active > maxActive {
+				maxActive = active
+			}
+			mux.Unlock()
+			time.Sleep(20 * time.Millisecond)
+			mux.Lock()
+			active--
+			mux.Unlock()
+			_, _ = w.Write([]byte("content"))
+		}))
+		defer server.Close()
+		local := registry.NewRegistry(repositoryhost.NewLocalTest(repo, "https://github.com/gardener/docforge", "test"))
+		r := &registryfakes.FakeInterface{}
+		r.ResourceURLCalls(local.ResourceURL)
+		r.ReadCalls(func(_ context.Context, _ string) ([]byte, error) {
+			resp, err := http.Get(server.URL)
+			if err != nil {
+				return nil, err
+			}
+			defer resp.Body.Close()
+			return []byte("content"), nil
+		})
+		writer := &writersfakes.FakeWriter{}
+		wg := &sync.WaitGroup{}
+		downloader, queue, err := resourcedownloader.New(6, false, wg, r, writer, resourcedownloader.Options{HostDownloads: 2})
+		Expect(err).NotTo(HaveOccurred())
+		queue.Start(context.TODO())
+		for i := 0; i < 12; i++ {
+			Expect(downloader.Schedule("https://github.com/gardener/docforge/blob/master/README.md", fmt.Sprintf("README_%d.md", i), "document")).To(Succeed())
+		}
+		wg.Wait()
+		queue.Stop()
+		Expect(queue.GetErrorList()).To(BeNil())
+		Expect(writer.WriteCallCount()).To(Equal(12))
+		Expect(maxActive).To(BeNumerically(">", 0))
+		Expect(maxActive).To(BeNumerically("<=", 2))
+	})
+})
+
+var _ = Describe("Resuming download", func() {
+	It("skips resources downloaded by the resumed build", func() {
+		r := registry.NewRegistry(repositoryhost.NewLocalTest(repo, "https://github.com/gardener/docforge", "test"))
+		dir, err := os.MkdirTemp("", "resume")
+		Expect(err).NotTo(HaveOccurred())
+		defer os.RemoveAll(dir)
+		stateFile := filepath.Join(dir, "state")
+		source := "https://github.com/gardener/docforge/blob/master/README.md"
+
+		writer := &writersfakes.FakeWriter{}
+		writer.WriteCalls(func(name string, path string, content []byte, _ *manifest.Node, _ []string) error {
+			return os.WriteFile(filepath.Join(dir, path, name), content, 0644)
+		})
+		state, err := buildstate.Load(stateFile, false, dir)
+		Expect(err).NotTo(HaveOccurred())
+		worker, err := resourcedownloader.NewDownloader(r, writer, resourcedownloader.Options{State: state})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(worker.Download(context.TODO(), source, "target", "document")).To(Succeed())
+		Expect(writer.WriteCallCount()).To(Equal(1))
+
+		rerunWriter := &writersfakes.FakeWriter{}
+		resumed, err := buildstate.Load(stateFile, true, dir)
+		Expect(err).NotTo(HaveOccurred())
+		rerun, err := resourcedownloader.NewDownloader(r, rerunWriter, resourcedownloader.Options{State: resumed})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rerun.Download(context.TODO(), source, "target", "document")).To(Succeed())
+		Expect(rerunWriter.WriteCallCount()).To(Equal(0))
+	})
+})
+
+var _ = Describe("Listing resources", func() {
+	It("lists each downloaded resource once with the documents referencing it", func() {
+		r := registry.NewRegistry(repositoryhost.NewLocalTest(repo, "https://github.com/gardener/docforge", "test"))
+		worker, err := resourcedownloader.NewDownloader(r, &writersfakes.FakeWriter{}, resourcedownloader.Options{})
+		Expect(err).NotTo(HaveOccurred())
+		readme := "https://github.com/gardener/docforge/blob/master/README.md"
+		logo := "https://github.com/gardener/docforge/blob/master/logo.png"
+		Expect(worker.Download(context.TODO(), readme, "README_1.md", "doc2.md")).To(Succeed())
+		Expect(worker.Download(context.TODO(), logo, "logo_2.png", "doc1.md")).To(Succeed())
+		Expect(worker.Download(context.TODO(), readme, "README_1.md", "doc1.md")).To(Succeed())
+		Expect(worker.Download(context.TODO(), readme, "README_1.md", "doc2.md")).To(Succeed())
+
+		resources := worker.Resources()
+		Expect(resources).To(Equal([]resourcedownloader.Resource{
+			{Source: readme, Path: "README_1.md", SHA256: "0de0ad4481b8d95b9b9b9cc2beaafaad42a8d04dcbcb91495c8cd207cdafe59a", Size: 14, Documents: []string{"doc1.md", "doc2.md"}},
+			{Source: logo, Path: "logo_2.png", SHA256: "8f8cbb7dcf46e0bc7d53265749a6c17d116093a6ba95e442764060c76fd4a86c", Size: 3, Documents: []string{"doc1.md"}},
+		}))
+		report, err := resourcedownloader.ResourcesReport(resources, true)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(report)).To(Equal("source,path,sha256,size,documents\n" +
+			readme + ",README_1.md,0de0ad4481b8d95b9b9b9cc2beaafaad42a8d04dcbcb91495c8cd207cdafe59a,14,doc1.md doc2.md\n" +
+			logo + ",logo_2.png,8f8cbb7dcf46e0bc7d53265749a6c17d116093a6ba95e442764060c76fd4a86c,3,doc1.md\n"))
 	})
 })
@@ -0,0 +1,67 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package resourcedownloader
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Resource is a resource downloaded by the run
+type Resource struct {
+	// Source is the URL the resource is downloaded from
+	Source string `json:"source"`
+	// Path is the path of the resource in the resources destination
+	Path string `json:"path"`
+	// SHA256 is the hex encoded sha256 hash of the resource content
+	SHA256 string `json:"sha256"`
+	// Size is the size of the resource content in bytes
+	Size int `json:"size"`
+	// Documents are the sources of the documents referencing the resource
+	Documents []string `json:"documents"`
+}
+
+// reference records that the document references the resource source
+func (d *ResourceDownloadWorker) reference(source string, document string) {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+	if !slices.Contains(d.references[source], document) {
+		d.references[source] = append(d.references[source], document)
+	}
+}
+
+// Resources returns the resources downloaded by the run, or by the resumed build, sorted by source
+func (d *ResourceDownloadWorker) Resources() []Resource {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+	resources := make([]Resource, 0, len(d.resources))
+	for source, resource := range d.resources {
+		resource.Documents = slices.Clone(d.references[source])
+		sort.Strings(resource.Documents)
+		resources = append(resources, resource)
+	}
+	sort.Slice(resources, func(i, j int) bool { return resources[i].Source < resources[j].Source })
+	return resources
+}
+
+// ResourcesReport returns the resources as JSON, or as CSV with the documents separated by spaces
+func ResourcesReport(resources []Resource, asCSV bool) ([]byte, error) {
+	if !asCSV {
+		return json.MarshalIndent(resources, "", "  ")
+	}
+	var b bytes.Buffer
+	w := csv.NewWriter(&b)
+	_ = w.Write([]string{"source", "path", "sha256", "size", "documents"})
+	for _, r := range resources {
+		_ = w.Write([]string{r.Source, r.Path, r.SHA256, strconv.Itoa(r.Size), strings.Join(r.Documents, " ")})
+	}
+	w.Flush()
+	return b.Bytes(), w.Error()
+}
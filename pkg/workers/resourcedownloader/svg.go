@@ -0,0 +1,126 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package resourcedownloader
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// isSVG checks whether the resource downloaded as target is an image/svg+xml resource
+func isSVG(target string, content []byte) bool {
+	if strings.EqualFold(path.Ext(target), ".svg") {
+		return true
+	}
+	contentType := http.DetectContentType(content)
+	if !strings.HasPrefix(contentType, "text/xml") && !strings.HasPrefix(contentType, "text/plain") {
+		return false
+	}
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+	for {
+		token, err := decoder.RawToken()
+		if err != nil {
+			return false
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name.Local == "svg"
+		}
+	}
+}
+
+var lineBreaks = regexp.MustCompile(`\s*\n\s*`)
+
+// InlineSVGMarkup returns the markup of the SVG content for inlining it into documents. The scripts and what comes
+// before the svg element, like the XML declaration, are stripped and the lines are joined into a single line of HTML
+func InlineSVGMarkup(content []byte) ([]byte, error) {
+	stripped, err := stripSVGScripts(content)
+	if err != nil {
+		return nil, err
+	}
+	i := bytes.Index(stripped, []byte("<svg"))
+	if i < 0 {
+		return nil, errors.New("content has no svg element")
+	}
+	return lineBreaks.ReplaceAll(bytes.TrimSpace(stripped[i:]), []byte(" ")), nil
+}
+
+// stripSVGScripts removes the script elements, the event handler attributes and the links with
+// javascript: URLs from the SVG content. The rest of the document is written as it is parsed
+func stripSVGScripts(content []byte) ([]byte, error) {
+	var b bytes.Buffer
+	decoder := xml.NewDecoder(bytes.NewReader(content))
+	// depth of the skipped script element, 0 when not in a script element
+	skip := 0
+	for {
+		token, err := decoder.RawToken()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parsing SVG failed: %w", err)
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			if skip > 0 || strings.EqualFold(t.Name.Local, "script") {
+				skip++
+				continue
+			}
+			b.WriteString("<" + qualifiedName(t.Name))
+			for _, attr := range t.Attr {
+				if isScriptAttr(attr) {
+					continue
+				}
+				b.WriteString(" " + qualifiedName(attr.Name) + `="`)
+				_ = xml.EscapeText(&b, []byte(attr.Value))
+				b.WriteString(`"`)
+			}
+			b.WriteString(">")
+		case xml.EndElement:
+			if skip > 0 {
+				skip--
+				continue
+			}
+			b.WriteString("</" + qualifiedName(t.Name) + ">")
+		case xml.CharData:
+			if skip == 0 {
+				b.WriteString(textEscaper.Replace(string(t)))
+			}
+		case xml.Comment:
+			if skip == 0 {
+				b.WriteString("<!--" + string(t) + "-->")
+			}
+		case xml.ProcInst:
+			b.WriteString("<?" + t.Target + " " + string(t.Inst) + "?>")
+		case xml.Directive:
+			b.WriteString("<!" + string(t) + ">")
+		}
+	}
+	return b.Bytes(), nil
+}
+
+// isScriptAttr checks whether the attribute is an event handler or a link with a javascript: URL
+func isScriptAttr(attr xml.Attr) bool {
+	name := strings.ToLower(attr.Name.Local)
+	if strings.HasPrefix(name, "on") {
+		return true
+	}
+	return name == "href" && strings.HasPrefix(strings.ToLower(strings.TrimSpace(attr.Value)), "javascript:")
+}
+
+var textEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+func qualifiedName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return name.Space + ":" + name.Local
+}
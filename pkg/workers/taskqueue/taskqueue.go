@@ -69,6 +69,8 @@ type taskQueue struct {
 	initMux, stopMux sync.Once
 	// synchronization mutex
 	mux sync.Mutex
+	// sendMux is held for reading while sending tasks and for writing while closing the tasks queue
+	sendMux sync.RWMutex
 	// if true the queue is stopped
 	stopped bool
 	// processed tasks count
@@ -117,9 +119,12 @@ func (jq *taskQueue) Start(ctx context.Context) {
 func (jq *taskQueue) Stop() {
 	jq.stopMux.Do(func() {
 		jq.mux.Lock()
-		defer jq.mux.Unlock()
 		klog.V(6).Infof("stopping %s queue\n", jq.id)
 		jq.stopped = true
+		jq.mux.Unlock()
+		// wait for the tasks being sent, no task is sent once stopped is set
+		jq.sendMux.Lock()
+		defer jq.sendMux.Unlock()
 		close(jq.tasks)
 	})
 }
@@ -128,13 +133,8 @@ func (jq *taskQueue) Stop() {
 // returns true if the task is added and false if it is skipped
 // (e.g. if the taskQueue is stopped or failFast situation)
 func (jq *taskQueue) AddTask(task interface{}) bool {
-	defer func() {
-		if recover() != nil {
-			// decrease wait group counter
-			jq.wg.Done()
-			klog.V(6).Infof("recover adding task %v in closed %s queue\n", task, jq.id)
-		}
-	}()
+	jq.sendMux.RLock()
+	defer jq.sendMux.RUnlock()
 	if jq.shouldProcess() {
 		jq.wg.Add(1)
 		jq.tasks <- task
@@ -156,7 +156,7 @@ func (jq *taskQueue) Name() string {
 
 // GetProcessedTasksCount returns the processed tasks count
 func (jq *taskQueue) GetProcessedTasksCount() int {
-	return int(jq.tc)
+	return int(atomic.LoadUint32(&jq.tc))
 }
 
 // GetWaitingTasksCount returns waiting tasks count
@@ -167,12 +167,15 @@ func (jq *taskQueue) GetWaitingTasksCount() int {
 // worker's goroutines call work to process tasks from the tasks queue in a loop
 // if context is canceled trigger taskQueue stop
 func (jq *taskQueue) work(ctx context.Context) {
+	done := ctx.Done()
 	for {
 		select {
-		case <-ctx.Done():
+		case <-done:
 			{
 				klog.V(6).Infof("context is done for %s queue\n", jq.id)
-				jq.Stop()
+				// stop in separate goroutine, as the tasks being sent are drained until the queue is closed
+				go jq.Stop()
+				done = nil
 			}
 		case t, ok := <-jq.tasks:
 			{
@@ -116,6 +116,27 @@ var _ = Describe("Jobs", func() {
 			Expect(queue.AddTask(&task{})).To(BeFalse())
 		})
 	})
+	When("stopping the JobQueue while tasks are added", func() {
+		JustBeforeEach(func() {
+			queue.Start(ctx)
+		})
+		It("waits for the added tasks and skips the next ones", func() {
+			var adding sync.WaitGroup
+			for i := 0; i < 10; i++ {
+				adding.Add(1)
+				go func() {
+					defer adding.Done()
+					for j := 0; j < 100; j++ {
+						queue.AddTask(struct{}{})
+					}
+				}()
+			}
+			queue.Stop()
+			adding.Wait()
+			wg.Wait()
+			Expect(queue.AddTask(struct{}{})).To(BeFalse())
+		})
+	})
 	When("fail fast strategy is set", func() {
 		BeforeEach(func() {
 			failFast = true
@@ -6,10 +6,13 @@ package writers
 
 import (
 	"bytes"
+	"crypto/sha256"
 	"fmt"
 	"os"
 	"path/filepath"
 	"slices"
+	"sync/atomic"
+	"time"
 
 	"github.com/gardener/docforge/pkg/manifest"
 	"gopkg.in/yaml.v3"
@@ -20,37 +23,83 @@ type FSWriter struct {
 	Root string
 	Ext  string
 	Hugo bool
+	// SkipUnchanged skips writing files whose content has the same sha256 hash as the existing file, keeping its mtime
+	SkipUnchanged bool
+
+	unchanged atomic.Int64
+}
+
+// Unchanged returns the number of files not written because their content didn't change
+func (f *FSWriter) Unchanged() int {
+	return int(f.unchanged.Load())
 }
 
 func (f *FSWriter) Write(name, path string, docBlob []byte, node *manifest.Node, IndexFileNames []string) error {
-	if slices.Contains(IndexFileNames, name) {
-		name = "_index.md"
+	name, docBlob, err := fileOf(name, docBlob, node, IndexFileNames, f.Hugo, f.Ext)
+	if err != nil {
+		return err
+	}
+	p := filepath.Join(f.Root, path)
+	if len(docBlob) == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(p, os.ModePerm); err != nil {
+		return err
+	}
+	filePath := filepath.Join(p, name)
+	if f.SkipUnchanged && unchanged(filePath, docBlob) {
+		f.unchanged.Add(1)
+		return nil
+	}
+	if err := os.WriteFile(filePath, docBlob, 0644); err != nil {
+		return fmt.Errorf("error writing %s: %v", filePath, err)
+	}
+	return nil
+}
+
+// SetModTime sets the modification time of the file written for a blob. Blobs that weren't written are skipped
+func (f *FSWriter) SetModTime(name, path string, IndexFileNames []string, modTime time.Time) error {
+	filePath := filepath.Join(f.Root, path, fileName(name, IndexFileNames, f.Ext))
+	if err := os.Chtimes(filePath, modTime, modTime); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error setting the modification time of %s: %v", filePath, err)
 	}
+	return nil
+}
+
+// fileOf returns the name and the content of the file written for a blob. Index files are named _index.md,
+// and with Hugo section files without content get the frontmatter of the node
+func fileOf(name string, docBlob []byte, node *manifest.Node, IndexFileNames []string, hugo bool, ext string) (string, []byte, error) {
 	//generate _index.md content
-	if f.Hugo && name == "_index.md" && node != nil && node.Frontmatter != nil && docBlob == nil {
+	if hugo && fileName(name, IndexFileNames, "") == "_index.md" && node != nil && node.Frontmatter != nil && docBlob == nil {
 		buf := bytes.Buffer{}
 		_, _ = buf.Write([]byte("---\n"))
 		fm, err := yaml.Marshal(node.Frontmatter)
 		if err != nil {
-			return err
+			return "", nil, err
 		}
 		_, _ = buf.Write(fm)
 		_, _ = buf.Write([]byte("---\n"))
 		docBlob = buf.Bytes()
 	}
-	p := filepath.Join(f.Root, path)
-	if len(docBlob) == 0 {
-		return nil
-	}
-	if err := os.MkdirAll(p, os.ModePerm); err != nil {
-		return err
+	return fileName(name, IndexFileNames, ext), docBlob, nil
+}
+
+// fileName returns the name of the file written for a blob
+func fileName(name string, IndexFileNames []string, ext string) string {
+	if slices.Contains(IndexFileNames, name) {
+		name = "_index.md"
 	}
-	if len(f.Ext) > 0 {
-		name = fmt.Sprintf("%s.%s", name, f.Ext)
+	if len(ext) > 0 {
+		name = fmt.Sprintf("%s.%s", name, ext)
 	}
-	filePath := filepath.Join(p, name)
-	if err := os.WriteFile(filePath, docBlob, 0644); err != nil {
-		return fmt.Errorf("error writing %s: %v", filePath, err)
+	return name
+}
+
+// unchanged checks whether the file exists with content of the same sha256 hash
+func unchanged(filePath string, docBlob []byte) bool {
+	existing, err := os.ReadFile(filePath)
+	if err != nil {
+		return false
 	}
-	return nil
+	return sha256.Sum256(existing) == sha256.Sum256(docBlob)
 }
@@ -9,7 +9,9 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/gardener/docforge/pkg/manifest"
 	"github.com/google/uuid"
@@ -78,3 +80,105 @@ func TestWrite(t *testing.T) {
 		})
 	}
 }
+
+func TestWriteSkipsUnchanged(t *testing.T) {
+	testPath, err := os.MkdirTemp("", "unchanged")
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+	defer os.RemoveAll(testPath)
+	fs := &FSWriter{
+		Root:          testPath,
+		SkipUnchanged: true,
+	}
+	fPath := filepath.Join(testPath, "a", "test.md")
+	if err = fs.Write("test.md", "a", []byte("# Test"), nil, nil); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	mtime := time.Now().Add(-time.Hour).Truncate(time.Second)
+	if err = os.Chtimes(fPath, mtime, mtime); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if err = fs.Write("test.md", "a", []byte("# Test"), nil, nil); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	info, err := os.Stat(fPath)
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+	if !info.ModTime().Equal(mtime) {
+		t.Errorf("expected unchanged file to keep mtime %v, got %v", mtime, info.ModTime())
+	}
+	if fs.Unchanged() != 1 {
+		t.Errorf("expected 1 unchanged file, got %d", fs.Unchanged())
+	}
+
+	if err = fs.Write("test.md", "a", []byte("# Changed"), nil, nil); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if b, _ := os.ReadFile(fPath); string(b) != "# Changed" {
+		t.Errorf("expected changed content to be written, got %s", b)
+	}
+	if fs.Unchanged() != 1 {
+		t.Errorf("expected 1 unchanged file, got %d", fs.Unchanged())
+	}
+}
+
+func TestWriteSkipsUnchangedConcurrently(t *testing.T) {
+	testPath, err := os.MkdirTemp("", "unchanged")
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+	defer os.RemoveAll(testPath)
+	fs := &FSWriter{
+		Root:          testPath,
+		SkipUnchanged: true,
+	}
+	const files = 20
+	for i := 0; i < files; i++ {
+		if err = fs.Write(fmt.Sprintf("%d.md", i), "", []byte("# Test"), nil, nil); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < files; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := fs.Write(fmt.Sprintf("%d.md", i), "", []byte("# Test"), nil, nil); err != nil {
+				t.Errorf("unexpected error %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if fs.Unchanged() != files {
+		t.Errorf("expected %d unchanged files, got %d", files, fs.Unchanged())
+	}
+}
+
+func TestSetModTime(t *testing.T) {
+	testPath, err := os.MkdirTemp("", "modtime")
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+	defer os.RemoveAll(testPath)
+	fs := &FSWriter{Root: testPath}
+	if err = fs.Write("README.md", "a", []byte("# A"), &manifest.Node{}, []string{"README.md"}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	lastmod := time.Date(2024, 2, 7, 13, 11, 0, 0, time.UTC)
+	if err = fs.SetModTime("README.md", "a", []string{"README.md"}, lastmod); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	info, err := os.Stat(filepath.Join(testPath, "a", "_index.md"))
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+	if !info.ModTime().Equal(lastmod) {
+		t.Errorf("expected mtime %v, got %v", lastmod, info.ModTime())
+	}
+	if err = fs.SetModTime("missing.md", "a", nil, lastmod); err != nil {
+		t.Errorf("expected files not written to be skipped, got %v", err)
+	}
+}
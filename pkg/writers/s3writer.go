@@ -0,0 +1,71 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package writers
+
+import (
+	"fmt"
+	"mime"
+	"path"
+
+	"github.com/gardener/docforge/pkg/manifest"
+)
+
+// S3Client puts objects into the buckets of an S3-compatible object storage
+//
+//counterfeiter:generate . S3Client
+type S3Client interface {
+	PutObject(bucket, key string, body []byte, contentType string) error
+}
+
+// contentTypes are the content types of the extensions not known by all mime type tables
+var contentTypes = map[string]string{
+	".md":       "text/markdown; charset=utf-8",
+	".markdown": "text/markdown; charset=utf-8",
+	".json":     "application/json",
+	".html":     "text/html; charset=utf-8",
+	".svg":      "image/svg+xml",
+}
+
+// S3Writer is implementation of Writer interface for writing blobs as objects of an S3 bucket
+type S3Writer struct {
+	Client S3Client
+	Bucket string
+	// Prefix is the key prefix the blobs are written under
+	Prefix string
+	Ext    string
+	Hugo   bool
+}
+
+// In returns a writer of the same bucket writing blobs under prefix
+func (s *S3Writer) In(prefix string) *S3Writer {
+	return &S3Writer{Client: s.Client, Bucket: s.Bucket, Prefix: path.Join(s.Prefix, prefix)}
+}
+
+func (s *S3Writer) Write(name, filePath string, docBlob []byte, node *manifest.Node, IndexFileNames []string) error {
+	name, docBlob, err := fileOf(name, docBlob, node, IndexFileNames, s.Hugo, s.Ext)
+	if err != nil {
+		return err
+	}
+	if len(docBlob) == 0 {
+		return nil
+	}
+	key := path.Join(s.Prefix, filePath, name)
+	if err := s.Client.PutObject(s.Bucket, key, docBlob, contentType(name)); err != nil {
+		return fmt.Errorf("error writing %s to bucket %s: %v", key, s.Bucket, err)
+	}
+	return nil
+}
+
+// contentType infers the content type of the file from its extension
+func contentType(name string) string {
+	ext := path.Ext(name)
+	if t, ok := contentTypes[ext]; ok {
+		return t
+	}
+	if t := mime.TypeByExtension(ext); t != "" {
+		return t
+	}
+	return "application/octet-stream"
+}
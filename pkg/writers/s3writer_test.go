@@ -0,0 +1,75 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package writers_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/gardener/docforge/pkg/manifest"
+	"github.com/gardener/docforge/pkg/writers"
+	"github.com/gardener/docforge/pkg/writers/writersfakes"
+)
+
+func TestS3Write(t *testing.T) {
+	client := &writersfakes.FakeS3Client{}
+	bucket := &writers.S3Writer{Client: client, Bucket: "docs", Prefix: "site"}
+	documents := bucket.In("")
+	documents.Hugo = true
+	resources := bucket.In("__resources")
+	gitInfo := bucket.In("gitinfo")
+	gitInfo.Ext = "json"
+	writes := []struct {
+		w       *writers.S3Writer
+		name    string
+		path    string
+		docBlob []byte
+		node    *manifest.Node
+	}{
+		{documents, "test.md", "a/b", []byte("# Test"), &manifest.Node{}},
+		{documents, "page.html", "a", []byte("<p>page</p>"), &manifest.Node{}},
+		{documents, "empty.md", "a", nil, &manifest.Node{}},
+		{resources, "logo.png", "", []byte("png"), nil},
+		{resources, "data.bin", "", []byte("bin"), nil},
+		{gitInfo, "test.md", "a/b", []byte("{}"), nil},
+	}
+	for _, w := range writes {
+		if err := w.w.Write(w.name, w.path, w.docBlob, w.node, []string{"README.md"}); err != nil {
+			t.Fatalf("unexpected error writing %s: %v", w.name, err)
+		}
+	}
+	want := []struct {
+		key         string
+		body        string
+		contentType string
+	}{
+		{"site/a/b/test.md", "# Test", "text/markdown; charset=utf-8"},
+		{"site/a/page.html", "<p>page</p>", "text/html; charset=utf-8"},
+		{"site/__resources/logo.png", "png", "image/png"},
+		{"site/__resources/data.bin", "bin", "application/octet-stream"},
+		{"site/gitinfo/a/b/test.md.json", "{}", "application/json"},
+	}
+	if client.PutObjectCallCount() != len(want) {
+		t.Fatalf("expected %d objects, got %d", len(want), client.PutObjectCallCount())
+	}
+	for i, w := range want {
+		b, key, body, contentType := client.PutObjectArgsForCall(i)
+		if b != "docs" || key != w.key || string(body) != w.body || contentType != w.contentType {
+			t.Errorf("expected object %s in bucket docs with body %q and content type %s, got %s in bucket %s with body %q and content type %s",
+				w.key, w.body, w.contentType, key, b, body, contentType)
+		}
+	}
+}
+
+func TestS3WriteError(t *testing.T) {
+	client := &writersfakes.FakeS3Client{}
+	client.PutObjectReturns(errors.New("access denied"))
+	s := &writers.S3Writer{Client: client, Bucket: "docs"}
+	err := s.Write("test.md", "a", []byte("# Test"), &manifest.Node{}, nil)
+	if err == nil || !strings.Contains(err.Error(), "error writing a/test.md to bucket docs: access denied") {
+		t.Errorf("expected put error, got %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package writers
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/gardener/docforge/pkg/manifest"
+)
+
+// TarWriter is implementation of Writer interface for writing blobs as files of a tar archive
+type TarWriter struct {
+	// Root is the path in the archive the blobs are written under
+	Root string
+	Ext  string
+	Hugo bool
+
+	archive *tarArchive
+}
+
+// tarArchive is the tar stream shared by the writers of an archive
+type tarArchive struct {
+	mux     sync.Mutex
+	tw      *tar.Writer
+	gw      *gzip.Writer
+	modTime time.Time
+}
+
+// NewTarWriter creates a writer of a tar archive streamed to w, gzipped if gzipped is set.
+// The archive is complete when the writer is closed
+func NewTarWriter(w io.Writer, gzipped bool) *TarWriter {
+	a := &tarArchive{modTime: time.Now()}
+	if gzipped {
+		a.gw = gzip.NewWriter(w)
+		w = a.gw
+	}
+	a.tw = tar.NewWriter(w)
+	return &TarWriter{archive: a}
+}
+
+// In returns a writer of the same archive writing blobs under root
+func (t *TarWriter) In(root string) *TarWriter {
+	return &TarWriter{Root: path.Join(t.Root, root), archive: t.archive}
+}
+
+func (t *TarWriter) Write(name, filePath string, docBlob []byte, node *manifest.Node, IndexFileNames []string) error {
+	name, docBlob, err := fileOf(name, docBlob, node, IndexFileNames, t.Hugo, t.Ext)
+	if err != nil {
+		return err
+	}
+	if len(docBlob) == 0 {
+		return nil
+	}
+	header := &tar.Header{
+		Name:     path.Join(t.Root, filePath, name),
+		Typeflag: tar.TypeReg,
+		Mode:     0644,
+		Size:     int64(len(docBlob)),
+		ModTime:  t.archive.modTime,
+	}
+	t.archive.mux.Lock()
+	defer t.archive.mux.Unlock()
+	if err := t.archive.tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("error writing %s: %v", header.Name, err)
+	}
+	if _, err := t.archive.tw.Write(docBlob); err != nil {
+		return fmt.Errorf("error writing %s: %v", header.Name, err)
+	}
+	return nil
+}
+
+// Close finishes the archive. It doesn't close the underlying writer
+func (t *TarWriter) Close() error {
+	t.archive.mux.Lock()
+	defer t.archive.mux.Unlock()
+	if err := t.archive.tw.Close(); err != nil {
+		return err
+	}
+	if t.archive.gw != nil {
+		return t.archive.gw.Close()
+	}
+	return nil
+}
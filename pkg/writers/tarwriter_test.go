@@ -0,0 +1,83 @@
+// SPDX-FileCopyrightText: 2023 SAP SE or an SAP affiliate company and Gardener contributors
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package writers
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/gardener/docforge/pkg/manifest"
+)
+
+func TestTarWrite(t *testing.T) {
+	for _, gzipped := range []bool{false, true} {
+		var b bytes.Buffer
+		tw := NewTarWriter(&b, gzipped)
+		documents := tw.In("")
+		documents.Hugo = true
+		resources := tw.In("__resources")
+		gitInfo := tw.In("gitinfo")
+		gitInfo.Ext = "json"
+		writes := []struct {
+			w       *TarWriter
+			name    string
+			path    string
+			docBlob []byte
+			node    *manifest.Node
+		}{
+			{documents, "test.md", "a/b", []byte("# Test"), &manifest.Node{}},
+			{documents, "README.md", "a", nil, &manifest.Node{Frontmatter: map[string]interface{}{"title": "A"}}},
+			{documents, "empty.md", "a", nil, &manifest.Node{}},
+			{resources, "logo.png", "", []byte("png"), nil},
+			{gitInfo, "test.md", "a/b", []byte("{}"), nil},
+		}
+		for _, w := range writes {
+			if err := w.w.Write(w.name, w.path, w.docBlob, w.node, []string{"README.md"}); err != nil {
+				t.Fatalf("unexpected error writing %s: %v", w.name, err)
+			}
+		}
+		if err := tw.Close(); err != nil {
+			t.Fatalf("unexpected error closing archive: %v", err)
+		}
+		var r io.Reader = &b
+		if gzipped {
+			gr, err := gzip.NewReader(&b)
+			if err != nil {
+				t.Fatalf("archive isn't gzipped: %v", err)
+			}
+			r = gr
+		}
+		files := map[string]string{}
+		tr := tar.NewReader(r)
+		for {
+			h, err := tr.Next()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				t.Fatalf("unexpected error reading archive: %v", err)
+			}
+			content, err := io.ReadAll(tr)
+			if err != nil {
+				t.Fatalf("unexpected error reading %s: %v", h.Name, err)
+			}
+			files[h.Name] = string(content)
+		}
+		want := map[string]string{
+			"a/b/test.md":              "# Test",
+			"a/_index.md":              "---\ntitle: A\n---\n",
+			"__resources/logo.png":     "png",
+			"gitinfo/a/b/test.md.json": "{}",
+		}
+		if !reflect.DeepEqual(files, want) {
+			t.Errorf("expected archive files %v != %v", want, files)
+		}
+	}
+}